@@ -27,6 +27,8 @@ func main() {
 		exit(fmt.Errorf("start service: %w", err))
 	}
 
+	go reloadOnHangup(svc)
+
 	go func() {
 		stop := make(chan os.Signal, 1)
 		signal.Notify(stop, syscall.SIGINT, syscall.SIGTERM)
@@ -54,6 +56,17 @@ func waitForShutdown(svc *app.App) {
 	fmt.Println("central-unit stopped")
 }
 
+// reloadOnHangup reloads the configuration on every SIGHUP
+func reloadOnHangup(svc *app.App) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	for range hup {
+		if err := svc.Reload(); err != nil {
+			fmt.Fprintf(os.Stderr, "reload config: %v\n", err)
+		}
+	}
+}
+
 func exit(err error) {
 	fmt.Fprintf(os.Stderr, "fatal: %v\n", err)
 	os.Exit(1)
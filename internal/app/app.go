@@ -7,16 +7,16 @@ import (
 	"central-unit/internal/common/logger"
 	cucontext "central-unit/internal/context"
 	"central-unit/pkg/config"
-	"central-unit/pkg/model"
 )
 
 // App represents the CU-CP application
 type App struct {
-	cfg    config.Config
-	logger *logger.Logger
-	cuCtx  *cucontext.CuCpContext
-	ctx    context.Context
-	cancel context.CancelFunc
+	cfg     config.Config
+	cfgPath string
+	logger  *logger.Logger
+	cuCtx   *cucontext.CuCpContext
+	ctx     context.Context
+	cancel  context.CancelFunc
 }
 
 // New creates a new App instance
@@ -40,10 +40,11 @@ func New(cfgPath string) (*App, error) {
 	// Create app instance
 	ctx, cancel := context.WithCancel(context.Background())
 	app := &App{
-		cfg:    cfg,
-		logger: log,
-		ctx:    ctx,
-		cancel: cancel,
+		cfg:     cfg,
+		cfgPath: cfgPath,
+		logger:  log,
+		ctx:     ctx,
+		cancel:  cancel,
 	}
 
 	return app, nil
@@ -53,15 +54,8 @@ func New(cfgPath string) (*App, error) {
 func (a *App) Start() error {
 	a.logger.Info("Starting CU-CP application %s", a.cfg.CUCP.NodeName)
 
-	// Initialize CU-CP context with config
-	// Convert config to model.AMF for initialization
-	amf := model.AMF{
-		Ip:   a.cfg.NGAP.AMFAddress,
-		Port: a.cfg.NGAP.AMFPort,
-	}
-
 	// Initialize CU-CP context
-	cuCtx := cucontext.InitContext(amf, a.cfg)
+	cuCtx := cucontext.InitContext(a.cfg)
 
 	// Update context
 	cuCtx.Ctx = a.ctx
@@ -71,6 +65,25 @@ func (a *App) Start() error {
 	return nil
 }
 
+// Reload reads the configuration file again and applies what can change
+// while running
+func (a *App) Reload() error {
+	cfg, err := config.Load(a.cfgPath)
+	if err != nil {
+		return fmt.Errorf("load config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	a.cfg = cfg
+
+	if a.cuCtx != nil {
+		a.cuCtx.ReloadConfig(cfg)
+	}
+	a.logger.Info("Configuration reloaded from %s", a.cfgPath)
+	return nil
+}
+
 // Stop stops the CU-CP application gracefully
 func (a *App) Stop(ctx context.Context) error {
 	a.logger.Info("Stopping CU-CP application")
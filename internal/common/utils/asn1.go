@@ -40,8 +40,8 @@ func Build5GSTMSI(part2, part1 aper.BitString) []byte {
 // Input: tmsi - 6-byte big-endian representation of 5G-S-TMSI
 // Returns:
 //
-//	amfSetID: 2-byte big-endian (10-bit value, zero-extended)
-//	amfPointer: 1-byte (6-bit value)
+//	amfSetID: 10-bit BIT STRING, left-aligned in 2 bytes
+//	amfPointer: 6-bit BIT STRING, left-aligned in 1 byte
 //	fivegTMSI: 4-byte big-endian (32-bit TMSI)
 func Decode5GSTMSI(tmsi []byte) (*ies.FiveGSTMSI, error) {
 	if len(tmsi) != 6 {
@@ -59,16 +59,16 @@ func Decode5GSTMSI(tmsi []byte) (*ies.FiveGSTMSI, error) {
 	fivegTMSIVal := uint32(val)            // 32 bits
 
 	amfSetID := make([]byte, 2)
-	binary.BigEndian.PutUint16(amfSetID, amfSetIDVal)
+	binary.BigEndian.PutUint16(amfSetID, amfSetIDVal<<6)
 
-	amfPointer := []byte{amfPtrVal}
+	amfPointer := []byte{amfPtrVal << 2}
 
 	fivegTMSI := make([]byte, 4)
 	binary.BigEndian.PutUint32(fivegTMSI, fivegTMSIVal)
 
 	return &ies.FiveGSTMSI{
 		AMFSetID:   aper.BitString{Bytes: amfSetID, NumBits: 10},
-		AMFPointer: aper.BitString{Bytes: amfPointer, NumBits: 9},
+		AMFPointer: aper.BitString{Bytes: amfPointer, NumBits: 6},
 		FiveGTMSI:  fivegTMSI,
 	}, nil
 }
@@ -0,0 +1,196 @@
+package context
+
+import (
+	"central-unit/internal/common/utils"
+	"central-unit/internal/context/amfcontext"
+	"fmt"
+	"math/rand/v2"
+
+	"github.com/lvdund/ngap/aper"
+	"github.com/lvdund/ngap/ies"
+	rrcies "github.com/lvdund/rrc/ies"
+)
+
+// SelectAMF picks the AMF of a new UE (TS 23.501 6.3.5). A UE with a
+// 5G-S-TMSI goes back to the AMF of its AMF Set and Pointer, to the backup AMF
+// of an unavailable GUAMI, or else to another AMF of the same set. Any other
+// UE is spread by the relative capacity of the AMFs. Overloaded AMFs are only
+// used when no other AMF is left, and only for the establishment causes their
+// overload action permits.
+func (cu *CuCpContext) SelectAMF(tmsi *ies.FiveGSTMSI, cause rrcies.EstablishmentCause) (*amfcontext.GNBAmf, error) {
+	var active, overloaded []*amfcontext.GNBAmf
+	refused := 0
+	cu.AmfPool.Range(func(_, value any) bool {
+		amf, ok := value.(*amfcontext.GNBAmf)
+		if !ok || !hasAvailableGuami(amf) {
+			return true
+		}
+		switch amf.State {
+		case amfcontext.AMF_ACTIVE:
+			active = append(active, amf)
+		case amfcontext.AMF_OVERLOADED:
+			if admitsUnderOverload(amf.Overload, cause) {
+				overloaded = append(overloaded, amf)
+			} else {
+				refused++
+			}
+		}
+		return true
+	})
+
+	candidates := active
+	if len(candidates) == 0 {
+		candidates = overloaded
+	}
+	if len(candidates) == 0 {
+		if refused > 0 {
+			return nil, fmt.Errorf("%d AMF(s) overloaded", refused)
+		}
+		return nil, fmt.Errorf("no active AMF available")
+	}
+
+	if tmsi != nil {
+		if amf := cu.amfOfTmsi(tmsi, append(active, overloaded...)); amf != nil {
+			return amf, nil
+		}
+		cu.Info("No AMF serves AMF set %d, selecting by capacity", amfSetId(tmsi.AMFSetID))
+	}
+	return weightedAmf(candidates), nil
+}
+
+// amfOfTmsi looks among the candidates for the AMF a 5G-S-TMSI belongs to
+func (cu *CuCpContext) amfOfTmsi(tmsi *ies.FiveGSTMSI, candidates []*amfcontext.GNBAmf) *amfcontext.GNBAmf {
+	setId, pointer := amfSetId(tmsi.AMFSetID), amfPointer(tmsi.AMFPointer)
+
+	// the AMF of an unavailable GUAMI may have left the candidates already
+	_, guami := cu.findGuami(func(g *amfcontext.Guami) bool {
+		return amfSetId(g.SetId) == setId && amfPointer(g.Pointer) == pointer
+	})
+	if guami != nil && guami.Unavailable && guami.BackupAMF != "" {
+		for _, amf := range candidates {
+			if amf.Name == guami.BackupAMF {
+				return amf
+			}
+		}
+		cu.Info("Backup AMF %s of AMF set %d pointer %d unavailable", guami.BackupAMF, setId, pointer)
+	}
+
+	var sameSet []*amfcontext.GNBAmf
+	for _, amf := range candidates {
+		for _, g := range amf.ServedGuamis {
+			if g.Unavailable || amfSetId(g.SetId) != setId {
+				continue
+			}
+			if amfPointer(g.Pointer) == pointer {
+				return amf
+			}
+			sameSet = append(sameSet, amf)
+			break
+		}
+	}
+	if len(sameSet) > 0 {
+		cu.Info("AMF set %d pointer %d unavailable, selecting another AMF of the set", setId, pointer)
+		return weightedAmf(sameSet)
+	}
+	return nil
+}
+
+// findGuami returns the first served GUAMI that matches, with its AMF
+func (cu *CuCpContext) findGuami(match func(*amfcontext.Guami) bool) (*amfcontext.GNBAmf, *amfcontext.Guami) {
+	var found *amfcontext.GNBAmf
+	var guami *amfcontext.Guami
+	cu.AmfPool.Range(func(_, value any) bool {
+		amf, ok := value.(*amfcontext.GNBAmf)
+		if !ok {
+			return true
+		}
+		for i := range amf.ServedGuamis {
+			if match(&amf.ServedGuamis[i]) {
+				found, guami = amf, &amf.ServedGuamis[i]
+				return false
+			}
+		}
+		return true
+	})
+	return found, guami
+}
+
+// hasAvailableGuami tells whether an AMF still takes new UEs, those without
+// any GUAMI included
+func hasAvailableGuami(amf *amfcontext.GNBAmf) bool {
+	for _, guami := range amf.ServedGuamis {
+		if !guami.Unavailable {
+			return true
+		}
+	}
+	return len(amf.ServedGuamis) == 0
+}
+
+// admitsUnderOverload applies the Overload Start of an AMF to an RRC
+// establishment cause (TS 38.413 8.7.6)
+func admitsUnderOverload(overload *amfcontext.Overload, cause rrcies.EstablishmentCause) bool {
+	if overload == nil || cause.Value == rrcies.EstablishmentCause_Enum_emergency {
+		return true
+	}
+	if overload.Action != nil && !overloadActionPermits(*overload.Action, cause) {
+		return false
+	}
+	return overload.Reduction == 0 || rand.Int64N(100) >= overload.Reduction
+}
+
+func overloadActionPermits(action aper.Enumerated, cause rrcies.EstablishmentCause) bool {
+	switch cause.Value {
+	case rrcies.EstablishmentCause_Enum_mt_Access:
+		return true
+	case rrcies.EstablishmentCause_Enum_highPriorityAccess,
+		rrcies.EstablishmentCause_Enum_mps_PriorityAccess,
+		rrcies.EstablishmentCause_Enum_mcs_PriorityAccess:
+		return action != ies.OverloadActionPermitemergencysessionsandmobileterminatedservicesonly
+	case rrcies.EstablishmentCause_Enum_mo_Signalling:
+		return action == ies.OverloadActionRejectnonemergencymodt
+	default:
+		// mobile originated data, voice, video and SMS
+		return false
+	}
+}
+
+// weightedAmf draws an AMF with a probability proportional to its relative
+// capacity, evenly when none is announced
+func weightedAmf(amfs []*amfcontext.GNBAmf) *amfcontext.GNBAmf {
+	var total int64
+	for _, amf := range amfs {
+		total += amf.RelativeAmfCapacity
+	}
+	if total == 0 {
+		return amfs[rand.IntN(len(amfs))]
+	}
+	n := rand.Int64N(total)
+	for _, amf := range amfs {
+		if n < amf.RelativeAmfCapacity {
+			return amf
+		}
+		n -= amf.RelativeAmfCapacity
+	}
+	return amfs[len(amfs)-1]
+}
+
+func amfRegionId(regionId aper.BitString) uint64 {
+	if len(regionId.Bytes) == 0 {
+		return 0
+	}
+	return uint64(regionId.Bytes[0])
+}
+
+func amfSetId(setId aper.BitString) uint64 {
+	if len(setId.Bytes) == 0 {
+		return 0
+	}
+	return utils.BitStringToUint64(&setId)
+}
+
+func amfPointer(pointer aper.BitString) uint64 {
+	if len(pointer.Bytes) == 0 {
+		return 0
+	}
+	return utils.BitStringToUint64(&pointer)
+}
@@ -4,8 +4,10 @@ import (
 	"central-unit/internal/common/logger"
 	"central-unit/internal/transport"
 	"fmt"
+	"time"
 
 	"github.com/lvdund/ngap/aper"
+	"github.com/lvdund/ngap/ies"
 )
 
 // AMF main states in the GNB Context.
@@ -19,6 +21,7 @@ type GNBAmf struct {
 	*logger.Logger
 	AmfIp               string         // AMF ip
 	AmfPort             int            // AMF port
+	LocalPort           int            // local SCTP port of the association
 	AmfId               int64          // AMF id
 	Tnla                TNLAssociation // AMF sctp associations
 	RelativeAmfCapacity int64          // AMF capacity
@@ -32,11 +35,37 @@ type GNBAmf struct {
 	LenSlice            int
 	LenPlmn             int
 	BackupAMF           string
+	ServedGuamis        []Guami
+	NgSetupBackoff      time.Duration // wait before the next NG Setup attempt
+	NgSetupTimer        *time.Timer   // retries a failed NG Setup
+	Overload            *Overload     // set between Overload Start and Stop
+	RanConfigTimer      *time.Timer   // retries a rejected RAN Configuration Update
+
+	// associations added by AMF Configuration Update, besides Tnla
+	ExtraTnlas []*TNLAssociation
 	// TODO implement the other fields of the AMF Context
 }
 
+// Guami is a GUAMI served by the AMF, as listed in its NG Setup Response
+type Guami struct {
+	Plmn      string
+	RegionId  aper.BitString
+	SetId     aper.BitString
+	Pointer   aper.BitString
+	BackupAMF string
+	// set by AMF Status Indication, new UEs go to the backup AMF instead
+	Unavailable bool
+}
+
+// Overload is what an AMF asked for in Overload Start
+type Overload struct {
+	Action    *aper.Enumerated // establishment causes still permitted, all of them when nil
+	Reduction int64            // percentage of the permitted establishments to reject
+}
+
 type TNLAssociation struct {
 	SctpConn         *transport.SctpConn
+	Address          []byte // AMF endpoint, only known for added associations
 	TnlaWeightFactor int64
 	Usage            aper.Enumerated
 	Streams          uint16
@@ -155,6 +184,44 @@ func (amf *GNBAmf) AddedSlice(sst string, sd string) {
 	amf.LenSlice++
 }
 
+// IsSetUp tells whether NG Setup succeeded on the current association,
+// overloaded AMFs included
+func (amf *GNBAmf) IsSetUp() bool {
+	return amf.State == AMF_ACTIVE || amf.State == AMF_OVERLOADED
+}
+
+// SetServedGuamis replaces the served GUAMIs, the first one identifying the
+// AMF
+func (amf *GNBAmf) SetServedGuamis(items []ies.ServedGUAMIItem) {
+	amf.ServedGuamis = amf.ServedGuamis[:0]
+	for _, item := range items {
+		amf.ServedGuamis = append(amf.ServedGuamis, Guami{
+			Plmn:      fmt.Sprintf("%x", item.GUAMI.PLMNIdentity),
+			RegionId:  item.GUAMI.AMFRegionID,
+			SetId:     item.GUAMI.AMFSetID,
+			Pointer:   item.GUAMI.AMFPointer,
+			BackupAMF: string(item.BackupAMFName),
+		})
+	}
+	if len(amf.ServedGuamis) > 0 {
+		guami := amf.ServedGuamis[0]
+		amf.RegionId, amf.SetId, amf.Pointer = guami.RegionId, guami.SetId, guami.Pointer
+		amf.BackupAMF = guami.BackupAMF
+	}
+}
+
+// SetPlmnSupport replaces the supported PLMNs and slices
+func (amf *GNBAmf) SetPlmnSupport(items []ies.PLMNSupportItem) {
+	amf.Plmns, amf.LenPlmn = nil, 0
+	amf.Slices, amf.LenSlice = nil, 0
+	for _, item := range items {
+		amf.AddedPlmn(fmt.Sprintf("%x", item.PLMNIdentity))
+		for _, slice := range item.SliceSupportList {
+			amf.AddedSlice(fmt.Sprintf("%x", slice.SNSSAI.SST), fmt.Sprintf("%x", slice.SNSSAI.SD))
+		}
+	}
+}
+
 func (amf *GNBAmf) SetRegionId(regionId []byte) {
 	amf.RegionId = aper.BitString{
 		Bytes:   regionId,
@@ -0,0 +1,175 @@
+package context
+
+import (
+	"central-unit/internal/context/uecontext"
+	"central-unit/pkg/config"
+	"central-unit/pkg/pdcp"
+	"fmt"
+	"slices"
+
+	asn1aper "github.com/lvdund/asn1go/aper"
+	"github.com/lvdund/ngap/aper"
+	"github.com/lvdund/ngap/ies"
+	"github.com/lvdund/rrc"
+	rrcies "github.com/lvdund/rrc/ies"
+)
+
+// SecurityPolicy holds the configured AS algorithm identities in order of
+// preference
+type SecurityPolicy struct {
+	Ciphering []uint8
+	Integrity []uint8
+}
+
+func newSecurityPolicy(cfg config.SecurityConfig) (SecurityPolicy, error) {
+	var policy SecurityPolicy
+	var err error
+	if policy.Ciphering, err = algorithmIds(cfg.Ciphering, config.CipheringAlgorithms); err != nil {
+		return policy, fmt.Errorf("ciphering: %w", err)
+	}
+	if policy.Integrity, err = algorithmIds(cfg.Integrity, config.IntegrityAlgorithms); err != nil {
+		return policy, fmt.Errorf("integrity: %w", err)
+	}
+	return policy, nil
+}
+
+// algorithmIds turns algorithm names into their identities, which are their
+// index in the list of known names
+func algorithmIds(names []string, known []string) ([]uint8, error) {
+	ids := make([]uint8, 0, len(names))
+	for _, name := range names {
+		id := slices.Index(known, name)
+		if id < 0 {
+			return nil, fmt.Errorf("unknown algorithm %q", name)
+		}
+		ids = append(ids, uint8(id))
+	}
+	return ids, nil
+}
+
+// selectAlgorithm picks the first preferred algorithm the UE supports. The
+// capability bit string starts with algorithm 1, algorithm 0 is mandatory.
+func selectAlgorithm(preferred []uint8, supported aper.BitString) (uint8, bool) {
+	for _, id := range preferred {
+		if id == 0 {
+			return id, true
+		}
+		bit := uint64(id - 1)
+		if bit < supported.NumBits && int(bit/8) < len(supported.Bytes) &&
+			supported.Bytes[bit/8]&(0x80>>(bit%8)) != 0 {
+			return id, true
+		}
+	}
+	return 0, false
+}
+
+// setupAsSecurity selects the algorithms for the UE and derives its AS keys
+// from the K_gNB
+func (cu *CuCpContext) setupAsSecurity(ue *uecontext.GNBUe, kgnb []byte, capabilities *ies.UESecurityCapabilities) error {
+	cipheringAlg, ok := selectAlgorithm(cu.securityPolicy.Ciphering, capabilities.NRencryptionAlgorithms)
+	if !ok {
+		return fmt.Errorf("UE supports none of the configured ciphering algorithms")
+	}
+	integrityAlg, ok := selectAlgorithm(cu.securityPolicy.Integrity, capabilities.NRintegrityProtectionAlgorithms)
+	if !ok {
+		return fmt.Errorf("UE supports none of the configured integrity algorithms")
+	}
+
+	asCtx, err := uecontext.NewAsContext(kgnb, cipheringAlg, integrityAlg)
+	if err != nil {
+		return fmt.Errorf("failed to derive AS keys: %w", err)
+	}
+	ue.AsSecurity = asCtx
+	cu.Info("AS security for UE RAN-UE-NGAP-ID=%d: NEA%d, NIA%d", ue.RanUeNgapId, cipheringAlg, integrityAlg)
+	return nil
+}
+
+// srbSecurity is the PDCP protection of SRB1 and SRB2 under the AS keys.
+// Ciphering starts after integrity in each direction.
+func srbSecurity(asCtx *uecontext.AsContext, ciphering bool) pdcp.Security {
+	sec := pdcp.Security{
+		IntegrityAlg: asCtx.IntegrityAlg,
+		IntegrityKey: asCtx.KrrcInt(),
+	}
+	if ciphering {
+		sec.CipheringAlg = asCtx.CipheringAlg
+		sec.CipheringKey = asCtx.KrrcEnc()
+	}
+	return sec
+}
+
+// protectSrb frames a DL-DCCH message into a PDCP PDU of the given SRB
+func protectSrb(ue *uecontext.GNBUe, srbId uint8, rrcBytes []byte) ([]byte, error) {
+	if int(srbId) >= len(ue.SrbPdcp) || ue.SrbPdcp[srbId] == nil {
+		return nil, fmt.Errorf("SRB%d is not established", srbId)
+	}
+	pdu, err := ue.SrbPdcp[srbId].Protect(rrcBytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to protect RRC message on SRB%d: %w", srbId, err)
+	}
+	return pdu, nil
+}
+
+// unprotectSrb checks and deciphers an UL PDCP PDU of the given SRB
+func unprotectSrb(ue *uecontext.GNBUe, srbId int64, pdcpPdu []byte) ([]byte, error) {
+	if srbId >= int64(len(ue.SrbPdcp)) || ue.SrbPdcp[srbId] == nil {
+		return nil, fmt.Errorf("SRB%d is not established", srbId)
+	}
+	return ue.SrbPdcp[srbId].Unprotect(pdcpPdu)
+}
+
+// encodeSecurityModeCommand builds the Security Mode Command that activates
+// AS security at the UE, the DU delivers it on SRB1 with the UE context
+func encodeSecurityModeCommand(asCtx *uecontext.AsContext) ([]byte, error) {
+	integrityAlg := rrcies.IntegrityProtAlgorithm{Value: asn1aper.Enumerated(asCtx.IntegrityAlg)}
+	dlDcchMsg := rrcies.DL_DCCH_Message{
+		Message: rrcies.DL_DCCH_MessageType{
+			Choice: rrcies.DL_DCCH_MessageType_Choice_C1,
+			C1: &rrcies.DL_DCCH_MessageType_C1{
+				Choice: rrcies.DL_DCCH_MessageType_C1_Choice_SecurityModeCommand,
+				SecurityModeCommand: &rrcies.SecurityModeCommand{
+					Rrc_TransactionIdentifier: rrcies.RRC_TransactionIdentifier{Value: 0},
+					CriticalExtensions: rrcies.SecurityModeCommand_CriticalExtensions{
+						Choice: rrcies.SecurityModeCommand_CriticalExtensions_Choice_SecurityModeCommand,
+						SecurityModeCommand: &rrcies.SecurityModeCommand_IEs{
+							SecurityConfigSMC: rrcies.SecurityConfigSMC{
+								SecurityAlgorithmConfig: rrcies.SecurityAlgorithmConfig{
+									CipheringAlgorithm:     rrcies.CipheringAlgorithm{Value: asn1aper.Enumerated(asCtx.CipheringAlg)},
+									IntegrityProtAlgorithm: &integrityAlg,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	rrcBytes, err := rrc.Encode(&dlDcchMsg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode Security Mode Command: %w", err)
+	}
+	return rrcBytes, nil
+}
+
+// handleRRCSecurityModeFailure fails the context setup of a UE that refused
+// AS security, it cannot be given SRB2 or DRBs
+func (cu *CuCpContext) handleRRCSecurityModeFailure(
+	ue *uecontext.GNBUe,
+	securityModeFailure *rrcies.SecurityModeFailure,
+) error {
+	cu.Error("UE RAN-UE-NGAP-ID=%d rejected the Security Mode Command", ue.RanUeNgapId)
+	ue.AsSecurity = nil
+	// the UE carries on without security on SRB1
+	ue.SrbPdcp[1].Tx = pdcp.Security{}
+	ue.SrbPdcp[1].Rx = pdcp.Security{}
+	if ue.InitialContextSetup != nil {
+		cu.failInitialContextSetup(ue, radioNetworkCause(ies.CauseRadioNetworkFailureinradiointerfaceprocedure))
+		return nil
+	}
+	if err := cu.sendUEContextReleaseRequest(ue, radioNetworkCause(ies.CauseRadioNetworkFailureinradiointerfaceprocedure)); err != nil {
+		cu.releaseUEContext(ue, true)
+		return err
+	}
+	return nil
+}
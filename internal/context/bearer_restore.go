@@ -0,0 +1,228 @@
+package context
+
+import (
+	"bytes"
+	"central-unit/internal/context/du"
+	"central-unit/internal/context/uecontext"
+	e1ies "central-unit/pkg/e1ap/ies"
+	f1ext "central-unit/pkg/f1ap/ies"
+	"central-unit/pkg/security"
+	"fmt"
+	"slices"
+
+	f1ies "github.com/JocelynWS/f1-gen/ies"
+	asn1aper "github.com/lvdund/asn1go/aper"
+	"github.com/lvdund/ngap/ies"
+	"github.com/lvdund/rrc"
+	rrcies "github.com/lvdund/rrc/ies"
+)
+
+// A UE resuming from RRC_INACTIVE or re-establishing its RRC connection comes
+// back in a cell where the DU holds no UE context for it. Once SRB1 is up
+// again, SRB2 and the DRBs are restored the same way: UE Context Setup and
+// Modification at the DU, then the new UP keys and F1-U tunnels to the CU-UP,
+// then the RRC message that ends the procedure.
+
+// restoringBearers tells whether the UE context at the DU is being set up for
+// a resume or a re-establishment
+func restoringBearers(ue *uecontext.GNBUe) bool {
+	return ue.Reestablishing || ue.Suspension != nil && ue.Suspension.Resuming
+}
+
+// attachToCell moves the UE to the UE context the DU of its new cell created
+// for it, under a new gNB-CU UE F1AP ID, and returns the one it leaves
+func (cu *CuCpContext) attachToCell(
+	ue *uecontext.GNBUe,
+	duCtx *du.GNBDU,
+	f1apMsg *f1ies.InitialULRRCMessageTransfer,
+) uecontext.DuLeg {
+	leg := uecontext.DuLeg{DuId: ue.DuId, DuUeId: ue.DuUeId, GnbCuUeF1apId: ue.GnbCuUeF1apId}
+	ue.GnbCuUeF1apId = uint64(cu.getNextGnbCuUeF1apId())
+	ue.DuId = uint64(duCtx.DuId)
+	ue.DuUeId = uint64(f1apMsg.GNBDUUEF1APID)
+	ue.Rnti = f1apMsg.CRNTI
+	ue.NrCellId = &f1apMsg.NRCGI.NRCellIdentity
+	cu.F1UePool.Store(int64(ue.GnbCuUeF1apId), ue)
+	return leg
+}
+
+// servingCell is the cell the UE is connected to, nil once its DU no longer
+// has it active
+func (cu *CuCpContext) servingCell(ue *uecontext.GNBUe) *du.ServedCell {
+	if ue.NrCellId == nil {
+		return nil
+	}
+	duCtx, err := cu.GetDUForUE(ue)
+	if err != nil {
+		return nil
+	}
+	return duCtx.GetActiveCell(cu.extractCellIDValue(*ue.NrCellId))
+}
+
+// rekeyForCell derives the AS keys of a UE moving to a cell from its K_gNB
+// and the PCI and downlink ARFCN of the cell (TS 33.501 6.9.2.3.2). The AMF
+// gives the CU-CP no fresh NH outside of an N2 handover, so the derivation is
+// always horizontal and the UE keeps its NCC.
+func rekeyForCell(asSecurity *uecontext.AsContext, cell *du.ServedCell) (*uecontext.AsContext, error) {
+	kgnbStar, err := asSecurity.KgnbStar(cell.PCI, uint32(cell.Mode.DlArfcn))
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive K_gNB*: %w", err)
+	}
+	asCtx, err := uecontext.NewAsContext(kgnbStar, asSecurity.CipheringAlg, asSecurity.IntegrityAlg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to derive AS keys: %w", err)
+	}
+	asCtx.Active = true
+	asCtx.Ncc = asSecurity.Ncc
+	return asCtx, nil
+}
+
+// decodeCellGroup takes the CellGroupConfig the DU built for the UE, if any
+func decodeCellGroup(ue *uecontext.GNBUe, cellGroupConfig []byte) error {
+	if len(cellGroupConfig) == 0 {
+		return nil
+	}
+	cellGroup := rrcies.CellGroupConfig{}
+	if err := rrc.Decode(cellGroupConfig, &cellGroup); err != nil {
+		return fmt.Errorf("failed to decode CellGroupConfig: %w", err)
+	}
+	ue.MasterCellGroup = &cellGroup
+	return nil
+}
+
+// shortMacValid checks a shortMAC-I or resumeMAC-I, the 16 least significant
+// bits of the MAC-I over the encoded VarShortMAC-Input or VarResumeMAC-Input
+// with the integrity key of the source cell, COUNT, BEARER and DIRECTION bits
+// all set to 1 (TS 38.331 5.3.7.4, 5.3.13.3)
+func shortMacValid(asSecurity *uecontext.AsContext, input []byte, shortMac asn1aper.BitString) (bool, error) {
+	mac, err := security.Mac(asSecurity.IntegrityAlg, asSecurity.KrrcInt(), 0xFFFFFFFF, 0x1F, 1, input)
+	if err != nil {
+		return false, err
+	}
+	return bytes.Equal(mac[len(mac)-2:], shortMac.Bytes), nil
+}
+
+// restoreDrbs sets the DRBs up again at the DU once it holds the UE context
+// with SRB2, in a UE Context Modification Request built as for a PDU session
+// setup
+func (cu *CuCpContext) restoreDrbs(ue *uecontext.GNBUe, msg *f1ies.UEContextSetupResponse) {
+	if err := decodeCellGroup(ue, msg.DUtoCURRCInformation.CellGroupConfig); err != nil {
+		cu.Error("UE RAN-UE-NGAP-ID=%d: %v", ue.RanUeNgapId, err)
+		cu.failBearerRestore(ue)
+		return
+	}
+
+	mod := f1ext.UEContextModificationRequest{}
+	for _, pduSession := range ue.PduSessions {
+		for _, drb := range pduSession.Drbs {
+			item, err := buildF1DrbToSetup(pduSession, drb)
+			if err != nil {
+				cu.Error("Failed to restore DRB ID=%d: %v", drb.DrbId, err)
+				cu.failBearerRestore(ue)
+				return
+			}
+			mod.DRBsToBeSetupModList = append(mod.DRBsToBeSetupModList, item)
+		}
+	}
+
+	var err error
+	if len(mod.DRBsToBeSetupModList) == 0 {
+		err = cu.restoreUserPlane(ue)
+	} else {
+		err = cu.sendF1UEContextModification(ue, &mod)
+	}
+	if err != nil {
+		cu.Error("Failed to restore the bearers of UE RAN-UE-NGAP-ID=%d: %v", ue.RanUeNgapId, err)
+		cu.failBearerRestore(ue)
+	}
+}
+
+// applyF1DrbsRestored records the DU side of the F1-U tunnels of the restored
+// DRBs. The UE keeps all of its DRBs, so the procedure fails without any.
+func (cu *CuCpContext) applyF1DrbsRestored(ue *uecontext.GNBUe, msg *f1ies.UEContextModificationResponse) {
+	for _, item := range msg.DRBsSetupModList {
+		if _, drb := ue.FindDrb(uint8(item.DRBID)); drb != nil {
+			drb.DlF1uTunnel = f1DlTunnel(item.DLUPTNLInformationToBeSetupList)
+		}
+	}
+	for _, pduSession := range ue.PduSessions {
+		for _, drb := range pduSession.Drbs {
+			if drb.DlF1uTunnel == nil || !slices.ContainsFunc(msg.DRBsSetupModList, func(item f1ies.DRBsSetupModItem) bool {
+				return uint8(item.DRBID) == drb.DrbId
+			}) {
+				cu.Error("DU did not restore DRB ID=%d of UE RAN-UE-NGAP-ID=%d", drb.DrbId, ue.RanUeNgapId)
+				cu.failBearerRestore(ue)
+				return
+			}
+		}
+	}
+
+	if err := cu.restoreUserPlane(ue); err != nil {
+		cu.Error("Failed to restore the bearers of UE RAN-UE-NGAP-ID=%d: %v", ue.RanUeNgapId, err)
+		cu.failBearerRestore(ue)
+	}
+}
+
+// restoreUserPlane updates the CU-UP for the new cell of the UE, then sends
+// the RRCResume or RRCReconfiguration that ends the procedure
+func (cu *CuCpContext) restoreUserPlane(ue *uecontext.GNBUe) error {
+	if err := cu.rekeyUserPlane(ue); err != nil {
+		return err
+	}
+	if ue.Suspension != nil {
+		return cu.sendRrcResume(ue)
+	}
+	return cu.sendReestablishmentReconfiguration(ue)
+}
+
+// rekeyUserPlane hands the CU-UP the UP keys of a UE that changed cell and the
+// F1-U tunnels at the DU now serving it, the PDCP entities of the DRBs being
+// re-established as they are at the UE. A suspended bearer context is resumed.
+func (cu *CuCpContext) rekeyUserPlane(ue *uecontext.GNBUe) error {
+	if !ue.HasBearerContext {
+		return nil
+	}
+	securityInfo := e1SecurityInformation(ue.AsSecurity)
+	msg := e1ies.BearerContextModificationRequest{SecurityInformation: &securityInfo}
+	if ue.Suspension != nil {
+		msg.BearerContextStatusChange = &e1ies.BearerContextStatusChange{Value: e1ies.BearerContextStatusChangeResume}
+	}
+	if len(ue.PduSessions) > 0 {
+		var sessions []*uecontext.PduSessionContext
+		for _, pduSession := range ue.PduSessions {
+			sessions = append(sessions, pduSession)
+		}
+		mod := dlF1uTunnelsModification(sessions, nil)
+		for i := range mod.PDUSessionResourceToModifyList {
+			drbs := mod.PDUSessionResourceToModifyList[i].DRBToModifyListNGRAN
+			for j := range drbs {
+				pdcpConfig := e1PdcpConfiguration()
+				pdcpConfig.PDCPReestablishment = &e1ies.PDCPReestablishment{Value: e1ies.PDCPReestablishmentTrue}
+				drbs[j].PDCPConfiguration = &pdcpConfig
+			}
+		}
+		msg.SystemBearerContextModificationRequest = &e1ies.SystemBearerContextModificationRequest{
+			Choice:                                e1ies.SystemBearerContextModificationRequestPresentNGRANBearerContextModificationRequest,
+			NGRANBearerContextModificationRequest: mod,
+		}
+	}
+	if err := cu.sendBearerContextModification(ue, &msg); err != nil {
+		return fmt.Errorf("failed to update the bearer context: %w", err)
+	}
+	return nil
+}
+
+// failBearerRestore gives up a resume or a re-establishment the DU could not
+// serve, the AMF is asked to release the UE
+func (cu *CuCpContext) failBearerRestore(ue *uecontext.GNBUe) {
+	if !restoringBearers(ue) {
+		return
+	}
+	ue.Suspension = nil
+	ue.Reestablishing = false
+	err := cu.sendUEContextReleaseRequest(ue, radioNetworkCause(ies.CauseRadioNetworkRadioresourcesnotavailable))
+	if err != nil {
+		cu.Error("Failed to send UE Context Release Request: %v", err)
+		cu.releaseUEContext(ue, true)
+	}
+}
@@ -2,7 +2,7 @@ package context
 
 import (
 	"central-unit/internal/common/logger"
-	"central-unit/internal/transport"
+	"central-unit/internal/context/amfcontext"
 	"context"
 	"encoding/hex"
 	"fmt"
@@ -24,24 +24,44 @@ type CuCpContext struct {
 
 	NgapUePool sync.Map // map[int64]*GNBUe - by RanUeNgapId
 	F1UePool   sync.Map // map[int64]*GNBUe - by GnbCuUeF1apId
+	E1UePool   sync.Map // map[int64]*GNBUe - by GnbCuCpUeE1apId
 
 	AmfPool sync.Map // map[int64]*GNBAmf, AmfId as key
 	DuPool  sync.Map // map[int64]*DU, DuId as key
 
 	F1ConnMap sync.Map // map[*sctp.SCTPConn]int64 - maps connection to DuId
 
+	CuUpPool  sync.Map // map[int64]*GNBCUUP, CuUpId as key
+	E1ConnMap sync.Map // map[*sctp.SCTPConn]int64 - maps connection to CuUpId
+
 	F1APListener *sctp.SCTPListener
 	f1apStop     chan struct{}
 
-	SliceInfo      Slice
-	IdUeGenerator  int64  // ran UE id.
-	IdAmfGenerator int64  // ran amf id
-	TeidGenerator  uint32 // ran UE downlink Teid
-	UeIpGenerator  uint8  // ran ue ip.
-
-	ranUeNgapIdGen   *IdGenerator
-	rrcUeIdGen       *IdGenerator
-	gnbCuUeF1apIdGen *IdGenerator
+	E1APListener *sctp.SCTPListener
+	e1apStop     chan struct{}
+
+	SliceInfo          Slice
+	drbMapping         DrbMappingPolicy   // groups the QoS flows of a PDU session into DRBs
+	securityPolicy     SecurityPolicy     // AS algorithms offered to the UEs
+	inactivePolicy     *InactivePolicy    // suspendConfig of the UEs, nil without RRC_INACTIVE
+	measPolicy         *MeasurementPolicy // measurements of the connected UEs, nil without any event
+	rejectWaitTime     uint64             // seconds a UE turned down by RRCReject waits
+	maxUEs             int                // UE contexts admitted in all, 0 for no limit
+	maxUEsPerDu        int                // UE contexts admitted per DU, 0 for no limit
+	icsTimeout         time.Duration      // supervises the Initial Context Setup
+	duReconnectTimeout time.Duration      // keeps the context of a lost DU
+	handoverTimeout    time.Duration      // supervises an inter-DU handover
+	servedRan          ranConfiguration   // last announced to the AMFs
+	slices             []Slice            // supported in every TA
+	IdUeGenerator      int64              // ran UE id.
+	IdAmfGenerator     int64              // ran amf id
+	TeidGenerator      uint32             // ran UE downlink Teid
+	UeIpGenerator      uint8              // ran ue ip.
+
+	ranUeNgapIdGen     *IdGenerator
+	rrcUeIdGen         *IdGenerator
+	gnbCuUeF1apIdGen   *IdGenerator
+	gnbCuCpUeE1apIdGen *IdGenerator
 
 	// OAI
 	IdRrcUeGenerator int64
@@ -64,6 +84,8 @@ type ControlInfo struct {
 	mnc string
 	tac string
 
+	cu_name string // gNB-CU-CP name announced to CU-UPs
+
 	// CU-CP for AMF
 	ng_gnbId   string
 	ng_gnbIp   string
@@ -74,9 +96,12 @@ type ControlInfo struct {
 	f1_gnbIp   string
 	f1_gnbPort int
 
+	// CU-CP for CU-UP
+	e1_gnbIp   string
+	e1_gnbPort int
+
 	// inboundChannel chan rlink.Message
 	rlinkPool sync.Map
-	n2        *transport.SctpConn
 }
 
 func (cu *CuCpContext) GetMccAndMncInOctets() []byte {
@@ -114,14 +139,14 @@ func (cu *CuCpContext) getGnbIdInBytes() []byte {
 	return resu
 }
 
-func (cu *CuCpContext) getSliceInBytes() ([]byte, []byte) {
-	sstBytes, err := hex.DecodeString(cu.SliceInfo.sst)
+func (cu *CuCpContext) getSliceInBytes(slice Slice) ([]byte, []byte) {
+	sstBytes, err := hex.DecodeString(slice.sst)
 	if err != nil {
 		cu.Error("can not get Slice-sst in byte")
 	}
 
-	if cu.SliceInfo.sd != "" {
-		sdBytes, err := hex.DecodeString(cu.SliceInfo.sd)
+	if slice.sd != "" {
+		sdBytes, err := hex.DecodeString(slice.sd)
 		if err != nil {
 			cu.Error("can not get Slice-sd in byte")
 		}
@@ -133,10 +158,10 @@ func (cu *CuCpContext) getSliceInBytes() ([]byte, []byte) {
 func (cu *CuCpContext) getTacInBytes() []byte {
 	// changed for bytes.
 	resu, err := hex.DecodeString(cu.ControlInfo.tac)
-	if err != nil {
+	if err != nil || len(resu) != 3 {
 		cu.Error("can not get Tac in byte")
+		return []byte{0x00, 0x00, 0x01}
 	}
-	resu = []byte{0x00, 0x00, 0x01}
 	return resu
 }
 
@@ -160,6 +185,10 @@ func (cu *CuCpContext) getNextGnbCuUeF1apId() int64 {
 	return cu.gnbCuUeF1apIdGen.Next()
 }
 
+func (cu *CuCpContext) getNextGnbCuCpUeE1apId() int64 {
+	return cu.gnbCuCpUeE1apIdGen.Next()
+}
+
 // // SetControlInfoFromConfig sets the control information from config values
 // func (cu *CuCpContext) SetControlInfoFromConfig(mcc, mnc, gnbIp, gnbId, tac string, gnbPort int) {
 // 	cu.ControlInfo.mcc = mcc
@@ -172,8 +201,11 @@ func (cu *CuCpContext) getNextGnbCuUeF1apId() int64 {
 
 // SetSliceInfoFromConfig sets the slice information from config values
 func (cu *CuCpContext) SetSliceInfoFromConfig(sst, sd string) {
-	cu.SliceInfo.sst = sst
-	cu.SliceInfo.sd = "" // open5gs does not support sd
+	cu.SliceInfo = newSlice(sst, sd)
+}
+
+func newSlice(sst, sd string) Slice {
+	return Slice{sst: sst} // open5gs does not support sd
 }
 
 func (cu *CuCpContext) GetPLMNIdentity() []byte {
@@ -206,11 +238,13 @@ func (cu *CuCpContext) Terminate() {
 	// close(cu.ControlInfo.InboundChannel)
 	cu.Info("NAS channel Terminated")
 
-	n2 := cu.ControlInfo.n2
-	if n2 != nil {
-		cu.Info("N2/TNLA Terminated")
-		n2.Close()
-	}
+	cu.AmfPool.Range(func(_, value any) bool {
+		if amf, ok := value.(*amfcontext.GNBAmf); ok && amf.Tnla.SctpConn != nil {
+			cu.Info("N2/TNLA to AMF %d Terminated", amf.AmfId)
+			amf.Tnla.SctpConn.Close()
+		}
+		return true
+	})
 
 	// Stop F1AP server
 	if cu.F1APListener != nil {
@@ -221,5 +255,14 @@ func (cu *CuCpContext) Terminate() {
 		}
 	}
 
+	// Stop E1AP server
+	if cu.E1APListener != nil {
+		cu.Info("E1AP SCTP server Terminated")
+		close(cu.e1apStop)
+		if err := cu.E1APListener.Close(); err != nil {
+			cu.Error("E1AP listener close error: %v", err)
+		}
+	}
+
 	cu.Info("CU-CP Terminated")
 }
@@ -3,20 +3,21 @@ package context
 import (
 	"central-unit/internal/common/logger"
 	"central-unit/pkg/config"
-	"central-unit/pkg/model"
 	"context"
+	"time"
 )
 
-func InitContext(amfs model.AMF, cfg config.Config) *CuCpContext {
+func InitContext(cfg config.Config) *CuCpContext {
 	cuCtx := &CuCpContext{
 		Logger:      logger.InitLogger("", map[string]string{"mod": "cucp"}),
-		IsReadyNgap: make(chan bool),
+		IsReadyNgap: make(chan bool, 1),
 		Close:       make(chan struct{}),
 		Ctx:         context.Background(),
 
-		ranUeNgapIdGen:   NewIdGenerator(0),
-		rrcUeIdGen:       NewIdGenerator(0),
-		gnbCuUeF1apIdGen: NewIdGenerator(0),
+		ranUeNgapIdGen:     NewIdGenerator(0),
+		rrcUeIdGen:         NewIdGenerator(0),
+		gnbCuUeF1apIdGen:   NewIdGenerator(0),
+		gnbCuCpUeE1apIdGen: NewIdGenerator(0),
 	}
 
 	// Set control info from config
@@ -26,10 +27,46 @@ func InitContext(amfs model.AMF, cfg config.Config) *CuCpContext {
 	cuCtx.ControlInfo.f1_gnbIp = cfg.F1AP.LocalAddress
 	cuCtx.ControlInfo.f1_gnbPort = cfg.F1AP.LocalPort
 	cuCtx.ControlInfo.f1_gnbId = cfg.CUCP.NodeID
+	cuCtx.ControlInfo.e1_gnbIp = cfg.E1AP.LocalAddress
+	cuCtx.ControlInfo.e1_gnbPort = cfg.E1AP.LocalPort
+	cuCtx.ControlInfo.cu_name = cfg.CUCP.NodeName
 	cuCtx.ControlInfo.mcc = cfg.CUCP.PLMN.MCC
 	cuCtx.ControlInfo.mnc = cfg.CUCP.PLMN.MNC
 	cuCtx.ControlInfo.tac = cfg.CUCP.TAC
 
+	drbMapping, err := newDrbMappingPolicy(cfg.Bearers.DrbMapping)
+	if err != nil {
+		cuCtx.Fatal("Error in: %v", err)
+	}
+	cuCtx.drbMapping = drbMapping
+
+	securityPolicy, err := newSecurityPolicy(cfg.Security)
+	if err != nil {
+		cuCtx.Fatal("Error in: %v", err)
+	}
+	cuCtx.securityPolicy = securityPolicy
+
+	if cfg.Features.ConnectedInactiveState {
+		inactivePolicy, err := newInactivePolicy(cfg.RRC.Inactive)
+		if err != nil {
+			cuCtx.Fatal("Error in: %v", err)
+		}
+		cuCtx.inactivePolicy = inactivePolicy
+	}
+
+	measPolicy, err := newMeasurementPolicy(cfg.Mobility)
+	if err != nil {
+		cuCtx.Fatal("Error in: %v", err)
+	}
+	cuCtx.measPolicy = measPolicy
+
+	cuCtx.rejectWaitTime = uint64(cfg.RRC.RejectWaitTime / time.Second)
+	cuCtx.maxUEs = cfg.RRC.Admission.MaxUEs
+	cuCtx.maxUEsPerDu = cfg.RRC.Admission.MaxUEsPerDU
+	cuCtx.icsTimeout = cfg.NGAP.Timers.InitialContextSetup
+	cuCtx.duReconnectTimeout = cfg.F1AP.Timers.DuReconnect
+	cuCtx.handoverTimeout = cfg.F1AP.Timers.Handover
+
 	// Set slice info from config
 	if len(cfg.CUCP.Slices) > 0 {
 		cuCtx.SetSliceInfoFromConfig(
@@ -37,15 +74,17 @@ func InitContext(amfs model.AMF, cfg config.Config) *CuCpContext {
 			cfg.CUCP.Slices[0].SD,
 		)
 	}
+	cuCtx.slices = configSlices(cfg.CUCP.Slices)
 
-	amf := cuCtx.newAmf(model.AMF{Ip: cfg.NGAP.AMFAddress, Port: cfg.NGAP.AMFPort})
-	if err := cuCtx.initAmfConn(amf); err != nil {
-		cuCtx.Fatal("Error in: %v", err)
-	} else {
-		cuCtx.Info("SCTP/NGAP service is running")
+	// Each AMF gets its own association, from the next local port
+	for i, amfCfg := range cfg.NGAP.AMFs {
+		amf := cuCtx.newAmf(amfCfg)
+		amf.LocalPort = cfg.NGAP.LocalPort + i
+		if err := cuCtx.initAmfConn(amf); err != nil {
+			cuCtx.Fatal("Error in: %v", err)
+		}
 	}
-	cuCtx.SendNgSetupRequest(amf)
-
+	cuCtx.Info("SCTP/NGAP service is running for %d AMF(s)", len(cfg.NGAP.AMFs))
 	<-cuCtx.IsReadyNgap
 
 	// Initialize F1AP SCTP server for DU connections
@@ -55,6 +94,13 @@ func InitContext(amfs model.AMF, cfg config.Config) *CuCpContext {
 		cuCtx.Info("SCTP/F1AP server is running")
 	}
 
+	// Initialize E1AP SCTP server for CU-UP connections
+	if err := cuCtx.initE1APServer(); err != nil {
+		cuCtx.Fatal("Error initializing E1AP server: %v", err)
+	} else {
+		cuCtx.Info("SCTP/E1AP server is running")
+	}
+
 	go func() {
 		<-cuCtx.Ctx.Done()
 		cuCtx.Terminate()
@@ -0,0 +1,57 @@
+package cuup
+
+import (
+	"central-unit/internal/common/logger"
+	"central-unit/pkg/e1ap/ies"
+	"fmt"
+
+	"github.com/ishidawataru/sctp"
+)
+
+// CU-UP main states
+const (
+	CUUP_INACTIVE string = "CUUP_INACTIVE"
+	CUUP_ACTIVE   string = "CUUP_ACTIVE"
+	CUUP_LOST     string = "CUUP_LOST"
+)
+
+// GNBCUUP represents a gNB-CU-UP context attached over E1
+type GNBCUUP struct {
+	*logger.Logger
+	CuUpId         int64  // gNB-CU-UP ID
+	CuUpName       string // gNB-CU-UP name
+	State          string // CU-UP state (INACTIVE, ACTIVE, LOST)
+	SctpConn       *sctp.SCTPConn
+	SetupReq       *ies.GNBCUUPE1SetupRequest // E1 Setup Request message
+	CNSupport      ies.CNSupport              // Core network types supported (EPC, 5GC or both)
+	SupportedPLMNs []ies.SupportedPLMNsItem   // PLMNs (and slices) served by the CU-UP
+	Capacity       int64                      // gNB-CU-UP capacity, -1 if not signalled
+}
+
+// SendE1ap sends E1AP message to the CU-UP
+func (cuup *GNBCUUP) SendE1ap(pdu []byte) error {
+	if cuup.SctpConn == nil {
+		return fmt.Errorf("SCTP connection not established for CU-UP %d", cuup.CuUpId)
+	}
+	info := &sctp.SndRcvInfo{
+		PPID:   64,
+		Stream: 0,
+	}
+	_, err := cuup.SctpConn.SCTPWrite(pdu, info)
+	return err
+}
+
+// SupportsPLMN returns true if the CU-UP announced the given PLMN identity
+func (cuup *GNBCUUP) SupportsPLMN(plmn []byte) bool {
+	for _, item := range cuup.SupportedPLMNs {
+		if string(item.PLMNIdentity) == string(plmn) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsActive returns true if CU-UP is in active state
+func (cuup *GNBCUUP) IsActive() bool {
+	return cuup.State == CUUP_ACTIVE
+}
@@ -0,0 +1,56 @@
+package cuup
+
+import (
+	"fmt"
+
+	"central-unit/pkg/e1ap"
+	"central-unit/pkg/e1ap/ies"
+)
+
+// SendE1SetupResponse answers a GNB-CU-UP E1 Setup Request
+func (cuup *GNBCUUP) SendE1SetupResponse(transactionID int64, cuCpName string) error {
+	msg := ies.GNBCUUPE1SetupResponse{
+		TransactionID: transactionID,
+	}
+	if cuCpName != "" {
+		msg.GNBCUCPName = []byte(cuCpName)
+	}
+
+	buf, err := e1ap.E1apEncode(&msg)
+	if err != nil {
+		return fmt.Errorf("encode E1 Setup Response: %w", err)
+	}
+
+	cuup.Info("Send E1 Setup Response to CU-UP %d", cuup.CuUpId)
+
+	return cuup.SendE1ap(buf)
+}
+
+// SendE1SetupFailure rejects a GNB-CU-UP E1 Setup Request with the given cause
+func (cuup *GNBCUUP) SendE1SetupFailure(transactionID int64, cause *ies.Cause, diagnostics *ies.CriticalityDiagnostics) error {
+	msg := ies.GNBCUUPE1SetupFailure{
+		TransactionID:          transactionID,
+		CriticalityDiagnostics: diagnostics,
+	}
+
+	if cause != nil {
+		msg.Cause = *cause
+	} else {
+		// Default cause if not provided - use Misc/Unspecified
+		msg.Cause = ies.Cause{
+			Choice: ies.CausePresentMisc,
+			Misc: &ies.CauseMisc{
+				Value: ies.CauseMiscUnspecified,
+			},
+		}
+	}
+
+	buf, err := e1ap.E1apEncode(&msg)
+	if err != nil {
+		return fmt.Errorf("encode E1 Setup Failure: %w", err)
+	}
+
+	cuup.Info("Send E1 Setup Failure to CU-UP %d", cuup.CuUpId)
+
+	return cuup.SendE1ap(buf)
+}
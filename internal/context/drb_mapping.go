@@ -0,0 +1,148 @@
+package context
+
+import (
+	"fmt"
+	"slices"
+
+	"central-unit/internal/context/uecontext"
+	"central-unit/pkg/config"
+)
+
+// DrbMappingPolicy decides which QoS flows of a PDU session share a DRB.
+// MapFlows returns one group of flows per DRB, the first group being the
+// default DRB of the session.
+type DrbMappingPolicy interface {
+	MapFlows(flows []*uecontext.QosFlowContext) [][]*uecontext.QosFlowContext
+}
+
+func newDrbMappingPolicy(name string) (DrbMappingPolicy, error) {
+	switch name {
+	case "", config.DrbMappingPerSession:
+		return perSessionMapping{}, nil
+	case config.DrbMappingPer5QI:
+		return per5qiMapping{}, nil
+	case config.DrbMappingPerGBRFlow:
+		return perGbrFlowMapping{}, nil
+	}
+	return nil, fmt.Errorf("unknown DRB mapping policy %q", name)
+}
+
+// perSessionMapping carries all flows of the session on a single DRB
+type perSessionMapping struct{}
+
+func (perSessionMapping) MapFlows(flows []*uecontext.QosFlowContext) [][]*uecontext.QosFlowContext {
+	return [][]*uecontext.QosFlowContext{flows}
+}
+
+// per5qiMapping gives every 5QI of the session its own DRB, in the order the
+// SMF listed the flows
+type per5qiMapping struct{}
+
+func (per5qiMapping) MapFlows(flows []*uecontext.QosFlowContext) [][]*uecontext.QosFlowContext {
+	var groups [][]*uecontext.QosFlowContext
+	index := make(map[int64]int)
+	for _, flow := range flows {
+		i, ok := index[flow.FiveQi]
+		if !ok {
+			i = len(groups)
+			index[flow.FiveQi] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], flow)
+	}
+	return groups
+}
+
+// perGbrFlowMapping gives every GBR flow a dedicated DRB while the non-GBR
+// flows share the default DRB
+type perGbrFlowMapping struct{}
+
+func (perGbrFlowMapping) MapFlows(flows []*uecontext.QosFlowContext) [][]*uecontext.QosFlowContext {
+	var nonGbr []*uecontext.QosFlowContext
+	var gbr [][]*uecontext.QosFlowContext
+	for _, flow := range flows {
+		if flow.Gbr != nil {
+			gbr = append(gbr, []*uecontext.QosFlowContext{flow})
+		} else {
+			nonGbr = append(nonGbr, flow)
+		}
+	}
+	if len(nonGbr) == 0 {
+		return gbr
+	}
+	return append([][]*uecontext.QosFlowContext{nonGbr}, gbr...)
+}
+
+// mapQosFlowsToDrbs splits the QoS flows of a new PDU session into DRBs
+// following the configured policy and assigns them DRB IDs
+func (cu *CuCpContext) mapQosFlowsToDrbs(ue *uecontext.GNBUe, pduSession *uecontext.PduSessionContext) error {
+	if len(pduSession.QosFlows) == 0 {
+		return fmt.Errorf("PDU Session ID=%d has no QoS flows", pduSession.PduSessionId)
+	}
+
+	for i, flows := range cu.drbMapping.MapFlows(pduSession.QosFlows) {
+		drbId, err := ue.AllocateDrbId()
+		if err != nil {
+			cu.releaseDrbs(ue, pduSession)
+			return err
+		}
+		pduSession.Drbs = append(pduSession.Drbs, &uecontext.DrbContext{
+			DrbId:    drbId,
+			Default:  i == 0,
+			QosFlows: flows,
+		})
+	}
+	return nil
+}
+
+// releaseDrbs frees the DRB IDs held by a PDU session
+func (cu *CuCpContext) releaseDrbs(ue *uecontext.GNBUe, pduSession *uecontext.PduSessionContext) {
+	for _, drb := range pduSession.Drbs {
+		ue.ReleaseDrbId(drb.DrbId)
+	}
+	pduSession.Drbs = nil
+}
+
+// mapAddedQosFlows places the QoS flows added to an established PDU session.
+// The policy is run over all flows of the session: an added flow joins the
+// DRB of the flows it is grouped with, or gets a new DRB when its group has
+// none. The IDs of the new and of the extended DRBs are returned.
+func (cu *CuCpContext) mapAddedQosFlows(
+	ue *uecontext.GNBUe,
+	pduSession *uecontext.PduSessionContext,
+	added []*uecontext.QosFlowContext,
+) (newDrbs, modifiedDrbs []uint8, err error) {
+	for _, group := range cu.drbMapping.MapFlows(pduSession.QosFlows) {
+		var drb *uecontext.DrbContext
+		var pending []*uecontext.QosFlowContext
+		for _, flow := range group {
+			if slices.Contains(added, flow) {
+				pending = append(pending, flow)
+			} else if drb == nil {
+				drb = pduSession.DrbOfFlow(flow.Qfi)
+			}
+		}
+		if len(pending) == 0 {
+			continue
+		}
+
+		if drb != nil {
+			drb.QosFlows = append(drb.QosFlows, pending...)
+			if !slices.Contains(newDrbs, drb.DrbId) && !slices.Contains(modifiedDrbs, drb.DrbId) {
+				modifiedDrbs = append(modifiedDrbs, drb.DrbId)
+			}
+			continue
+		}
+
+		drbId, err := ue.AllocateDrbId()
+		if err != nil {
+			return newDrbs, modifiedDrbs, err
+		}
+		pduSession.Drbs = append(pduSession.Drbs, &uecontext.DrbContext{
+			DrbId:    drbId,
+			QosFlows: pending,
+		})
+		newDrbs = append(newDrbs, drbId)
+	}
+	return newDrbs, modifiedDrbs, nil
+}
@@ -3,6 +3,7 @@ package du
 import (
 	"central-unit/internal/common/logger"
 	"fmt"
+	"time"
 
 	"github.com/JocelynWS/f1-gen/ies"
 	"github.com/ishidawataru/sctp"
@@ -24,10 +25,19 @@ type GNBDU struct {
 	State       string // DU state (INACTIVE, ACTIVE, LOST)
 	SctpConn    *sctp.SCTPConn
 	SetupReq    *ies.F1SetupRequest // F1 Setup Request message
-	MIB         []byte              // Decoded Master Information Block (raw bytes for now)
-	SIB1        []byte              // Decoded System Information Block Type 1 (raw bytes for now)
-	MTC         []byte              // Decoded Measurement Timing Configuration (raw bytes for now)
 	ServedCells []ServedCell        // List of served cells
+
+	transactionId    int64                     // last F1AP transaction ID used by the CU
+	PendingCuUpdates map[int64]*CuConfigUpdate // gNB-CU Configuration Updates by transaction ID
+
+	LostTimer *time.Timer // removes the context of a lost DU that does not reconnect
+}
+
+// CuConfigUpdate is a gNB-CU Configuration Update waiting for the answer of
+// the DU, cells are given by NR Cell Identity
+type CuConfigUpdate struct {
+	Activate   []uint64
+	Deactivate []uint64
 }
 
 // // TNLAssociation represents the transport network layer association
@@ -39,18 +49,38 @@ type GNBDU struct {
 
 // ServedCell represents a cell served by the DU
 type ServedCell struct {
-	CellID       uint64 // Physical Cell ID
+	CellID       uint64 // NR Cell Identity
 	GlobalCellID string // Global Cell Identifier
-	PLMN         PLMNInfo
-	PCI          uint16 // Physical Cell Identifier
+	NRCGI        ies.NRCGI
+	PLMN         PLMNInfo   // PLMN of the NR CGI
+	ServedPLMNs  []PLMNInfo // PLMNs broadcast in the cell
+	PCI          uint16     // Physical Cell Identifier
 	BandwidthMHz uint16
 	TAC          []byte // Tracking Area Code
+	Mode         NRMode
+	MIB          []byte // Master Information Block from the DU System Information
+	SIB1         []byte // System Information Block Type 1 from the DU System Information
+	MTC          []byte // Measurement Timing Configuration
+	Active       bool   // activated by the CU
+	OutOfService bool   // reported by the DU in the Cells Status
 }
 
 // PLMNInfo represents PLMN information
 type PLMNInfo struct {
-	MCC string
-	MNC string
+	MCC      string
+	MNC      string
+	Identity []byte // PLMN Identity octets as signalled
+}
+
+// NRMode is the FDD or TDD carrier of a cell. For FDD the SCS and number of
+// RBs are the downlink ones.
+type NRMode struct {
+	TDD     bool
+	DlArfcn int64
+	UlArfcn int64 // DlArfcn for TDD
+	Bands   []int64
+	ScsKHz  uint16
+	Nrb     uint16
 }
 
 // SendF1ap sends F1AP message to the DU
@@ -76,6 +106,32 @@ func (du *GNBDU) GetCellByID(cellID uint64) *ServedCell {
 	return nil
 }
 
+// GetActiveCell returns a served cell by its NR Cell Identity if it is active
+func (du *GNBDU) GetActiveCell(cellID uint64) *ServedCell {
+	if cell := du.GetCellByID(cellID); cell != nil && cell.Active {
+		return cell
+	}
+	return nil
+}
+
+// RemoveCell drops a served cell, telling whether the DU had it
+func (du *GNBDU) RemoveCell(cellID uint64) bool {
+	for i := range du.ServedCells {
+		if du.ServedCells[i].CellID == cellID {
+			du.ServedCells = append(du.ServedCells[:i], du.ServedCells[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// NextTransactionId allocates the transaction ID of a procedure started by
+// the CU
+func (du *GNBDU) NextTransactionId() int64 {
+	du.transactionId = (du.transactionId + 1) % 256
+	return du.transactionId
+}
+
 // IsActive returns true if DU is in active state
 func (du *GNBDU) IsActive() bool {
 	return du.State == DU_ACTIVE
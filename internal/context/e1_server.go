@@ -0,0 +1,144 @@
+package context
+
+import (
+	"central-unit/internal/context/cuup"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+
+	"github.com/ishidawataru/sctp"
+)
+
+const E1AP_PPID uint32 = 64
+
+// initE1APServer initializes the SCTP server for E1AP (CU-UP connections)
+func (cu *CuCpContext) initE1APServer() error {
+	netAddr, err := net.ResolveIPAddr("ip", cu.ControlInfo.e1_gnbIp)
+	if err != nil {
+		return fmt.Errorf("resolve IP: %w", err)
+	}
+
+	addr := &sctp.SCTPAddr{
+		IPAddrs: []net.IPAddr{*netAddr},
+		Port:    cu.ControlInfo.e1_gnbPort,
+	}
+
+	config := sctp.SocketConfig{
+		InitMsg: sctp.InitMsg{
+			NumOstreams:    2,
+			MaxInstreams:   2,
+			MaxAttempts:    2,
+			MaxInitTimeout: 2,
+		},
+	}
+
+	listener, err := config.Listen("sctp", addr)
+	if err != nil {
+		return fmt.Errorf("listen: %w", err)
+	}
+
+	cu.E1APListener = listener
+	cu.e1apStop = make(chan struct{})
+
+	cu.Info("E1AP server listening on %s", listener.Addr().String())
+
+	go cu.e1apAcceptLoop()
+
+	return nil
+}
+
+func (cu *CuCpContext) e1apAcceptLoop() {
+	for {
+		select {
+		case <-cu.e1apStop:
+			return
+		default:
+			conn, err := cu.E1APListener.AcceptSCTP()
+			if err != nil {
+				if err == syscall.EINTR || err == syscall.EAGAIN {
+					continue
+				}
+				cu.Error("E1AP accept error: %v", err)
+				continue
+			}
+
+			if conn == nil {
+				continue
+			}
+
+			if err := cu.configureE1APConnection(conn); err != nil {
+				cu.Error("Failed to configure E1AP connection: %v", err)
+				conn.Close()
+				continue
+			}
+
+			go cu.handleE1APConnection(conn)
+		}
+	}
+}
+
+func (cu *CuCpContext) configureE1APConnection(conn *sctp.SCTPConn) error {
+	events := sctp.SCTP_EVENT_DATA_IO | sctp.SCTP_EVENT_SHUTDOWN | sctp.SCTP_EVENT_ASSOCIATION
+	if err := conn.SubscribeEvents(events); err != nil {
+		return fmt.Errorf("subscribe events: %w", err)
+	}
+
+	info := &sctp.SndRcvInfo{PPID: E1AP_PPID}
+	if err := conn.SetDefaultSentParam(info); err != nil {
+		return fmt.Errorf("set default sent param: %w", err)
+	}
+
+	if err := conn.SetReadBuffer(8192); err != nil {
+		return fmt.Errorf("set read buffer: %w", err)
+	}
+
+	return nil
+}
+
+func (cu *CuCpContext) handleE1APConnection(conn *sctp.SCTPConn) {
+	remoteAddr := conn.RemoteAddr().String()
+
+	defer func() {
+		if cuupCtx, err := cu.GetCUUPByConn(conn); err == nil {
+			cuupCtx.State = cuup.CUUP_LOST
+			cu.RemoveCUUP(cuupCtx)
+		}
+		cu.E1ConnMap.Delete(conn)
+		conn.Close()
+		cu.Info("CU-UP connection %s closed", remoteAddr)
+	}()
+
+	cu.Info("New CU-UP connection from %s", remoteAddr)
+
+	buf := make([]byte, 8192)
+
+	for {
+		n, info, err := conn.SCTPRead(buf)
+		if err != nil {
+			if err == io.EOF || err == io.ErrUnexpectedEOF {
+				return
+			}
+			if err == syscall.EAGAIN || err == syscall.EINTR {
+				continue
+			}
+			cu.Error("E1AP read error: %v", err)
+			return
+		}
+
+		if info == nil {
+			cu.Warn("Received nil info")
+			continue
+		}
+
+		if info.PPID != E1AP_PPID {
+			cu.Warn("Wrong PPID %d, expected %d", info.PPID, E1AP_PPID)
+			continue
+		}
+
+		rawMsg := make([]byte, n)
+		copy(rawMsg, buf[:n])
+
+		go cu.dispatchE1(rawMsg, conn)
+	}
+}
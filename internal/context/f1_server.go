@@ -1,16 +1,26 @@
 package context
 
 import (
+	"central-unit/internal/context/du"
+	"central-unit/internal/context/uecontext"
+	"encoding/binary"
+	"errors"
 	"fmt"
 	"io"
 	"net"
 	"syscall"
+	"time"
 
 	"github.com/ishidawataru/sctp"
+	"github.com/lvdund/ngap/ies"
 )
 
 const F1AP_PPID uint32 = 62
 
+// errAssociationDown ends the read loop of an association the SCTP stack
+// reports as lost or shut down
+var errAssociationDown = errors.New("SCTP association down")
+
 // initF1APServer initializes the SCTP server for F1AP (DU connections)
 func (cu *CuCpContext) initF1APServer() error {
 	// Resolve IP address
@@ -33,6 +43,7 @@ func (cu *CuCpContext) initF1APServer() error {
 			MaxAttempts:    2,
 			MaxInitTimeout: 2,
 		},
+		NotificationHandler: handleSctpNotification,
 	}
 
 	// Listen
@@ -111,9 +122,12 @@ func (cu *CuCpContext) handleF1APConnection(conn *sctp.SCTPConn) {
 	remoteAddr := conn.RemoteAddr().String()
 
 	defer func() {
-		cu.F1ConnMap.Delete(conn)
+		duId, known := cu.F1ConnMap.LoadAndDelete(conn)
 		conn.Close()
 		cu.Info("DU connection %s closed", remoteAddr)
+		if known {
+			cu.handleDUAssociationLost(duId.(int64))
+		}
 	}()
 
 	cu.Info("New DU connection from %s", remoteAddr)
@@ -129,6 +143,10 @@ func (cu *CuCpContext) handleF1APConnection(conn *sctp.SCTPConn) {
 				cu.Info("Connection %s closed", remoteAddr)
 				return
 			}
+			if errors.Is(err, errAssociationDown) {
+				cu.Warn("Association with %s is down", remoteAddr)
+				return
+			}
 			if err == syscall.EAGAIN || err == syscall.EINTR {
 				continue
 			}
@@ -152,3 +170,74 @@ func (cu *CuCpContext) handleF1APConnection(conn *sctp.SCTPConn) {
 		go cu.dispatchF1(rawMsg, conn)
 	}
 }
+
+// handleSctpNotification reads the association change and shutdown events
+// the F1 associations subscribe to. Notifications come in host byte order.
+func handleSctpNotification(data []byte) error {
+	if len(data) < 8 {
+		return nil
+	}
+	switch sctp.SCTPNotificationType(binary.NativeEndian.Uint16(data)) {
+	case sctp.SCTP_ASSOC_CHANGE:
+		if len(data) < 10 {
+			return nil
+		}
+		switch sctp.SCTPState(binary.NativeEndian.Uint16(data[8:])) {
+		case sctp.SCTP_COMM_LOST, sctp.SCTP_SHUTDOWN_COMP, sctp.SCTP_CANT_STR_ASSOC:
+			return errAssociationDown
+		}
+	case sctp.SCTP_SHUTDOWN_EVENT:
+		return errAssociationDown
+	}
+	return nil
+}
+
+// handleDUAssociationLost marks a DU lost once its F1 association is down.
+// Its UEs cannot be reached anymore: the AMF is asked to release the ones it
+// knows and the others are dropped. The DU context is kept for a while so
+// that the DU can reconnect.
+func (cu *CuCpContext) handleDUAssociationLost(duId int64) {
+	if cu.Ctx.Err() != nil {
+		return
+	}
+	duCtx, err := cu.GetDUById(duId)
+	if err != nil {
+		return
+	}
+	duCtx.State = du.DU_LOST
+
+	ues := cu.GetUEsOfDU(duId)
+	cu.Warn("F1 association with DU %d (%s) lost, releasing %d UE(s)", duId, duCtx.DuName, len(ues))
+	cause := radioNetworkCause(ies.CauseRadioNetworkRadioconnectionwithuelost)
+	for _, ue := range ues {
+		switch ue.State {
+		case uecontext.UE_INITIALIZED:
+			cu.dropUEContext(ue)
+		case uecontext.UE_DOWN:
+			// The UE Context Release Complete of the DU will not come
+			cu.completeUEContextRelease(ue)
+		default:
+			if err := cu.sendUEContextReleaseRequest(ue, cause); err != nil {
+				cu.Error("Failed to send UE Context Release Request: %v", err)
+				cu.dropUEContext(ue)
+			}
+		}
+	}
+	cu.updateRanConfiguration()
+
+	if cu.duReconnectTimeout == 0 {
+		cu.removeLostDU(duCtx)
+		return
+	}
+	duCtx.LostTimer = time.AfterFunc(cu.duReconnectTimeout, func() {
+		cu.removeLostDU(duCtx)
+	})
+}
+
+// removeLostDU forgets a lost DU, unless it has set up a new association
+// meanwhile
+func (cu *CuCpContext) removeLostDU(duCtx *du.GNBDU) {
+	if duCtx.State == du.DU_LOST && cu.DuPool.CompareAndDelete(duCtx.DuId, duCtx) {
+		cu.Info("Removed lost DU %d (%s)", duCtx.DuId, duCtx.DuName)
+	}
+}
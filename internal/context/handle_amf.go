@@ -3,12 +3,12 @@ package context
 import (
 	"central-unit/internal/common/logger"
 	"central-unit/internal/context/amfcontext"
+	"central-unit/internal/context/uecontext"
 	"central-unit/internal/transport"
 	"central-unit/pkg/model"
 	"fmt"
+	"time"
 
-	f1ap "github.com/JocelynWS/f1-gen"
-	f1ies "github.com/JocelynWS/f1-gen/ies"
 	"github.com/lvdund/ngap"
 	"github.com/lvdund/ngap/ies"
 	"github.com/lvdund/rrc"
@@ -30,25 +30,68 @@ func (cu *CuCpContext) newAmf(amfs model.AMF) *amfcontext.GNBAmf {
 	return amf
 }
 
+// Bounds of the back-off between two attempts to connect to an AMF or to set
+// up NG with it, the wait doubling at every failure
+const (
+	ngRetryMin = time.Second
+	ngRetryMax = time.Minute
+)
+
 func (cu *CuCpContext) initAmfConn(amf *amfcontext.GNBAmf) error {
-	// check AMF IP and AMF port.
-	remote := fmt.Sprintf("%s:%d", amf.AmfIp, amf.AmfPort)
-	local := fmt.Sprintf("%s:%d", cu.ControlInfo.ng_gnbIp, cu.ControlInfo.ng_gnbPort)
+	go cu.runAmfAssociation(amf)
+	return nil
+}
 
-	conn := transport.NewSctpConn(cu.ControlInfo.ng_gnbId, local, remote, cu.Ctx)
-	if err := conn.Connect(); err != nil {
-		cu.Fatal("Create SCTP connection err: %s", err.Error())
-	}
-	amf.Tnla.SctpConn = conn
-	cu.ControlInfo.n2 = conn
+// runAmfAssociation serves the NG association with an AMF and sets it up
+// again whenever it goes down, the F1 side being left untouched
+func (cu *CuCpContext) runAmfAssociation(amf *amfcontext.GNBAmf) {
+	for cu.connectAmf(amf) {
+		cu.SendNgSetupRequest(amf)
 
-	// listen NGAP messages from AMF.
-	go func() {
-		for rawMsg := range cu.ControlInfo.n2.Read() {
+		// listen NGAP messages from AMF.
+		conn := amf.Tnla.SctpConn
+		for rawMsg := range conn.Read() {
 			go cu.dispatch(amf, rawMsg)
 		}
-	}()
-	return nil
+		conn.Close()
+
+		cu.handleAmfAssociationLost(amf)
+		if !cu.waitNgRetry(ngRetryMin) {
+			return
+		}
+	}
+}
+
+// connectAmf dials the AMF until the SCTP association is up, false when the
+// CU-CP stops meanwhile
+func (cu *CuCpContext) connectAmf(amf *amfcontext.GNBAmf) bool {
+	remote := fmt.Sprintf("%s:%d", amf.AmfIp, amf.AmfPort)
+	local := fmt.Sprintf("%s:%d", cu.ControlInfo.ng_gnbIp, amf.LocalPort)
+
+	delay := ngRetryMin
+	for {
+		conn := transport.NewSctpConn(cu.ControlInfo.ng_gnbId, local, remote, cu.Ctx)
+		err := conn.Connect()
+		if err == nil {
+			amf.Tnla.SctpConn = conn
+			return true
+		}
+		cu.Error("SCTP connection to AMF %s failed, retrying in %v: %v", remote, delay, err)
+		if !cu.waitNgRetry(delay) {
+			return false
+		}
+		delay = min(2*delay, ngRetryMax)
+	}
+}
+
+// waitNgRetry sleeps unless the CU-CP stops
+func (cu *CuCpContext) waitNgRetry(delay time.Duration) bool {
+	select {
+	case <-time.After(delay):
+		return cu.Ctx.Err() == nil
+	case <-cu.Ctx.Done():
+		return false
+	}
 }
 
 func (cu *CuCpContext) dispatch(amf *amfcontext.GNBAmf, rawMsg []byte) {
@@ -58,8 +101,14 @@ func (cu *CuCpContext) dispatch(amf *amfcontext.GNBAmf, rawMsg []byte) {
 	}
 
 	ngapMsg, err, _ := ngap.NgapDecode(rawMsg)
+	if err != nil && isNgOverloadStop(rawMsg) {
+		cu.Info("Receive Overload Stop")
+		cu.handleOverloadStop(amf)
+		return
+	}
 	if err != nil {
 		cu.Error("Error decoding NGAP message in %s GNB: %v", cu.ControlInfo.ng_gnbId, err)
+		return
 	}
 	cu.Info("Receive NGAP message", ngapMsg.Present, ngapMsg.Message.ProcedureCode.Value)
 
@@ -79,6 +128,38 @@ func (cu *CuCpContext) dispatch(amf *amfcontext.GNBAmf, rawMsg []byte) {
 			cu.Info("Receive PDU Session Resource Setup Request")
 			innerMsg := ngapMsg.Message.Msg.(*ies.PDUSessionResourceSetupRequest)
 			cu.handlePduSessionResourceSetupRequest(amf, innerMsg)
+		case ies.ProcedureCode_PDUSessionResourceModify:
+			cu.Info("Receive PDU Session Resource Modify Request")
+			innerMsg := ngapMsg.Message.Msg.(*ies.PDUSessionResourceModifyRequest)
+			cu.handlePduSessionResourceModifyRequest(amf, innerMsg)
+		case ies.ProcedureCode_PDUSessionResourceRelease:
+			cu.Info("Receive PDU Session Resource Release Command")
+			innerMsg := ngapMsg.Message.Msg.(*ies.PDUSessionResourceReleaseCommand)
+			cu.handlePduSessionResourceReleaseCommand(amf, innerMsg)
+		case ies.ProcedureCode_UEContextRelease:
+			cu.Info("Receive UE Context Release Command")
+			innerMsg := ngapMsg.Message.Msg.(*ies.UEContextReleaseCommand)
+			cu.handleUEContextReleaseCommand(amf, innerMsg)
+		case ies.ProcedureCode_NGReset:
+			cu.Info("Receive NG Reset")
+			innerMsg := ngapMsg.Message.Msg.(*ies.NGReset)
+			cu.handleNgReset(amf, innerMsg)
+		case ies.ProcedureCode_AMFConfigurationUpdate:
+			cu.Info("Receive AMF Configuration Update")
+			innerMsg := ngapMsg.Message.Msg.(*ies.AMFConfigurationUpdate)
+			cu.handleAmfConfigurationUpdate(amf, innerMsg)
+		case ies.ProcedureCode_AMFStatusIndication:
+			cu.Info("Receive AMF Status Indication")
+			innerMsg := ngapMsg.Message.Msg.(*ies.AMFStatusIndication)
+			cu.handleAmfStatusIndication(amf, innerMsg)
+		case ies.ProcedureCode_OverloadStart:
+			cu.Info("Receive Overload Start")
+			innerMsg := ngapMsg.Message.Msg.(*ies.OverloadStart)
+			cu.handleOverloadStart(amf, innerMsg)
+		case ies.ProcedureCode_Paging:
+			cu.Info("Receive Paging")
+			innerMsg := ngapMsg.Message.Msg.(*ies.Paging)
+			cu.handleNgPaging(amf, innerMsg)
 		default:
 			cu.Warn("Received unknown NgapPduInitiatingMessage ProcedureCode 0x%x", ngapMsg.Message.ProcedureCode.Value)
 		}
@@ -88,36 +169,93 @@ func (cu *CuCpContext) dispatch(amf *amfcontext.GNBAmf, rawMsg []byte) {
 			cu.Info("Receive NG Setup Response")
 			innerMsg := ngapMsg.Message.Msg.(*ies.NGSetupResponse)
 			cu.handlerNgSetupResponse(amf, innerMsg)
+		case ies.ProcedureCode_NGReset:
+			cu.Info("Receive NG Reset Acknowledge")
+		case ies.ProcedureCode_RANConfigurationUpdate:
+			cu.Info("Receive RAN Configuration Update Acknowledge")
+			cu.handleRanConfigurationUpdateAcknowledge(amf)
 		default:
 			cu.Warn("Received unknown NgapPduSuccessfulOutcome ProcedureCode 0x%x", ngapMsg.Message.ProcedureCode.Value)
 		}
+	case ies.NgapPduUnsuccessfulOutcome:
+		switch ngapMsg.Message.ProcedureCode.Value {
+		case ies.ProcedureCode_NGSetup:
+			cu.Info("Receive NG Setup Failure")
+			innerMsg := ngapMsg.Message.Msg.(*ies.NGSetupFailure)
+			cu.handleNgSetupFailure(amf, innerMsg)
+		case ies.ProcedureCode_RANConfigurationUpdate:
+			cu.Info("Receive RAN Configuration Update Failure")
+			innerMsg := ngapMsg.Message.Msg.(*ies.RANConfigurationUpdateFailure)
+			cu.handleRanConfigurationUpdateFailure(amf, innerMsg)
+		default:
+			cu.Warn("Received unknown NgapPduUnsuccessfulOutcome ProcedureCode 0x%x", ngapMsg.Message.ProcedureCode.Value)
+		}
 	default:
 		cu.Warn("Received unknown NGAP message present 0x%x", ngapMsg.Present)
 	}
 
 }
 
+// handleNgSetupFailure tries the NG Setup again on the same association,
+// after the TimeToWait of the AMF or else after the back-off
+func (cu *CuCpContext) handleNgSetupFailure(amf *amfcontext.GNBAmf, msg *ies.NGSetupFailure) {
+	delay := max(amf.NgSetupBackoff, ngRetryMin)
+	if msg.TimeToWait != nil {
+		delay = ngTimeToWait(msg.TimeToWait)
+	}
+	amf.NgSetupBackoff = min(2*delay, ngRetryMax)
+	cu.Error("NG Setup rejected by AMF %s:%d (cause choice %d), retrying in %v",
+		amf.AmfIp, amf.AmfPort, msg.Cause.Choice, delay)
+
+	conn := amf.Tnla.SctpConn
+	amf.NgSetupTimer = time.AfterFunc(delay, func() {
+		if amf.Tnla.SctpConn == conn && !amf.IsSetUp() && cu.Ctx.Err() == nil {
+			cu.SendNgSetupRequest(amf)
+		}
+	})
+}
+
+func ngTimeToWait(ttw *ies.TimeToWait) time.Duration {
+	switch ttw.Value {
+	case ies.TimeToWaitV1S:
+		return time.Second
+	case ies.TimeToWaitV2S:
+		return 2 * time.Second
+	case ies.TimeToWaitV5S:
+		return 5 * time.Second
+	case ies.TimeToWaitV10S:
+		return 10 * time.Second
+	case ies.TimeToWaitV20S:
+		return 20 * time.Second
+	default:
+		return time.Minute
+	}
+}
+
 func (cu *CuCpContext) handlerNgSetupResponse(amf *amfcontext.GNBAmf, msg *ies.NGSetupResponse) {
 	cu.Info("Receive NGSetupResponse")
-	var plmn string
 
 	amfName := msg.AMFName
 	amf.Name = string(amfName)
 
+	amf.NgSetupBackoff = 0
 	amf.RelativeAmfCapacity = msg.RelativeAMFCapacity
+	amf.SetServedGuamis(msg.ServedGUAMIList)
+	amf.SetPlmnSupport(msg.PLMNSupportList)
 
-	for _, items := range msg.PLMNSupportList {
-
-		plmn = fmt.Sprintf("%x", items.PLMNIdentity)
-		amf.AddedPlmn(plmn)
+	amf.State = amfcontext.AMF_ACTIVE
+	amf.Overload = nil
+	cu.Info("AMF Name: %s - state: Active - capacity: %d", amf.Name, amf.RelativeAmfCapacity)
+	cu.logAmfSupport(amf)
 
-		for _, slice := range items.SliceSupportList {
-			amf.AddedSlice(fmt.Sprintf("%x", slice.SNSSAI.SST), fmt.Sprintf("%x", slice.SNSSAI.SD))
-		}
+	// Only the first setup is waited for
+	select {
+	case cu.IsReadyNgap <- true:
+	default:
 	}
+}
 
-	amf.State = amfcontext.AMF_ACTIVE
-	cu.Info("AMF Name: %s - state: Active - capacity: %d", amf.Name, amf.RelativeAmfCapacity)
+func (cu *CuCpContext) logAmfSupport(amf *amfcontext.GNBAmf) {
 	for i := range amf.LenPlmn {
 		mcc, mnc := amf.GetPlmnSupport(i)
 		cu.Info("\tPLMNs Identities Supported by AMF -- mcc:%s mnc:%s", mcc, mnc)
@@ -126,8 +264,10 @@ func (cu *CuCpContext) handlerNgSetupResponse(amf *amfcontext.GNBAmf, msg *ies.N
 		sst, sd := amf.GetSliceSupport(i)
 		cu.Info("\tList of AMF slices Supported by AMF -- sst:%s sd:%s", sst, sd)
 	}
-
-	cu.IsReadyNgap <- true
+	for _, guami := range amf.ServedGuamis {
+		cu.Info("\tGUAMI Served by AMF -- plmn:%s region:%x set:%d pointer:%d",
+			guami.Plmn, guami.RegionId.Bytes, amfSetId(guami.SetId), amfPointer(guami.Pointer))
+	}
 }
 
 func (cu *CuCpContext) handleNgDownlinkNasTransport(amf *amfcontext.GNBAmf, msg *ies.DownlinkNASTransport) {
@@ -137,14 +277,26 @@ func (cu *CuCpContext) handleNgDownlinkNasTransport(amf *amfcontext.GNBAmf, msg
 		return
 	}
 
-	duCtx, err := cu.GetDUForUE(ue)
-	if err != nil {
-		cu.Error("DU not found for UE (DuId=%d): %v", ue.DuId, err)
+	ue.AmfUeNgapId = msg.AMFUENGAPID
+
+	// a UE in RRC_INACTIVE gets the NAS PDU once it has resumed
+	if ue.State == uecontext.UE_INACTIVE {
+		ue.Suspension.PendingNas = append(ue.Suspension.PendingNas, msg.NASPDU)
+		if err := cu.SendRanPaging(ue); err != nil {
+			cu.Error("Failed to page UE RAN-UE-NGAP-ID=%d for a NAS PDU: %v", ue.RanUeNgapId, err)
+		}
 		return
 	}
 
-	ue.AmfUeNgapId = msg.AMFUENGAPID
+	if err = cu.sendDlNasPdu(ue, msg.NASPDU); err != nil {
+		cu.Error("Error sending Downlink NAS Transport to DU: %v", err)
+		return
+	}
+	cu.Info("Send DL RRC Message Transfer to DU %d", ue.DuId)
+}
 
+// sendDlNasPdu carries a NAS PDU to the UE in a DLInformationTransfer
+func (cu *CuCpContext) sendDlNasPdu(ue *uecontext.GNBUe, nasPdu []byte) error {
 	rrcmsg := rrcies.DL_DCCH_Message{
 		Message: rrcies.DL_DCCH_MessageType{
 			Choice: rrcies.DL_DCCH_MessageType_Choice_C1,
@@ -156,7 +308,7 @@ func (cu *CuCpContext) handleNgDownlinkNasTransport(amf *amfcontext.GNBAmf, msg
 						Choice: rrcies.DLInformationTransfer_CriticalExtensions_Choice_DlInformationTransfer,
 						DlInformationTransfer: &rrcies.DLInformationTransfer_IEs{
 							DedicatedNAS_Message: &rrcies.DedicatedNAS_Message{
-								Value: msg.NASPDU,
+								Value: nasPdu,
 							},
 						},
 					},
@@ -167,31 +319,9 @@ func (cu *CuCpContext) handleNgDownlinkNasTransport(amf *amfcontext.GNBAmf, msg
 
 	buf, err := rrc.Encode(&rrcmsg)
 	if err != nil {
-		cu.Error("Error encoding DL RRC Message Transfer: %v", err)
-		return
-	}
-
-	f1rrcdl := f1ies.DLRRCMessageTransfer{
-		GNBCUUEF1APID: int64(ue.GnbCuUeF1apId),
-		GNBDUUEF1APID: int64(ue.DuUeId),
-		SRBID:         0,
-		RRCContainer:  buf,
-		ExecuteDuplication: &f1ies.ExecuteDuplication{
-			Value: 0,
-		},
-		RedirectedRRCmessage: []byte{0},
-	}
-
-	f1apBytes, err := f1ap.F1apEncode(&f1rrcdl)
-	if err != nil {
-		cu.Error("Error encoding DL RRC Message Transfer: %v", err)
-		return
-	}
-	err = duCtx.SendF1ap(f1apBytes)
-	if err != nil {
-		cu.Error("Error sending Downlink NAS Transport to DU: %v", err)
+		return fmt.Errorf("failed to encode DL Information Transfer: %w", err)
 	}
-	cu.Info("Send DL RRC Message Transfer to .DU %d", duCtx.DuId)
+	return cu.sendDlRrcMessage(ue, buf)
 }
 
 func (cu *CuCpContext) handlerInitialContextSetupRequest(amf *amfcontext.GNBAmf, msg *ies.InitialContextSetupRequest) {
@@ -236,21 +366,20 @@ func (cu *CuCpContext) handlerInitialContextSetupRequest(amf *amfcontext.GNBAmf,
 		maskedImeisv = fmt.Sprintf("%x", msg.MaskedIMEISV)
 	}
 
-	// TODO using for create new security context between UE and cu.
-	// TODO algorithms for create new security context between UE and cu.
 	ueSecurityCapabilities = msg.UESecurityCapabilities
 
-	// if msg.PDUSessionResourceSetupListCxtReq == nil {
-	// 	cu.Warn("PDUSessionResourceSetupListCxtReq is missing")
-	// }
-	// pDUSessionResourceSetupListCxtReq = msg.PDUSessionResourceSetupListCxtReq
-
 	ue, err := cu.GetUEByNgapId(msg.RANUENGAPID)
 	if err != nil {
 		cu.Error("UE not found for RAN-UE-NGAP-ID %d: %v", msg.RANUENGAPID, err)
 		return
 	}
+	ue.AmfUeNgapId = msg.AMFUENGAPID
 	ue.CreateUeContext(mobilityRestrict, maskedImeisv, allowednssai, &ueSecurityCapabilities)
+	if msg.UEAggregateMaximumBitRate != nil {
+		ue.UeAmbr = msg.UEAggregateMaximumBitRate
+	}
+	ue.InactiveAssistance = msg.CoreNetworkAssistanceInformationForInactive
+	ue.InactiveReport = msg.RRCInactiveTransitionReportRequest
 
 	// show UE context.
 	cu.Info(" Context was created with successful")
@@ -260,10 +389,5 @@ func (cu *CuCpContext) handlerInitialContextSetupRequest(amf *amfcontext.GNBAmf,
 	cu.Info(" Masked Imeisv: %s", ue.MaskedIMEISV)
 	cu.Info(" lowed Nssai (Sst-Sd): %v", allowednssai)
 
-	ue.RegistrationAccept = msg.NASPDU
-	// getDUdata, _ := cu.DuPool.Load(0)
-	// duCtx := getDUdata.(*du.GNBDU)
-	// if msg.NASPDU != nil {
-	// 	duCtx.SendF1ap(msg.NASPDU)
-	// }
+	cu.startInitialContextSetup(ue, msg)
 }
@@ -0,0 +1,238 @@
+package context
+
+import (
+	"bytes"
+	"central-unit/internal/context/amfcontext"
+	"central-unit/internal/transport"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/lvdund/ngap"
+	"github.com/lvdund/ngap/aper"
+	"github.com/lvdund/ngap/ies"
+)
+
+// handleAmfConfigurationUpdate applies the changes an AMF made to its
+// configuration, then acknowledges with the TNL associations it could add
+func (cu *CuCpContext) handleAmfConfigurationUpdate(amf *amfcontext.GNBAmf, msg *ies.AMFConfigurationUpdate) {
+	if msg.AMFName != nil {
+		amf.Name = string(msg.AMFName)
+	}
+	if msg.ServedGUAMIList != nil {
+		amf.SetServedGuamis(msg.ServedGUAMIList)
+	}
+	if msg.RelativeAMFCapacity != nil {
+		amf.RelativeAmfCapacity = *msg.RelativeAMFCapacity
+	}
+	if msg.PLMNSupportList != nil {
+		amf.SetPlmnSupport(msg.PLMNSupportList)
+	}
+	cu.Info("AMF Name: %s - configuration updated - capacity: %d", amf.Name, amf.RelativeAmfCapacity)
+	cu.logAmfSupport(amf)
+
+	for _, item := range msg.AMFTNLAssociationToRemoveList {
+		cu.removeAmfTnla(amf, item.AMFTNLAssociationAddress)
+	}
+	for _, item := range msg.AMFTNLAssociationToUpdateList {
+		tnla := findAmfTnla(amf, tnlAddress(item.AMFTNLAssociationAddress))
+		if tnla == nil {
+			cu.Warn("AMF %s updates an unknown TNL association", amf.Name)
+			continue
+		}
+		if item.TNLAssociationUsage != nil {
+			tnla.Usage = item.TNLAssociationUsage.Value
+		}
+		if item.TNLAddressWeightFactor != nil {
+			tnla.TnlaWeightFactor = *item.TNLAddressWeightFactor
+		}
+	}
+
+	ack := ies.AMFConfigurationUpdateAcknowledge{}
+	for _, item := range msg.AMFTNLAssociationToAddList {
+		if err := cu.addAmfTnla(amf, item); err != nil {
+			cu.Error("Failed to add TNL association with AMF %s: %v", amf.Name, err)
+			ack.AMFTNLAssociationFailedToSetupList = append(ack.AMFTNLAssociationFailedToSetupList, ies.TNLAssociationItem{
+				TNLAssociationAddress: item.AMFTNLAssociationAddress,
+				Cause: ies.Cause{
+					Choice:    ies.CausePresentTransport,
+					Transport: &ies.CauseTransport{Value: ies.CauseTransportTransportresourceunavailable},
+				},
+			})
+			continue
+		}
+		ack.AMFTNLAssociationSetupList = append(ack.AMFTNLAssociationSetupList,
+			ies.AMFTNLAssociationSetupItem{AMFTNLAssociationAddress: item.AMFTNLAssociationAddress})
+	}
+
+	ngapBytes, err := ngap.NgapEncode(&ack)
+	if err == nil {
+		err = amf.SendNgap(ngapBytes)
+	}
+	if err != nil {
+		cu.Error("Failed to send AMF Configuration Update Acknowledge to AMF %s: %v", amf.Name, err)
+		return
+	}
+	cu.Info("AMF Configuration Update Acknowledge sent to AMF %s", amf.Name)
+}
+
+// addAmfTnla sets up one more SCTP association with an AMF. Its messages are
+// dispatched like those of the main association, but it is not set up again
+// when it goes down.
+func (cu *CuCpContext) addAmfTnla(amf *amfcontext.GNBAmf, item ies.AMFTNLAssociationToAddItem) error {
+	ip := tnlAddress(item.AMFTNLAssociationAddress)
+	if ip == nil {
+		return fmt.Errorf("unsupported transport layer address")
+	}
+	if findAmfTnla(amf, ip) != nil {
+		return nil
+	}
+
+	remote := net.JoinHostPort(ip.String(), strconv.Itoa(amf.AmfPort))
+	local := net.JoinHostPort(cu.ControlInfo.ng_gnbIp, "0")
+	conn := transport.NewSctpConn(cu.ControlInfo.ng_gnbId, local, remote, cu.Ctx)
+	if err := conn.Connect(); err != nil {
+		return err
+	}
+	tnla := &amfcontext.TNLAssociation{
+		SctpConn:         conn,
+		Address:          ip,
+		TnlaWeightFactor: item.TNLAddressWeightFactor,
+	}
+	if item.TNLAssociationUsage != nil {
+		tnla.Usage = item.TNLAssociationUsage.Value
+	}
+	amf.ExtraTnlas = append(amf.ExtraTnlas, tnla)
+	cu.Info("TNL association %s added to AMF %s", remote, amf.Name)
+
+	go func() {
+		for rawMsg := range conn.Read() {
+			go cu.dispatch(amf, rawMsg)
+		}
+		cu.removeAmfTnla(amf, item.AMFTNLAssociationAddress)
+	}()
+	return nil
+}
+
+// removeAmfTnla closes an association added by AMF Configuration Update, the
+// one from the configuration is kept
+func (cu *CuCpContext) removeAmfTnla(amf *amfcontext.GNBAmf, address ies.CPTransportLayerInformation) {
+	ip := tnlAddress(address)
+	for i, tnla := range amf.ExtraTnlas {
+		if ip.Equal(tnla.Address) {
+			amf.ExtraTnlas = append(amf.ExtraTnlas[:i], amf.ExtraTnlas[i+1:]...)
+			tnla.SctpConn.Close()
+			cu.Info("TNL association %s removed from AMF %s", ip, amf.Name)
+			return
+		}
+	}
+	if ip.Equal(net.ParseIP(amf.AmfIp)) {
+		cu.Warn("AMF %s removes its configured TNL association, kept", amf.Name)
+	}
+}
+
+func findAmfTnla(amf *amfcontext.GNBAmf, ip net.IP) *amfcontext.TNLAssociation {
+	if ip == nil {
+		return nil
+	}
+	if ip.Equal(net.ParseIP(amf.AmfIp)) {
+		return &amf.Tnla
+	}
+	for _, tnla := range amf.ExtraTnlas {
+		if ip.Equal(tnla.Address) {
+			return tnla
+		}
+	}
+	return nil
+}
+
+// tnlAddress reads the IP address of a TNL endpoint, the IPv4 one when both
+// are given (TS 38.414 7)
+func tnlAddress(info ies.CPTransportLayerInformation) net.IP {
+	if info.Choice != ies.CPTransportLayerInformationPresentEndpointipaddress || info.EndpointIPAddress == nil {
+		return nil
+	}
+	addr := info.EndpointIPAddress
+	switch {
+	case addr.NumBits == 32 && len(addr.Bytes) >= 4, addr.NumBits == 160 && len(addr.Bytes) >= 20:
+		return net.IP(addr.Bytes[:4])
+	case addr.NumBits == 128 && len(addr.Bytes) >= 16:
+		return net.IP(addr.Bytes[:16])
+	}
+	return nil
+}
+
+// handleAmfStatusIndication marks the GUAMIs an AMF no longer serves. New UEs
+// of these GUAMIs go to their backup AMF, or to another AMF of the set.
+func (cu *CuCpContext) handleAmfStatusIndication(amf *amfcontext.GNBAmf, msg *ies.AMFStatusIndication) {
+	for _, item := range msg.UnavailableGUAMIList {
+		plmn := fmt.Sprintf("%x", item.GUAMI.PLMNIdentity)
+		owner, guami := cu.findGuami(func(g *amfcontext.Guami) bool {
+			return g.Plmn == plmn &&
+				amfRegionId(g.RegionId) == amfRegionId(item.GUAMI.AMFRegionID) &&
+				amfSetId(g.SetId) == amfSetId(item.GUAMI.AMFSetID) &&
+				amfPointer(g.Pointer) == amfPointer(item.GUAMI.AMFPointer)
+		})
+		if guami == nil {
+			cu.Warn("AMF %s reports an unknown GUAMI unavailable: plmn:%s set:%d pointer:%d",
+				amf.Name, plmn, amfSetId(item.GUAMI.AMFSetID), amfPointer(item.GUAMI.AMFPointer))
+			continue
+		}
+		guami.Unavailable = true
+		if item.BackupAMFName != nil {
+			guami.BackupAMF = string(item.BackupAMFName)
+		}
+		cu.Warn("GUAMI plmn:%s set:%d pointer:%d of AMF %s unavailable, backup AMF: %q",
+			plmn, amfSetId(guami.SetId), amfPointer(guami.Pointer), owner.Name, guami.BackupAMF)
+	}
+}
+
+// handleOverloadStart restricts the RRC establishments given to an AMF. An
+// overload limited to some slices is not applied, the slices of a UE being
+// unknown at RRC establishment.
+func (cu *CuCpContext) handleOverloadStart(amf *amfcontext.GNBAmf, msg *ies.OverloadStart) {
+	if msg.AMFOverloadResponse == nil && msg.AMFTrafficLoadReductionIndication == nil {
+		cu.Warn("AMF %s overloaded for %d slice group(s) only, ignored", amf.Name, len(msg.OverloadStartNSSAIList))
+		return
+	}
+
+	overload := &amfcontext.Overload{}
+	if response := msg.AMFOverloadResponse; response != nil &&
+		response.Choice == ies.OverloadResponsePresentOverloadaction && response.OverloadAction != nil {
+		action := response.OverloadAction.Value
+		overload.Action = &action
+	}
+	if msg.AMFTrafficLoadReductionIndication != nil {
+		overload.Reduction = *msg.AMFTrafficLoadReductionIndication
+	}
+	amf.Overload = overload
+	amf.State = amfcontext.AMF_OVERLOADED
+
+	action := "none"
+	if overload.Action != nil {
+		action = fmt.Sprint(*overload.Action)
+	}
+	cu.Warn("AMF %s overloaded - action: %s - traffic reduction: %d%%", amf.Name, action, overload.Reduction)
+}
+
+func (cu *CuCpContext) handleOverloadStop(amf *amfcontext.GNBAmf) {
+	if amf.State == amfcontext.AMF_OVERLOADED {
+		amf.State = amfcontext.AMF_ACTIVE
+	}
+	amf.Overload = nil
+	cu.Info("AMF %s no longer overloaded", amf.Name)
+}
+
+// isNgOverloadStop recognizes an Overload Stop, which ngap cannot decode
+func isNgOverloadStop(rawMsg []byte) bool {
+	r := aper.NewReader(bytes.NewReader(rawMsg))
+	if _, err := r.ReadBool(); err != nil {
+		return false
+	}
+	present, err := r.ReadChoice(2, false)
+	if err != nil || uint8(present) != ies.NgapPduInitiatingMessage {
+		return false
+	}
+	code, err := r.ReadInteger(&aper.Constraint{Lb: 0, Ub: 255}, false)
+	return err == nil && code == ies.ProcedureCode_OverloadStop
+}
@@ -0,0 +1,98 @@
+package context
+
+import (
+	"central-unit/pkg/e1ap"
+	"central-unit/pkg/e1ap/ies"
+
+	"github.com/ishidawataru/sctp"
+)
+
+func (cu *CuCpContext) dispatchE1(rawMsg []byte, conn *sctp.SCTPConn) {
+	if len(rawMsg) == 0 {
+		cu.Error("E1AP message is empty")
+		return
+	}
+
+	pdu, err, diagnostics := e1ap.E1apDecode(rawMsg)
+	if err != nil {
+		cu.Error("Error decoding E1AP message from CU-UP: %v", err.Error())
+		return
+	}
+
+	if pdu.Present == ies.E1apPresentNothing || pdu.Message.Msg == nil {
+		cu.Warn("Decoded E1AP PDU is nil or has no message")
+		return
+	}
+
+	switch pdu.Present {
+	case ies.E1apPduInitiatingMessage:
+		switch pdu.Message.ProcedureCode.Value {
+		case ies.ProcedureCode_GNBCUUPE1Setup:
+			cu.Info("Receive GNB-CU-UP E1 Setup Request from CU-UP")
+			if setupReq, ok := pdu.Message.Msg.(*ies.GNBCUUPE1SetupRequest); ok {
+				cu.handleE1SetupRequest(setupReq, diagnostics, conn)
+			} else {
+				cu.Error("Failed to cast GNB-CU-UP E1 Setup Request")
+			}
+		case ies.ProcedureCode_DLDataNotification:
+			cu.Info("Receive DL Data Notification from CU-UP")
+			if notification, ok := pdu.Message.Msg.(*ies.DLDataNotification); ok {
+				cu.handleDLDataNotification(notification)
+			} else {
+				cu.Error("Failed to cast DL Data Notification")
+			}
+		default:
+			cu.Warn("Received unknown E1AP message with procedure code %d", pdu.Message.ProcedureCode.Value)
+		}
+
+	case ies.E1apPduSuccessfulOutcome:
+		switch pdu.Message.ProcedureCode.Value {
+		case ies.ProcedureCode_BearerContextSetup:
+			cu.Info("Receive Bearer Context Setup Response from CU-UP")
+			if resp, ok := pdu.Message.Msg.(*ies.BearerContextSetupResponse); ok {
+				cu.handleBearerContextSetupResponse(resp)
+			} else {
+				cu.Error("Failed to cast Bearer Context Setup Response")
+			}
+		case ies.ProcedureCode_BearerContextModification:
+			cu.Info("Receive Bearer Context Modification Response from CU-UP")
+			if resp, ok := pdu.Message.Msg.(*ies.BearerContextModificationResponse); ok {
+				cu.handleBearerContextModificationResponse(resp)
+			} else {
+				cu.Error("Failed to cast Bearer Context Modification Response")
+			}
+		case ies.ProcedureCode_BearerContextRelease:
+			cu.Info("Receive Bearer Context Release Complete from CU-UP")
+			if complete, ok := pdu.Message.Msg.(*ies.BearerContextReleaseComplete); ok {
+				cu.handleBearerContextReleaseComplete(complete)
+			} else {
+				cu.Error("Failed to cast Bearer Context Release Complete")
+			}
+		default:
+			cu.Warn("Received unknown E1AP successful outcome with procedure code %d", pdu.Message.ProcedureCode.Value)
+		}
+
+	case ies.E1apPduUnsuccessfulOutcome:
+		switch pdu.Message.ProcedureCode.Value {
+		case ies.ProcedureCode_BearerContextSetup:
+			cu.Info("Receive Bearer Context Setup Failure from CU-UP")
+			if failure, ok := pdu.Message.Msg.(*ies.BearerContextSetupFailure); ok {
+				cu.handleBearerContextSetupFailure(failure)
+			} else {
+				cu.Error("Failed to cast Bearer Context Setup Failure")
+			}
+		case ies.ProcedureCode_BearerContextModification:
+			cu.Info("Receive Bearer Context Modification Failure from CU-UP")
+			if failure, ok := pdu.Message.Msg.(*ies.BearerContextModificationFailure); ok {
+				cu.handleBearerContextModificationFailure(failure)
+			} else {
+				cu.Error("Failed to cast Bearer Context Modification Failure")
+			}
+		default:
+			cu.Warn("Received unknown E1AP unsuccessful outcome with procedure code %d", pdu.Message.ProcedureCode.Value)
+		}
+
+	default:
+		cu.Warn("Received E1AP message with unknown present type %d", pdu.Present)
+	}
+}
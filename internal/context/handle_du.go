@@ -44,6 +44,27 @@ func (cu *CuCpContext) dispatchF1(rawMsg []byte, conn *sctp.SCTPConn) {
 			} else {
 				cu.Error("Failed to cast Initial UL RRC Message Transfer")
 			}
+		case ies.ProcedureCode_UEContextReleaseRequest:
+			cu.Info("Receive UE Context Release Request from DU")
+			if releaseReq, ok := pdu.Message.Msg.(*ies.UEContextReleaseRequest); ok {
+				cu.handleF1UEContextReleaseRequest(releaseReq)
+			} else {
+				cu.Error("Failed to cast UE Context Release Request")
+			}
+		case ies.ProcedureCode_GNBDUConfigurationUpdate:
+			cu.Info("Receive gNB-DU Configuration Update from DU")
+			if updateMsg, ok := pdu.Message.Msg.(*ies.GNBDUConfigurationUpdate); ok {
+				cu.handleGNBDUConfigurationUpdate(updateMsg, conn)
+			} else {
+				cu.Error("Failed to cast gNB-DU Configuration Update")
+			}
+		case ies.ProcedureCode_Reset:
+			cu.Info("Receive F1 Reset from DU")
+			if resetMsg, ok := pdu.Message.Msg.(*ies.Reset); ok {
+				cu.handleF1Reset(resetMsg, conn)
+			} else {
+				cu.Error("Failed to cast F1 Reset")
+			}
 		default:
 			cu.Warn("Received unknown F1AP message with procedure code %d", pdu.Message.ProcedureCode)
 		}
@@ -53,7 +74,7 @@ func (cu *CuCpContext) dispatchF1(rawMsg []byte, conn *sctp.SCTPConn) {
 		case ies.ProcedureCode_UEContextSetup:
 			cu.Info("Receive UE Context Setup Response from DU")
 			if ueContextSetupResponse, ok := pdu.Message.Msg.(*ies.UEContextSetupResponse); ok {
-				cu.handleRRCUEContextSetupResponse(ueContextSetupResponse)
+				cu.handleF1UEContextSetupResponse(ueContextSetupResponse)
 			} else {
 				cu.Error("Failed to cast UE Context Setup Response")
 			}
@@ -66,9 +87,50 @@ func (cu *CuCpContext) dispatchF1(rawMsg []byte, conn *sctp.SCTPConn) {
 			} else {
 				cu.Error("Failed to cast UE Context Modification Response")
 			}
+		case ies.ProcedureCode_UEContextRelease:
+			cu.Info("Receive UE Context Release Complete from DU")
+			if releaseComplete, ok := pdu.Message.Msg.(*ies.UEContextReleaseComplete); ok {
+				cu.handleF1UEContextReleaseComplete(releaseComplete)
+			} else {
+				cu.Error("Failed to cast UE Context Release Complete")
+			}
+		case ies.ProcedureCode_GNBCUConfigurationUpdate:
+			cu.Info("Receive gNB-CU Configuration Update Acknowledge from DU")
+			if updateAck, ok := pdu.Message.Msg.(*ies.GNBCUConfigurationUpdateAcknowledge); ok {
+				cu.handleGNBCUConfigurationUpdateAcknowledge(updateAck, conn)
+			} else {
+				cu.Error("Failed to cast gNB-CU Configuration Update Acknowledge")
+			}
+		case ies.ProcedureCode_Reset:
+			cu.Info("Receive F1 Reset Acknowledge from DU")
 		}
 
 	case ies.F1apPduUnsuccessfulOutcome:
+		switch pdu.Message.ProcedureCode.Value {
+		case ies.ProcedureCode_UEContextSetup:
+			cu.Info("Receive UE Context Setup Failure from DU")
+			if ueContextSetupFailure, ok := pdu.Message.Msg.(*ies.UEContextSetupFailure); ok {
+				cu.handleF1UEContextSetupFailure(ueContextSetupFailure)
+			} else {
+				cu.Error("Failed to cast UE Context Setup Failure")
+			}
+		case ies.ProcedureCode_UEContextModification:
+			cu.Info("Receive UE Context Modification Failure from DU")
+			if ueContextModFailure, ok := pdu.Message.Msg.(*ies.UEContextModificationFailure); ok {
+				if err := cu.handleF1UEContextModificationFailure(ueContextModFailure); err != nil {
+					cu.Error("Failed to handle UE Context Modification Failure: %v", err)
+				}
+			} else {
+				cu.Error("Failed to cast UE Context Modification Failure")
+			}
+		case ies.ProcedureCode_GNBCUConfigurationUpdate:
+			cu.Info("Receive gNB-CU Configuration Update Failure from DU")
+			if updateFailure, ok := pdu.Message.Msg.(*ies.GNBCUConfigurationUpdateFailure); ok {
+				cu.handleGNBCUConfigurationUpdateFailure(updateFailure, conn)
+			} else {
+				cu.Error("Failed to cast gNB-CU Configuration Update Failure")
+			}
+		}
 
 	default:
 		cu.Warn("Received F1AP message with unknown present type %d", pdu.Present)
@@ -0,0 +1,272 @@
+package context
+
+import (
+	"central-unit/internal/context/du"
+	f1ext "central-unit/pkg/f1ap/ies"
+	"fmt"
+	"slices"
+
+	f1ap "github.com/JocelynWS/f1-gen"
+	"github.com/JocelynWS/f1-gen/ies"
+	"github.com/ishidawataru/sctp"
+)
+
+// handleGNBDUConfigurationUpdate applies the cells added, modified and deleted
+// by the DU and acknowledges with the cells the CU-CP activates or deactivates
+// as a result
+func (cu *CuCpContext) handleGNBDUConfigurationUpdate(msg *ies.GNBDUConfigurationUpdate, conn *sctp.SCTPConn) {
+	duCtx, err := cu.GetDUByConn(conn)
+	if err != nil {
+		cu.Error("gNB-DU Configuration Update from an unknown DU: %v", err)
+		return
+	}
+	cu.Info("gNB-DU Configuration Update from DU %d: %d cell(s) to add, %d to modify, %d to delete, %d status",
+		duCtx.DuId, len(msg.ServedCellsToAddList), len(msg.ServedCellsToModifyList),
+		len(msg.ServedCellsToDeleteList), len(msg.CellsStatusList))
+
+	if msg.GNBDUID != nil && *msg.GNBDUID != duCtx.DuId {
+		cu.Error("gNB-DU Configuration Update carries gNB-DU ID %d, DU is set up as %d", *msg.GNBDUID, duCtx.DuId)
+		if err := cu.sendGNBDUConfigurationUpdateFailure(duCtx, msg.TransactionID, f1MiscCause(ies.CauseMiscUnspecified)); err != nil {
+			cu.Error("Error sending gNB-DU Configuration Update Failure: %v", err)
+		}
+		return
+	}
+
+	var toActivate []ies.CellstobeActivatedListItem
+	var toDeactivate []ies.CellsToBeDeactivatedListItem
+
+	for _, item := range msg.ServedCellsToAddList {
+		cell := cu.decodeServedCell(ies.GNBDUServedCellsItem{
+			ServedCellInformation:  item.ServedCellInformation,
+			GNBDUSystemInformation: item.GNBDUSystemInformation,
+		})
+		if _, ok := cu.checkServedCell(duCtx, &cell); !ok {
+			continue
+		}
+		cell.Active = true
+		duCtx.ServedCells = append(duCtx.ServedCells, cell)
+		toActivate = append(toActivate, ies.CellstobeActivatedListItem{
+			NRCGI: cell.NRCGI,
+			NRPCI: &ies.NRPCI{Value: int64(cell.PCI)},
+		})
+	}
+
+	// A modified cell that no longer passes the checks is deactivated and
+	// forgotten, the DU has to add it again
+	for _, item := range msg.ServedCellsToModifyList {
+		oldID := cu.extractCellIDValue(item.OldNRCGI.NRCellIdentity)
+		cell := cu.decodeServedCell(ies.GNBDUServedCellsItem{
+			ServedCellInformation:  item.ServedCellInformation,
+			GNBDUSystemInformation: item.GNBDUSystemInformation,
+		})
+		old := duCtx.GetCellByID(oldID)
+		if old == nil {
+			cu.Warn("DU %d modifies unknown cell %d", duCtx.DuId, oldID)
+			continue
+		}
+		if item.GNBDUSystemInformation == nil {
+			cell.MIB, cell.SIB1 = old.MIB, old.SIB1
+		}
+		cell.OutOfService = old.OutOfService
+		wasActive := old.Active
+		duCtx.RemoveCell(oldID)
+
+		if _, ok := cu.checkServedCell(duCtx, &cell); !ok {
+			if wasActive {
+				toDeactivate = append(toDeactivate, ies.CellsToBeDeactivatedListItem{NRCGI: item.OldNRCGI})
+			}
+			continue
+		}
+		cell.Active = true
+		duCtx.ServedCells = append(duCtx.ServedCells, cell)
+		if !wasActive || oldID != cell.CellID {
+			toActivate = append(toActivate, ies.CellstobeActivatedListItem{
+				NRCGI: cell.NRCGI,
+				NRPCI: &ies.NRPCI{Value: int64(cell.PCI)},
+			})
+		}
+		cu.Info("Cell %s of DU %d modified", cell.GlobalCellID, duCtx.DuId)
+	}
+
+	for _, item := range msg.ServedCellsToDeleteList {
+		cellID := cu.extractCellIDValue(item.OldNRCGI.NRCellIdentity)
+		if !duCtx.RemoveCell(cellID) {
+			cu.Warn("DU %d deletes unknown cell %d", duCtx.DuId, cellID)
+			continue
+		}
+		cu.Info("Cell %d of DU %d deleted", cellID, duCtx.DuId)
+	}
+
+	for _, item := range msg.CellsStatusList {
+		cellID := cu.extractCellIDValue(item.NRCGI.NRCellIdentity)
+		cell := duCtx.GetCellByID(cellID)
+		if cell == nil {
+			cu.Warn("DU %d reports the status of unknown cell %d", duCtx.DuId, cellID)
+			continue
+		}
+		cell.OutOfService = item.ServiceStatus.ServiceState.Value == ies.ServiceStateOutofservice
+		cu.Info("Cell %s of DU %d is out of service: %t", cell.GlobalCellID, duCtx.DuId, cell.OutOfService)
+	}
+
+	ack := ies.GNBDUConfigurationUpdateAcknowledge{
+		TransactionID:            msg.TransactionID,
+		CellstobeActivatedList:   toActivate,
+		CellstobeDeactivatedList: toDeactivate,
+	}
+	f1apBytes, err := f1ap.F1apEncode(&ack)
+	if err != nil {
+		cu.Error("Error encoding gNB-DU Configuration Update Acknowledge: %v", err)
+		return
+	}
+	if err := duCtx.SendF1ap(f1apBytes); err != nil {
+		cu.Error("Error sending gNB-DU Configuration Update Acknowledge: %v", err)
+		return
+	}
+	cu.Info("gNB-DU Configuration Update of DU %d acknowledged: %d cell(s) to activate, %d to deactivate",
+		duCtx.DuId, len(toActivate), len(toDeactivate))
+
+	cu.updateRanConfiguration()
+}
+
+func (cu *CuCpContext) sendGNBDUConfigurationUpdateFailure(duCtx *du.GNBDU, transactionID int64, cause ies.Cause) error {
+	msg := ies.GNBDUConfigurationUpdateFailure{
+		TransactionID: transactionID,
+		Cause:         cause,
+	}
+	f1apBytes, err := f1ap.F1apEncode(&msg)
+	if err != nil {
+		return fmt.Errorf("encode gNB-DU Configuration Update Failure: %w", err)
+	}
+	return duCtx.SendF1ap(f1apBytes)
+}
+
+// ActivateCell asks the DU to activate one of its cells, or to broadcast new
+// system information in it when it is already active
+func (cu *CuCpContext) ActivateCell(duId int64, cellID uint64, sibs []ies.SibtypetobeupdatedListItem) error {
+	duCtx, cell, err := cu.getServedCell(duId, cellID)
+	if err != nil {
+		return err
+	}
+
+	item := f1ext.CellstobeActivatedListItem{
+		NRCGI: cell.NRCGI,
+		NRPCI: &ies.NRPCI{Value: int64(cell.PCI)},
+	}
+	if len(sibs) > 0 {
+		item.GNBCUSystemInformation = &ies.GNBCUSystemInformation{Sibtypetobeupdatedlist: sibs}
+	}
+	msg := f1ext.GNBCUConfigurationUpdate{
+		CellstobeActivatedList: []f1ext.CellstobeActivatedListItem{item},
+	}
+	return cu.sendGNBCUConfigurationUpdate(duCtx, &msg, &du.CuConfigUpdate{Activate: []uint64{cellID}})
+}
+
+// DeactivateCell asks the DU to stop one of its cells
+func (cu *CuCpContext) DeactivateCell(duId int64, cellID uint64) error {
+	duCtx, cell, err := cu.getServedCell(duId, cellID)
+	if err != nil {
+		return err
+	}
+
+	msg := f1ext.GNBCUConfigurationUpdate{
+		CellstobeDeactivatedList: []ies.CellsToBeDeactivatedListItem{{NRCGI: cell.NRCGI}},
+	}
+	return cu.sendGNBCUConfigurationUpdate(duCtx, &msg, &du.CuConfigUpdate{Deactivate: []uint64{cellID}})
+}
+
+func (cu *CuCpContext) getServedCell(duId int64, cellID uint64) (*du.GNBDU, *du.ServedCell, error) {
+	duCtx, err := cu.GetDUById(duId)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !cu.isDUConnected(duCtx) {
+		return nil, nil, fmt.Errorf("DU %d is not connected", duId)
+	}
+	cell := duCtx.GetCellByID(cellID)
+	if cell == nil {
+		return nil, nil, fmt.Errorf("DU %d does not serve cell %d", duId, cellID)
+	}
+	return duCtx, cell, nil
+}
+
+// sendGNBCUConfigurationUpdate starts the procedure, the cells change state
+// once the DU acknowledges
+func (cu *CuCpContext) sendGNBCUConfigurationUpdate(duCtx *du.GNBDU, msg *f1ext.GNBCUConfigurationUpdate, update *du.CuConfigUpdate) error {
+	msg.TransactionID = duCtx.NextTransactionId()
+
+	f1apBytes, err := f1ap.F1apEncode(msg)
+	if err != nil {
+		return fmt.Errorf("encode gNB-CU Configuration Update: %w", err)
+	}
+
+	if duCtx.PendingCuUpdates == nil {
+		duCtx.PendingCuUpdates = make(map[int64]*du.CuConfigUpdate)
+	}
+	duCtx.PendingCuUpdates[msg.TransactionID] = update
+
+	cu.Info("Sending gNB-CU Configuration Update %d to DU %d: %d cell(s) to activate, %d to deactivate",
+		msg.TransactionID, duCtx.DuId, len(update.Activate), len(update.Deactivate))
+	if err := duCtx.SendF1ap(f1apBytes); err != nil {
+		delete(duCtx.PendingCuUpdates, msg.TransactionID)
+		return err
+	}
+	return nil
+}
+
+// handleGNBCUConfigurationUpdateAcknowledge activates the requested cells,
+// except the ones the DU failed to activate, and deactivates the others
+func (cu *CuCpContext) handleGNBCUConfigurationUpdateAcknowledge(msg *ies.GNBCUConfigurationUpdateAcknowledge, conn *sctp.SCTPConn) {
+	duCtx, update, err := cu.takePendingCuUpdate(msg.TransactionID, conn)
+	if err != nil {
+		cu.Error("gNB-CU Configuration Update Acknowledge: %v", err)
+		return
+	}
+
+	var failed []uint64
+	for _, item := range msg.CellsFailedtobeActivatedList {
+		cellID := cu.extractCellIDValue(item.NRCGI.NRCellIdentity)
+		failed = append(failed, cellID)
+		cu.Warn("DU %d failed to activate cell %d, cause %+v", duCtx.DuId, cellID, item.Cause)
+	}
+
+	for _, cellID := range update.Activate {
+		if cell := duCtx.GetCellByID(cellID); cell != nil && !slices.Contains(failed, cellID) {
+			cell.Active = true
+			cu.Info("Cell %s of DU %d activated", cell.GlobalCellID, duCtx.DuId)
+		}
+	}
+	for _, cellID := range update.Deactivate {
+		if cell := duCtx.GetCellByID(cellID); cell != nil {
+			cell.Active = false
+			cu.Info("Cell %s of DU %d deactivated", cell.GlobalCellID, duCtx.DuId)
+		}
+	}
+
+	cu.updateRanConfiguration()
+}
+
+func (cu *CuCpContext) handleGNBCUConfigurationUpdateFailure(msg *ies.GNBCUConfigurationUpdateFailure, conn *sctp.SCTPConn) {
+	duCtx, _, err := cu.takePendingCuUpdate(msg.TransactionID, conn)
+	if err != nil {
+		cu.Error("gNB-CU Configuration Update Failure: %v", err)
+		return
+	}
+	cu.Error("DU %d rejected gNB-CU Configuration Update %d, cause %+v", duCtx.DuId, msg.TransactionID, msg.Cause)
+	if msg.TimeToWait != nil {
+		cu.Warn("DU %d asks to wait (TimeToWait %d) before a new gNB-CU Configuration Update",
+			duCtx.DuId, msg.TimeToWait.Value)
+	}
+}
+
+func (cu *CuCpContext) takePendingCuUpdate(transactionID int64, conn *sctp.SCTPConn) (*du.GNBDU, *du.CuConfigUpdate, error) {
+	duCtx, err := cu.GetDUByConn(conn)
+	if err != nil {
+		return nil, nil, err
+	}
+	update, ok := duCtx.PendingCuUpdates[transactionID]
+	if !ok {
+		return nil, nil, fmt.Errorf("no procedure with transaction ID %d pending at DU %d", transactionID, duCtx.DuId)
+	}
+	delete(duCtx.PendingCuUpdates, transactionID)
+	return duCtx, update, nil
+}
@@ -0,0 +1,400 @@
+package context
+
+import (
+	"central-unit/internal/context/uecontext"
+	"central-unit/pkg/pdcp"
+	"fmt"
+	"time"
+
+	f1ap "github.com/JocelynWS/f1-gen"
+	f1ies "github.com/JocelynWS/f1-gen/ies"
+	"github.com/lvdund/ngap"
+	"github.com/lvdund/ngap/aper"
+	"github.com/lvdund/ngap/ies"
+	"github.com/lvdund/rrc"
+	rrcies "github.com/lvdund/rrc/ies"
+)
+
+// startInitialContextSetup sets up the UE context at the DU, which delivers
+// the Security Mode Command with it. The PDU sessions of the request wait
+// until both the DU and the UE have answered.
+func (cu *CuCpContext) startInitialContextSetup(ue *uecontext.GNBUe, msg *ies.InitialContextSetupRequest) {
+	ics := &uecontext.InitialContextSetup{NasPdu: msg.NASPDU}
+	ue.InitialContextSetup = ics
+	ics.Timer = time.AfterFunc(cu.icsTimeout, func() {
+		if ue.InitialContextSetup != ics {
+			return
+		}
+		cu.Error("Initial Context Setup of UE RAN-UE-NGAP-ID=%d timed out", ue.RanUeNgapId)
+		cu.failInitialContextSetup(ue, radioNetworkCause(ies.CauseRadioNetworkFailureinradiointerfaceprocedure))
+	})
+
+	for _, item := range msg.PDUSessionResourceSetupListCxtReq {
+		cu.Info("Processing PDU Session ID: %d", item.PDUSessionID)
+		cu.newPduSession(ue, uint8(item.PDUSessionID), item.SNSSAI, item.NASPDU, item.PDUSessionResourceSetupRequestTransfer)
+	}
+
+	if err := cu.setupAsSecurity(ue, msg.SecurityKey.Bytes, ue.UeSecurityCapabilities); err != nil {
+		cu.Error("Failed to set up AS security: %v", err)
+		cu.failInitialContextSetup(ue, radioNetworkCause(ies.CauseRadioNetworkEncryptionandorintegrityprotectionalgorithmsnotsupported))
+		return
+	}
+
+	// The Security Mode Command is integrity protected but not ciphered,
+	// downlink ciphering on SRB1 starts right after it
+	srb1 := ue.SrbPdcp[1]
+	srb1.Tx = srbSecurity(ue.AsSecurity, false)
+	srb1.Rx = srbSecurity(ue.AsSecurity, false)
+	smcBytes, err := encodeSecurityModeCommand(ue.AsSecurity)
+	if err == nil {
+		smcBytes, err = protectSrb(ue, 1, smcBytes)
+	}
+	if err == nil {
+		srb1.Tx = srbSecurity(ue.AsSecurity, true)
+		err = cu.sendF1UEContextSetupRequest(ue, smcBytes)
+	}
+	if err != nil {
+		cu.Error("Failed to set up the UE context at the DU: %v", err)
+		cu.failInitialContextSetup(ue, radioNetworkCause(ies.CauseRadioNetworkUnspecified))
+		return
+	}
+
+	cu.Info("Initial Context Setup started for UE RAN-UE-NGAP-ID=%d with %d PDU session(s)",
+		ue.RanUeNgapId, len(ue.PduSessions))
+}
+
+// sendF1UEContextSetupRequest asks the DU for SRB2 and hands it the PDCP PDU
+// to deliver on SRB1
+func (cu *CuCpContext) sendF1UEContextSetupRequest(ue *uecontext.GNBUe, rrcContainer []byte) error {
+	duUeId := int64(ue.DuUeId)
+	msg := f1ies.UEContextSetupRequest{
+		GNBCUUEF1APID: int64(ue.GnbCuUeF1apId),
+		GNBDUUEF1APID: &duUeId,
+		SpCellID: f1ies.NRCGI{
+			PLMNIdentity:   cu.GetMccAndMncInOctets(),
+			NRCellIdentity: aper.BitString(*ue.NrCellId),
+		},
+		ServCellIndex: 0,
+		CUtoDURRCInformation: &f1ies.CUtoDURRCInformation{
+			CGConfigInfo: []byte{0x00}, //FIX: this field is not mandatory
+		},
+		SRBsToBeSetupList: []f1ies.SRBsToBeSetupItem{{
+			SRBID: 2, //SRB2
+		}},
+		RRCContainer: rrcContainer,
+		NRUESidelinkAggregateMaximumBitrate: &f1ies.NRUESidelinkAggregateMaximumBitrate{
+			UENRSidelinkAggregateMaximumBitrate: 1000000000,
+		},
+		ConditionalInterDUMobilityInformation: &f1ies.ConditionalInterDUMobilityInformation{
+			CHOTrigger: f1ies.CHOTriggerInterDU{
+				Value: f1ies.CHOtriggerInterDUChoinitiation,
+			},
+		},
+	}
+
+	f1apBytes, err := f1ap.F1apEncode(&msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode UE Context Setup Request: %w", err)
+	}
+
+	duCtx, err := cu.GetDUForUE(ue)
+	if err != nil {
+		return fmt.Errorf("DU not found for UE: %v", err)
+	}
+	err = duCtx.SendF1ap(f1apBytes)
+	if err != nil {
+		return fmt.Errorf("failed to send UE Context Setup Request: %w", err)
+	}
+
+	cu.Info("F1AP UE Context Setup Request sent to DU %d", duCtx.DuId)
+	return nil
+}
+
+func (cu *CuCpContext) handleF1UEContextSetupResponse(msg *f1ies.UEContextSetupResponse) {
+	ue, err := cu.GetUEByF1Id(msg.GNBCUUEF1APID)
+	if err != nil {
+		cu.Error("UE not found for CU-UE-F1AP-ID %d: %v", msg.GNBCUUEF1APID, err)
+		return
+	}
+	if ho := ue.Handover; ho != nil && msg.GNBCUUEF1APID == int64(ho.Target.GnbCuUeF1apId) {
+		cu.handleHandoverSetupResponse(ue, msg)
+		return
+	}
+	if restoringBearers(ue) {
+		cu.restoreDrbs(ue, msg)
+		return
+	}
+	if ue.InitialContextSetup == nil {
+		cu.Warn("Unexpected UE Context Setup Response for UE CU-UE-F1AP-ID=%d", ue.GnbCuUeF1apId)
+		return
+	}
+
+	ue.InitialContextSetup.DuReady = true
+	cu.continueInitialContextSetup(ue)
+}
+
+func (cu *CuCpContext) handleF1UEContextSetupFailure(msg *f1ies.UEContextSetupFailure) {
+	ue, err := cu.GetUEByF1Id(msg.GNBCUUEF1APID)
+	if err != nil {
+		cu.Error("UE not found for CU-UE-F1AP-ID %d: %v", msg.GNBCUUEF1APID, err)
+		return
+	}
+	if ho := ue.Handover; ho != nil && msg.GNBCUUEF1APID == int64(ho.Target.GnbCuUeF1apId) {
+		cu.Error("Target DU %d could not set up UE RAN-UE-NGAP-ID=%d (cause choice %d)", ho.Target.DuId, ue.RanUeNgapId, msg.Cause.Choice)
+		cu.failHandover(ue)
+		return
+	}
+	if restoringBearers(ue) {
+		cu.Error("DU %d could not restore UE CU-UE-F1AP-ID=%d (cause choice %d)", ue.DuId, ue.GnbCuUeF1apId, msg.Cause.Choice)
+		cu.failBearerRestore(ue)
+		return
+	}
+	if ue.InitialContextSetup == nil {
+		cu.Warn("Unexpected UE Context Setup Failure for UE CU-UE-F1AP-ID=%d", ue.GnbCuUeF1apId)
+		return
+	}
+
+	cu.Error("DU %d could not set up UE CU-UE-F1AP-ID=%d (cause choice %d)", ue.DuId, ue.GnbCuUeF1apId, msg.Cause.Choice)
+	cu.failInitialContextSetup(ue, radioNetworkCause(ies.CauseRadioNetworkRadioresourcesnotavailable))
+}
+
+// continueInitialContextSetup moves the procedure on once the DU holds the UE
+// context and AS security is active: first the PDU sessions are set up at the
+// CU-UP and the DU, then the UE gets a single RRCReconfiguration.
+func (cu *CuCpContext) continueInitialContextSetup(ue *uecontext.GNBUe) {
+	ics := ue.InitialContextSetup
+	if ics == nil || ics.ReconfigSent || !ics.DuReady || ue.AsSecurity == nil || !ue.AsSecurity.Active {
+		return
+	}
+
+	if !ics.BearersRequested {
+		ics.BearersRequested = true
+		var sessions []*uecontext.PduSessionContext
+		for _, pduSession := range ue.PduSessions {
+			if pduSession.State == uecontext.PDU_SESSION_ESTABLISHING {
+				sessions = append(sessions, pduSession)
+			}
+		}
+		if len(sessions) > 0 {
+			err := cu.setupBearerContext(ue, sessions)
+			if err == nil {
+				return
+			}
+			cu.Error("Failed to set up the bearer context at CU-UP: %v", err)
+			for _, pduSession := range sessions {
+				cu.failPduSessionSetup(ue, pduSession.PduSessionId, userPlaneFailureCause())
+			}
+		}
+	}
+
+	for _, pduSession := range ue.PduSessions {
+		if pduSession.State == uecontext.PDU_SESSION_ESTABLISHING && !pduSession.DrbsSetupAtDu() {
+			return
+		}
+	}
+
+	if err := cu.sendRRCReconfigurationForInitialContext(ue); err != nil {
+		cu.Error("Failed to send RRC Reconfiguration: %v", err)
+		cu.failInitialContextSetup(ue, radioNetworkCause(ies.CauseRadioNetworkUnspecified))
+		return
+	}
+	ics.ReconfigSent = true
+}
+
+// sendRRCReconfigurationForInitialContext adds SRB2 and the DRBs of the
+// sessions set up with the context, and delivers the NAS PDUs of the request
+func (cu *CuCpContext) sendRRCReconfigurationForInitialContext(ue *uecontext.GNBUe) error {
+	var drbToAddModList []rrcies.DRB_ToAddMod
+	var nasPduList []rrcies.DedicatedNAS_Message
+	if nasPdu := ue.InitialContextSetup.NasPdu; len(nasPdu) > 0 {
+		nasPduList = append(nasPduList, rrcies.DedicatedNAS_Message{Value: nasPdu})
+	}
+
+	for _, pduSession := range ue.PduSessions {
+		if pduSession.State != uecontext.PDU_SESSION_ESTABLISHING {
+			continue
+		}
+		for _, drb := range pduSession.Drbs {
+			drbToAddModList = append(drbToAddModList, buildRrcDrbToAddMod(pduSession, drb))
+		}
+		if len(pduSession.NasPduSessionAccept) > 0 {
+			nasPduList = append(nasPduList, rrcies.DedicatedNAS_Message{
+				Value: pduSession.NasPduSessionAccept,
+			})
+		}
+	}
+
+	masterCellGroupBytes, err := rrc.Encode(ue.MasterCellGroup)
+	if err != nil {
+		return fmt.Errorf("failed to encode MasterCellGroup: %w", err)
+	}
+
+	radioBearerConfig := &rrcies.RadioBearerConfig{
+		Srb_ToAddModList: &rrcies.SRB_ToAddModList{
+			Value: []rrcies.SRB_ToAddMod{{
+				Srb_Identity: rrcies.SRB_Identity{Value: 2},
+			}},
+		},
+	}
+	if len(drbToAddModList) > 0 {
+		radioBearerConfig.Drb_ToAddModList = &rrcies.DRB_ToAddModList{Value: drbToAddModList}
+	}
+
+	measConfig, meas := cu.measConfigFor(ue, cu.servingCell(ue))
+	rrcBytes, err := encodeRrcReconfiguration(rrcTransactionInitialContext, &rrcies.RRCReconfiguration_IEs{
+		RadioBearerConfig: radioBearerConfig,
+		MeasConfig:        measConfig,
+		NonCriticalExtension: &rrcies.RRCReconfiguration_v1530_IEs{
+			MasterCellGroup:          &masterCellGroupBytes,
+			DedicatedNAS_MessageList: nasPduList,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	srb2 := pdcp.NewSrbEntity(2)
+	srb2.Tx = srbSecurity(ue.AsSecurity, true)
+	srb2.Rx = srbSecurity(ue.AsSecurity, true)
+	ue.SrbPdcp[2] = srb2
+
+	if err = cu.sendRrcReconfiguration(ue, rrcBytes); err != nil {
+		return err
+	}
+	ue.Measurements = meas
+	return nil
+}
+
+// completeInitialContextSetup activates the sessions the UE has just been
+// configured with and answers the AMF
+func (cu *CuCpContext) completeInitialContextSetup(ue *uecontext.GNBUe) error {
+	ics := ue.InitialContextSetup
+	if ics == nil || !ics.ReconfigSent {
+		cu.Warn("Unexpected RRC Reconfiguration Complete for the initial context of UE RAN-UE-NGAP-ID=%d", ue.RanUeNgapId)
+		return nil
+	}
+	ics.Timer.Stop()
+	ue.InitialContextSetup = nil
+
+	var setupList []ies.PDUSessionResourceSetupItemCxtRes
+	for _, pduSession := range ue.PduSessions {
+		if pduSession.State != uecontext.PDU_SESSION_ESTABLISHING || !pduSession.DrbsSetupAtDu() {
+			continue
+		}
+		transferBytes, err := buildPduSessionSetupResponseTransfer(pduSession)
+		if err != nil {
+			cu.Error("PDU Session ID=%d: %v", pduSession.PduSessionId, err)
+			cu.failPduSessionSetup(ue, pduSession.PduSessionId, userPlaneFailureCause())
+			continue
+		}
+		pduSession.State = uecontext.PDU_SESSION_ACTIVE
+		cu.Info("PDU Session ID=%d is now ACTIVE", pduSession.PduSessionId)
+
+		setupList = append(setupList, ies.PDUSessionResourceSetupItemCxtRes{
+			PDUSessionID:                            int64(pduSession.PduSessionId),
+			PDUSessionResourceSetupResponseTransfer: transferBytes,
+		})
+	}
+
+	var failedList []ies.PDUSessionResourceFailedToSetupItemCxtRes
+	for _, item := range ue.FailedPduSessions {
+		failedList = append(failedList, ies.PDUSessionResourceFailedToSetupItemCxtRes{
+			PDUSessionID: item.PDUSessionID,
+			PDUSessionResourceSetupUnsuccessfulTransfer: item.PDUSessionResourceSetupUnsuccessfulTransfer,
+		})
+	}
+	ue.FailedPduSessions = nil
+
+	msg := ies.InitialContextSetupResponse{
+		AMFUENGAPID:                       ue.AmfUeNgapId,
+		RANUENGAPID:                       ue.RanUeNgapId,
+		PDUSessionResourceSetupListCxtRes: setupList,
+		PDUSessionResourceFailedToSetupListCxtRes: failedList,
+	}
+
+	ngapBytes, err := ngap.NgapEncode(&msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode Initial Context Setup Response: %w", err)
+	}
+
+	amf, err := cu.GetAMFById(ue.AmfId)
+	if err != nil {
+		return fmt.Errorf("AMF not found for UE: %v", err)
+	}
+	err = amf.SendNgap(ngapBytes)
+	if err != nil {
+		return fmt.Errorf("failed to send NGAP Initial Context Setup Response: %w", err)
+	}
+
+	cu.Info("NGAP Initial Context Setup Response sent with %d PDU session(s) set up, %d failed",
+		len(setupList), len(failedList))
+	return nil
+}
+
+// failInitialContextSetup drops the sessions requested with the context and
+// sends the Initial Context Setup Failure. The AMF then releases the UE.
+func (cu *CuCpContext) failInitialContextSetup(ue *uecontext.GNBUe, cause ies.Cause) {
+	ics := ue.InitialContextSetup
+	if ics == nil {
+		return
+	}
+	ics.Timer.Stop()
+	ue.InitialContextSetup = nil
+
+	var failed []uint8
+	for id, pduSession := range ue.PduSessions {
+		if pduSession.State == uecontext.PDU_SESSION_ESTABLISHING {
+			failed = append(failed, id)
+		}
+	}
+	for _, id := range failed {
+		cu.failPduSessionSetup(ue, id, cause)
+	}
+	if len(failed) > 0 {
+		if err := cu.removeBearerContextSessions(ue, failed); err != nil {
+			cu.Error("Failed to remove PDU sessions from the bearer context: %v", err)
+		}
+	}
+
+	var failedList []ies.PDUSessionResourceFailedToSetupItemCxtFail
+	for _, item := range ue.FailedPduSessions {
+		failedList = append(failedList, ies.PDUSessionResourceFailedToSetupItemCxtFail{
+			PDUSessionID: item.PDUSessionID,
+			PDUSessionResourceSetupUnsuccessfulTransfer: item.PDUSessionResourceSetupUnsuccessfulTransfer,
+		})
+	}
+	ue.FailedPduSessions = nil
+
+	if err := cu.sendInitialContextSetupFailure(ue, failedList, cause); err != nil {
+		cu.Error("Failed to send Initial Context Setup Failure: %v", err)
+	}
+}
+
+func (cu *CuCpContext) sendInitialContextSetupFailure(
+	ue *uecontext.GNBUe,
+	failedList []ies.PDUSessionResourceFailedToSetupItemCxtFail,
+	cause ies.Cause,
+) error {
+	msg := ies.InitialContextSetupFailure{
+		AMFUENGAPID: ue.AmfUeNgapId,
+		RANUENGAPID: ue.RanUeNgapId,
+		PDUSessionResourceFailedToSetupListCxtFail: failedList,
+		Cause: cause,
+	}
+
+	ngapBytes, err := ngap.NgapEncode(&msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode Initial Context Setup Failure: %w", err)
+	}
+
+	amf, err := cu.GetAMFById(ue.AmfId)
+	if err != nil {
+		return fmt.Errorf("AMF not found for UE: %v", err)
+	}
+	if err = amf.SendNgap(ngapBytes); err != nil {
+		return fmt.Errorf("failed to send NGAP message: %w", err)
+	}
+
+	cu.Info("NGAP Initial Context Setup Failure sent to AMF")
+	return nil
+}
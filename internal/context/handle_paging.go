@@ -0,0 +1,168 @@
+package context
+
+import (
+	"bytes"
+	"central-unit/internal/context/amfcontext"
+	"central-unit/internal/context/du"
+	"central-unit/internal/context/uecontext"
+	"encoding/binary"
+	"fmt"
+	"slices"
+
+	f1ap "github.com/JocelynWS/f1-gen"
+	f1ies "github.com/JocelynWS/f1-gen/ies"
+	"github.com/lvdund/ngap/aper"
+	"github.com/lvdund/ngap/ies"
+)
+
+// handleNgPaging forwards a CN paging to every DU serving a cell in one of
+// the tracking areas of the paging (TS 38.413 8.5.1, TS 38.473 8.7.1)
+func (cu *CuCpContext) handleNgPaging(amf *amfcontext.GNBAmf, msg *ies.Paging) {
+	identity := msg.UEPagingIdentity
+	if identity.Choice != ies.UEPagingIdentityPresentFivegSTmsi || identity.FiveGSTMSI == nil {
+		cu.Error("Paging from AMF %s without a 5G-S-TMSI", amf.Name)
+		return
+	}
+	tmsi, err := fiveGSTMSIValue(identity.FiveGSTMSI)
+	if err != nil {
+		cu.Error("Paging from AMF %s: %v", amf.Name, err)
+		return
+	}
+
+	tmsiBits := aper.BitString{NumBits: 48, Bytes: binary.BigEndian.AppendUint64(nil, tmsi<<16)[:6]}
+	paging := f1ies.Paging{
+		UEIdentityIndexValue: ueIdentityIndex(tmsi),
+		PagingIdentity: f1ies.PagingIdentity{
+			Choice: f1ies.PagingIdentityPresentCNUEPagingIdentity,
+			CNUEPagingIdentity: &f1ies.CNUEPagingIdentity{
+				Choice:     f1ies.CNUEPagingIdentityPresentFivegSTmsi,
+				FiveGSTMSI: &tmsiBits,
+			},
+		},
+	}
+	if msg.PagingDRX != nil {
+		paging.PagingDRX = &f1ies.PagingDRX{Value: msg.PagingDRX.Value}
+	}
+	if msg.PagingPriority != nil {
+		paging.PagingPriority = &f1ies.PagingPriority{Value: msg.PagingPriority.Value}
+	}
+	if msg.PagingOrigin != nil {
+		paging.PagingOrigin = &f1ies.PagingOrigin{Value: msg.PagingOrigin.Value}
+	}
+
+	duCount := cu.sendPaging(&paging, func(cell *du.ServedCell) bool {
+		return slices.ContainsFunc(msg.TAIListForPaging, func(item ies.TAIListForPagingItem) bool {
+			return bytes.Equal(cell.TAC, item.TAI.TAC) && cu.cellServesPlmn(cell, item.TAI.PLMNIdentity)
+		})
+	})
+	if duCount == 0 {
+		cu.Warn("Paging of 5G-S-TMSI %012x from AMF %s: no cell in service in its %d TA(s)",
+			tmsi, amf.Name, len(msg.TAIListForPaging))
+		return
+	}
+	cu.Info("Paging of 5G-S-TMSI %012x from AMF %s sent to %d DU(s)", tmsi, amf.Name, duCount)
+}
+
+// SendRanPaging pages a UE in RRC_INACTIVE with its I-RNTI in the cells of its
+// RAN notification area (TS 38.300 9.2.2.2)
+func (cu *CuCpContext) SendRanPaging(ue *uecontext.GNBUe) error {
+	ranPaging := ue.RanPaging
+	if ranPaging == nil {
+		return fmt.Errorf("UE %d is not in RRC_INACTIVE", ue.RanUeNgapId)
+	}
+
+	index := ranPaging.UeIdIndex
+	paging := f1ies.Paging{
+		UEIdentityIndexValue: f1ies.UEIdentityIndexValue{
+			Choice:        f1ies.UEIdentityIndexValuePresentIndexlength10,
+			IndexLength10: &index,
+		},
+		PagingIdentity: f1ies.PagingIdentity{
+			Choice:              f1ies.PagingIdentityPresentRANUEPagingIdentity,
+			RANUEPagingIdentity: &f1ies.RANUEPagingIdentity{IRNTI: ranPaging.IRnti},
+		},
+	}
+	if ranPaging.PagingDRX != nil {
+		paging.PagingDRX = &f1ies.PagingDRX{Value: ranPaging.PagingDRX.Value}
+	}
+
+	plmn := cu.GetMccAndMncInOctets()
+	duCount := cu.sendPaging(&paging, func(cell *du.ServedCell) bool {
+		return slices.ContainsFunc(ranPaging.Tacs, func(tac []byte) bool { return bytes.Equal(cell.TAC, tac) }) &&
+			cu.cellServesPlmn(cell, plmn)
+	})
+	if duCount == 0 {
+		return fmt.Errorf("no cell in service in the RAN notification area of UE %d", ue.RanUeNgapId)
+	}
+	cu.Info("RAN paging of UE %d sent to %d DU(s)", ue.RanUeNgapId, duCount)
+	return nil
+}
+
+// sendPaging sends the paging to each connected DU with the cells of the DU
+// in the paging area, and returns the number of DUs paged
+func (cu *CuCpContext) sendPaging(paging *f1ies.Paging, inArea func(*du.ServedCell) bool) int {
+	duCount := 0
+	cu.DuPool.Range(func(_, value any) bool {
+		duCtx, ok := value.(*du.GNBDU)
+		if !ok || !cu.isDUConnected(duCtx) {
+			return true
+		}
+		msg := *paging
+		msg.PagingCellList = nil
+		for i := range duCtx.ServedCells {
+			cell := &duCtx.ServedCells[i]
+			if cell.Active && !cell.OutOfService && inArea(cell) {
+				msg.PagingCellList = append(msg.PagingCellList, f1ies.PagingCellItem{NRCGI: cell.NRCGI})
+			}
+		}
+		if len(msg.PagingCellList) == 0 {
+			return true
+		}
+
+		f1apBytes, err := f1ap.F1apEncode(&msg)
+		if err == nil {
+			err = duCtx.SendF1ap(f1apBytes)
+		}
+		if err != nil {
+			cu.Error("Failed to send Paging to DU %d: %v", duCtx.DuId, err)
+			return true
+		}
+		duCount++
+		return true
+	})
+	return duCount
+}
+
+// cellServesPlmn tells whether a cell broadcasts a PLMN, in its NR CGI or in
+// its served PLMNs
+func (cu *CuCpContext) cellServesPlmn(cell *du.ServedCell, plmn []byte) bool {
+	if cu.plmnMatches(cell.PLMN.Identity, plmn) {
+		return true
+	}
+	for _, served := range cell.ServedPLMNs {
+		if cu.plmnMatches(served.Identity, plmn) {
+			return true
+		}
+	}
+	return false
+}
+
+// fiveGSTMSIValue packs the AMF Set ID, the AMF Pointer and the 5G-TMSI into
+// the 48 bits of the 5G-S-TMSI
+func fiveGSTMSIValue(tmsi *ies.FiveGSTMSI) (uint64, error) {
+	if len(tmsi.FiveGTMSI) != 4 {
+		return 0, fmt.Errorf("5G-TMSI of %d bytes", len(tmsi.FiveGTMSI))
+	}
+	return amfSetId(tmsi.AMFSetID)<<38 | amfPointer(tmsi.AMFPointer)<<32 |
+		uint64(binary.BigEndian.Uint32(tmsi.FiveGTMSI)), nil
+}
+
+// ueIdentityIndex is the UE_ID of the paging occasions, 5G-S-TMSI mod 1024
+// (TS 38.304 7.1)
+func ueIdentityIndex(tmsi uint64) f1ies.UEIdentityIndexValue {
+	index := tmsi % 1024
+	return f1ies.UEIdentityIndexValue{
+		Choice:        f1ies.UEIdentityIndexValuePresentIndexlength10,
+		IndexLength10: &aper.BitString{NumBits: 10, Bytes: []byte{byte(index >> 2), byte(index << 6)}},
+	}
+}
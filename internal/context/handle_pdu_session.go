@@ -3,7 +3,10 @@ package context
 import (
 	"central-unit/internal/context/amfcontext"
 	"central-unit/internal/context/uecontext"
+	f1ext "central-unit/pkg/f1ap/ies"
+	"encoding/binary"
 	"fmt"
+	"slices"
 
 	f1ap "github.com/JocelynWS/f1-gen"
 	f1ies "github.com/JocelynWS/f1-gen/ies"
@@ -28,53 +31,210 @@ func (cu *CuCpContext) handlePduSessionResourceSetupRequest(
 
 	ue.AmfUeNgapId = msg.AMFUENGAPID
 
+	// a UE in RRC_INACTIVE gets its sessions once it has resumed
+	if ue.Suspension != nil {
+		if !cu.holdForResume(ue, msg) {
+			for _, item := range msg.PDUSessionResourceSetupListSUReq {
+				if _, exists := ue.PduSessions[uint8(item.PDUSessionID)]; !exists {
+					cu.failPduSessionSetup(ue, uint8(item.PDUSessionID), unreachableCause())
+				}
+			}
+			cu.reportPduSessionSetupFailures(ue)
+		}
+		return
+	}
+
+	if msg.UEAggregateMaximumBitRate != nil {
+		ue.UeAmbr = msg.UEAggregateMaximumBitRate
+	}
+
 	if msg.PDUSessionResourceSetupListSUReq == nil || len(msg.PDUSessionResourceSetupListSUReq) == 0 {
 		cu.Error("PDUSessionResourceSetupListSUReq is empty")
 		return
 	}
 
+	var newSessions []*uecontext.PduSessionContext
 	for _, item := range msg.PDUSessionResourceSetupListSUReq {
 		cu.Info("Processing PDU Session ID: %d", item.PDUSessionID)
+		pduSession := cu.newPduSession(ue, uint8(item.PDUSessionID), item.SNSSAI, item.PDUSessionNASPDU, item.PDUSessionResourceSetupRequestTransfer)
+		if pduSession != nil {
+			newSessions = append(newSessions, pduSession)
+		}
+	}
 
-		pduSessionId := uint8(item.PDUSessionID)
-		nasPdu := item.PDUSessionNASPDU
+	if len(newSessions) == 0 {
+		cu.reportPduSessionSetupFailures(ue)
+		return
+	}
 
-		if ue.PduSessions == nil {
-			ue.PduSessions = make(map[uint8]*uecontext.PduSessionContext)
+	// The DU is only asked for the DRBs once the CU-UP has returned its F1-U tunnels
+	if err = cu.setupBearerContext(ue, newSessions); err != nil {
+		cu.Error("Failed to set up the bearer context at CU-UP: %v", err)
+		for _, pduSession := range newSessions {
+			cu.failPduSessionSetup(ue, pduSession.PduSessionId, userPlaneFailureCause())
 		}
+		cu.reportPduSessionSetupFailures(ue)
+		return
+	}
 
-		if _, exists := ue.PduSessions[pduSessionId]; exists {
-			cu.Error("PDU Session ID %d already exists for UE", pduSessionId)
-			continue
-		}
+	cu.Info("PDU Session setup initiated, waiting for E1AP, F1AP and RRC confirmation")
+}
 
-		drbId := pduSessionId
+// newPduSession creates a PDU session requested by the AMF and maps its QoS
+// flows to DRBs. A session that cannot be created is queued as failed and nil
+// is returned.
+func (cu *CuCpContext) newPduSession(
+	ue *uecontext.GNBUe,
+	pduSessionId uint8,
+	snssai ies.SNSSAI,
+	nasPdu []byte,
+	transfer []byte,
+) *uecontext.PduSessionContext {
+	if ue.PduSessions == nil {
+		ue.PduSessions = make(map[uint8]*uecontext.PduSessionContext)
+	}
 
-		pduSession := &uecontext.PduSessionContext{
-			PduSessionId:        pduSessionId,
-			State:               uecontext.PDU_SESSION_ESTABLISHING,
-			DrbId:               drbId,
-			NasPduSessionAccept: nasPdu,
-			UlTeid:              0x12345678,
-			DlTeid:              0x87654321,
-		}
+	if _, exists := ue.PduSessions[pduSessionId]; exists {
+		cu.Error("PDU Session ID %d already exists for UE", pduSessionId)
+		return nil
+	}
+
+	pduSession := &uecontext.PduSessionContext{
+		PduSessionId:        pduSessionId,
+		State:               uecontext.PDU_SESSION_ESTABLISHING,
+		Snssai:              &snssai,
+		NasPduSessionAccept: nasPdu,
+	}
 
-		ue.PduSessions[pduSessionId] = pduSession
-		ue.NumActiveSessions++
+	if err := decodePduSessionSetupTransfer(pduSession, transfer); err != nil {
+		cu.Error("Invalid PDU Session Resource Setup Request Transfer for PDU Session ID %d: %v", pduSessionId, err)
+		cu.failPduSessionSetup(ue, pduSessionId, ies.Cause{
+			Choice:   ies.CausePresentProtocol,
+			Protocol: &ies.CauseProtocol{Value: ies.CauseProtocolSemanticerror},
+		})
+		return nil
+	}
 
-		cu.Info("Created PDU Session ID=%d, DRB ID=%d for UE RAN-NGAP-ID=%d",
-			pduSessionId, drbId, ue.RanUeNgapId)
+	if err := cu.mapQosFlowsToDrbs(ue, pduSession); err != nil {
+		cu.Error("No DRB for PDU Session ID %d: %v", pduSessionId, err)
+		cu.failPduSessionSetup(ue, pduSessionId, ies.Cause{
+			Choice:       ies.CausePresentRadionetwork,
+			RadioNetwork: &ies.CauseRadioNetwork{Value: ies.CauseRadioNetworkRadioresourcesnotavailable},
+		})
+		return nil
+	}
 
-		cu.Info("TODO: E1AP Bearer Context Setup Request to CU-UP (out of scope)")
+	ue.PduSessions[pduSessionId] = pduSession
+	ue.NumActiveSessions++
 
-		err = cu.sendF1UEContextModificationRequest(ue, pduSession)
+	for _, drb := range pduSession.Drbs {
+		cu.Info("Created PDU Session ID=%d, DRB ID=%d with %d QoS flow(s) for UE RAN-NGAP-ID=%d",
+			pduSessionId, drb.DrbId, len(drb.QosFlows), ue.RanUeNgapId)
+	}
+	return pduSession
+}
+
+// decodePduSessionSetupTransfer fills the PDU session with the UPF tunnel,
+// Session-AMBR and QoS flows requested by the SMF
+func decodePduSessionSetupTransfer(pduSession *uecontext.PduSessionContext, wire []byte) error {
+	var transfer ies.PDUSessionResourceSetupRequestTransfer
+	if err, _ := transfer.Decode(wire); err != nil {
+		return err
+	}
+
+	tunnel := transfer.ULNGUUPTNLInformation.GTPTunnel
+	if tunnel == nil || len(tunnel.GTPTEID) != 4 {
+		return fmt.Errorf("UL NG-U UP TNL Information carries no GTP tunnel")
+	}
+	pduSession.UlNguTunnel = &uecontext.GtpTunnel{
+		Address: tunnel.TransportLayerAddress.Bytes,
+		Teid:    binary.BigEndian.Uint32(tunnel.GTPTEID),
+	}
+	pduSession.PduSessionType = transfer.PDUSessionType.Value
+	pduSession.SecurityIndication = transfer.SecurityIndication
+	pduSession.Ambr = transfer.PDUSessionAggregateMaximumBitRate
+
+	for _, flow := range transfer.QosFlowSetupRequestList {
+		qosFlow, err := newQosFlowContext(flow.QosFlowIdentifier, &flow.QosFlowLevelQosParameters)
 		if err != nil {
-			cu.Error("Failed to send F1AP UE Context Modification Request: %v", err)
-			continue
+			return err
+		}
+		pduSession.QosFlows = append(pduSession.QosFlows, qosFlow)
+	}
+	return nil
+}
+
+// newQosFlowContext keeps the QoS parameters the SMF gave for a flow
+func newQosFlowContext(qfi int64, qos *ies.QosFlowLevelQosParameters) (*uecontext.QosFlowContext, error) {
+	arp := qos.AllocationAndRetentionPriority
+	qosFlow := &uecontext.QosFlowContext{
+		QosFlowId:               uint8(qfi),
+		Qfi:                     uint8(qfi),
+		Priority:                uint8(arp.PriorityLevelARP),
+		PreemptionCapability:    arp.PreemptionCapability.Value,
+		PreemptionVulnerability: arp.PreemptionVulnerability.Value,
+		Gbr:                     qos.GBRQosInformation,
+	}
+
+	switch qos.QosCharacteristics.Choice {
+	case ies.QosCharacteristicsPresentNondynamic5Qi:
+		nonDynamic := qos.QosCharacteristics.NonDynamic5QI
+		qosFlow.FiveQi = nonDynamic.FiveQI
+		qosFlow.PriorityLevelQos = nonDynamic.PriorityLevelQos
+	case ies.QosCharacteristicsPresentDynamic5Qi:
+		dynamic := qos.QosCharacteristics.Dynamic5QI
+		qosFlow.Dynamic5Qi = dynamic
+		qosFlow.PriorityLevelQos = &dynamic.PriorityLevelQos
+		if dynamic.FiveQI != nil {
+			qosFlow.FiveQi = *dynamic.FiveQI
 		}
+	default:
+		return nil, fmt.Errorf("QoS flow %d has no QoS characteristics", qfi)
+	}
+	return qosFlow, nil
+}
+
+// failPduSessionSetup drops a PDU session that could not be set up and queues
+// it for the FailedToSetup list of the next PDU Session Resource Setup Response
+func (cu *CuCpContext) failPduSessionSetup(ue *uecontext.GNBUe, pduSessionId uint8, cause ies.Cause) {
+	if pduSession, ok := ue.PduSessions[pduSessionId]; ok {
+		cu.releaseDrbs(ue, pduSession)
+		delete(ue.PduSessions, pduSessionId)
+		ue.NumActiveSessions--
+	}
+
+	transfer := ies.PDUSessionResourceSetupUnsuccessfulTransfer{Cause: cause}
+	transferBytes, err := transfer.Encode()
+	if err != nil {
+		cu.Error("Failed to encode PDU Session Resource Setup Unsuccessful Transfer: %v", err)
 	}
 
-	cu.Info("PDU Session setup initiated, waiting for F1AP and RRC confirmation")
+	ue.FailedPduSessions = append(ue.FailedPduSessions, ies.PDUSessionResourceFailedToSetupItemSURes{
+		PDUSessionID: int64(pduSessionId),
+		PDUSessionResourceSetupUnsuccessfulTransfer: transferBytes,
+	})
+}
+
+// reportPduSessionSetupFailures answers the AMF right away when every
+// requested session failed, otherwise the failures are reported along with the
+// sessions that complete. Sessions requested with the initial context are
+// reported in the Initial Context Setup Response instead.
+func (cu *CuCpContext) reportPduSessionSetupFailures(ue *uecontext.GNBUe) {
+	if ue.InitialContextSetup != nil {
+		cu.continueInitialContextSetup(ue)
+		return
+	}
+	if len(ue.FailedPduSessions) == 0 {
+		return
+	}
+	for _, pduSession := range ue.PduSessions {
+		if pduSession.State == uecontext.PDU_SESSION_ESTABLISHING {
+			return
+		}
+	}
+	if err := cu.sendPduSessionResourceSetupResponse(ue, nil); err != nil {
+		cu.Error("Failed to send PDU Session Resource Setup Response: %v", err)
+	}
 }
 
 func (cu *CuCpContext) sendF1UEContextModificationRequest(
@@ -83,55 +243,34 @@ func (cu *CuCpContext) sendF1UEContextModificationRequest(
 ) error {
 	cu.Info("Building F1AP UE Context Modification Request for PDU Session ID=%d", pduSession.PduSessionId)
 
+	msg := f1ext.UEContextModificationRequest{}
+	for _, drb := range pduSession.Drbs {
+		item, err := buildF1DrbToSetup(pduSession, drb)
+		if err != nil {
+			return err
+		}
+		msg.DRBsToBeSetupModList = append(msg.DRBsToBeSetupModList, item)
+	}
+	return cu.sendF1UEContextModification(ue, &msg)
+}
+
+// sendF1UEContextModification addresses a UE Context Modification Request to
+// the DU serving the UE, the RRC message it carries going out on SRB1
+func (cu *CuCpContext) sendF1UEContextModification(ue *uecontext.GNBUe, msg *f1ext.UEContextModificationRequest) error {
 	duCtx, err := cu.GetDUForUE(ue)
 	if err != nil {
 		return fmt.Errorf("DU not found for UE: %v", err)
 	}
 
-	msg := f1ies.UEContextModificationRequest{
-		GNBCUUEF1APID: int64(ue.GnbCuUeF1apId),
-		GNBDUUEF1APID: int64(ue.DuUeId),
-		DRBsToBeSetupModList: []f1ies.DRBsToBeSetupModItem{{
-			DRBID: int64(pduSession.DrbId),
-			QoSInformation: f1ies.QoSInformation{
-				Choice: f1ies.QoSInformationPresentEUTRANQoS,
-				EUTRANQoS: &f1ies.EUTRANQoS{
-					QCI: 9,
-					AllocationAndRetentionPriority: f1ies.AllocationAndRetentionPriority{
-						PriorityLevel: 1,
-						PreEmptionCapability: f1ies.PreEmptionCapability{
-							Value: f1ies.PreEmptionCapabilityShallnottriggerpreemption,
-						},
-						PreEmptionVulnerability: f1ies.PreEmptionVulnerability{
-							Value: f1ies.PreEmptionVulnerabilityNotpreemptable,
-						},
-					},
-				},
-			},
-			ULUPTNLInformationToBeSetupList: []f1ies.ULUPTNLInformationToBeSetupItem{{
-				ULUPTNLInformation: f1ies.UPTransportLayerInformation{
-					Choice: f1ies.UPTransportLayerInformationPresentGTPTunnel,
-					GTPTunnel: &f1ies.GTPTunnel{
-						TransportLayerAddress: aper.BitString{
-							Bytes:   []byte{0xC0, 0xA8, 0x01, 0x64},
-							NumBits: 32,
-						},
-						GTPTEID: []byte{
-							byte(pduSession.UlTeid >> 24),
-							byte(pduSession.UlTeid >> 16),
-							byte(pduSession.UlTeid >> 8),
-							byte(pduSession.UlTeid),
-						},
-					},
-				},
-			}},
-			RLCMode: f1ies.RLCMode{
-				Value: f1ies.RLCModeRlcam,
-			},
-		}},
+	msg.GNBCUUEF1APID = int64(ue.GnbCuUeF1apId)
+	msg.GNBDUUEF1APID = int64(ue.DuUeId)
+	if msg.RRCContainer != nil {
+		if msg.RRCContainer, err = protectSrb(ue, 1, msg.RRCContainer); err != nil {
+			return err
+		}
 	}
 
-	f1apBytes, err := f1ap.F1apEncode(&msg)
+	f1apBytes, err := f1ap.F1apEncode(msg)
 	if err != nil {
 		return fmt.Errorf("failed to encode F1AP UE Context Modification Request: %w", err)
 	}
@@ -145,6 +284,141 @@ func (cu *CuCpContext) sendF1UEContextModificationRequest(
 	return nil
 }
 
+func buildF1DrbToSetup(pduSession *uecontext.PduSessionContext, drb *uecontext.DrbContext) (f1ext.DRBsToBeSetupModItem, error) {
+	drbInfo, err := buildF1DrbInformation(pduSession, drb)
+	if err != nil {
+		return f1ext.DRBsToBeSetupModItem{}, err
+	}
+
+	return f1ext.DRBsToBeSetupModItem{
+		DRBID: int64(drb.DrbId),
+		QoSInformation: f1ext.QoSInformation{
+			Choice:         f1ext.QoSInformationPresentDRBInformation,
+			DRBInformation: drbInfo,
+		},
+		ULUPTNLInformationToBeSetupList: f1UlTunnels(drb),
+		RLCMode: f1ies.RLCMode{
+			Value: f1ies.RLCModeRlcam,
+		},
+	}, nil
+}
+
+// f1UlTunnels gives the DU the CU-UP end of the F1-U tunnel of a DRB
+func f1UlTunnels(drb *uecontext.DrbContext) []f1ies.ULUPTNLInformationToBeSetupItem {
+	ulTeid := make([]byte, 4)
+	binary.BigEndian.PutUint32(ulTeid, drb.UlF1uTunnel.Teid)
+
+	return []f1ies.ULUPTNLInformationToBeSetupItem{{
+		ULUPTNLInformation: f1ies.UPTransportLayerInformation{
+			Choice: f1ies.UPTransportLayerInformationPresentGTPTunnel,
+			GTPTunnel: &f1ies.GTPTunnel{
+				TransportLayerAddress: aper.BitString{
+					Bytes:   drb.UlF1uTunnel.Address,
+					NumBits: uint64(len(drb.UlF1uTunnel.Address) * 8),
+				},
+				GTPTEID: ulTeid,
+			},
+		},
+	}}
+}
+
+// f1DlTunnel picks the first GTP tunnel the DU returned for a DRB
+func f1DlTunnel(list []f1ies.DLUPTNLInformationToBeSetupItem) *uecontext.GtpTunnel {
+	for _, tnl := range list {
+		if tunnel := tnl.DLUPTNLInformation.GTPTunnel; tunnel != nil && len(tunnel.GTPTEID) == 4 {
+			return &uecontext.GtpTunnel{
+				Address: tunnel.TransportLayerAddress.Bytes,
+				Teid:    binary.BigEndian.Uint32(tunnel.GTPTEID),
+			}
+		}
+	}
+	return nil
+}
+
+// buildF1DrbInformation describes a DRB to the DU with the NR
+// DRB-Information. The DRB level QoS follows the admitted flow with the
+// highest ARP priority so that the DU schedules the bearer for its most
+// demanding flow, while every flow keeps its own parameters in the mapping.
+func buildF1DrbInformation(pduSession *uecontext.PduSessionContext, drb *uecontext.DrbContext) (*f1ies.DRBInformation, error) {
+	var drbFlow *uecontext.QosFlowContext
+	flows := make([]f1ies.FlowsMappedToDRBItem, 0, len(drb.QosFlows))
+	for _, flow := range drb.QosFlows {
+		if flow.Failed {
+			continue
+		}
+		if drbFlow == nil || flow.Priority < drbFlow.Priority {
+			drbFlow = flow
+		}
+		flows = append(flows, f1ies.FlowsMappedToDRBItem{
+			QoSFlowIdentifier:         int64(flow.Qfi),
+			QoSFlowLevelQoSParameters: f1QosFlowParameters(flow),
+		})
+	}
+	if drbFlow == nil {
+		return nil, fmt.Errorf("no QoS flow admitted on DRB ID=%d of PDU Session ID=%d", drb.DrbId, pduSession.PduSessionId)
+	}
+
+	snssai := f1ies.SNSSAI{SST: []byte{0x01}}
+	if pduSession.Snssai != nil {
+		snssai = f1ies.SNSSAI{SST: pduSession.Snssai.SST, SD: pduSession.Snssai.SD}
+	}
+
+	return &f1ies.DRBInformation{
+		DRBQoS:               f1QosFlowParameters(drbFlow),
+		SNSSAI:               snssai,
+		FlowsMappedToDRBList: flows,
+	}, nil
+}
+
+// f1QosFlowParameters is the F1AP counterpart of e1QosFlowParameters
+func f1QosFlowParameters(flow *uecontext.QosFlowContext) f1ies.QoSFlowLevelQoSParameters {
+	params := f1ies.QoSFlowLevelQoSParameters{
+		NGRANAllocationRetentionPriority: f1ies.NGRANAllocationAndRetentionPriority{
+			PriorityLevel:           int64(flow.Priority),
+			PreEmptionCapability:    f1ies.PreEmptionCapability{Value: flow.PreemptionCapability},
+			PreEmptionVulnerability: f1ies.PreEmptionVulnerability{Value: flow.PreemptionVulnerability},
+		},
+	}
+
+	if dynamic := flow.Dynamic5Qi; dynamic != nil {
+		descriptor := &f1ies.Dynamic5QIDescriptor{
+			QoSPriorityLevel:   dynamic.PriorityLevelQos,
+			PacketDelayBudget:  f1ies.PacketDelayBudget{Value: dynamic.PacketDelayBudget},
+			PacketErrorRate:    f1ies.PacketErrorRate{PERScalar: dynamic.PacketErrorRate.PERScalar, PERExponent: dynamic.PacketErrorRate.PERExponent},
+			FiveQI:             dynamic.FiveQI,
+			AveragingWindow:    dynamic.AveragingWindow,
+			MaxDataBurstVolume: dynamic.MaximumDataBurstVolume,
+		}
+		if dynamic.DelayCritical != nil {
+			descriptor.DelayCritical = &f1ies.DelayCritical{Value: dynamic.DelayCritical.Value}
+		}
+		params.QoSCharacteristics = f1ies.QoSCharacteristics{
+			Choice:     f1ies.QoSCharacteristicsPresentDynamic5QI,
+			Dynamic5QI: descriptor,
+		}
+	} else {
+		params.QoSCharacteristics = f1ies.QoSCharacteristics{
+			Choice: f1ies.QoSCharacteristicsPresentNonDynamic5QI,
+			NonDynamic5QI: &f1ies.NonDynamic5QIDescriptor{
+				FiveQI:           flow.FiveQi,
+				QoSPriorityLevel: flow.PriorityLevelQos,
+			},
+		}
+	}
+
+	if gbr := flow.Gbr; gbr != nil {
+		params.GBRQoSFlowInformation = &f1ies.GBRQoSFlowInformation{
+			MaxFlowBitRateDownlink:        gbr.MaximumFlowBitRateDL,
+			MaxFlowBitRateUplink:          gbr.MaximumFlowBitRateUL,
+			GuaranteedFlowBitRateDownlink: gbr.GuaranteedFlowBitRateDL,
+			GuaranteedFlowBitRateUplink:   gbr.GuaranteedFlowBitRateUL,
+			MaxPacketLossRateDownlink:     gbr.MaximumPacketLossRateDL,
+			MaxPacketLossRateUplink:       gbr.MaximumPacketLossRateUL,
+		}
+	}
+	return params
+}
+
 func (cu *CuCpContext) handleF1UEContextModificationResponse(
 	msg *f1ies.UEContextModificationResponse,
 ) error {
@@ -154,30 +428,97 @@ func (cu *CuCpContext) handleF1UEContextModificationResponse(
 	if err != nil {
 		return fmt.Errorf("UE not found for CU-UE-F1AP-ID %d: %v", msg.GNBCUUEF1APID, err)
 	}
+	if msg.GNBCUUEF1APID != int64(ue.GnbCuUeF1apId) {
+		return cu.handleHandoverCommandDelivery(ue, msg.GNBCUUEF1APID, true)
+	}
+	if restoringBearers(ue) {
+		cu.applyF1DrbsRestored(ue, msg)
+		return nil
+	}
 
-	if msg.DRBsSetupModList != nil {
-		for _, drb := range msg.DRBsSetupModList {
-			drbId := uint8(drb.DRBID)
-			cu.Info("DRB ID=%d setup successful at DU", drbId)
+	cu.applyF1PduSessionModify(ue, msg)
 
-			var pduSession *uecontext.PduSessionContext
-			for _, ps := range ue.PduSessions {
-				if ps.DrbId == drbId {
-					pduSession = ps
-					break
-				}
-			}
+	var updated []*uecontext.PduSessionContext
+	var failedDrbs []uint8
+	for _, item := range msg.DRBsSetupModList {
+		drbId := uint8(item.DRBID)
+		pduSession, drb := ue.FindDrb(drbId)
+		if drb == nil {
+			cu.Error("No PDU session found for DRB ID=%d", drbId)
+			continue
+		}
+		if pduSession.State == uecontext.PDU_SESSION_MODIFYING {
+			continue
+		}
+		if pduSession.State != uecontext.PDU_SESSION_ESTABLISHING {
+			cu.Warn("PDU Session ID=%d is no longer being set up, ignoring DRB ID=%d", pduSession.PduSessionId, drbId)
+			continue
+		}
 
-			if pduSession == nil {
-				cu.Error("No PDU session found for DRB ID=%d", drbId)
-				continue
-			}
+		drb.DlF1uTunnel = f1DlTunnel(item.DLUPTNLInformationToBeSetupList)
+		if drb.DlF1uTunnel == nil {
+			cu.Error("DU returned no DL F1-U tunnel for DRB ID=%d", drbId)
+			failedDrbs = append(failedDrbs, drbId)
+			continue
+		}
 
-			cu.Info("TODO: Extract DL GTP Tunnel info from DRB setup response")
+		cu.Info("DRB ID=%d setup successful at DU", drbId)
+		if !slices.Contains(updated, pduSession) {
+			updated = append(updated, pduSession)
 		}
 	}
+	for _, item := range msg.DRBsFailedToBeSetupModList {
+		if pduSession, drb := ue.FindDrb(uint8(item.DRBID)); drb != nil && pduSession.State == uecontext.PDU_SESSION_MODIFYING {
+			continue
+		}
+		cu.Error("DU could not set up DRB ID=%d", item.DRBID)
+		failedDrbs = append(failedDrbs, uint8(item.DRBID))
+	}
 
-	cu.Info("TODO: E1AP Bearer Context Modification Request to CU-UP (out of scope)")
+	// A session keeps going without a dedicated DRB the DU refused, only the
+	// flows mapped to it are lost. Without its default DRB the session fails.
+	removedDrbs := make(map[uint8][]uint8)
+	var lost []uint8
+	for _, drbId := range failedDrbs {
+		pduSession, drb := ue.FindDrb(drbId)
+		if drb == nil || pduSession.State != uecontext.PDU_SESSION_ESTABLISHING {
+			continue
+		}
+		pduSession.RemoveDrb(drbId)
+		ue.ReleaseDrbId(drbId)
+		if drb.Default {
+			lost = append(lost, pduSession.PduSessionId)
+			continue
+		}
+		removedDrbs[pduSession.PduSessionId] = append(removedDrbs[pduSession.PduSessionId], drbId)
+		if !slices.Contains(updated, pduSession) {
+			updated = append(updated, pduSession)
+		}
+	}
+	for _, id := range lost {
+		cu.Error("Default DRB of PDU Session ID=%d was not set up by the DU", id)
+		updated = slices.DeleteFunc(updated, func(ps *uecontext.PduSessionContext) bool {
+			return ps.PduSessionId == id
+		})
+		cu.failPduSessionSetup(ue, id, ies.Cause{
+			Choice:       ies.CausePresentRadionetwork,
+			RadioNetwork: &ies.CauseRadioNetwork{Value: ies.CauseRadioNetworkRadioresourcesnotavailable},
+		})
+	}
+	if len(lost) > 0 {
+		if err = cu.removeBearerContextSessions(ue, lost); err != nil {
+			cu.Error("Failed to remove PDU sessions from the bearer context: %v", err)
+		}
+	}
+
+	if len(updated) == 0 {
+		cu.reportPduSessionSetupFailures(ue)
+		return nil
+	}
+
+	if err = cu.sendBearerContextDlF1uTunnels(ue, updated, removedDrbs); err != nil {
+		cu.Error("Failed to send DL F1-U tunnels to CU-UP: %v", err)
+	}
 
 	err = cu.sendRRCReconfigurationForPduSession(ue)
 	if err != nil {
@@ -187,42 +528,70 @@ func (cu *CuCpContext) handleF1UEContextModificationResponse(
 	return nil
 }
 
-func (cu *CuCpContext) sendRRCReconfigurationForPduSession(
-	ue *uecontext.GNBUe,
+func (cu *CuCpContext) handleF1UEContextModificationFailure(
+	msg *f1ies.UEContextModificationFailure,
 ) error {
-	cu.Info("Building RRC Reconfiguration for PDU Session establishment")
+	cu.Info("Processing F1AP UE Context Modification Failure")
 
-	duCtx, err := cu.GetDUForUE(ue)
+	ue, err := cu.GetUEByF1Id(msg.GNBCUUEF1APID)
 	if err != nil {
-		return fmt.Errorf("DU not found for UE: %v", err)
+		return fmt.Errorf("UE not found for CU-UE-F1AP-ID %d: %v", msg.GNBCUUEF1APID, err)
+	}
+	if msg.GNBCUUEF1APID != int64(ue.GnbCuUeF1apId) {
+		return cu.handleHandoverCommandDelivery(ue, msg.GNBCUUEF1APID, false)
+	}
+	if restoringBearers(ue) {
+		cu.failBearerRestore(ue)
+		return fmt.Errorf("DU could not restore the DRBs of UE RAN-UE-NGAP-ID=%d", ue.RanUeNgapId)
+	}
+
+	var failed []uint8
+	for id, pduSession := range ue.PduSessions {
+		if pduSession.State == uecontext.PDU_SESSION_ESTABLISHING {
+			failed = append(failed, id)
+		}
+	}
+	for _, id := range failed {
+		cu.Error("DU could not set up DRB for PDU Session ID=%d", id)
+		cu.failPduSessionSetup(ue, id, ies.Cause{
+			Choice:       ies.CausePresentRadionetwork,
+			RadioNetwork: &ies.CauseRadioNetwork{Value: ies.CauseRadioNetworkUnspecified},
+		})
+	}
+
+	if err = cu.removeBearerContextSessions(ue, failed); err != nil {
+		cu.Error("Failed to remove PDU sessions from the bearer context: %v", err)
 	}
+	cu.reportPduSessionSetupFailures(ue)
+
+	for id := range ue.PduSessions {
+		if pduSession := modifyingPduSession(ue, int64(id), uecontext.PDU_SESSION_MODIFY_DU); pduSession != nil {
+			cu.Error("DU could not set up the DRBs added to PDU Session ID=%d", id)
+			cu.abortPduSessionModify(ue, pduSession, radioNetworkCause(ies.CauseRadioNetworkRadioresourcesnotavailable))
+		}
+	}
+	cu.reportPduSessionModify(ue)
+	return nil
+}
+
+func (cu *CuCpContext) sendRRCReconfigurationForPduSession(
+	ue *uecontext.GNBUe,
+) error {
+	if ue.InitialContextSetup != nil {
+		// The DRBs go with SRB2 in the reconfiguration of the initial context
+		cu.continueInitialContextSetup(ue)
+		return nil
+	}
+	cu.Info("Building RRC Reconfiguration for PDU Session establishment")
 
 	var drbToAddModList []rrcies.DRB_ToAddMod
 	var nasPduList []rrcies.DedicatedNAS_Message
 
 	for _, pduSession := range ue.PduSessions {
-		if pduSession.State == uecontext.PDU_SESSION_ESTABLISHING {
-			drbToAddModList = append(drbToAddModList, rrcies.DRB_ToAddMod{
-				Drb_Identity: rrcies.DRB_Identity{
-					Value: uint64(pduSession.DrbId),
-				},
-				Pdcp_Config: &rrcies.PDCP_Config{
-					Drb: &rrcies.PDCP_Config_drb{
-						Pdcp_SN_SizeUL: &rrcies.PDCP_Config_drb_pdcp_SN_SizeUL{
-							Value: rrcies.PDCP_Config_drb_pdcp_SN_SizeUL_Enum_len18bits,
-						},
-						Pdcp_SN_SizeDL: &rrcies.PDCP_Config_drb_pdcp_SN_SizeDL{
-							Value: rrcies.PDCP_Config_drb_pdcp_SN_SizeDL_Enum_len18bits,
-						},
-						HeaderCompression: &rrcies.PDCP_Config_drb_headerCompression{
-							Choice: rrcies.PDCP_Config_drb_headerCompression_Choice_NotUsed,
-						},
-					},
-					T_Reordering: &rrcies.PDCP_Config_t_Reordering{
-						Value: rrcies.PDCP_Config_t_Reordering_Enum_ms100,
-					},
-				},
-			})
+		if pduSession.State == uecontext.PDU_SESSION_ESTABLISHING && pduSession.DrbsSetupAtDu() {
+			for _, drb := range pduSession.Drbs {
+				drbToAddModList = append(drbToAddModList, buildRrcDrbToAddMod(pduSession, drb))
+			}
 
 			if len(pduSession.NasPduSessionAccept) > 0 {
 				nasPduList = append(nasPduList, rrcies.DedicatedNAS_Message{
@@ -237,85 +606,101 @@ func (cu *CuCpContext) sendRRCReconfigurationForPduSession(
 		return fmt.Errorf("failed to encode MasterCellGroup: %w", err)
 	}
 
-	rrcmsg := rrcies.RRCReconfiguration{
-		Rrc_TransactionIdentifier: rrcies.RRC_TransactionIdentifier{Value: 1},
-		CriticalExtensions: rrcies.RRCReconfiguration_CriticalExtensions{
-			Choice: rrcies.RRCReconfiguration_CriticalExtensions_Choice_RrcReconfiguration,
-			RrcReconfiguration: &rrcies.RRCReconfiguration_IEs{
-				RadioBearerConfig: &rrcies.RadioBearerConfig{
-					Drb_ToAddModList: &rrcies.DRB_ToAddModList{
-						Value: drbToAddModList,
-					},
-				},
-				NonCriticalExtension: &rrcies.RRCReconfiguration_v1530_IEs{
-					MasterCellGroup:          &masterCellGroupBytes,
-					DedicatedNAS_MessageList: nasPduList,
-				},
+	rrcBytes, err := encodeRrcReconfiguration(rrcTransactionPduSessionSetup, &rrcies.RRCReconfiguration_IEs{
+		RadioBearerConfig: &rrcies.RadioBearerConfig{
+			Drb_ToAddModList: &rrcies.DRB_ToAddModList{
+				Value: drbToAddModList,
 			},
 		},
-	}
-
-	dlDcchMsg := rrcies.DL_DCCH_Message{
-		Message: rrcies.DL_DCCH_MessageType{
-			Choice: rrcies.DL_DCCH_MessageType_Choice_C1,
-			C1: &rrcies.DL_DCCH_MessageType_C1{
-				Choice:             rrcies.DL_DCCH_MessageType_C1_Choice_RrcReconfiguration,
-				RrcReconfiguration: &rrcmsg,
-			},
+		NonCriticalExtension: &rrcies.RRCReconfiguration_v1530_IEs{
+			MasterCellGroup:          &masterCellGroupBytes,
+			DedicatedNAS_MessageList: nasPduList,
 		},
-	}
-
-	rrcBytes, err := rrc.Encode(&dlDcchMsg)
+	})
 	if err != nil {
-		return fmt.Errorf("failed to encode RRC Reconfiguration: %w", err)
+		return err
 	}
 
-	f1rrcdl := f1ies.DLRRCMessageTransfer{
-		GNBCUUEF1APID:      int64(ue.GnbCuUeF1apId),
-		GNBDUUEF1APID:      int64(ue.DuUeId),
-		SRBID:              1,
-		RRCContainer:       rrcBytes,
-		ExecuteDuplication: &f1ies.ExecuteDuplication{Value: 0},
-	}
+	return cu.sendRrcReconfiguration(ue, rrcBytes)
+}
 
-	f1apBytes, err := f1ap.F1apEncode(&f1rrcdl)
-	if err != nil {
-		return fmt.Errorf("failed to encode F1AP DL RRC Message Transfer: %w", err)
+// buildRrcDrbToAddMod configures a DRB at the UE. The SDAP configuration
+// tells the UE which QoS flows of the session go on the DRB; the SDAP headers
+// stay absent as in the configuration given to the CU-UP.
+func buildRrcDrbToAddMod(pduSession *uecontext.PduSessionContext, drb *uecontext.DrbContext) rrcies.DRB_ToAddMod {
+	var qfis []rrcies.QFI
+	for _, flow := range drb.QosFlows {
+		if !flow.Failed {
+			qfis = append(qfis, rrcies.QFI{Value: uint64(flow.Qfi)})
+		}
 	}
 
-	err = duCtx.SendF1ap(f1apBytes)
-	if err != nil {
-		return fmt.Errorf("failed to send RRC Reconfiguration to DU: %w", err)
+	return rrcies.DRB_ToAddMod{
+		CnAssociation: &rrcies.DRB_ToAddMod_cnAssociation{
+			Choice: rrcies.DRB_ToAddMod_cnAssociation_Choice_Sdap_Config,
+			Sdap_Config: &rrcies.SDAP_Config{
+				Pdu_Session:          rrcies.PDU_SessionID{Value: uint64(pduSession.PduSessionId)},
+				Sdap_HeaderDL:        rrcies.SDAP_Config_sdap_HeaderDL{Value: rrcies.SDAP_Config_sdap_HeaderDL_Enum_absent},
+				Sdap_HeaderUL:        rrcies.SDAP_Config_sdap_HeaderUL{Value: rrcies.SDAP_Config_sdap_HeaderUL_Enum_absent},
+				DefaultDRB:           drb.Default,
+				MappedQoS_FlowsToAdd: qfis,
+			},
+		},
+		Drb_Identity: rrcies.DRB_Identity{
+			Value: uint64(drb.DrbId),
+		},
+		Pdcp_Config: &rrcies.PDCP_Config{
+			Drb: &rrcies.PDCP_Config_drb{
+				Pdcp_SN_SizeUL: &rrcies.PDCP_Config_drb_pdcp_SN_SizeUL{
+					Value: rrcies.PDCP_Config_drb_pdcp_SN_SizeUL_Enum_len18bits,
+				},
+				Pdcp_SN_SizeDL: &rrcies.PDCP_Config_drb_pdcp_SN_SizeDL{
+					Value: rrcies.PDCP_Config_drb_pdcp_SN_SizeDL_Enum_len18bits,
+				},
+				HeaderCompression: &rrcies.PDCP_Config_drb_headerCompression{
+					Choice: rrcies.PDCP_Config_drb_headerCompression_Choice_NotUsed,
+				},
+			},
+			T_Reordering: &rrcies.PDCP_Config_t_Reordering{
+				Value: rrcies.PDCP_Config_t_Reordering_Enum_ms100,
+			},
+		},
 	}
-
-	cu.Info("RRC Reconfiguration sent to DU %d for PDU Session establishment", duCtx.DuId)
-	return nil
 }
 
+// sendPduSessionResourceSetupResponse reports the given newly established
+// sessions together with the queued setup failures
 func (cu *CuCpContext) sendPduSessionResourceSetupResponse(
 	ue *uecontext.GNBUe,
+	established []*uecontext.PduSessionContext,
 ) error {
 	cu.Info("Building NGAP PDU Session Resource Setup Response")
 
 	var setupList []ies.PDUSessionResourceSetupItemSURes
+	for _, pduSession := range established {
+		transferBytes, err := buildPduSessionSetupResponseTransfer(pduSession)
+		if err != nil {
+			cu.Error("PDU Session ID=%d: %v", pduSession.PduSessionId, err)
+			cu.failPduSessionSetup(ue, pduSession.PduSessionId, userPlaneFailureCause())
+			continue
+		}
 
-	for _, pduSession := range ue.PduSessions {
-		if pduSession.State == uecontext.PDU_SESSION_ACTIVE {
-			transferBytes := []byte{}
-
-			setupItem := ies.PDUSessionResourceSetupItemSURes{
-				PDUSessionID:                            int64(pduSession.PduSessionId),
-				PDUSessionResourceSetupResponseTransfer: transferBytes,
-			}
-
-			setupList = append(setupList, setupItem)
+		setupItem := ies.PDUSessionResourceSetupItemSURes{
+			PDUSessionID:                            int64(pduSession.PduSessionId),
+			PDUSessionResourceSetupResponseTransfer: transferBytes,
 		}
+
+		setupList = append(setupList, setupItem)
 	}
 
+	failedList := ue.FailedPduSessions
+	ue.FailedPduSessions = nil
+
 	msg := ies.PDUSessionResourceSetupResponse{
-		AMFUENGAPID:                      ue.AmfUeNgapId,
-		RANUENGAPID:                      ue.RanUeNgapId,
-		PDUSessionResourceSetupListSURes: setupList,
+		AMFUENGAPID:                              ue.AmfUeNgapId,
+		RANUENGAPID:                              ue.RanUeNgapId,
+		PDUSessionResourceSetupListSURes:         setupList,
+		PDUSessionResourceFailedToSetupListSURes: failedList,
 	}
 
 	ngapBytes, err := ngap.NgapEncode(&msg)
@@ -336,3 +721,55 @@ func (cu *CuCpContext) sendPduSessionResourceSetupResponse(
 	cu.Info("NGAP PDU Session Resource Setup Response sent to AMF")
 	return nil
 }
+
+// buildPduSessionSetupResponseTransfer encodes the CU-UP NG-U tunnel and the
+// QoS flows admitted on it for the SMF
+func buildPduSessionSetupResponseTransfer(pduSession *uecontext.PduSessionContext) ([]byte, error) {
+	if pduSession.DlNguTunnel == nil {
+		return nil, fmt.Errorf("no DL NG-U tunnel allocated")
+	}
+
+	var associated []ies.AssociatedQosFlowItem
+	var failed []ies.QosFlowWithCauseItem
+	for _, flow := range pduSession.QosFlows {
+		if flow.Failed {
+			failed = append(failed, ies.QosFlowWithCauseItem{
+				QosFlowIdentifier: int64(flow.Qfi),
+				Cause:             userPlaneFailureCause(),
+			})
+			continue
+		}
+		associated = append(associated, ies.AssociatedQosFlowItem{
+			QosFlowIdentifier: int64(flow.Qfi),
+		})
+	}
+	if len(associated) == 0 {
+		return nil, fmt.Errorf("no QoS flow admitted")
+	}
+
+	teid := make([]byte, 4)
+	binary.BigEndian.PutUint32(teid, pduSession.DlNguTunnel.Teid)
+
+	transfer := ies.PDUSessionResourceSetupResponseTransfer{
+		DLQosFlowPerTNLInformation: ies.QosFlowPerTNLInformation{
+			UPTransportLayerInformation: ies.UPTransportLayerInformation{
+				Choice: ies.UPTransportLayerInformationPresentGtptunnel,
+				GTPTunnel: &ies.GTPTunnel{
+					TransportLayerAddress: aper.BitString{
+						Bytes:   pduSession.DlNguTunnel.Address,
+						NumBits: uint64(len(pduSession.DlNguTunnel.Address) * 8),
+					},
+					GTPTEID: teid,
+				},
+			},
+			AssociatedQosFlowList: associated,
+		},
+		QosFlowFailedToSetupList: failed,
+	}
+
+	transferBytes, err := transfer.Encode()
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode PDU Session Resource Setup Response Transfer: %w", err)
+	}
+	return transferBytes, nil
+}
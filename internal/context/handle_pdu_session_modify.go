@@ -0,0 +1,616 @@
+package context
+
+import (
+	"central-unit/internal/context/amfcontext"
+	"central-unit/internal/context/uecontext"
+	f1ext "central-unit/pkg/f1ap/ies"
+	"fmt"
+	"slices"
+
+	f1ies "github.com/JocelynWS/f1-gen/ies"
+	"github.com/lvdund/ngap"
+	"github.com/lvdund/ngap/aper"
+	"github.com/lvdund/ngap/ies"
+	"github.com/lvdund/rrc"
+	rrcies "github.com/lvdund/rrc/ies"
+)
+
+// handlePduSessionResourceModifyRequest applies the QoS flow changes asked by
+// the SMF. Like a setup, each session goes through the CU-UP, the DU and the
+// UE before it is reported in the PDU Session Resource Modify Response.
+func (cu *CuCpContext) handlePduSessionResourceModifyRequest(
+	amf *amfcontext.GNBAmf,
+	msg *ies.PDUSessionResourceModifyRequest,
+) {
+	cu.Info("Processing PDU Session Resource Modify Request")
+
+	ue, err := cu.GetUEByNgapId(msg.RANUENGAPID)
+	if err != nil {
+		cu.Error("UE not found for RAN-UE-NGAP-ID %d: %v", msg.RANUENGAPID, err)
+		return
+	}
+
+	if ue.Suspension != nil {
+		if !cu.holdForResume(ue, msg) {
+			for _, item := range msg.PDUSessionResourceModifyListModReq {
+				queuePduSessionModifyFailure(ue, uint8(item.PDUSessionID), unreachableCause())
+			}
+			cu.reportPduSessionModify(ue)
+		}
+		return
+	}
+
+	var toCuUp []*uecontext.PduSessionContext
+	var modifying []*uecontext.PduSessionContext
+	for _, item := range msg.PDUSessionResourceModifyListModReq {
+		pduSessionId := uint8(item.PDUSessionID)
+
+		pduSession, ok := ue.PduSessions[pduSessionId]
+		if !ok {
+			cu.Error("PDU Session ID=%d to modify does not exist", pduSessionId)
+			queuePduSessionModifyFailure(ue, pduSessionId, radioNetworkCause(ies.CauseRadioNetworkUnknownpdusessionid))
+			continue
+		}
+		if pduSession.State != uecontext.PDU_SESSION_ACTIVE {
+			cu.Error("PDU Session ID=%d is busy with another procedure", pduSessionId)
+			queuePduSessionModifyFailure(ue, pduSessionId, radioNetworkCause(ies.CauseRadioNetworkInteractionwithotherprocedure))
+			continue
+		}
+
+		if err = cu.startPduSessionModify(ue, pduSession, item); err != nil {
+			cu.Error("Invalid PDU Session Resource Modify Request Transfer for PDU Session ID %d: %v", pduSessionId, err)
+			queuePduSessionModifyFailure(ue, pduSessionId, ies.Cause{
+				Choice:   ies.CausePresentProtocol,
+				Protocol: &ies.CauseProtocol{Value: ies.CauseProtocolSemanticerror},
+			})
+			continue
+		}
+
+		mod := pduSession.Modification
+		if mod.Ambr != nil || len(mod.NewDrbs) > 0 || len(mod.ModifiedDrbs) > 0 || len(mod.ReleasedDrbs) > 0 {
+			toCuUp = append(toCuUp, pduSession)
+		}
+		modifying = append(modifying, pduSession)
+	}
+
+	if len(toCuUp) > 0 {
+		if err = cu.modifyBearerContext(ue, toCuUp); err != nil {
+			cu.Error("Failed to modify the bearer context at CU-UP: %v", err)
+			for _, pduSession := range toCuUp {
+				cu.abortPduSessionModify(ue, pduSession, userPlaneFailureCause())
+			}
+		}
+	}
+
+	// Sessions with nothing to change at the CU-UP move on right away
+	for _, pduSession := range modifying {
+		if pduSession.State == uecontext.PDU_SESSION_MODIFYING && !slices.Contains(toCuUp, pduSession) {
+			cu.continuePduSessionModify(ue, pduSession)
+		}
+	}
+	cu.reportPduSessionModify(ue)
+}
+
+// startPduSessionModify applies the requested changes to the session context
+// and keeps what is needed to report or undo them. Flows that cannot be added
+// or modified are only failed on their own.
+func (cu *CuCpContext) startPduSessionModify(
+	ue *uecontext.GNBUe,
+	pduSession *uecontext.PduSessionContext,
+	item ies.PDUSessionResourceModifyItemModReq,
+) error {
+	var transfer ies.PDUSessionResourceModifyRequestTransfer
+	if err, _ := transfer.Decode(item.PDUSessionResourceModifyRequestTransfer); err != nil {
+		return err
+	}
+
+	mod := &uecontext.PduSessionModification{
+		Stage:         uecontext.PDU_SESSION_MODIFY_CUUP,
+		NasPdu:        item.NASPDU,
+		Ambr:          transfer.PDUSessionAggregateMaximumBitRate,
+		PreviousQos:   make(map[uint8]uecontext.QosFlowContext),
+		ReleasedFlows: make(map[uint8][]uint8),
+	}
+	pduSession.State = uecontext.PDU_SESSION_MODIFYING
+	pduSession.Modification = mod
+
+	// Flows rejected at setup were never carried, forget them so that the
+	// SMF can add them again
+	for _, flow := range slices.Clone(pduSession.QosFlows) {
+		if flow.Failed {
+			pduSession.RemoveQosFlow(flow.Qfi)
+		}
+	}
+
+	for _, release := range transfer.QosFlowToReleaseList {
+		qfi := uint8(release.QosFlowIdentifier)
+		if pduSession.GetQosFlow(qfi) == nil {
+			cu.Warn("QoS flow %d to release is not part of PDU Session ID=%d", qfi, pduSession.PduSessionId)
+			continue
+		}
+		if drb := pduSession.RemoveQosFlow(qfi); drb != nil {
+			mod.ReleasedFlows[drb.DrbId] = append(mod.ReleasedFlows[drb.DrbId], qfi)
+		}
+	}
+	// The default DRB stays even without flows, it carries the traffic of
+	// flows that have no mapping
+	for _, drb := range slices.Clone(pduSession.Drbs) {
+		if _, ok := mod.ReleasedFlows[drb.DrbId]; !ok {
+			continue
+		}
+		if len(drb.QosFlows) == 0 && !drb.Default {
+			pduSession.RemoveDrb(drb.DrbId)
+			ue.ReleaseDrbId(drb.DrbId)
+			mod.ReleasedDrbs = append(mod.ReleasedDrbs, drb.DrbId)
+		} else {
+			mod.ModifiedDrbs = append(mod.ModifiedDrbs, drb.DrbId)
+		}
+	}
+
+	seen := make(map[uint8]bool)
+	for _, request := range transfer.QosFlowAddOrModifyRequestList {
+		qfi := uint8(request.QosFlowIdentifier)
+		if seen[qfi] {
+			mod.FailedFlows = append(mod.FailedFlows, ies.QosFlowWithCauseItem{
+				QosFlowIdentifier: request.QosFlowIdentifier,
+				Cause:             radioNetworkCause(ies.CauseRadioNetworkMultipleqosflowidinstances),
+			})
+			continue
+		}
+		seen[qfi] = true
+
+		flow := pduSession.GetQosFlow(qfi)
+		if flow != nil && request.QosFlowLevelQosParameters == nil {
+			// Nothing the RAN has to change for the flow
+			mod.ModifiedFlows = append(mod.ModifiedFlows, flow)
+			mod.PreviousQos[qfi] = *flow
+			continue
+		}
+
+		var updated *uecontext.QosFlowContext
+		err := fmt.Errorf("no QoS parameters for the new flow")
+		if request.QosFlowLevelQosParameters != nil {
+			updated, err = newQosFlowContext(request.QosFlowIdentifier, request.QosFlowLevelQosParameters)
+		}
+		if err != nil {
+			cu.Error("QoS flow %d of PDU Session ID=%d: %v", qfi, pduSession.PduSessionId, err)
+			mod.FailedFlows = append(mod.FailedFlows, ies.QosFlowWithCauseItem{
+				QosFlowIdentifier: request.QosFlowIdentifier,
+				Cause: ies.Cause{
+					Choice:   ies.CausePresentProtocol,
+					Protocol: &ies.CauseProtocol{Value: ies.CauseProtocolSemanticerror},
+				},
+			})
+			continue
+		}
+
+		if flow == nil {
+			pduSession.QosFlows = append(pduSession.QosFlows, updated)
+			mod.AddedFlows = append(mod.AddedFlows, updated)
+			continue
+		}
+
+		// A modified flow stays on its DRB, only the QoS the DRB is set up
+		// with changes
+		mod.PreviousQos[qfi] = *flow
+		*flow = *updated
+		mod.ModifiedFlows = append(mod.ModifiedFlows, flow)
+		if drb := pduSession.DrbOfFlow(qfi); drb != nil && !slices.Contains(mod.ModifiedDrbs, drb.DrbId) {
+			mod.ModifiedDrbs = append(mod.ModifiedDrbs, drb.DrbId)
+		}
+	}
+
+	if len(mod.AddedFlows) > 0 {
+		newDrbs, extendedDrbs, err := cu.mapAddedQosFlows(ue, pduSession, mod.AddedFlows)
+		mod.NewDrbs = newDrbs
+		for _, drbId := range extendedDrbs {
+			if !slices.Contains(mod.ModifiedDrbs, drbId) {
+				mod.ModifiedDrbs = append(mod.ModifiedDrbs, drbId)
+			}
+		}
+		if err != nil {
+			cu.Error("No DRB for the QoS flows added to PDU Session ID=%d: %v", pduSession.PduSessionId, err)
+			for _, flow := range slices.Clone(mod.AddedFlows) {
+				failModifiedQosFlow(pduSession, flow.Qfi, radioNetworkCause(ies.CauseRadioNetworkRadioresourcesnotavailable))
+			}
+			for _, drbId := range mod.NewDrbs {
+				pduSession.RemoveDrb(drbId)
+				ue.ReleaseDrbId(drbId)
+			}
+			mod.NewDrbs = nil
+		}
+	}
+
+	for _, drb := range pduSession.Drbs {
+		cu.Info("PDU Session ID=%d, DRB ID=%d now carries %d QoS flow(s)", pduSession.PduSessionId, drb.DrbId, len(drb.QosFlows))
+	}
+	return nil
+}
+
+// failModifiedQosFlow gives up the change requested for a QoS flow: an added
+// flow leaves the session, a modified one gets its previous QoS back
+func failModifiedQosFlow(pduSession *uecontext.PduSessionContext, qfi uint8, cause ies.Cause) {
+	mod := pduSession.Modification
+	isFlow := func(flow *uecontext.QosFlowContext) bool { return flow.Qfi == qfi }
+
+	if i := slices.IndexFunc(mod.AddedFlows, isFlow); i >= 0 {
+		mod.AddedFlows = slices.Delete(mod.AddedFlows, i, i+1)
+		pduSession.RemoveQosFlow(qfi)
+	} else if i := slices.IndexFunc(mod.ModifiedFlows, isFlow); i >= 0 {
+		*mod.ModifiedFlows[i] = mod.PreviousQos[qfi]
+		mod.ModifiedFlows = slices.Delete(mod.ModifiedFlows, i, i+1)
+	} else {
+		return
+	}
+
+	mod.FailedFlows = append(mod.FailedFlows, ies.QosFlowWithCauseItem{
+		QosFlowIdentifier: int64(qfi),
+		Cause:             cause,
+	})
+}
+
+// modifyingPduSession returns the session with the given ID when its
+// modification waits at the given stage
+func modifyingPduSession(ue *uecontext.GNBUe, pduSessionId int64, stage uint8) *uecontext.PduSessionContext {
+	pduSession, ok := ue.PduSessions[uint8(pduSessionId)]
+	if !ok || pduSession.State != uecontext.PDU_SESSION_MODIFYING || pduSession.Modification.Stage != stage {
+		return nil
+	}
+	return pduSession
+}
+
+// continuePduSessionModify moves a modification on once the CU-UP is done.
+// New DRBs need the DU tunnels before the UE can be reconfigured, other DRB
+// changes reach the DU together with the RRC Reconfiguration.
+func (cu *CuCpContext) continuePduSessionModify(ue *uecontext.GNBUe, pduSession *uecontext.PduSessionContext) {
+	mod := pduSession.Modification
+	if len(mod.NewDrbs) == 0 {
+		cu.reconfigurePduSessionModify(ue, pduSession, true)
+		return
+	}
+
+	mod.Stage = uecontext.PDU_SESSION_MODIFY_DU
+	if err := cu.sendF1PduSessionModify(ue, pduSession, nil); err != nil {
+		cu.Error("Failed to modify DRBs of PDU Session ID=%d at the DU: %v", pduSession.PduSessionId, err)
+		cu.abortPduSessionModify(ue, pduSession, radioNetworkCause(ies.CauseRadioNetworkRadioresourcesnotavailable))
+	}
+}
+
+// reconfigurePduSessionModify tells the UE about the DRB changes and hands
+// it the NAS PDU. With toDu, the DRB changes are also sent to the DU. A
+// modification the UE does not need to know about completes right away.
+func (cu *CuCpContext) reconfigurePduSessionModify(ue *uecontext.GNBUe, pduSession *uecontext.PduSessionContext, toDu bool) {
+	mod := pduSession.Modification
+
+	rrcBytes, err := cu.buildRrcReconfigurationForModify(ue, pduSession)
+	if err != nil {
+		cu.Error("Failed to build RRC Reconfiguration: %v", err)
+		cu.abortPduSessionModify(ue, pduSession, radioNetworkCause(ies.CauseRadioNetworkUnspecified))
+		return
+	}
+
+	if toDu && (len(mod.ModifiedDrbs) > 0 || len(mod.ReleasedDrbs) > 0) {
+		err = cu.sendF1PduSessionModify(ue, pduSession, rrcBytes)
+	} else if rrcBytes != nil {
+		err = cu.sendRrcReconfiguration(ue, rrcBytes)
+	}
+	if err != nil {
+		cu.Error("Failed to reconfigure PDU Session ID=%d: %v", pduSession.PduSessionId, err)
+		cu.abortPduSessionModify(ue, pduSession, radioNetworkCause(ies.CauseRadioNetworkRadioresourcesnotavailable))
+		return
+	}
+
+	if rrcBytes == nil {
+		cu.finishPduSessionModify(ue, pduSession)
+		return
+	}
+	mod.Stage = uecontext.PDU_SESSION_MODIFY_UE
+}
+
+// sendF1PduSessionModify sends the DRB changes of a session to the DU
+func (cu *CuCpContext) sendF1PduSessionModify(ue *uecontext.GNBUe, pduSession *uecontext.PduSessionContext, rrcContainer []byte) error {
+	mod := pduSession.Modification
+	msg := f1ext.UEContextModificationRequest{RRCContainer: rrcContainer}
+
+	for _, drb := range pduSession.Drbs {
+		if slices.Contains(mod.NewDrbs, drb.DrbId) {
+			item, err := buildF1DrbToSetup(pduSession, drb)
+			if err != nil {
+				return err
+			}
+			msg.DRBsToBeSetupModList = append(msg.DRBsToBeSetupModList, item)
+			continue
+		}
+		if !slices.Contains(mod.ModifiedDrbs, drb.DrbId) {
+			continue
+		}
+		drbInfo, err := buildF1DrbInformation(pduSession, drb)
+		if err != nil {
+			return err
+		}
+		msg.DRBsToBeModifiedList = append(msg.DRBsToBeModifiedList, f1ext.DRBsToBeModifiedItem{
+			DRBID: int64(drb.DrbId),
+			QoSInformation: &f1ext.QoSInformation{
+				Choice:         f1ext.QoSInformationPresentDRBInformation,
+				DRBInformation: drbInfo,
+			},
+			ULUPTNLInformationToBeSetupList: f1UlTunnels(drb),
+		})
+	}
+	for _, drbId := range mod.ReleasedDrbs {
+		msg.DRBsToBeReleasedList = append(msg.DRBsToBeReleasedList, f1ies.DRBsToBeReleasedItem{DRBID: int64(drbId)})
+	}
+
+	return cu.sendF1UEContextModification(ue, &msg)
+}
+
+// applyF1PduSessionModify takes the DU tunnels of the DRBs added by a
+// modification. DRBs the DU refused are dropped along with their flows before
+// the UE is reconfigured.
+func (cu *CuCpContext) applyF1PduSessionModify(ue *uecontext.GNBUe, msg *f1ies.UEContextModificationResponse) {
+	var answered []*uecontext.PduSessionContext
+	answer := func(drbId int64) *uecontext.DrbContext {
+		pduSession, drb := ue.FindDrb(uint8(drbId))
+		if drb == nil || modifyingPduSession(ue, int64(pduSession.PduSessionId), uecontext.PDU_SESSION_MODIFY_DU) == nil {
+			return nil
+		}
+		if !slices.Contains(answered, pduSession) {
+			answered = append(answered, pduSession)
+		}
+		return drb
+	}
+
+	for _, item := range msg.DRBsSetupModList {
+		if drb := answer(item.DRBID); drb != nil {
+			drb.DlF1uTunnel = f1DlTunnel(item.DLUPTNLInformationToBeSetupList)
+		}
+	}
+	for _, item := range msg.DRBsFailedToBeSetupModList {
+		answer(item.DRBID)
+	}
+
+	for _, pduSession := range answered {
+		mod := pduSession.Modification
+
+		var removed []uint8
+		for _, drbId := range slices.Clone(mod.NewDrbs) {
+			drb := pduSession.GetDrb(drbId)
+			if drb.DlF1uTunnel != nil {
+				continue
+			}
+			cu.Error("DU could not set up DRB ID=%d of PDU Session ID=%d", drbId, pduSession.PduSessionId)
+			for _, flow := range slices.Clone(drb.QosFlows) {
+				failModifiedQosFlow(pduSession, flow.Qfi, radioNetworkCause(ies.CauseRadioNetworkRadioresourcesnotavailable))
+			}
+			pduSession.RemoveDrb(drbId)
+			ue.ReleaseDrbId(drbId)
+			mod.NewDrbs = slices.DeleteFunc(mod.NewDrbs, func(id uint8) bool { return id == drbId })
+			removed = append(removed, drbId)
+		}
+
+		err := cu.sendBearerContextDlF1uTunnels(ue, []*uecontext.PduSessionContext{pduSession}, map[uint8][]uint8{
+			pduSession.PduSessionId: removed,
+		})
+		if err != nil {
+			cu.Error("Failed to send DL F1-U tunnels to CU-UP: %v", err)
+		}
+
+		cu.reconfigurePduSessionModify(ue, pduSession, false)
+	}
+	cu.reportPduSessionModify(ue)
+}
+
+// buildRrcReconfigurationForModify describes the DRB changes of a session to
+// the UE, nil when there is nothing the UE has to apply
+func (cu *CuCpContext) buildRrcReconfigurationForModify(ue *uecontext.GNBUe, pduSession *uecontext.PduSessionContext) ([]byte, error) {
+	mod := pduSession.Modification
+
+	var drbs []rrcies.DRB_ToAddMod
+	for _, drb := range pduSession.Drbs {
+		if slices.Contains(mod.NewDrbs, drb.DrbId) {
+			drbs = append(drbs, buildRrcDrbToAddMod(pduSession, drb))
+			continue
+		}
+
+		var toAdd, toRelease []rrcies.QFI
+		for _, flow := range drb.QosFlows {
+			if slices.Contains(mod.AddedFlows, flow) {
+				toAdd = append(toAdd, rrcies.QFI{Value: uint64(flow.Qfi)})
+			}
+		}
+		for _, qfi := range mod.ReleasedFlows[drb.DrbId] {
+			toRelease = append(toRelease, rrcies.QFI{Value: uint64(qfi)})
+		}
+		if len(toAdd) > 0 || len(toRelease) > 0 {
+			drbs = append(drbs, buildRrcDrbToModify(pduSession, drb, toAdd, toRelease))
+		}
+	}
+
+	reconfig := &rrcies.RRCReconfiguration_IEs{}
+	if len(drbs) > 0 || len(mod.ReleasedDrbs) > 0 {
+		reconfig.RadioBearerConfig = &rrcies.RadioBearerConfig{}
+		if len(drbs) > 0 {
+			reconfig.RadioBearerConfig.Drb_ToAddModList = &rrcies.DRB_ToAddModList{Value: drbs}
+		}
+		for _, drbId := range mod.ReleasedDrbs {
+			if reconfig.RadioBearerConfig.Drb_ToReleaseList == nil {
+				reconfig.RadioBearerConfig.Drb_ToReleaseList = &rrcies.DRB_ToReleaseList{}
+			}
+			reconfig.RadioBearerConfig.Drb_ToReleaseList.Value = append(reconfig.RadioBearerConfig.Drb_ToReleaseList.Value,
+				rrcies.DRB_Identity{Value: uint64(drbId)})
+		}
+	}
+
+	if len(mod.NewDrbs) > 0 || len(mod.NasPdu) > 0 {
+		reconfig.NonCriticalExtension = &rrcies.RRCReconfiguration_v1530_IEs{}
+		// The new DRBs come with the cell group configuration, as at setup
+		if len(mod.NewDrbs) > 0 {
+			masterCellGroupBytes, err := rrc.Encode(ue.MasterCellGroup)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode MasterCellGroup: %w", err)
+			}
+			reconfig.NonCriticalExtension.MasterCellGroup = &masterCellGroupBytes
+		}
+		if len(mod.NasPdu) > 0 {
+			reconfig.NonCriticalExtension.DedicatedNAS_MessageList = []rrcies.DedicatedNAS_Message{{Value: mod.NasPdu}}
+		}
+	}
+
+	if reconfig.RadioBearerConfig == nil && reconfig.NonCriticalExtension == nil {
+		return nil, nil
+	}
+	return encodeRrcReconfiguration(rrcTransactionPduSessionModify, reconfig)
+}
+
+// buildRrcDrbToModify changes the QoS flows mapped to an established DRB,
+// the PDCP configuration of the DRB is kept
+func buildRrcDrbToModify(pduSession *uecontext.PduSessionContext, drb *uecontext.DrbContext, toAdd, toRelease []rrcies.QFI) rrcies.DRB_ToAddMod {
+	return rrcies.DRB_ToAddMod{
+		CnAssociation: &rrcies.DRB_ToAddMod_cnAssociation{
+			Choice: rrcies.DRB_ToAddMod_cnAssociation_Choice_Sdap_Config,
+			Sdap_Config: &rrcies.SDAP_Config{
+				Pdu_Session:              rrcies.PDU_SessionID{Value: uint64(pduSession.PduSessionId)},
+				Sdap_HeaderDL:            rrcies.SDAP_Config_sdap_HeaderDL{Value: rrcies.SDAP_Config_sdap_HeaderDL_Enum_absent},
+				Sdap_HeaderUL:            rrcies.SDAP_Config_sdap_HeaderUL{Value: rrcies.SDAP_Config_sdap_HeaderUL_Enum_absent},
+				DefaultDRB:               drb.Default,
+				MappedQoS_FlowsToAdd:     toAdd,
+				MappedQoS_FlowsToRelease: toRelease,
+			},
+		},
+		Drb_Identity: rrcies.DRB_Identity{
+			Value: uint64(drb.DrbId),
+		},
+	}
+}
+
+// completePduSessionModify finishes the modifications the UE has applied
+func (cu *CuCpContext) completePduSessionModify(ue *uecontext.GNBUe) {
+	for id := range ue.PduSessions {
+		if pduSession := modifyingPduSession(ue, int64(id), uecontext.PDU_SESSION_MODIFY_UE); pduSession != nil {
+			cu.finishPduSessionModify(ue, pduSession)
+		}
+	}
+	cu.reportPduSessionModify(ue)
+}
+
+// finishPduSessionModify queues a session for the Modify Response with the
+// QoS flows that were added or modified and those that failed
+func (cu *CuCpContext) finishPduSessionModify(ue *uecontext.GNBUe, pduSession *uecontext.PduSessionContext) {
+	mod := pduSession.Modification
+	if mod.Ambr != nil {
+		pduSession.Ambr = mod.Ambr
+	}
+	pduSession.State = uecontext.PDU_SESSION_ACTIVE
+	pduSession.Modification = nil
+
+	var flows []ies.QosFlowAddOrModifyResponseItem
+	for _, flow := range append(slices.Clone(mod.AddedFlows), mod.ModifiedFlows...) {
+		flows = append(flows, ies.QosFlowAddOrModifyResponseItem{QosFlowIdentifier: int64(flow.Qfi)})
+	}
+	transfer := ies.PDUSessionResourceModifyResponseTransfer{
+		QosFlowAddOrModifyResponseList: flows,
+		QosFlowFailedToAddOrModifyList: mod.FailedFlows,
+	}
+	transferBytes, err := transfer.Encode()
+	if err != nil {
+		cu.Error("Failed to encode PDU Session Resource Modify Response Transfer: %v", err)
+	}
+
+	ue.ModifiedPduSessions = append(ue.ModifiedPduSessions, ies.PDUSessionResourceModifyItemModRes{
+		PDUSessionID:                             int64(pduSession.PduSessionId),
+		PDUSessionResourceModifyResponseTransfer: transferBytes,
+	})
+	cu.Info("PDU Session ID=%d modified: %d QoS flow(s) added or modified, %d failed",
+		pduSession.PduSessionId, len(flows), len(mod.FailedFlows))
+}
+
+// abortPduSessionModify puts back the session as it was before the request,
+// except for the released flows, and reports it as failed to modify
+func (cu *CuCpContext) abortPduSessionModify(ue *uecontext.GNBUe, pduSession *uecontext.PduSessionContext, cause ies.Cause) {
+	mod := pduSession.Modification
+
+	for _, flow := range slices.Clone(mod.AddedFlows) {
+		pduSession.RemoveQosFlow(flow.Qfi)
+	}
+	for _, flow := range mod.ModifiedFlows {
+		*flow = mod.PreviousQos[flow.Qfi]
+	}
+	var removed []uint8
+	for _, drbId := range mod.NewDrbs {
+		if drb := pduSession.GetDrb(drbId); drb != nil && drb.UlF1uTunnel != nil {
+			removed = append(removed, drbId)
+		}
+		pduSession.RemoveDrb(drbId)
+		ue.ReleaseDrbId(drbId)
+	}
+	if len(removed) > 0 {
+		if err := cu.removeBearerContextDrbs(ue, pduSession.PduSessionId, removed); err != nil {
+			cu.Error("Failed to remove DRBs of PDU Session ID=%d from the bearer context: %v", pduSession.PduSessionId, err)
+		}
+	}
+
+	pduSession.State = uecontext.PDU_SESSION_ACTIVE
+	pduSession.Modification = nil
+	queuePduSessionModifyFailure(ue, pduSession.PduSessionId, cause)
+}
+
+// queuePduSessionModifyFailure adds a session to the FailedToModify list of
+// the next PDU Session Resource Modify Response
+func queuePduSessionModifyFailure(ue *uecontext.GNBUe, pduSessionId uint8, cause ies.Cause) {
+	transfer := ies.PDUSessionResourceModifyUnsuccessfulTransfer{Cause: cause}
+	transferBytes, _ := transfer.Encode()
+	ue.FailedToModifyPduSessions = append(ue.FailedToModifyPduSessions, ies.PDUSessionResourceFailedToModifyItemModRes{
+		PDUSessionID: int64(pduSessionId),
+		PDUSessionResourceModifyUnsuccessfulTransfer: transferBytes,
+	})
+}
+
+// reportPduSessionModify answers the AMF once no session of the UE is being
+// modified anymore
+func (cu *CuCpContext) reportPduSessionModify(ue *uecontext.GNBUe) {
+	if len(ue.ModifiedPduSessions) == 0 && len(ue.FailedToModifyPduSessions) == 0 {
+		return
+	}
+	for _, pduSession := range ue.PduSessions {
+		if pduSession.State == uecontext.PDU_SESSION_MODIFYING {
+			return
+		}
+	}
+	if err := cu.sendPduSessionResourceModifyResponse(ue); err != nil {
+		cu.Error("Failed to send PDU Session Resource Modify Response: %v", err)
+	}
+}
+
+func (cu *CuCpContext) sendPduSessionResourceModifyResponse(ue *uecontext.GNBUe) error {
+	msg := ies.PDUSessionResourceModifyResponse{
+		AMFUENGAPID:                        ue.AmfUeNgapId,
+		RANUENGAPID:                        ue.RanUeNgapId,
+		PDUSessionResourceModifyListModRes: ue.ModifiedPduSessions,
+		PDUSessionResourceFailedToModifyListModRes: ue.FailedToModifyPduSessions,
+	}
+	ue.ModifiedPduSessions = nil
+	ue.FailedToModifyPduSessions = nil
+
+	ngapBytes, err := ngap.NgapEncode(&msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode PDU Session Resource Modify Response: %w", err)
+	}
+
+	amf, err := cu.GetAMFById(ue.AmfId)
+	if err != nil {
+		return fmt.Errorf("AMF not found: %v", err)
+	}
+	if err = amf.SendNgap(ngapBytes); err != nil {
+		return fmt.Errorf("failed to send NGAP message: %w", err)
+	}
+
+	cu.Info("NGAP PDU Session Resource Modify Response sent to AMF")
+	return nil
+}
+
+func radioNetworkCause(value aper.Enumerated) ies.Cause {
+	return ies.Cause{
+		Choice:       ies.CausePresentRadionetwork,
+		RadioNetwork: &ies.CauseRadioNetwork{Value: value},
+	}
+}
@@ -0,0 +1,198 @@
+package context
+
+import (
+	"bytes"
+	"central-unit/internal/context/amfcontext"
+	"central-unit/internal/context/uecontext"
+	f1ext "central-unit/pkg/f1ap/ies"
+	"fmt"
+	"slices"
+
+	f1ies "github.com/JocelynWS/f1-gen/ies"
+	"github.com/lvdund/ngap"
+	"github.com/lvdund/ngap/aper"
+	"github.com/lvdund/ngap/ies"
+	rrcies "github.com/lvdund/rrc/ies"
+)
+
+// handlePduSessionResourceReleaseCommand releases the DRBs of the sessions at
+// the DU and the UE in one F1 UE Context Modification carrying the RRC
+// Reconfiguration. The CU-UP and the AMF are answered once the UE confirms.
+func (cu *CuCpContext) handlePduSessionResourceReleaseCommand(
+	amf *amfcontext.GNBAmf,
+	msg *ies.PDUSessionResourceReleaseCommand,
+) {
+	cu.Info("Processing PDU Session Resource Release Command")
+
+	ue, err := cu.GetUEByNgapId(msg.RANUENGAPID)
+	if err != nil {
+		cu.Error("UE not found for RAN-UE-NGAP-ID %d: %v", msg.RANUENGAPID, err)
+		return
+	}
+
+	// a release cannot fail: the command waits for the resume even when the UE
+	// cannot be paged, until the AMF releases the UE
+	if ue.Suspension != nil {
+		cu.holdForResume(ue, msg)
+		return
+	}
+
+	var releasing []*uecontext.PduSessionContext
+	for _, item := range msg.PDUSessionResourceToReleaseListRelCmd {
+		pduSessionId := uint8(item.PDUSessionID)
+
+		var transfer ies.PDUSessionResourceReleaseCommandTransfer
+		if err := transfer.Decode(item.PDUSessionResourceReleaseCommandTransfer); err != nil {
+			cu.Warn("Invalid PDU Session Resource Release Command Transfer for PDU Session ID %d: %v", pduSessionId, err)
+		} else {
+			cu.Info("Releasing PDU Session ID=%d (cause choice %d)", pduSessionId, transfer.Cause.Choice)
+		}
+
+		pduSession, ok := ue.PduSessions[pduSessionId]
+		if !ok || pduSession.State == uecontext.PDU_SESSION_RELEASING {
+			cu.Warn("PDU Session ID=%d has no resources to release, reporting it as released", pduSessionId)
+			queuePduSessionReleased(ue, pduSessionId)
+			continue
+		}
+		releasing = append(releasing, pduSession)
+	}
+
+	// The DU knows the DRBs it returned a tunnel for, the UE only those of
+	// sessions that completed their setup, without the DRBs a modification
+	// is still adding
+	var duDrbs []f1ies.DRBsToBeReleasedItem
+	var ueDrbs []rrcies.DRB_Identity
+	for _, pduSession := range releasing {
+		for _, drb := range pduSession.Drbs {
+			if drb.DlF1uTunnel != nil {
+				duDrbs = append(duDrbs, f1ies.DRBsToBeReleasedItem{DRBID: int64(drb.DrbId)})
+			}
+			switch pduSession.State {
+			case uecontext.PDU_SESSION_ACTIVE:
+				ueDrbs = append(ueDrbs, rrcies.DRB_Identity{Value: uint64(drb.DrbId)})
+			case uecontext.PDU_SESSION_MODIFYING:
+				if !slices.Contains(pduSession.Modification.NewDrbs, drb.DrbId) {
+					ueDrbs = append(ueDrbs, rrcies.DRB_Identity{Value: uint64(drb.DrbId)})
+				}
+			}
+		}
+		pduSession.State = uecontext.PDU_SESSION_RELEASING
+		pduSession.Modification = nil
+	}
+
+	var rrcBytes []byte
+	if len(ueDrbs) > 0 || len(msg.NASPDU) > 0 {
+		if rrcBytes, err = buildRrcReconfigurationForRelease(ueDrbs, msg.NASPDU); err != nil {
+			cu.Error("Failed to build RRC Reconfiguration: %v", err)
+		}
+	}
+
+	if len(duDrbs) > 0 || rrcBytes != nil {
+		msg := f1ext.UEContextModificationRequest{
+			RRCContainer:         rrcBytes,
+			DRBsToBeReleasedList: duDrbs,
+		}
+		if err = cu.sendF1UEContextModification(ue, &msg); err != nil {
+			cu.Error("Failed to release DRBs at the DU: %v", err)
+			rrcBytes = nil
+		}
+	}
+
+	// Without an RRC Reconfiguration there is no UE answer to wait for
+	if rrcBytes == nil {
+		cu.completePduSessionRelease(ue)
+	}
+}
+
+func buildRrcReconfigurationForRelease(drbs []rrcies.DRB_Identity, nasPdu []byte) ([]byte, error) {
+	ies := &rrcies.RRCReconfiguration_IEs{}
+	if len(drbs) > 0 {
+		ies.RadioBearerConfig = &rrcies.RadioBearerConfig{
+			Drb_ToReleaseList: &rrcies.DRB_ToReleaseList{Value: drbs},
+		}
+	}
+	if len(nasPdu) > 0 {
+		ies.NonCriticalExtension = &rrcies.RRCReconfiguration_v1530_IEs{
+			DedicatedNAS_MessageList: []rrcies.DedicatedNAS_Message{{Value: nasPdu}},
+		}
+	}
+	return encodeRrcReconfiguration(rrcTransactionPduSessionRelease, ies)
+}
+
+// completePduSessionRelease drops the sessions being released, removes them
+// from the bearer context and reports them to the AMF
+func (cu *CuCpContext) completePduSessionRelease(ue *uecontext.GNBUe) {
+	var released []uint8
+	for id, pduSession := range ue.PduSessions {
+		if pduSession.State != uecontext.PDU_SESSION_RELEASING {
+			continue
+		}
+		cu.releaseDrbs(ue, pduSession)
+		delete(ue.PduSessions, id)
+		ue.NumActiveSessions--
+		released = append(released, id)
+		queuePduSessionReleased(ue, id)
+		cu.Info("PDU Session ID=%d released", id)
+	}
+
+	if err := cu.removeBearerContextSessions(ue, released); err != nil {
+		cu.Error("Failed to remove released PDU sessions from the bearer context: %v", err)
+	}
+	if err := cu.sendPduSessionResourceReleaseResponse(ue); err != nil {
+		cu.Error("Failed to send PDU Session Resource Release Response: %v", err)
+	}
+}
+
+// queuePduSessionReleased adds a session to the next PDU Session Resource
+// Release Response
+func queuePduSessionReleased(ue *uecontext.GNBUe, pduSessionId uint8) {
+	transferBytes, _ := encodePduSessionReleaseResponseTransfer()
+	ue.ReleasedPduSessions = append(ue.ReleasedPduSessions, ies.PDUSessionResourceReleasedItemRelRes{
+		PDUSessionID: int64(pduSessionId),
+		PDUSessionResourceReleaseResponseTransfer: transferBytes,
+	})
+}
+
+// encodePduSessionReleaseResponseTransfer encodes the (empty) transfer on its
+// own, ngap only provides the in-message encoder for it
+func encodePduSessionReleaseResponseTransfer() ([]byte, error) {
+	var buf bytes.Buffer
+	w := aper.NewWriter(&buf)
+	transfer := ies.PDUSessionResourceReleaseResponseTransfer{}
+	if err := transfer.Encode(w); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (cu *CuCpContext) sendPduSessionResourceReleaseResponse(ue *uecontext.GNBUe) error {
+	if len(ue.ReleasedPduSessions) == 0 {
+		return nil
+	}
+
+	msg := ies.PDUSessionResourceReleaseResponse{
+		AMFUENGAPID:                          ue.AmfUeNgapId,
+		RANUENGAPID:                          ue.RanUeNgapId,
+		PDUSessionResourceReleasedListRelRes: ue.ReleasedPduSessions,
+	}
+	ue.ReleasedPduSessions = nil
+
+	ngapBytes, err := ngap.NgapEncode(&msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode PDU Session Resource Release Response: %w", err)
+	}
+
+	amf, err := cu.GetAMFById(ue.AmfId)
+	if err != nil {
+		return fmt.Errorf("AMF not found: %v", err)
+	}
+	if err = amf.SendNgap(ngapBytes); err != nil {
+		return fmt.Errorf("failed to send NGAP message: %w", err)
+	}
+
+	cu.Info("NGAP PDU Session Resource Release Response sent to AMF")
+	return nil
+}
@@ -0,0 +1,265 @@
+package context
+
+import (
+	"central-unit/internal/context/amfcontext"
+	"central-unit/internal/context/du"
+	"central-unit/internal/context/uecontext"
+	f1ext "central-unit/pkg/f1ap/ies"
+	"fmt"
+
+	f1ap "github.com/JocelynWS/f1-gen"
+	f1ies "github.com/JocelynWS/f1-gen/ies"
+	"github.com/ishidawataru/sctp"
+	"github.com/lvdund/ngap"
+	"github.com/lvdund/ngap/ies"
+)
+
+// handleF1Reset drops the UEs the DU has reset, has the AMF drop them as well
+// and acknowledges the connections it was given
+func (cu *CuCpContext) handleF1Reset(msg *f1ies.Reset, conn *sctp.SCTPConn) {
+	duCtx, err := cu.GetDUByConn(conn)
+	if err != nil {
+		cu.Error("F1 Reset from an unknown DU: %v", err)
+		return
+	}
+
+	ack := f1ext.ResetAcknowledge{TransactionID: msg.TransactionID}
+	var ues []*uecontext.GNBUe
+	switch msg.ResetType.Choice {
+	case f1ies.ResetTypePresentF1Interface:
+		ues = cu.GetUEsOfDU(duCtx.DuId)
+		cu.Info("DU %d resets the F1 interface, %d UE(s) to drop", duCtx.DuId, len(ues))
+	case f1ies.ResetTypePresentPartOfF1Interface:
+		for _, item := range msg.ResetType.PartOfF1Interface {
+			connection := item.UEAssociatedLogicalF1ConnectionItem
+			if ue := cu.findF1Connection(duCtx.DuId, connection); ue != nil {
+				ues = append(ues, ue)
+			}
+			ack.UEAssociatedLogicalF1ConnectionListResAck = append(ack.UEAssociatedLogicalF1ConnectionListResAck,
+				f1ies.UEAssociatedLogicalF1ConnectionItemResAck{UEAssociatedLogicalF1ConnectionItem: connection})
+		}
+		cu.Info("DU %d resets %d UE-associated connection(s), %d known",
+			duCtx.DuId, len(msg.ResetType.PartOfF1Interface), len(ues))
+	default:
+		cu.Error("F1 Reset from DU %d with unknown reset type %d", duCtx.DuId, msg.ResetType.Choice)
+		return
+	}
+
+	cu.resetUEsAtAmf(ues, ngReleaseCause(msg.Cause))
+
+	f1apBytes, err := f1ap.F1apEncode(&ack)
+	if err == nil {
+		err = duCtx.SendF1ap(f1apBytes)
+	}
+	if err != nil {
+		cu.Error("Failed to send F1 Reset Acknowledge to DU %d: %v", duCtx.DuId, err)
+		return
+	}
+	cu.Info("F1 Reset Acknowledge sent to DU %d", duCtx.DuId)
+}
+
+// findF1Connection prefers the CU side ID of a reset connection, the DU side
+// one is only needed for UEs the CU-CP never answered
+func (cu *CuCpContext) findF1Connection(duId int64, connection f1ies.UEAssociatedLogicalF1ConnectionItem) *uecontext.GNBUe {
+	if connection.GNBCUUEF1APID != nil {
+		if ue, err := cu.GetUEByF1Id(*connection.GNBCUUEF1APID); err == nil && ue.DuId == uint64(duId) {
+			return ue
+		}
+	}
+	if connection.GNBDUUEF1APID != nil {
+		if ue, err := cu.GetUEByDuUeId(duId, *connection.GNBDUUEF1APID); err == nil {
+			return ue
+		}
+	}
+	return nil
+}
+
+// sendF1Reset resets the given UEs at the DU, or the whole F1 interface
+// without UEs
+func (cu *CuCpContext) sendF1Reset(duCtx *du.GNBDU, cause f1ies.Cause, ues []*uecontext.GNBUe) error {
+	msg := f1ext.Reset{
+		TransactionID: duCtx.NextTransactionId(),
+		Cause:         cause,
+	}
+	if len(ues) == 0 {
+		msg.ResetType = f1ies.ResetType{
+			Choice:      f1ies.ResetTypePresentF1Interface,
+			F1Interface: &f1ies.ResetAll{Value: f1ies.ResetAllResetall},
+		}
+	} else {
+		msg.ResetType.Choice = f1ies.ResetTypePresentPartOfF1Interface
+		for _, ue := range ues {
+			cuUeF1apId, duUeF1apId := int64(ue.GnbCuUeF1apId), int64(ue.DuUeId)
+			msg.ResetType.PartOfF1Interface = append(msg.ResetType.PartOfF1Interface, f1ies.UEAssociatedLogicalF1ConnectionItemRes{
+				UEAssociatedLogicalF1ConnectionItem: f1ies.UEAssociatedLogicalF1ConnectionItem{
+					GNBCUUEF1APID: &cuUeF1apId,
+					GNBDUUEF1APID: &duUeF1apId,
+				},
+			})
+		}
+	}
+
+	f1apBytes, err := f1ap.F1apEncode(&msg)
+	if err != nil {
+		return fmt.Errorf("encode F1 Reset: %w", err)
+	}
+	if err = duCtx.SendF1ap(f1apBytes); err != nil {
+		return err
+	}
+	cu.Info("F1 Reset sent to DU %d for %d UE(s)", duCtx.DuId, len(ues))
+	return nil
+}
+
+// handleNgReset drops the UEs the AMF has reset, has their DUs drop them as
+// well and acknowledges the connections it was given
+func (cu *CuCpContext) handleNgReset(amf *amfcontext.GNBAmf, msg *ies.NGReset) {
+	ack := ies.NGResetAcknowledge{}
+	var ues []*uecontext.GNBUe
+	switch msg.ResetType.Choice {
+	case ies.ResetTypePresentNgInterface:
+		ues = cu.GetUEsOfAMF(amf.AmfId)
+		cu.Info("AMF %s resets the NG interface, %d UE(s) to drop", amf.Name, len(ues))
+	case ies.ResetTypePresentPartofngInterface:
+		for _, item := range msg.ResetType.PartOfNGInterface {
+			if ue := cu.findNgConnection(amf.AmfId, item); ue != nil {
+				ues = append(ues, ue)
+			}
+		}
+		ack.UEassociatedLogicalNGconnectionList = msg.ResetType.PartOfNGInterface
+		cu.Info("AMF %s resets %d UE-associated connection(s), %d known",
+			amf.Name, len(msg.ResetType.PartOfNGInterface), len(ues))
+	default:
+		cu.Error("NG Reset from AMF %s with unknown reset type %d", amf.Name, msg.ResetType.Choice)
+		return
+	}
+
+	cu.resetUEsAtDu(ues, f1MiscCause(f1ies.CauseMiscUnspecified))
+
+	ngapBytes, err := ngap.NgapEncode(&ack)
+	if err == nil {
+		err = amf.SendNgap(ngapBytes)
+	}
+	if err != nil {
+		cu.Error("Failed to send NG Reset Acknowledge to AMF %s: %v", amf.Name, err)
+		return
+	}
+	cu.Info("NG Reset Acknowledge sent to AMF %s", amf.Name)
+}
+
+func (cu *CuCpContext) findNgConnection(amfId int64, connection ies.UEassociatedLogicalNGconnectionItem) *uecontext.GNBUe {
+	if connection.RANUENGAPID != nil {
+		if ue, err := cu.GetUEByNgapId(*connection.RANUENGAPID); err == nil && ue.AmfId == amfId {
+			return ue
+		}
+	}
+	if connection.AMFUENGAPID != nil {
+		if ue, err := cu.GetUEByAmfNgapId(amfId, *connection.AMFUENGAPID); err == nil {
+			return ue
+		}
+	}
+	return nil
+}
+
+// sendNgReset resets the given UEs at the AMF, or the whole NG interface
+// without UEs. The AMF-UE-NGAP-ID is only known once the AMF has answered.
+func (cu *CuCpContext) sendNgReset(amf *amfcontext.GNBAmf, cause ies.Cause, ues []*uecontext.GNBUe) error {
+	msg := ies.NGReset{Cause: cause}
+	if len(ues) == 0 {
+		msg.ResetType = ies.ResetType{
+			Choice:      ies.ResetTypePresentNgInterface,
+			NGInterface: &ies.ResetAll{Value: ies.ResetAllResetall},
+		}
+	} else {
+		msg.ResetType.Choice = ies.ResetTypePresentPartofngInterface
+		for _, ue := range ues {
+			item := ies.UEassociatedLogicalNGconnectionItem{RANUENGAPID: &ue.RanUeNgapId}
+			if ue.State != uecontext.UE_INITIALIZED {
+				item.AMFUENGAPID = &ue.AmfUeNgapId
+			}
+			msg.ResetType.PartOfNGInterface = append(msg.ResetType.PartOfNGInterface, item)
+		}
+	}
+
+	ngapBytes, err := ngap.NgapEncode(&msg)
+	if err != nil {
+		return fmt.Errorf("encode NG Reset: %w", err)
+	}
+	if err = amf.SendNgap(ngapBytes); err != nil {
+		return err
+	}
+	cu.Info("NG Reset sent to AMF %s for %d UE(s)", amf.Name, len(ues))
+	return nil
+}
+
+// resetUEsAtAmf drops UEs the DU side has lost, with a partial NG Reset to
+// each AMF still serving some of them
+func (cu *CuCpContext) resetUEsAtAmf(ues []*uecontext.GNBUe, cause ies.Cause) {
+	byAmf := make(map[int64][]*uecontext.GNBUe)
+	for _, ue := range ues {
+		byAmf[ue.AmfId] = append(byAmf[ue.AmfId], ue)
+	}
+	for amfId, amfUes := range byAmf {
+		amf, err := cu.GetAMFById(amfId)
+		if err != nil || !amf.IsSetUp() {
+			continue
+		}
+		if err := cu.sendNgReset(amf, cause, amfUes); err != nil {
+			cu.Error("Failed to send NG Reset to AMF %s: %v", amf.Name, err)
+		}
+	}
+	for _, ue := range ues {
+		cu.dropUEContext(ue)
+	}
+}
+
+// resetUEsAtDu drops UEs the AMF side has lost, with a partial F1 Reset to
+// each DU still connected
+func (cu *CuCpContext) resetUEsAtDu(ues []*uecontext.GNBUe, cause f1ies.Cause) {
+	byDu := make(map[int64][]*uecontext.GNBUe)
+	for _, ue := range ues {
+		// no DU holds a UE in RRC_INACTIVE
+		if ue.State != uecontext.UE_INACTIVE {
+			byDu[int64(ue.DuId)] = append(byDu[int64(ue.DuId)], ue)
+		}
+	}
+	for duId, duUes := range byDu {
+		duCtx, err := cu.GetDUById(duId)
+		if err != nil || !cu.isDUConnected(duCtx) {
+			continue
+		}
+		if err := cu.sendF1Reset(duCtx, cause, duUes); err != nil {
+			cu.Error("Failed to send F1 Reset to DU %d: %v", duId, err)
+		}
+	}
+	for _, ue := range ues {
+		cu.dropUEContext(ue)
+	}
+}
+
+// dropUEContext releases a UE without signalling towards the DU or the AMF,
+// one of them has lost it already. The bearer context at the CU-UP is still
+// released.
+func (cu *CuCpContext) dropUEContext(ue *uecontext.GNBUe) {
+	ue.State = uecontext.UE_DOWN
+	ue.NgReleasing = false
+	if ics := ue.InitialContextSetup; ics != nil {
+		ics.Timer.Stop()
+		ue.InitialContextSetup = nil
+	}
+	cu.completeUEContextRelease(ue)
+}
+
+// handleAmfAssociationLost resets at the DUs the UEs of an AMF whose NG
+// association went down
+func (cu *CuCpContext) handleAmfAssociationLost(amf *amfcontext.GNBAmf) {
+	if cu.Ctx.Err() != nil {
+		return
+	}
+	amf.State = amfcontext.AMF_INACTIVE
+	if amf.NgSetupTimer != nil {
+		amf.NgSetupTimer.Stop()
+	}
+	ues := cu.GetUEsOfAMF(amf.AmfId)
+	cu.Warn("NG association with AMF %s lost, resetting %d UE(s)", amf.Name, len(ues))
+	cu.resetUEsAtDu(ues, f1MiscCause(f1ies.CauseMiscUnspecified))
+}
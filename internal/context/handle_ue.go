@@ -2,25 +2,35 @@ package context
 
 import (
 	"central-unit/internal/context/uecontext"
+	"central-unit/pkg/pdcp"
+	"fmt"
 
 	"github.com/lvdund/asn1go/aper"
+	rrcies "github.com/lvdund/rrc/ies"
 )
 
+// createUE admits a UE asking for an RRC connection, the error telling why
+// it is turned down
 func (cu *CuCpContext) createUE(
 	duid int64,
 	crnti int64,
 	ueIdentity aper.BitString,
 	duUeId int64,
-) *uecontext.GNBUe {
+	cause rrcies.EstablishmentCause,
+) (*uecontext.GNBUe, error) {
+	if err := cu.admitUE(duid); err != nil {
+		return nil, err
+	}
+	// the 5G-S-TMSI, if any, is only complete in the RRCSetupComplete
+	amf, err := cu.SelectAMF(nil, cause)
+	if err != nil {
+		return nil, err
+	}
+
 	rrcUeId := cu.getNextRrcUeId()
 	ranUeNgapId := cu.getNextRanUeNgapId()
 	gnbCuUeF1apId := cu.getNextGnbCuUeF1apId()
-
-	amf, err := cu.GetPrimaryAMF()
-	if err != nil {
-		cu.Error("No AMF available for UE creation: %v", err)
-		return nil
-	}
+	gnbCuCpUeE1apId := cu.getNextGnbCuCpUeE1apId()
 
 	ue := &uecontext.GNBUe{
 		DuId:               uint64(duid),
@@ -31,16 +41,39 @@ func (cu *CuCpContext) createUE(
 		RrcUeId:            uint64(rrcUeId),
 		DuUeId:             uint64(duUeId),
 		GnbCuUeF1apId:      uint64(gnbCuUeF1apId),
+		GnbCuCpUeE1apId:    uint64(gnbCuCpUeE1apId),
 		AmfId:              amf.AmfId,
 		State:              uecontext.UE_INITIALIZED,
+		// SRB1 comes with the RRCSetup, without security until the SMC
+		SrbPdcp: [3]*pdcp.SrbEntity{1: pdcp.NewSrbEntity(1)},
 	}
 
 	cu.RrcUePool.Store(rrcUeId, ue)
 	cu.NgapUePool.Store(ranUeNgapId, ue)
 	cu.F1UePool.Store(gnbCuUeF1apId, ue)
+	cu.E1UePool.Store(gnbCuCpUeE1apId, ue)
 
 	cu.Info("Created UE: RrcId=%d, RanNgapId=%d, CuF1apId=%d, DuId=%d",
 		rrcUeId, ranUeNgapId, gnbCuUeF1apId, duid)
 
-	return ue
+	return ue, nil
+}
+
+// admitUE checks the UE admission limits, a UE in RRC_INACTIVE counting
+// against the global one only
+func (cu *CuCpContext) admitUE(duId int64) error {
+	if cu.maxUEs > 0 {
+		count := 0
+		cu.NgapUePool.Range(func(_, _ any) bool {
+			count++
+			return true
+		})
+		if count >= cu.maxUEs {
+			return fmt.Errorf("UE admission limit of %d reached", cu.maxUEs)
+		}
+	}
+	if cu.maxUEsPerDu > 0 && len(cu.GetUEsOfDU(duId)) >= cu.maxUEsPerDu {
+		return fmt.Errorf("UE admission limit of %d reached at DU %d", cu.maxUEsPerDu, duId)
+	}
+	return nil
 }
@@ -0,0 +1,362 @@
+package context
+
+import (
+	"central-unit/internal/context/amfcontext"
+	"central-unit/internal/context/uecontext"
+	e1ies "central-unit/pkg/e1ap/ies"
+	f1ext "central-unit/pkg/f1ap/ies"
+	"fmt"
+
+	f1ap "github.com/JocelynWS/f1-gen"
+	f1ies "github.com/JocelynWS/f1-gen/ies"
+	"github.com/lvdund/ngap"
+	"github.com/lvdund/ngap/ies"
+	"github.com/lvdund/rrc"
+	rrcies "github.com/lvdund/rrc/ies"
+)
+
+// handleUEContextReleaseCommand releases the UE at the DU with an RRCRelease.
+// The AMF gets its UE Context Release Complete once the DU confirms.
+func (cu *CuCpContext) handleUEContextReleaseCommand(amf *amfcontext.GNBAmf, msg *ies.UEContextReleaseCommand) {
+	var ue *uecontext.GNBUe
+	var err error
+	switch msg.UENGAPIDs.Choice {
+	case ies.UENGAPIDsPresentUeNgapIdPair:
+		ue, err = cu.GetUEByNgapId(msg.UENGAPIDs.UENGAPIDpair.RANUENGAPID)
+	case ies.UENGAPIDsPresentAmfUeNgapId:
+		ue, err = cu.GetUEByAmfNgapId(amf.AmfId, *msg.UENGAPIDs.AMFUENGAPID)
+	default:
+		err = fmt.Errorf("unsupported UE NGAP IDs choice %d", msg.UENGAPIDs.Choice)
+	}
+	if err != nil {
+		// The AMF drops its side regardless, confirm so it does not retry
+		cu.Warn("UE Context Release Command for unknown UE: %v", err)
+		if msg.UENGAPIDs.Choice == ies.UENGAPIDsPresentUeNgapIdPair {
+			pair := msg.UENGAPIDs.UENGAPIDpair
+			if err = cu.sendUEContextReleaseComplete(amf, pair.AMFUENGAPID, pair.RANUENGAPID, nil); err != nil {
+				cu.Error("Failed to send UE Context Release Complete: %v", err)
+			}
+		}
+		return
+	}
+
+	cu.Info("Releasing UE RAN-UE-NGAP-ID=%d (cause choice %d)", ue.RanUeNgapId, msg.Cause.Choice)
+	ue.NgReleasing = true
+	if ue.State == uecontext.UE_DOWN {
+		// Already releasing at the DU on its own request, the answer to that
+		// command completes this one
+		return
+	}
+	cu.releaseUEContext(ue, true)
+}
+
+// handleF1UEContextReleaseRequest asks the AMF to release a UE the DU lost
+// or found inactive, unless the UE can be suspended to RRC_INACTIVE. A UE the
+// AMF does not know yet is released right away.
+func (cu *CuCpContext) handleF1UEContextReleaseRequest(msg *f1ies.UEContextReleaseRequest) {
+	ue, err := cu.GetUEByF1Id(msg.GNBCUUEF1APID)
+	if err != nil {
+		cu.Error("UE Context Release Request for unknown UE: %v", err)
+		return
+	}
+	if msg.GNBCUUEF1APID != int64(ue.GnbCuUeF1apId) {
+		// the UE moved on to another DU, its release there is already underway
+		cu.Info("DU requests the release of the UE context UE RAN-UE-NGAP-ID=%d left behind", ue.RanUeNgapId)
+		return
+	}
+	if uint64(msg.GNBDUUEF1APID) != ue.DuUeId {
+		cu.Error("UE Context Release Request: DU UE ID mismatch. Expected %d, got %d", ue.DuUeId, msg.GNBDUUEF1APID)
+		return
+	}
+	if ue.State == uecontext.UE_DOWN {
+		cu.Info("UE CU-UE-F1AP-ID=%d is already being released", ue.GnbCuUeF1apId)
+		return
+	}
+
+	cu.Info("DU %d requests the release of UE CU-UE-F1AP-ID=%d (cause choice %d)", ue.DuId, ue.GnbCuUeF1apId, msg.Cause.Choice)
+	if cu.canSuspend(ue, msg.Cause) {
+		err = cu.suspendUE(ue)
+		if err == nil {
+			return
+		}
+		cu.Error("Failed to suspend UE RAN-UE-NGAP-ID=%d, releasing it: %v", ue.RanUeNgapId, err)
+	}
+	if ue.State == uecontext.UE_INITIALIZED {
+		cu.releaseUEContext(ue, false)
+		return
+	}
+	if err := cu.sendUEContextReleaseRequest(ue, ngReleaseCause(msg.Cause)); err != nil {
+		cu.Error("Failed to send UE Context Release Request: %v", err)
+		cu.releaseUEContext(ue, true)
+	}
+}
+
+// ngReleaseCause translates the reason the DU gives for a release into the
+// one reported to the AMF
+func ngReleaseCause(cause f1ies.Cause) ies.Cause {
+	if cause.Choice == f1ies.CausePresentRadioNetwork && cause.RadioNetwork != nil {
+		switch cause.RadioNetwork.Value {
+		case f1ies.CauseRadioNetworkRlfailurerlc, f1ies.CauseRadioNetworkRlfailureothers:
+			return radioNetworkCause(ies.CauseRadioNetworkRadioconnectionwithuelost)
+		case f1ies.CauseRadioNetworkNormalrelease:
+			return radioNetworkCause(ies.CauseRadioNetworkUserinactivity)
+		}
+	}
+	return radioNetworkCause(ies.CauseRadioNetworkReleaseduetongrangeneratedreason)
+}
+
+// releaseUEContext sends the F1 UE Context Release Command, with an RRCRelease
+// for a UE that has an RRC connection to end. Without a DU to answer, the
+// release completes at once, as it does for a UE in RRC_INACTIVE, which no DU
+// holds.
+func (cu *CuCpContext) releaseUEContext(ue *uecontext.GNBUe, rrcRelease bool) {
+	if ue.State == uecontext.UE_INACTIVE {
+		ue.State = uecontext.UE_DOWN
+		cu.completeUEContextRelease(ue)
+		return
+	}
+	ue.State = uecontext.UE_DOWN
+	cu.abortHandover(ue)
+	if ue.SourceDu != nil {
+		cu.releaseSourceDu(ue)
+	}
+	if ics := ue.InitialContextSetup; ics != nil {
+		ics.Timer.Stop()
+		ue.InitialContextSetup = nil
+	}
+
+	msg := f1ext.UEContextReleaseCommand{
+		GNBCUUEF1APID: int64(ue.GnbCuUeF1apId),
+		GNBDUUEF1APID: int64(ue.DuUeId),
+		Cause: f1ies.Cause{
+			Choice:       f1ies.CausePresentRadioNetwork,
+			RadioNetwork: &f1ies.CauseRadioNetwork{Value: f1ies.CauseRadioNetworkNormalrelease},
+		},
+	}
+	if rrcRelease {
+		rrcBytes, err := encodeRrcRelease(nil)
+		if err == nil {
+			rrcBytes, err = protectSrb(ue, 1, rrcBytes)
+		}
+		if err != nil {
+			cu.Error("Failed to build RRC Release: %v", err)
+		} else {
+			srbId := int64(1)
+			msg.RRCContainer = rrcBytes
+			msg.SRBID = &srbId
+		}
+	}
+
+	if err := cu.sendF1UEContextReleaseCommand(ue, &msg); err != nil {
+		cu.Error("Failed to release UE at the DU: %v", err)
+		cu.completeUEContextRelease(ue)
+	}
+}
+
+// releaseSourceDu releases the UE context the UE left at its previous DU,
+// without an RRCRelease since the UE is now served elsewhere
+func (cu *CuCpContext) releaseSourceDu(ue *uecontext.GNBUe) {
+	leg := ue.SourceDu
+	if leg == nil {
+		return
+	}
+	if err := cu.releaseDuLeg(leg); err != nil {
+		cu.Warn("Failed to release UE RAN-UE-NGAP-ID=%d at its previous DU %d: %v", ue.RanUeNgapId, leg.DuId, err)
+		ue.SourceDu = nil
+		return
+	}
+	cu.Info("F1AP UE Context Release Command sent to the previous DU %d of UE RAN-UE-NGAP-ID=%d", leg.DuId, ue.RanUeNgapId)
+}
+
+// releaseDuLeg releases a UE context the UE does not use at a DU. The context
+// is forgotten at once if the DU cannot be reached, otherwise when the DU
+// confirms.
+func (cu *CuCpContext) releaseDuLeg(leg *uecontext.DuLeg) error {
+	msg := f1ext.UEContextReleaseCommand{
+		GNBCUUEF1APID: int64(leg.GnbCuUeF1apId),
+		GNBDUUEF1APID: int64(leg.DuUeId),
+		Cause: f1ies.Cause{
+			Choice:       f1ies.CausePresentRadioNetwork,
+			RadioNetwork: &f1ies.CauseRadioNetwork{Value: f1ies.CauseRadioNetworkNormalrelease},
+		},
+	}
+	if err := cu.sendF1UEContextReleaseCommandToDu(leg.DuId, &msg); err != nil {
+		cu.F1UePool.Delete(int64(leg.GnbCuUeF1apId))
+		return err
+	}
+	return nil
+}
+
+// encodeRrcRelease builds the RRCRelease of a UE, sent to RRC_INACTIVE when
+// it is given a suspendConfig
+func encodeRrcRelease(suspend *rrcies.SuspendConfig) ([]byte, error) {
+	dlDcchMsg := rrcies.DL_DCCH_Message{
+		Message: rrcies.DL_DCCH_MessageType{
+			Choice: rrcies.DL_DCCH_MessageType_Choice_C1,
+			C1: &rrcies.DL_DCCH_MessageType_C1{
+				Choice: rrcies.DL_DCCH_MessageType_C1_Choice_RrcRelease,
+				RrcRelease: &rrcies.RRCRelease{
+					Rrc_TransactionIdentifier: rrcies.RRC_TransactionIdentifier{Value: 0},
+					CriticalExtensions: rrcies.RRCRelease_CriticalExtensions{
+						Choice:     rrcies.RRCRelease_CriticalExtensions_Choice_RrcRelease,
+						RrcRelease: &rrcies.RRCRelease_IEs{SuspendConfig: suspend},
+					},
+				},
+			},
+		},
+	}
+
+	rrcBytes, err := rrc.Encode(&dlDcchMsg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode RRC Release: %w", err)
+	}
+	return rrcBytes, nil
+}
+
+func (cu *CuCpContext) sendF1UEContextReleaseCommand(ue *uecontext.GNBUe, msg *f1ext.UEContextReleaseCommand) error {
+	return cu.sendF1UEContextReleaseCommandToDu(ue.DuId, msg)
+}
+
+func (cu *CuCpContext) sendF1UEContextReleaseCommandToDu(duId uint64, msg *f1ext.UEContextReleaseCommand) error {
+	duCtx, err := cu.GetDUById(int64(duId))
+	if err != nil {
+		return fmt.Errorf("DU not found for UE: %v", err)
+	}
+	if !duCtx.IsActive() {
+		return fmt.Errorf("DU %d is lost", duCtx.DuId)
+	}
+
+	f1apBytes, err := f1ap.F1apEncode(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode F1AP UE Context Release Command: %w", err)
+	}
+	if err = duCtx.SendF1ap(f1apBytes); err != nil {
+		return fmt.Errorf("failed to send F1AP message to DU: %w", err)
+	}
+
+	cu.Info("F1AP UE Context Release Command sent to DU %d", duCtx.DuId)
+	return nil
+}
+
+func (cu *CuCpContext) handleF1UEContextReleaseComplete(msg *f1ies.UEContextReleaseComplete) {
+	if msg.GNBCUUEF1APID == rejectedUeF1apId {
+		cu.Info("UE context DU-UE-F1AP-ID=%d of a rejected UE released", msg.GNBDUUEF1APID)
+		return
+	}
+	ue, err := cu.GetUEByF1Id(msg.GNBCUUEF1APID)
+	if err != nil {
+		cu.Error("UE Context Release Complete for unknown UE: %v", err)
+		return
+	}
+	if ue.State == uecontext.UE_INACTIVE {
+		// the UE context stays at the CU-CP while the UE is suspended
+		cu.F1UePool.Delete(msg.GNBCUUEF1APID)
+		cu.Info("UE CU-UE-F1AP-ID=%d suspended, released at DU %d", ue.GnbCuUeF1apId, ue.DuId)
+		return
+	}
+	if msg.GNBCUUEF1APID != int64(ue.GnbCuUeF1apId) {
+		cu.F1UePool.Delete(msg.GNBCUUEF1APID)
+		if ue.SourceDu != nil && int64(ue.SourceDu.GnbCuUeF1apId) == msg.GNBCUUEF1APID {
+			ue.SourceDu = nil
+		}
+		cu.Info("UE context CU-UE-F1AP-ID=%d left behind by UE RAN-UE-NGAP-ID=%d released", msg.GNBCUUEF1APID, ue.RanUeNgapId)
+		return
+	}
+	if ue.State != uecontext.UE_DOWN {
+		cu.Warn("Unexpected UE Context Release Complete for UE CU-UE-F1AP-ID=%d", ue.GnbCuUeF1apId)
+		return
+	}
+
+	cu.Info("UE CU-UE-F1AP-ID=%d released at DU %d", ue.GnbCuUeF1apId, ue.DuId)
+	cu.completeUEContextRelease(ue)
+}
+
+// completeUEContextRelease answers the AMF and drops the UE. A UE with a
+// bearer context stays reachable from the E1 side until the CU-UP confirms
+// its release, or answers the Bearer Context Setup still in flight.
+func (cu *CuCpContext) completeUEContextRelease(ue *uecontext.GNBUe) {
+	var sessions []ies.PDUSessionResourceItemCxtRelCpl
+	for id, pduSession := range ue.PduSessions {
+		sessions = append(sessions, ies.PDUSessionResourceItemCxtRelCpl{PDUSessionID: int64(id)})
+		cu.releaseDrbs(ue, pduSession)
+	}
+	ue.PduSessions = nil
+	ue.NumActiveSessions = 0
+
+	if ue.NgReleasing {
+		amf, err := cu.GetAMFById(ue.AmfId)
+		if err == nil {
+			err = cu.sendUEContextReleaseComplete(amf, ue.AmfUeNgapId, ue.RanUeNgapId, sessions)
+		}
+		if err != nil {
+			cu.Error("Failed to send UE Context Release Complete: %v", err)
+		}
+	}
+
+	if ue.BearerSetupSent {
+		return
+	}
+	if ue.HasBearerContext {
+		cause := e1ies.Cause{
+			Choice:       e1ies.CausePresentRadioNetwork,
+			RadioNetwork: &e1ies.CauseRadioNetwork{Value: e1ies.CauseRadioNetworkNormalrelease},
+		}
+		err := cu.sendBearerContextReleaseCommand(ue, cause)
+		if err == nil {
+			return
+		}
+		cu.Error("Failed to release the bearer context: %v", err)
+	}
+	cu.RemoveUE(ue)
+}
+
+func (cu *CuCpContext) sendUEContextReleaseRequest(ue *uecontext.GNBUe, cause ies.Cause) error {
+	msg := ies.UEContextReleaseRequest{
+		AMFUENGAPID: ue.AmfUeNgapId,
+		RANUENGAPID: ue.RanUeNgapId,
+		Cause:       cause,
+	}
+	for id := range ue.PduSessions {
+		msg.PDUSessionResourceListCxtRelReq = append(msg.PDUSessionResourceListCxtRelReq,
+			ies.PDUSessionResourceItemCxtRelReq{PDUSessionID: int64(id)})
+	}
+
+	ngapBytes, err := ngap.NgapEncode(&msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode UE Context Release Request: %w", err)
+	}
+
+	amf, err := cu.GetAMFById(ue.AmfId)
+	if err != nil {
+		return fmt.Errorf("AMF not found: %v", err)
+	}
+	if err = amf.SendNgap(ngapBytes); err != nil {
+		return fmt.Errorf("failed to send NGAP message: %w", err)
+	}
+
+	cu.Info("NGAP UE Context Release Request sent to AMF")
+	return nil
+}
+
+func (cu *CuCpContext) sendUEContextReleaseComplete(
+	amf *amfcontext.GNBAmf,
+	amfUeNgapId, ranUeNgapId int64,
+	sessions []ies.PDUSessionResourceItemCxtRelCpl,
+) error {
+	msg := ies.UEContextReleaseComplete{
+		AMFUENGAPID:                     amfUeNgapId,
+		RANUENGAPID:                     ranUeNgapId,
+		PDUSessionResourceListCxtRelCpl: sessions,
+	}
+
+	ngapBytes, err := ngap.NgapEncode(&msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode UE Context Release Complete: %w", err)
+	}
+	if err = amf.SendNgap(ngapBytes); err != nil {
+		return fmt.Errorf("failed to send NGAP message: %w", err)
+	}
+
+	cu.Info("NGAP UE Context Release Complete sent to AMF")
+	return nil
+}
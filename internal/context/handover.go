@@ -0,0 +1,448 @@
+package context
+
+import (
+	"central-unit/internal/context/du"
+	"central-unit/internal/context/uecontext"
+	f1ext "central-unit/pkg/f1ap/ies"
+	"central-unit/pkg/pdcp"
+	rrcext "central-unit/pkg/rrc/ies"
+	"fmt"
+	"time"
+
+	f1ap "github.com/JocelynWS/f1-gen"
+	f1ies "github.com/JocelynWS/f1-gen/ies"
+	"github.com/lvdund/ngap/ies"
+	"github.com/lvdund/rrc"
+	rrcies "github.com/lvdund/rrc/ies"
+)
+
+// An intra-CU handover moves a UE to a cell of another DU (TS 38.401 8.2.1.1).
+// The target DU sets up a UE context from the HandoverPreparationInformation
+// and answers with a CellGroupConfig holding reconfigurationWithSync, which
+// the source DU delivers in an RRCReconfiguration under the old keys. From
+// then on the UE belongs to the target DU. Its context at the source DU is
+// released once it completes the reconfiguration in the target cell. Should
+// the source DU fail to deliver the RRCReconfiguration, the UE never left and
+// gets its source context back.
+
+// evaluateMobility hands a UE over to the strongest neighbour of an A3 or A5
+// report when that cell is served by another DU. A2 reports are only kept.
+func (cu *CuCpContext) evaluateMobility(ue *uecontext.GNBUe, report *uecontext.MeasReport) {
+	if report.Event != uecontext.MEAS_EVENT_A3 && report.Event != uecontext.MEAS_EVENT_A5 {
+		return
+	}
+	best := report.BestNeighbour()
+	if best == nil {
+		return
+	}
+	targetDu, cell := cu.findCellByPci(best.Pci, report.SsbArfcn)
+	if cell == nil {
+		cu.Info("PCI %d on SSB ARFCN %d is not a cell of the CU-CP, no handover for UE RAN-UE-NGAP-ID=%d",
+			best.Pci, report.SsbArfcn, ue.RanUeNgapId)
+		return
+	}
+	if uint64(targetDu.DuId) == ue.DuId {
+		cu.Warn("Handover of UE RAN-UE-NGAP-ID=%d within DU %d is not supported", ue.RanUeNgapId, ue.DuId)
+		return
+	}
+	if reason := cu.mobilityBlocker(ue); reason != "" {
+		cu.Warn("UE RAN-UE-NGAP-ID=%d cannot be handed over (%s)", ue.RanUeNgapId, reason)
+		return
+	}
+	if err := cu.startHandover(ue, targetDu, cell); err != nil {
+		cu.Error("Failed to start the handover of UE RAN-UE-NGAP-ID=%d: %v", ue.RanUeNgapId, err)
+	}
+}
+
+// findCellByPci looks for an active cell with a PCI on an SSB frequency at
+// the connected DUs. The PCIs of the cells of the CU-CP are expected to be
+// unique per frequency, the first match is taken.
+func (cu *CuCpContext) findCellByPci(pci uint16, ssbArfcn uint64) (*du.GNBDU, *du.ServedCell) {
+	return cu.findCell(func(cell *du.ServedCell) bool {
+		if cell.PCI != pci {
+			return false
+		}
+		freq, err := cellSsbFrequency(cell)
+		return err == nil && freq.Arfcn == ssbArfcn
+	})
+}
+
+// findCell looks for an active cell at the connected DUs
+func (cu *CuCpContext) findCell(match func(cell *du.ServedCell) bool) (*du.GNBDU, *du.ServedCell) {
+	var foundDu *du.GNBDU
+	var foundCell *du.ServedCell
+	cu.DuPool.Range(func(_, value any) bool {
+		duCtx, ok := value.(*du.GNBDU)
+		if !ok || !cu.isDUConnected(duCtx) {
+			return true
+		}
+		for i := range duCtx.ServedCells {
+			cell := &duCtx.ServedCells[i]
+			if cell.Active && !cell.OutOfService && match(cell) {
+				foundDu, foundCell = duCtx, cell
+				return false
+			}
+		}
+		return true
+	})
+	return foundDu, foundCell
+}
+
+// startHandover asks the DU of the target cell for a UE context with SRB1,
+// SRB2 and the DRBs of the UE, under a new gNB-CU UE F1AP ID
+func (cu *CuCpContext) startHandover(ue *uecontext.GNBUe, targetDu *du.GNBDU, cell *du.ServedCell) error {
+	hoPrep, err := encodeHandoverPreparation(ue)
+	if err != nil {
+		return err
+	}
+
+	ho := &uecontext.Handover{
+		Target: uecontext.DuLeg{
+			DuId:          uint64(targetDu.DuId),
+			GnbCuUeF1apId: uint64(cu.getNextGnbCuUeF1apId()),
+		},
+		TargetCellId: cell.NRCGI.NRCellIdentity,
+	}
+	msg := f1ext.UEContextSetupRequest{
+		GNBCUUEF1APID: int64(ho.Target.GnbCuUeF1apId),
+		SpCellID:      cell.NRCGI,
+		CUtoDURRCInformation: f1ext.CUtoDURRCInformation{
+			HandoverPreparationInformation: hoPrep,
+		},
+		SRBsToBeSetupList: []f1ies.SRBsToBeSetupItem{{SRBID: 1}, {SRBID: 2}},
+	}
+	// the target DU sees the measurements of the UE to schedule their gaps
+	ho.MeasConfig, ho.Measurements = cu.measConfigFor(ue, cell)
+	if ho.MeasConfig != nil {
+		measConfigBytes, err := rrcext.Encode(&rrcext.MeasConfig{MeasConfig: ho.MeasConfig})
+		if err != nil {
+			return fmt.Errorf("failed to encode MeasConfig: %w", err)
+		}
+		msg.CUtoDURRCInformation.MeasConfig = measConfigBytes
+	}
+	for _, pduSession := range ue.PduSessions {
+		for _, drb := range pduSession.Drbs {
+			item, err := buildF1DrbToSetup(pduSession, drb)
+			if err != nil {
+				return fmt.Errorf("failed to build DRB ID=%d: %w", drb.DrbId, err)
+			}
+			msg.DRBsToBeSetupList = append(msg.DRBsToBeSetupList, item)
+		}
+	}
+	f1apBytes, err := f1ap.F1apEncode(&msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode UE Context Setup Request: %w", err)
+	}
+
+	cu.F1UePool.Store(int64(ho.Target.GnbCuUeF1apId), ue)
+	ue.Handover = ho
+	ho.Timer = time.AfterFunc(cu.handoverTimeout, func() {
+		if ue.Handover != ho {
+			return
+		}
+		cu.Error("Handover of UE RAN-UE-NGAP-ID=%d to DU %d timed out", ue.RanUeNgapId, ho.Target.DuId)
+		cu.failHandover(ue)
+	})
+	if err = targetDu.SendF1ap(f1apBytes); err != nil {
+		cu.abortHandover(ue)
+		return fmt.Errorf("failed to send UE Context Setup Request: %w", err)
+	}
+
+	cu.Info("Handover of UE RAN-UE-NGAP-ID=%d from DU %d to cell %x of DU %d started",
+		ue.RanUeNgapId, ue.DuId, cell.NRCGI.NRCellIdentity.Bytes, targetDu.DuId)
+	return nil
+}
+
+// encodeHandoverPreparation describes the configuration of the UE in its
+// current cell to the target DU (TS 38.331 11.2.2). The CU-CP keeps no UE
+// radio capabilities, so their list is empty.
+func encodeHandoverPreparation(ue *uecontext.GNBUe) ([]byte, error) {
+	radioBearerConfig := &rrcies.RadioBearerConfig{
+		Srb_ToAddModList: &rrcies.SRB_ToAddModList{
+			Value: []rrcies.SRB_ToAddMod{
+				{Srb_Identity: rrcies.SRB_Identity{Value: 1}},
+				{Srb_Identity: rrcies.SRB_Identity{Value: 2}},
+			},
+		},
+	}
+	var drbToAddModList []rrcies.DRB_ToAddMod
+	for _, pduSession := range ue.PduSessions {
+		for _, drb := range pduSession.Drbs {
+			drbToAddModList = append(drbToAddModList, buildRrcDrbToAddMod(pduSession, drb))
+		}
+	}
+	if len(drbToAddModList) > 0 {
+		radioBearerConfig.Drb_ToAddModList = &rrcies.DRB_ToAddModList{Value: drbToAddModList}
+	}
+
+	sourceConfig := &rrcies.RRCReconfiguration_IEs{RadioBearerConfig: radioBearerConfig}
+	if ue.MasterCellGroup != nil {
+		masterCellGroupBytes, err := rrc.Encode(ue.MasterCellGroup)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode MasterCellGroup: %w", err)
+		}
+		sourceConfig.NonCriticalExtension = &rrcies.RRCReconfiguration_v1530_IEs{MasterCellGroup: &masterCellGroupBytes}
+	}
+	reconfig := rrcies.RRCReconfiguration{
+		Rrc_TransactionIdentifier: rrcies.RRC_TransactionIdentifier{Value: rrcTransactionInitialContext},
+		CriticalExtensions: rrcies.RRCReconfiguration_CriticalExtensions{
+			Choice:             rrcies.RRCReconfiguration_CriticalExtensions_Choice_RrcReconfiguration,
+			RrcReconfiguration: sourceConfig,
+		},
+	}
+	reconfigBytes, err := rrc.Encode(&reconfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode source RRC Reconfiguration: %w", err)
+	}
+
+	hoPrep := rrcies.HandoverPreparationInformation{
+		CriticalExtensions: rrcies.HandoverPreparationInformation_CriticalExtensions{
+			Choice: rrcies.HandoverPreparationInformation_CriticalExtensions_Choice_C1,
+			C1: &rrcies.HandoverPreparationInformation_CriticalExtensions_C1{
+				Choice: rrcies.HandoverPreparationInformation_CriticalExtensions_C1_Choice_HandoverPreparationInformation,
+				HandoverPreparationInformation: &rrcies.HandoverPreparationInformation_IEs{
+					SourceConfig: &rrcies.AS_Config{RrcReconfiguration: reconfigBytes},
+				},
+			},
+		},
+	}
+	hoPrepBytes, err := rrc.Encode(&hoPrep)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode HandoverPreparationInformation: %w", err)
+	}
+	return hoPrepBytes, nil
+}
+
+// handleHandoverSetupResponse moves the UE to the target DU once it holds the
+// UE context, then gives the CU-UP the new keys and F1-U tunnels
+func (cu *CuCpContext) handleHandoverSetupResponse(ue *uecontext.GNBUe, msg *f1ies.UEContextSetupResponse) {
+	ho := ue.Handover
+	ho.DuReady = true
+	ho.Target.DuUeId = uint64(msg.GNBDUUEF1APID)
+
+	if err := cu.sendHandoverCommand(ue, msg); err != nil {
+		cu.Error("Handover of UE RAN-UE-NGAP-ID=%d to DU %d failed: %v", ue.RanUeNgapId, ho.Target.DuId, err)
+		cu.failHandover(ue)
+		return
+	}
+	if err := cu.rekeyUserPlane(ue); err != nil {
+		cu.Error("Handover of UE RAN-UE-NGAP-ID=%d to DU %d failed: %v", ue.RanUeNgapId, ho.Target.DuId, err)
+		cu.failHandover(ue)
+	}
+}
+
+// sendHandoverCommand has the source DU stop transmitting to the UE and
+// deliver the RRCReconfiguration with the CellGroupConfig of the target cell,
+// its measurements and the NCC of the keys the UE derives for it (TS 38.331
+// 5.3.5.7). SRB1, SRB2 and the DRBs are re-established under those keys, the
+// UE context then switching to the target DU. What the UE had at the source DU
+// is kept until that DU confirms the delivery.
+func (cu *CuCpContext) sendHandoverCommand(ue *uecontext.GNBUe, msg *f1ies.UEContextSetupResponse) error {
+	ho := ue.Handover
+	targetDu, err := cu.GetDUById(int64(ho.Target.DuId))
+	if err != nil {
+		return err
+	}
+	cell := targetDu.GetActiveCell(cu.extractCellIDValue(ho.TargetCellId))
+	if cell == nil {
+		return fmt.Errorf("target cell %x no longer active", ho.TargetCellId.Bytes)
+	}
+
+	cellGroupConfig := msg.DUtoCURRCInformation.CellGroupConfig
+	cellGroup := rrcies.CellGroupConfig{}
+	if err = rrc.Decode(cellGroupConfig, &cellGroup); err != nil {
+		return fmt.Errorf("failed to decode CellGroupConfig: %w", err)
+	}
+	if cellGroup.SpCellConfig == nil || cellGroup.SpCellConfig.ReconfigurationWithSync == nil {
+		return fmt.Errorf("target DU gave no reconfigurationWithSync")
+	}
+
+	tunnels := make(map[uint8]*uecontext.GtpTunnel)
+	for _, item := range msg.DRBsSetupList {
+		if tunnel := f1DlTunnel(item.DLUPTNLInformationToBeSetupList); tunnel != nil {
+			tunnels[uint8(item.DRBID)] = tunnel
+		}
+	}
+	for _, pduSession := range ue.PduSessions {
+		for _, drb := range pduSession.Drbs {
+			if tunnels[drb.DrbId] == nil {
+				return fmt.Errorf("target DU did not set up DRB ID=%d", drb.DrbId)
+			}
+		}
+	}
+
+	asCtx, err := rekeyForCell(ue.AsSecurity, cell)
+	if err != nil {
+		return err
+	}
+	rrcBytes, err := encodeRrcReconfiguration(rrcTransactionInitialContext, &rrcies.RRCReconfiguration_IEs{
+		RadioBearerConfig: reestablishedBearers(ue, 1, 2),
+		MeasConfig:        ho.MeasConfig,
+		NonCriticalExtension: &rrcies.RRCReconfiguration_v1530_IEs{
+			MasterCellGroup: &cellGroupConfig,
+			MasterKeyUpdate: &rrcies.MasterKeyUpdate{
+				KeySetChangeIndicator: false,
+				NextHopChainingCount:  rrcies.NextHopChainingCount{Value: uint64(asCtx.Ncc)},
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	action := f1ies.TransmissionActionIndicator{Value: f1ies.TransmissionActionIndicatorStop}
+	err = cu.sendF1UEContextModification(ue, &f1ext.UEContextModificationRequest{
+		TransmissionActionIndicator: &action,
+		RRCContainer:                rrcBytes,
+	})
+	if err != nil {
+		return err
+	}
+	ho.ReconfigSent = true
+	cu.Info("Handover command sent to UE RAN-UE-NGAP-ID=%d through DU %d", ue.RanUeNgapId, ue.DuId)
+
+	ho.Source = &uecontext.HandoverSource{
+		Leg:             uecontext.DuLeg{DuId: ue.DuId, DuUeId: ue.DuUeId, GnbCuUeF1apId: ue.GnbCuUeF1apId},
+		NrCellId:        ue.NrCellId,
+		Rnti:            ue.Rnti,
+		MasterCellGroup: ue.MasterCellGroup,
+		Measurements:    ue.Measurements,
+		AsSecurity:      ue.AsSecurity,
+		SrbPdcp:         ue.SrbPdcp,
+		DlF1uTunnels:    make(map[uint8]*uecontext.GtpTunnel),
+	}
+	source := ho.Source.Leg
+	ue.SourceDu = &source
+	ue.DuId = ho.Target.DuId
+	ue.DuUeId = ho.Target.DuUeId
+	ue.GnbCuUeF1apId = ho.Target.GnbCuUeF1apId
+	targetCellId := ho.TargetCellId
+	ue.NrCellId = &targetCellId
+	ue.Rnti = int64(cellGroup.SpCellConfig.ReconfigurationWithSync.NewUE_Identity.Value)
+	// later reconfigurations must not trigger another synchronisation
+	cellGroup.SpCellConfig.ReconfigurationWithSync = nil
+	ue.MasterCellGroup = &cellGroup
+	ue.Measurements = ho.Measurements
+	for _, pduSession := range ue.PduSessions {
+		for _, drb := range pduSession.Drbs {
+			ho.Source.DlF1uTunnels[drb.DrbId] = drb.DlF1uTunnel
+			drb.DlF1uTunnel = tunnels[drb.DrbId]
+		}
+	}
+
+	ue.AsSecurity = asCtx
+	for _, srbId := range []uint8{1, 2} {
+		srb := pdcp.NewSrbEntity(srbId)
+		srb.Tx = srbSecurity(asCtx, true)
+		srb.Rx = srbSecurity(asCtx, true)
+		ue.SrbPdcp[srbId] = srb
+	}
+	return nil
+}
+
+// handleHandoverCommandDelivery follows the answer of the source DU to the UE
+// Context Modification carrying the handover command. A UE context left at a
+// DU for other reasons has nothing to answer.
+func (cu *CuCpContext) handleHandoverCommandDelivery(ue *uecontext.GNBUe, gnbCuUeF1apId int64, delivered bool) error {
+	ho := ue.Handover
+	if ho == nil || ho.Source == nil || int64(ho.Source.Leg.GnbCuUeF1apId) != gnbCuUeF1apId {
+		cu.Warn("UE Context Modification answer for CU-UE-F1AP-ID=%d, which UE RAN-UE-NGAP-ID=%d has left",
+			gnbCuUeF1apId, ue.RanUeNgapId)
+		return nil
+	}
+	if !delivered {
+		cu.revertHandover(ue)
+		return fmt.Errorf("source DU could not deliver the handover command to UE RAN-UE-NGAP-ID=%d", ue.RanUeNgapId)
+	}
+	ho.Source = nil
+	cu.Info("Source DU delivered the handover command to UE RAN-UE-NGAP-ID=%d", ue.RanUeNgapId)
+	return nil
+}
+
+// revertHandover gives a UE the context it has at the source DU back when
+// that DU could not deliver the handover command: the UE is still in the
+// source cell. The UE context at the target DU is released and the CU-UP
+// returns to the source keys and F1-U tunnels.
+func (cu *CuCpContext) revertHandover(ue *uecontext.GNBUe) {
+	ho := ue.Handover
+	ho.Timer.Stop()
+	ue.Handover = nil
+
+	source := ho.Source
+	ue.SourceDu = nil
+	ue.DuId = source.Leg.DuId
+	ue.DuUeId = source.Leg.DuUeId
+	ue.GnbCuUeF1apId = source.Leg.GnbCuUeF1apId
+	ue.NrCellId = source.NrCellId
+	ue.Rnti = source.Rnti
+	ue.MasterCellGroup = source.MasterCellGroup
+	ue.Measurements = source.Measurements
+	ue.AsSecurity = source.AsSecurity
+	ue.SrbPdcp = source.SrbPdcp
+	for _, pduSession := range ue.PduSessions {
+		for _, drb := range pduSession.Drbs {
+			drb.DlF1uTunnel = source.DlF1uTunnels[drb.DrbId]
+		}
+	}
+
+	if err := cu.releaseDuLeg(&ho.Target); err != nil {
+		cu.Warn("Failed to release UE RAN-UE-NGAP-ID=%d at the target DU %d: %v", ue.RanUeNgapId, ho.Target.DuId, err)
+	}
+	if err := cu.rekeyUserPlane(ue); err != nil {
+		cu.Error("UE RAN-UE-NGAP-ID=%d: %v", ue.RanUeNgapId, err)
+	}
+	cu.Warn("Handover of UE RAN-UE-NGAP-ID=%d reverted, the UE stays at DU %d", ue.RanUeNgapId, ue.DuId)
+}
+
+// completeHandover releases the UE context at the source DU once the UE has
+// completed the RRCReconfiguration in the target cell
+func (cu *CuCpContext) completeHandover(ue *uecontext.GNBUe) {
+	ho := ue.Handover
+	ho.Timer.Stop()
+	ue.Handover = nil
+	cu.Info("UE RAN-UE-NGAP-ID=%d handed over to cell %x of DU %d",
+		ue.RanUeNgapId, ho.TargetCellId.Bytes, ue.DuId)
+	cu.releaseSourceDu(ue)
+}
+
+// abortHandover stops a handover in progress and releases the UE context set
+// up at the target DU unless the UE was sent there. It tells whether the
+// RRCReconfiguration was sent.
+func (cu *CuCpContext) abortHandover(ue *uecontext.GNBUe) bool {
+	ho := ue.Handover
+	if ho == nil {
+		return false
+	}
+	ho.Timer.Stop()
+	ue.Handover = nil
+	if ho.ReconfigSent {
+		return true
+	}
+	if !ho.DuReady {
+		// without the gNB-DU UE F1AP ID there is nothing to release
+		cu.F1UePool.Delete(int64(ho.Target.GnbCuUeF1apId))
+		return false
+	}
+	if err := cu.releaseDuLeg(&ho.Target); err != nil {
+		cu.Warn("Failed to release UE RAN-UE-NGAP-ID=%d at the target DU %d: %v", ue.RanUeNgapId, ho.Target.DuId, err)
+	}
+	return false
+}
+
+// failHandover ends a handover that did not go through. A UE that was not
+// sent to the target cell stays where it is, otherwise it cannot be brought
+// back and the AMF is asked to release it.
+func (cu *CuCpContext) failHandover(ue *uecontext.GNBUe) {
+	if ue.Handover == nil {
+		return
+	}
+	if !cu.abortHandover(ue) {
+		cu.Warn("Handover of UE RAN-UE-NGAP-ID=%d cancelled, the UE stays at DU %d", ue.RanUeNgapId, ue.DuId)
+		return
+	}
+	err := cu.sendUEContextReleaseRequest(ue, radioNetworkCause(ies.CauseRadioNetworkHofailureintarget5Gcngrannodeortargetsystem))
+	if err != nil {
+		cu.Error("Failed to send UE Context Release Request: %v", err)
+		cu.releaseUEContext(ue, true)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 
 	"central-unit/internal/context/amfcontext"
+	"central-unit/internal/context/cuup"
 	"central-unit/internal/context/du"
 	"central-unit/internal/context/uecontext"
 	"github.com/ishidawataru/sctp"
@@ -32,6 +33,42 @@ func (cu *CuCpContext) GetDUForUE(ue *uecontext.GNBUe) (*du.GNBDU, error) {
 	return cu.GetDUById(int64(ue.DuId))
 }
 
+func (cu *CuCpContext) GetCUUPByConn(conn *sctp.SCTPConn) (*cuup.GNBCUUP, error) {
+	if conn == nil {
+		return nil, fmt.Errorf("connection is nil")
+	}
+	cuUpIdVal, ok := cu.E1ConnMap.Load(conn)
+	if !ok {
+		return nil, fmt.Errorf("no CU-UP registered for connection %v", conn.RemoteAddr())
+	}
+	return cu.GetCUUPById(cuUpIdVal.(int64))
+}
+
+func (cu *CuCpContext) GetCUUPById(cuUpId int64) (*cuup.GNBCUUP, error) {
+	cuupVal, ok := cu.CuUpPool.Load(cuUpId)
+	if !ok {
+		return nil, fmt.Errorf("CU-UP %d not found in pool", cuUpId)
+	}
+	return cuupVal.(*cuup.GNBCUUP), nil
+}
+
+// GetActiveCUUP returns an active CU-UP serving the CU-CP PLMN
+func (cu *CuCpContext) GetActiveCUUP() (*cuup.GNBCUUP, error) {
+	var selected *cuup.GNBCUUP
+	plmn := cu.GetMccAndMncInOctets()
+	cu.CuUpPool.Range(func(_, value any) bool {
+		if c, ok := value.(*cuup.GNBCUUP); ok && c.IsActive() && c.SupportsPLMN(plmn) {
+			selected = c
+			return false
+		}
+		return true
+	})
+	if selected == nil {
+		return nil, fmt.Errorf("no active CU-UP available")
+	}
+	return selected, nil
+}
+
 func (cu *CuCpContext) GetUEByRrcId(rrcUeId int64) (*uecontext.GNBUe, error) {
 	ueVal, ok := cu.RrcUePool.Load(rrcUeId)
 	if !ok {
@@ -48,6 +85,14 @@ func (cu *CuCpContext) GetUEByF1Id(cuUeF1apId int64) (*uecontext.GNBUe, error) {
 	return ueVal.(*uecontext.GNBUe), nil
 }
 
+func (cu *CuCpContext) GetUEByE1Id(cuCpUeE1apId int64) (*uecontext.GNBUe, error) {
+	ueVal, ok := cu.E1UePool.Load(cuCpUeE1apId)
+	if !ok {
+		return nil, fmt.Errorf("UE with CU-CP-UE-E1AP-ID %d not found", cuCpUeE1apId)
+	}
+	return ueVal.(*uecontext.GNBUe), nil
+}
+
 func (cu *CuCpContext) GetUEByNgapId(ranUeNgapId int64) (*uecontext.GNBUe, error) {
 	ueVal, ok := cu.NgapUePool.Load(ranUeNgapId)
 	if !ok {
@@ -56,21 +101,61 @@ func (cu *CuCpContext) GetUEByNgapId(ranUeNgapId int64) (*uecontext.GNBUe, error
 	return ueVal.(*uecontext.GNBUe), nil
 }
 
-func (cu *CuCpContext) GetPrimaryAMF() (*amfcontext.GNBAmf, error) {
-	var primaryAmf *amfcontext.GNBAmf
-	cu.AmfPool.Range(func(key, value any) bool {
-		if amf, ok := value.(*amfcontext.GNBAmf); ok {
-			if amf.State == amfcontext.AMF_ACTIVE {
-				primaryAmf = amf
-				return false
-			}
+// GetUEByAmfNgapId looks up a UE by the AMF-UE-NGAP-ID the AMF assigned to it
+func (cu *CuCpContext) GetUEByAmfNgapId(amfId int64, amfUeNgapId int64) (*uecontext.GNBUe, error) {
+	var found *uecontext.GNBUe
+	cu.NgapUePool.Range(func(_, value any) bool {
+		if ue, ok := value.(*uecontext.GNBUe); ok && ue.AmfId == amfId && ue.AmfUeNgapId == amfUeNgapId {
+			found = ue
+			return false
 		}
 		return true
 	})
-	if primaryAmf == nil {
-		return nil, fmt.Errorf("no active AMF available")
+	if found == nil {
+		return nil, fmt.Errorf("UE with AMF-UE-NGAP-ID %d not found", amfUeNgapId)
 	}
-	return primaryAmf, nil
+	return found, nil
+}
+
+// GetUEByDuUeId looks up a UE by the gNB-DU UE F1AP ID its DU assigned to it
+func (cu *CuCpContext) GetUEByDuUeId(duId int64, duUeId int64) (*uecontext.GNBUe, error) {
+	var found *uecontext.GNBUe
+	cu.F1UePool.Range(func(_, value any) bool {
+		if ue, ok := value.(*uecontext.GNBUe); ok && ue.DuId == uint64(duId) && ue.DuUeId == uint64(duUeId) {
+			found = ue
+			return false
+		}
+		return true
+	})
+	if found == nil {
+		return nil, fmt.Errorf("UE with DU-UE-F1AP-ID %d not found at DU %d", duUeId, duId)
+	}
+	return found, nil
+}
+
+// GetUEsOfDU lists the UEs served by a DU. The UE context a UE left at
+// another DU does not count.
+func (cu *CuCpContext) GetUEsOfDU(duId int64) []*uecontext.GNBUe {
+	var ues []*uecontext.GNBUe
+	cu.F1UePool.Range(func(key, value any) bool {
+		if ue, ok := value.(*uecontext.GNBUe); ok && ue.DuId == uint64(duId) && key == int64(ue.GnbCuUeF1apId) {
+			ues = append(ues, ue)
+		}
+		return true
+	})
+	return ues
+}
+
+// GetUEsOfAMF lists the UEs registered through an AMF
+func (cu *CuCpContext) GetUEsOfAMF(amfId int64) []*uecontext.GNBUe {
+	var ues []*uecontext.GNBUe
+	cu.NgapUePool.Range(func(_, value any) bool {
+		if ue, ok := value.(*uecontext.GNBUe); ok && ue.AmfId == amfId {
+			ues = append(ues, ue)
+		}
+		return true
+	})
+	return ues
 }
 
 func (cu *CuCpContext) GetAMFById(amfId int64) (*amfcontext.GNBAmf, error) {
@@ -85,9 +170,13 @@ func (cu *CuCpContext) RemoveUE(ue *uecontext.GNBUe) {
 	cu.RrcUePool.Delete(int64(ue.RrcUeId))
 	cu.NgapUePool.Delete(ue.RanUeNgapId)
 	cu.F1UePool.Delete(int64(ue.GnbCuUeF1apId))
+	if ue.SourceDu != nil {
+		cu.F1UePool.Delete(int64(ue.SourceDu.GnbCuUeF1apId))
+	}
+	cu.E1UePool.Delete(int64(ue.GnbCuCpUeE1apId))
 
-	cu.Info("Removed UE: RrcId=%d, NgapId=%d, F1Id=%d from all pools",
-		ue.RrcUeId, ue.RanUeNgapId, ue.GnbCuUeF1apId)
+	cu.Info("Removed UE: RrcId=%d, NgapId=%d, F1Id=%d, E1Id=%d from all pools",
+		ue.RrcUeId, ue.RanUeNgapId, ue.GnbCuUeF1apId, ue.GnbCuCpUeE1apId)
 }
 
 func (cu *CuCpContext) RemoveDU(duCtx *du.GNBDU) {
@@ -98,6 +187,14 @@ func (cu *CuCpContext) RemoveDU(duCtx *du.GNBDU) {
 	cu.Info("Removed DU: %d from all pools", duCtx.DuId)
 }
 
+func (cu *CuCpContext) RemoveCUUP(cuupCtx *cuup.GNBCUUP) {
+	cu.CuUpPool.CompareAndDelete(cuupCtx.CuUpId, cuupCtx)
+	if cuupCtx.SctpConn != nil {
+		cu.E1ConnMap.Delete(cuupCtx.SctpConn)
+	}
+	cu.Info("Removed CU-UP: %d from all pools", cuupCtx.CuUpId)
+}
+
 func (cu *CuCpContext) GetConnectedDUCount() int {
 	count := 0
 	cu.DuPool.Range(func(_, value any) bool {
@@ -0,0 +1,485 @@
+package context
+
+import (
+	"central-unit/internal/context/du"
+	"central-unit/internal/context/uecontext"
+	"central-unit/pkg/config"
+	"cmp"
+	"fmt"
+	"maps"
+	"slices"
+	"time"
+
+	asn1aper "github.com/lvdund/asn1go/aper"
+	"github.com/lvdund/rrc"
+	rrcies "github.com/lvdund/rrc/ies"
+)
+
+// A connected UE measures the SSBs of its serving cell and of the neighbour
+// cells of it (TS 38.331 5.5). Neighbours are the other cells of the CU-CP
+// unless the configuration lists them for the serving cell. Each SSB
+// frequency is a measurement object, each enabled event a report
+// configuration, and the measurement identities link them: A2 on the serving
+// frequency, A3 and A5 on every frequency with neighbours. The configuration
+// is given whole in each RRCReconfiguration or RRCResume that moves the UE,
+// the identities of the previous one being removed first.
+
+const (
+	maxMeasCells   = 32 // maxNrofCellMeas, neighbours listed per measurement object
+	maxReportCells = 8  // maxCellReport, neighbours reported per MeasurementReport
+)
+
+// MeasurementPolicy is the part of the measurement configuration that is the
+// same for every UE
+type MeasurementPolicy struct {
+	Events     []MeasEvent                // in reportConfigId order
+	Neighbours map[uint64][]neighbourCell // configured lists by NR Cell Identity of the serving cell
+}
+
+// MeasEvent is an enabled event with its report configuration
+type MeasEvent struct {
+	Event        uint8 // uecontext.MEAS_EVENT_*
+	ReportConfig rrcies.ReportConfigNR
+}
+
+// neighbourCell is a configured neighbour: a cell of the CU-CP, looked up by
+// its NR Cell Identity when a UE is configured, or a cell of another gNB
+type neighbourCell struct {
+	CellId   uint64
+	External *measCell
+}
+
+// measCell is a cell a UE measures
+type measCell struct {
+	Pci  uint16
+	Freq ssbFrequency
+}
+
+// ssbFrequency is where and when the SSBs of a cell are found. Cells of other
+// gNBs come without an SMTC and are measured with the one of the serving cell.
+type ssbFrequency struct {
+	Arfcn uint64
+	Scs   asn1aper.Enumerated
+	Smtc  *rrcies.SSB_MTC
+}
+
+func newMeasurementPolicy(cfg config.MobilityConfig) (*MeasurementPolicy, error) {
+	interval := slices.Index(config.ReportIntervals, cfg.ReportInterval)
+	if interval < 0 {
+		return nil, fmt.Errorf("unsupported measurement report interval %v", cfg.ReportInterval)
+	}
+	policy := &MeasurementPolicy{Neighbours: make(map[uint64][]neighbourCell)}
+	for _, event := range []struct {
+		event uint8
+		cfg   config.EventConfig
+	}{
+		{uecontext.MEAS_EVENT_A2, cfg.A2},
+		{uecontext.MEAS_EVENT_A3, cfg.A3},
+		{uecontext.MEAS_EVENT_A5, cfg.A5},
+	} {
+		if !event.cfg.Enabled {
+			continue
+		}
+		reportConfig, err := eventReportConfig(event.event, event.cfg, asn1aper.Enumerated(interval))
+		if err != nil {
+			return nil, fmt.Errorf("event A%d: %w", event.event, err)
+		}
+		policy.Events = append(policy.Events, MeasEvent{Event: event.event, ReportConfig: reportConfig})
+	}
+	if len(policy.Events) == 0 {
+		return nil, nil
+	}
+
+	for _, list := range cfg.Neighbours {
+		cellId, err := config.ParseCellID(list.CellID)
+		if err != nil {
+			return nil, err
+		}
+		// an empty list leaves the cell without neighbours
+		neighbours := []neighbourCell{}
+		for _, neighbour := range list.Neighbours {
+			if neighbour.CellID != "" {
+				id, err := config.ParseCellID(neighbour.CellID)
+				if err != nil {
+					return nil, err
+				}
+				neighbours = append(neighbours, neighbourCell{CellId: id})
+				continue
+			}
+			scs := slices.Index(config.SsbSubcarrierSpacings, neighbour.SsbScsKHz)
+			if scs < 0 {
+				return nil, fmt.Errorf("unsupported SSB subcarrier spacing of %d kHz", neighbour.SsbScsKHz)
+			}
+			neighbours = append(neighbours, neighbourCell{External: &measCell{
+				Pci:  uint16(neighbour.PCI),
+				Freq: ssbFrequency{Arfcn: uint64(neighbour.SsbArfcn), Scs: asn1aper.Enumerated(scs)},
+			}})
+		}
+		policy.Neighbours[cellId] = neighbours
+	}
+	return policy, nil
+}
+
+// eventReportConfig is the SSB RSRP report configuration of an event
+func eventReportConfig(event uint8, cfg config.EventConfig, interval asn1aper.Enumerated) (rrcies.ReportConfigNR, error) {
+	ttt := slices.Index(config.TimesToTrigger, cfg.TimeToTrigger)
+	if ttt < 0 {
+		return rrcies.ReportConfigNR{}, fmt.Errorf("unsupported time to trigger %v", cfg.TimeToTrigger)
+	}
+	hysteresis := rrcies.Hysteresis{Value: uint64(cfg.Hysteresis * 2)}
+	timeToTrigger := rrcies.TimeToTrigger{Value: asn1aper.Enumerated(ttt)}
+
+	trigger := &rrcies.EventTriggerConfig{
+		RsType:             rrcies.NR_RS_Type{Value: rrcies.NR_RS_Type_Enum_ssb},
+		ReportInterval:     rrcies.ReportInterval{Value: interval},
+		ReportAmount:       rrcies.EventTriggerConfig_reportAmount{Value: rrcies.EventTriggerConfig_reportAmount_Enum_r4},
+		ReportQuantityCell: rrcies.MeasReportQuantity{Rsrp: true},
+		MaxReportCells:     maxReportCells,
+	}
+	switch event {
+	case uecontext.MEAS_EVENT_A2:
+		trigger.EventId = rrcies.EventTriggerConfig_eventId{
+			Choice: rrcies.EventTriggerConfig_eventId_Choice_EventA2,
+			EventA2: &rrcies.EventTriggerConfig_eventId_eventA2{
+				A2_Threshold:  rsrpThreshold(cfg.Threshold),
+				Hysteresis:    hysteresis,
+				TimeToTrigger: timeToTrigger,
+			},
+		}
+	case uecontext.MEAS_EVENT_A3:
+		trigger.EventId = rrcies.EventTriggerConfig_eventId{
+			Choice: rrcies.EventTriggerConfig_eventId_Choice_EventA3,
+			EventA3: &rrcies.EventTriggerConfig_eventId_eventA3{
+				A3_Offset: rrcies.MeasTriggerQuantityOffset{
+					Choice: rrcies.MeasTriggerQuantityOffset_Choice_Rsrp,
+					Rsrp:   int64(cfg.Offset * 2),
+				},
+				Hysteresis:    hysteresis,
+				TimeToTrigger: timeToTrigger,
+			},
+		}
+	case uecontext.MEAS_EVENT_A5:
+		trigger.EventId = rrcies.EventTriggerConfig_eventId{
+			Choice: rrcies.EventTriggerConfig_eventId_Choice_EventA5,
+			EventA5: &rrcies.EventTriggerConfig_eventId_eventA5{
+				A5_Threshold1: rsrpThreshold(cfg.Threshold),
+				A5_Threshold2: rsrpThreshold(cfg.Threshold2),
+				Hysteresis:    hysteresis,
+				TimeToTrigger: timeToTrigger,
+			},
+		}
+	default:
+		return rrcies.ReportConfigNR{}, fmt.Errorf("unsupported event")
+	}
+	return rrcies.ReportConfigNR{
+		ReportType: rrcies.ReportConfigNR_reportType{
+			Choice:         rrcies.ReportConfigNR_reportType_Choice_EventTriggered,
+			EventTriggered: trigger,
+		},
+	}, nil
+}
+
+// rsrpThreshold maps dBm onto the RSRP-Range of TS 38.133, where n stands
+// for -157+n dBm
+func rsrpThreshold(dBm int) rrcies.MeasTriggerQuantity {
+	return rrcies.MeasTriggerQuantity{
+		Choice: rrcies.MeasTriggerQuantity_Choice_Rsrp,
+		Rsrp:   &rrcies.RSRP_Range{Value: uint64(dBm + 157)},
+	}
+}
+
+// cellSsbFrequency reads the SSB frequency of a cell from the
+// MeasurementTimingConfiguration its DU gave in the F1 Setup
+func cellSsbFrequency(cell *du.ServedCell) (ssbFrequency, error) {
+	if len(cell.MTC) == 0 {
+		return ssbFrequency{}, fmt.Errorf("cell %x has no MeasurementTimingConfiguration", cell.NRCGI.NRCellIdentity.Bytes)
+	}
+	mtc := rrcies.MeasurementTimingConfiguration{}
+	if err := rrc.Decode(cell.MTC, &mtc); err != nil {
+		return ssbFrequency{}, fmt.Errorf("failed to decode MeasurementTimingConfiguration of cell %x: %w",
+			cell.NRCGI.NRCellIdentity.Bytes, err)
+	}
+	if c1 := mtc.CriticalExtensions.C1; c1 != nil && c1.MeasTimingConf != nil && c1.MeasTimingConf.MeasTiming != nil {
+		for _, timing := range c1.MeasTimingConf.MeasTiming.Value {
+			if timing.FrequencyAndTiming == nil {
+				continue
+			}
+			smtc := timing.FrequencyAndTiming.Ssb_MeasurementTimingConfiguration
+			return ssbFrequency{
+				Arfcn: timing.FrequencyAndTiming.CarrierFreq.Value,
+				Scs:   timing.FrequencyAndTiming.SsbSubcarrierSpacing.Value,
+				Smtc:  &smtc,
+			}, nil
+		}
+	}
+	return ssbFrequency{}, fmt.Errorf("cell %x gives no SSB frequency", cell.NRCGI.NRCellIdentity.Bytes)
+}
+
+// neighbourCells lists the cells a UE in a serving cell measures
+func (cu *CuCpContext) neighbourCells(serving *du.ServedCell) []measCell {
+	var cells []measCell
+	addCell := func(cell *du.ServedCell) {
+		freq, err := cellSsbFrequency(cell)
+		if err != nil {
+			cu.Warn("Neighbour cell %x left out of the measurements: %v", cell.NRCGI.NRCellIdentity.Bytes, err)
+			return
+		}
+		cells = append(cells, measCell{Pci: cell.PCI, Freq: freq})
+	}
+
+	if configured, ok := cu.measPolicy.Neighbours[serving.CellID]; ok {
+		for _, neighbour := range configured {
+			if neighbour.External != nil {
+				cells = append(cells, *neighbour.External)
+				continue
+			}
+			_, cell := cu.findCell(func(cell *du.ServedCell) bool { return cell.CellID == neighbour.CellId })
+			if cell != nil {
+				addCell(cell)
+			}
+		}
+		return cells
+	}
+	cu.DuPool.Range(func(_, value any) bool {
+		duCtx, ok := value.(*du.GNBDU)
+		if !ok || !cu.isDUConnected(duCtx) {
+			return true
+		}
+		for i := range duCtx.ServedCells {
+			cell := &duCtx.ServedCells[i]
+			if cell.Active && !cell.OutOfService && cell.CellID != serving.CellID {
+				addCell(cell)
+			}
+		}
+		return true
+	})
+	return cells
+}
+
+// measConfigFor builds the measurement configuration of a UE in a serving
+// cell, replacing the one it has. It returns what the UE is configured with
+// once the MeasConfig is delivered, and a nil MeasConfig when measurements are
+// off or the serving cell gives no SSB frequency.
+func (cu *CuCpContext) measConfigFor(ue *uecontext.GNBUe, serving *du.ServedCell) (*rrcies.MeasConfig, *uecontext.Measurements) {
+	if cu.measPolicy == nil || serving == nil {
+		return nil, ue.Measurements
+	}
+	servingFreq, err := cellSsbFrequency(serving)
+	if err != nil {
+		cu.Warn("No measurements for UE RAN-UE-NGAP-ID=%d: %v", ue.RanUeNgapId, err)
+		return nil, ue.Measurements
+	}
+
+	meas := &uecontext.Measurements{
+		Objects:       make(map[uint64]*uecontext.MeasObject),
+		ReportConfigs: make(map[uint64]uint8),
+		MeasIds:       make(map[uint64]uecontext.MeasId),
+		Reports:       make(map[uint64]*uecontext.MeasReport),
+	}
+	measConfig := &rrcies.MeasConfig{}
+	if old := ue.Measurements; old != nil {
+		measConfig.MeasIdToRemoveList = &rrcies.MeasIdToRemoveList{}
+		for _, id := range slices.Sorted(maps.Keys(old.MeasIds)) {
+			measConfig.MeasIdToRemoveList.Value = append(measConfig.MeasIdToRemoveList.Value, rrcies.MeasId{Value: id})
+		}
+		measConfig.MeasObjectToRemoveList = &rrcies.MeasObjectToRemoveList{}
+		for _, id := range slices.Sorted(maps.Keys(old.Objects)) {
+			measConfig.MeasObjectToRemoveList.Value = append(measConfig.MeasObjectToRemoveList.Value, rrcies.MeasObjectId{Value: id})
+		}
+		measConfig.ReportConfigToRemoveList = &rrcies.ReportConfigToRemoveList{}
+		for _, id := range slices.Sorted(maps.Keys(old.ReportConfigs)) {
+			measConfig.ReportConfigToRemoveList.Value = append(measConfig.ReportConfigToRemoveList.Value, rrcies.ReportConfigId{Value: id})
+		}
+		if len(measConfig.MeasIdToRemoveList.Value) == 0 {
+			measConfig.MeasIdToRemoveList = nil
+		}
+		if len(measConfig.MeasObjectToRemoveList.Value) == 0 {
+			measConfig.MeasObjectToRemoveList = nil
+		}
+		if len(measConfig.ReportConfigToRemoveList.Value) == 0 {
+			measConfig.ReportConfigToRemoveList = nil
+		}
+	}
+
+	// one measurement object per SSB frequency, the serving one first
+	freqs := []ssbFrequency{servingFreq}
+	meas.Objects[1] = &uecontext.MeasObject{SsbArfcn: servingFreq.Arfcn}
+	for _, cell := range cu.neighbourCells(serving) {
+		if cell.Freq.Arfcn == servingFreq.Arfcn && cell.Pci == serving.PCI {
+			continue
+		}
+		index := slices.IndexFunc(freqs, func(freq ssbFrequency) bool { return freq.Arfcn == cell.Freq.Arfcn })
+		if index < 0 {
+			if cell.Freq.Smtc == nil {
+				cell.Freq.Smtc = servingFreq.Smtc
+			}
+			freqs = append(freqs, cell.Freq)
+			index = len(freqs) - 1
+			meas.Objects[uint64(len(freqs))] = &uecontext.MeasObject{SsbArfcn: cell.Freq.Arfcn}
+		}
+		object := meas.Objects[uint64(index+1)]
+		if len(object.Pcis) < maxMeasCells && !slices.Contains(object.Pcis, cell.Pci) {
+			object.Pcis = append(object.Pcis, cell.Pci)
+		}
+	}
+	measConfig.MeasObjectToAddModList = &rrcies.MeasObjectToAddModList{}
+	for i, freq := range freqs {
+		objectId := uint64(i + 1)
+		measConfig.MeasObjectToAddModList.Value = append(measConfig.MeasObjectToAddModList.Value, rrcies.MeasObjectToAddMod{
+			MeasObjectId: rrcies.MeasObjectId{Value: objectId},
+			MeasObject: rrcies.MeasObjectToAddMod_measObject{
+				Choice:       rrcies.MeasObjectToAddMod_measObject_Choice_MeasObjectNR,
+				MeasObjectNR: measObjectNR(freq, meas.Objects[objectId].Pcis),
+			},
+		})
+	}
+
+	measConfig.ReportConfigToAddModList = &rrcies.ReportConfigToAddModList{}
+	var measIds []rrcies.MeasIdToAddMod
+	for i, event := range cu.measPolicy.Events {
+		reportConfigId := uint64(i + 1)
+		reportConfig := event.ReportConfig
+		meas.ReportConfigs[reportConfigId] = event.Event
+		measConfig.ReportConfigToAddModList.Value = append(measConfig.ReportConfigToAddModList.Value, rrcies.ReportConfigToAddMod{
+			ReportConfigId: rrcies.ReportConfigId{Value: reportConfigId},
+			ReportConfig: rrcies.ReportConfigToAddMod_reportConfig{
+				Choice:         rrcies.ReportConfigToAddMod_reportConfig_Choice_ReportConfigNR,
+				ReportConfigNR: &reportConfig,
+			},
+		})
+		for objectId := uint64(1); objectId <= uint64(len(freqs)); objectId++ {
+			// A2 is about the serving cell only
+			if event.Event == uecontext.MEAS_EVENT_A2 && objectId != 1 ||
+				event.Event != uecontext.MEAS_EVENT_A2 && len(meas.Objects[objectId].Pcis) == 0 {
+				continue
+			}
+			measId := uint64(len(measIds) + 1)
+			meas.MeasIds[measId] = uecontext.MeasId{ObjectId: objectId, ReportConfigId: reportConfigId}
+			measIds = append(measIds, rrcies.MeasIdToAddMod{
+				MeasId:         rrcies.MeasId{Value: measId},
+				MeasObjectId:   rrcies.MeasObjectId{Value: objectId},
+				ReportConfigId: rrcies.ReportConfigId{Value: reportConfigId},
+			})
+		}
+	}
+	if len(measIds) > 0 {
+		measConfig.MeasIdToAddModList = &rrcies.MeasIdToAddModList{Value: measIds}
+	}
+
+	filter := rrcies.FilterConfig{
+		FilterCoefficientRSRP:    rrcies.FilterCoefficient{Value: rrcies.FilterCoefficient_Enum_fc4},
+		FilterCoefficientRSRQ:    rrcies.FilterCoefficient{Value: rrcies.FilterCoefficient_Enum_fc4},
+		FilterCoefficientRS_SINR: rrcies.FilterCoefficient{Value: rrcies.FilterCoefficient_Enum_fc4},
+	}
+	measConfig.QuantityConfig = &rrcies.QuantityConfig{
+		QuantityConfigNR_List: []rrcies.QuantityConfigNR{{
+			QuantityConfigCell: rrcies.QuantityConfigRS{Ssb_FilterConfig: filter, Csi_RS_FilterConfig: filter},
+		}},
+	}
+	return measConfig, meas
+}
+
+// measObjectNR is the measurement object of an SSB frequency listing the
+// PCIs of the neighbour cells on it, without offsets
+func measObjectNR(freq ssbFrequency, pcis []uint16) *rrcies.MeasObjectNR {
+	noOffset := rrcies.Q_OffsetRange{Value: rrcies.Q_OffsetRange_Enum_dB0}
+	offsets := rrcies.Q_OffsetRangeList{
+		RsrpOffsetSSB:    noOffset,
+		RsrqOffsetSSB:    noOffset,
+		SinrOffsetSSB:    noOffset,
+		RsrpOffsetCSI_RS: noOffset,
+		RsrqOffsetCSI_RS: noOffset,
+		SinrOffsetCSI_RS: noOffset,
+	}
+	object := &rrcies.MeasObjectNR{
+		SsbFrequency:         &rrcies.ARFCN_ValueNR{Value: freq.Arfcn},
+		SsbSubcarrierSpacing: &rrcies.SubcarrierSpacing{Value: freq.Scs},
+		Smtc1:                freq.Smtc,
+		QuantityConfigIndex:  1,
+		OffsetMO:             offsets,
+	}
+	if len(pcis) > 0 {
+		object.CellsToAddModList = &rrcies.CellsToAddModList{}
+		for _, pci := range pcis {
+			object.CellsToAddModList.Value = append(object.CellsToAddModList.Value, rrcies.CellsToAddMod{
+				PhysCellId:           rrcies.PhysCellId{Value: uint64(pci)},
+				CellIndividualOffset: offsets,
+			})
+		}
+	}
+	return object
+}
+
+// handleMeasurementReport keeps the report of a UE as the last one of its
+// measurement identity and acts on it
+func (cu *CuCpContext) handleMeasurementReport(ue *uecontext.GNBUe, msg *rrcies.MeasurementReport) error {
+	ext := msg.CriticalExtensions
+	if ext.Choice != rrcies.MeasurementReport_CriticalExtensions_Choice_MeasurementReport || ext.MeasurementReport == nil {
+		return fmt.Errorf("unsupported MeasurementReport critical extension %d", ext.Choice)
+	}
+	report, err := measReport(ue.Measurements, &ext.MeasurementReport.MeasResults)
+	if err != nil {
+		return err
+	}
+	ue.Measurements.Reports[report.MeasId] = report
+
+	if best := report.BestNeighbour(); best != nil {
+		cu.Info("UE RAN-UE-NGAP-ID=%d reports event A%d on SSB ARFCN %d, best neighbour PCI %d with RSRP %d",
+			ue.RanUeNgapId, report.Event, report.SsbArfcn, best.Pci, best.Rsrp)
+	} else {
+		cu.Info("UE RAN-UE-NGAP-ID=%d reports event A%d on SSB ARFCN %d", ue.RanUeNgapId, report.Event, report.SsbArfcn)
+	}
+	cu.evaluateMobility(ue, report)
+	return nil
+}
+
+// measReport reads the results of a MeasurementReport against the
+// measurement configuration the UE was given
+func measReport(meas *uecontext.Measurements, results *rrcies.MeasResults) (*uecontext.MeasReport, error) {
+	if meas == nil {
+		return nil, fmt.Errorf("MeasurementReport from a UE without measurements")
+	}
+	measId, ok := meas.MeasIds[results.MeasId.Value]
+	if !ok {
+		return nil, fmt.Errorf("MeasurementReport for unknown measId %d", results.MeasId.Value)
+	}
+	report := &uecontext.MeasReport{
+		MeasId:   results.MeasId.Value,
+		Event:    meas.ReportConfigs[measId.ReportConfigId],
+		SsbArfcn: meas.Objects[measId.ObjectId].SsbArfcn,
+		Received: time.Now(),
+	}
+	for i := range results.MeasResultServingMOList.Value {
+		servingMo := &results.MeasResultServingMOList.Value[i]
+		if servingMo.ServCellId.Value != 0 {
+			continue
+		}
+		if rsrp, ok := ssbRsrp(&servingMo.MeasResultServingCell); ok {
+			report.ServingRsrp = &rsrp
+		}
+	}
+	neighCells := results.MeasResultNeighCells
+	if neighCells != nil && neighCells.Choice == rrcies.MeasResults_measResultNeighCells_Choice_MeasResultListNR &&
+		neighCells.MeasResultListNR != nil {
+		for i := range neighCells.MeasResultListNR.Value {
+			result := &neighCells.MeasResultListNR.Value[i]
+			if rsrp, ok := ssbRsrp(result); ok && result.PhysCellId != nil {
+				report.Neighbours = append(report.Neighbours, uecontext.CellRsrp{Pci: uint16(result.PhysCellId.Value), Rsrp: rsrp})
+			}
+		}
+	}
+	slices.SortStableFunc(report.Neighbours, func(a, b uecontext.CellRsrp) int { return cmp.Compare(b.Rsrp, a.Rsrp) })
+	return report, nil
+}
+
+// ssbRsrp is the cell level SSB RSRP of a measurement result, if reported
+func ssbRsrp(result *rrcies.MeasResultNR) (uint64, bool) {
+	if result.MeasResult == nil || result.MeasResult.CellResults == nil {
+		return 0, false
+	}
+	ssb := result.MeasResult.CellResults.ResultsSSB_Cell
+	if ssb == nil || ssb.Rsrp == nil {
+		return 0, false
+	}
+	return ssb.Rsrp.Value, true
+}
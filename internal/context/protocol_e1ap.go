@@ -0,0 +1,865 @@
+package context
+
+import (
+	"encoding/binary"
+	"fmt"
+	"slices"
+
+	"central-unit/internal/common/logger"
+	"central-unit/internal/context/cuup"
+	"central-unit/internal/context/uecontext"
+	"central-unit/pkg/e1ap"
+	"central-unit/pkg/e1ap/ies"
+
+	"github.com/ishidawataru/sctp"
+	"github.com/lvdund/ngap/aper"
+	ngapies "github.com/lvdund/ngap/ies"
+)
+
+func (cu *CuCpContext) handleE1SetupRequest(setupReq *ies.GNBCUUPE1SetupRequest, diagnostics *ies.CriticalityDiagnostics, conn *sctp.SCTPConn) {
+	transactionID := setupReq.TransactionID
+	cuUpId := setupReq.GNBCUUPID
+	cuUpName := string(setupReq.GNBCUUPName)
+	cu.Info("Received E1 Setup Request from gNB-CU-UP %d (%s)", cuUpId, cuUpName)
+
+	cuupCtx := &cuup.GNBCUUP{
+		Logger:         logger.InitLogger("info", map[string]string{"mod": "cuup"}),
+		CuUpId:         cuUpId,
+		CuUpName:       cuUpName,
+		State:          cuup.CUUP_INACTIVE,
+		SctpConn:       conn,
+		SetupReq:       setupReq,
+		CNSupport:      setupReq.CNSupport,
+		SupportedPLMNs: setupReq.SupportedPLMNs,
+		Capacity:       -1,
+	}
+	if setupReq.GNBCUUPCapacity != nil {
+		cuupCtx.Capacity = *setupReq.GNBCUUPCapacity
+	}
+
+	reject := func(cause ies.Cause, diag *ies.CriticalityDiagnostics) {
+		if err := cuupCtx.SendE1SetupFailure(transactionID, &cause, diag); err != nil {
+			cu.Error("Error sending E1 Setup Failure: %v", err)
+		}
+	}
+
+	if diagnostics != nil {
+		cu.Error("E1 Setup Request from gNB-CU-UP %d has not comprehended IEs, rejecting", cuUpId)
+		reject(ies.Cause{
+			Choice:   ies.CausePresentProtocol,
+			Protocol: &ies.CauseProtocol{Value: ies.CauseProtocolAbstractsyntaxerrorreject},
+		}, diagnostics)
+		return
+	}
+
+	if setupReq.CNSupport.Value == ies.CNSupportCepc {
+		cu.Error("gNB-CU-UP %d only supports EPC, rejecting", cuUpId)
+		reject(ies.Cause{
+			Choice:       ies.CausePresentRadioNetwork,
+			RadioNetwork: &ies.CauseRadioNetwork{Value: ies.CauseRadioNetworkUnspecified},
+		}, nil)
+		return
+	}
+
+	cuPLMNBytes := cu.GetMccAndMncInOctets()
+	if !cuupCtx.SupportsPLMN(cuPLMNBytes) {
+		cu.Error("PLMN mismatch: CU-CP %s.%s not served by gNB-CU-UP %d", cu.ControlInfo.mcc, cu.ControlInfo.mnc, cuUpId)
+		reject(ies.Cause{
+			Choice:       ies.CausePresentRadioNetwork,
+			RadioNetwork: &ies.CauseRadioNetwork{Value: ies.CauseRadioNetworkUnspecified},
+		}, nil)
+		return
+	}
+
+	if existing, err := cu.GetCUUPById(cuUpId); err == nil && existing.SctpConn != conn && existing.IsActive() {
+		cu.Error("gNB-CU-UP ID %d already in use by %s, rejecting", cuUpId, existing.CuUpName)
+		reject(ies.Cause{
+			Choice:   ies.CausePresentProtocol,
+			Protocol: &ies.CauseProtocol{Value: ies.CauseProtocolMessagenotcompatiblewithreceiverstate},
+		}, nil)
+		return
+	}
+
+	cuupCtx.State = cuup.CUUP_ACTIVE
+	cu.CuUpPool.Store(cuUpId, cuupCtx)
+	cu.E1ConnMap.Store(conn, cuUpId)
+	cu.Info("==== Store CU-UP %d ====", cuUpId)
+
+	if err := cuupCtx.SendE1SetupResponse(transactionID, cu.ControlInfo.cu_name); err != nil {
+		cu.Error("Error sending E1 Setup Response: %v", err)
+	} else {
+		cu.Info("E1 Setup Procedure successfully with CU-UP %d (%s)", cuUpId, cuUpName)
+	}
+}
+
+// maxBitRate is the largest BitRate value, used when the AMF did not signal a UE-AMBR
+const maxBitRate int64 = 4000000000000
+
+// setupBearerContext asks a CU-UP for the user plane resources of the given
+// PDU sessions. The first sessions of a UE create its bearer context, later
+// ones are added to it with Bearer Context Modification. Sessions requested
+// while the Bearer Context Setup is unanswered wait for its response: the
+// CU-UP has not given its gNB-CU-UP UE E1AP ID yet.
+func (cu *CuCpContext) setupBearerContext(ue *uecontext.GNBUe, sessions []*uecontext.PduSessionContext) error {
+	if ue.BearerSetupSent {
+		ue.BearerSetupQueue = append(ue.BearerSetupQueue, sessions...)
+		cu.Info("%d PDU session(s) of UE CU-CP-E1AP-ID=%d wait for the Bearer Context Setup Response",
+			len(sessions), ue.GnbCuCpUeE1apId)
+		return nil
+	}
+	if ue.HasBearerContext {
+		return cu.addBearerContextSessions(ue, sessions)
+	}
+
+	cuupCtx, err := cu.GetActiveCUUP()
+	if err != nil {
+		return err
+	}
+
+	items := make([]ies.PDUSessionResourceToSetupItem, 0, len(sessions))
+	for _, ps := range sessions {
+		item := ies.PDUSessionResourceToSetupItem{
+			PDUSessionID:         int64(ps.PduSessionId),
+			PDUSessionType:       ies.PDUSessionType{Value: ps.PduSessionType},
+			SNSSAI:               e1Snssai(ps.Snssai),
+			SecurityIndication:   e1SecurityIndication(ps.SecurityIndication),
+			NGULUPTNLInformation: e1Tunnel(ps.UlNguTunnel),
+			DRBToSetupListNGRAN:  buildDrbsToSetup(ps),
+		}
+		if ps.Ambr != nil {
+			item.PDUSessionResourceDLAMBR = &ps.Ambr.PDUSessionAggregateMaximumBitRateDL
+		}
+		items = append(items, item)
+	}
+
+	ueDlAmbr := maxBitRate
+	if ue.UeAmbr != nil {
+		ueDlAmbr = ue.UeAmbr.UEAggregateMaximumBitRateDL
+	}
+
+	msg := ies.BearerContextSetupRequest{
+		GNBCUCPUEE1APID:             int64(ue.GnbCuCpUeE1apId),
+		SecurityInformation:         e1SecurityInformation(ue.AsSecurity),
+		UEDLAggregateMaximumBitRate: ueDlAmbr,
+		ServingPLMN:                 cu.GetMccAndMncInOctets(),
+		ActivityNotificationLevel:   ies.ActivityNotificationLevel{Value: ies.ActivityNotificationLevelUe},
+		SystemBearerContextSetupRequest: ies.SystemBearerContextSetupRequest{
+			Choice: ies.SystemBearerContextSetupRequestPresentNGRANBearerContextSetupRequest,
+			NGRANBearerContextSetupRequest: &ies.NGRANBearerContextSetupRequest{
+				PDUSessionResourceToSetupList: items,
+			},
+		},
+	}
+
+	buf, err := e1ap.E1apEncode(&msg)
+	if err != nil {
+		return fmt.Errorf("encode Bearer Context Setup Request: %w", err)
+	}
+	if err = cuupCtx.SendE1ap(buf); err != nil {
+		return fmt.Errorf("send Bearer Context Setup Request to CU-UP %d: %w", cuupCtx.CuUpId, err)
+	}
+
+	ue.CuUpId = cuupCtx.CuUpId
+	ue.BearerSetupSent = true
+	cu.Info("Bearer Context Setup Request sent to CU-UP %d for UE CU-CP-E1AP-ID=%d", cuupCtx.CuUpId, ue.GnbCuCpUeE1apId)
+	return nil
+}
+
+// addBearerContextSessions adds PDU sessions to the bearer context of the UE
+func (cu *CuCpContext) addBearerContextSessions(ue *uecontext.GNBUe, sessions []*uecontext.PduSessionContext) error {
+	items := make([]ies.PDUSessionResourceToSetupModItem, 0, len(sessions))
+	for _, ps := range sessions {
+		item := ies.PDUSessionResourceToSetupModItem{
+			PDUSessionID:           int64(ps.PduSessionId),
+			PDUSessionType:         ies.PDUSessionType{Value: ps.PduSessionType},
+			SNSSAI:                 e1Snssai(ps.Snssai),
+			SecurityIndication:     e1SecurityIndication(ps.SecurityIndication),
+			NGULUPTNLInformation:   e1Tunnel(ps.UlNguTunnel),
+			DRBToSetupModListNGRAN: buildDrbsToSetup(ps),
+		}
+		if ps.Ambr != nil {
+			item.PDUSessionResourceAMBR = &ps.Ambr.PDUSessionAggregateMaximumBitRateDL
+		}
+		items = append(items, item)
+	}
+	return cu.sendBearerContextModificationRequest(ue, &ies.NGRANBearerContextModificationRequest{
+		PDUSessionResourceToSetupModList: items,
+	})
+}
+
+// addQueuedBearerSessions adds the sessions that waited for the Bearer Context
+// Setup Response to the bearer context, unless they were dropped meanwhile
+func (cu *CuCpContext) addQueuedBearerSessions(ue *uecontext.GNBUe) {
+	var sessions []*uecontext.PduSessionContext
+	for _, ps := range ue.BearerSetupQueue {
+		if ue.PduSessions[ps.PduSessionId] == ps && ps.State == uecontext.PDU_SESSION_ESTABLISHING {
+			sessions = append(sessions, ps)
+		}
+	}
+	ue.BearerSetupQueue = nil
+	if len(sessions) == 0 {
+		return
+	}
+	if err := cu.addBearerContextSessions(ue, sessions); err != nil {
+		cu.Error("Failed to add PDU sessions to the bearer context: %v", err)
+		for _, ps := range sessions {
+			cu.failPduSessionSetup(ue, ps.PduSessionId, userPlaneFailureCause())
+		}
+	}
+}
+
+// sendBearerContextDlF1uTunnels gives the CU-UP the DU side of the F1-U
+// tunnels once the DU has set up the DRBs, and drops the DRBs it refused
+func (cu *CuCpContext) sendBearerContextDlF1uTunnels(
+	ue *uecontext.GNBUe,
+	sessions []*uecontext.PduSessionContext,
+	removedDrbs map[uint8][]uint8,
+) error {
+	return cu.sendBearerContextModificationRequest(ue, dlF1uTunnelsModification(sessions, removedDrbs))
+}
+
+func dlF1uTunnelsModification(
+	sessions []*uecontext.PduSessionContext,
+	removedDrbs map[uint8][]uint8,
+) *ies.NGRANBearerContextModificationRequest {
+	items := make([]ies.PDUSessionResourceToModifyItem, 0, len(sessions))
+	for _, ps := range sessions {
+		item := ies.PDUSessionResourceToModifyItem{PDUSessionID: int64(ps.PduSessionId)}
+		for _, drb := range ps.Drbs {
+			if drb.DlF1uTunnel == nil {
+				continue
+			}
+			item.DRBToModifyListNGRAN = append(item.DRBToModifyListNGRAN, ies.DRBToModifyItemNGRAN{
+				DRBID: int64(drb.DrbId),
+				DLUPParameters: []ies.UPParametersItem{{
+					UPTNLInformation: e1Tunnel(drb.DlF1uTunnel),
+					CellGroupID:      0,
+				}},
+			})
+		}
+		for _, drbId := range removedDrbs[ps.PduSessionId] {
+			item.DRBToRemoveListNGRAN = append(item.DRBToRemoveListNGRAN, ies.DRBToRemoveItemNGRAN{DRBID: int64(drbId)})
+		}
+		items = append(items, item)
+	}
+	return &ies.NGRANBearerContextModificationRequest{PDUSessionResourceToModifyList: items}
+}
+
+// modifyBearerContext passes the QoS flow changes of PDU sessions being
+// modified to the CU-UP: DRBs set up for added flows, DRBs whose flow mapping
+// changed and DRBs left without flows
+func (cu *CuCpContext) modifyBearerContext(ue *uecontext.GNBUe, sessions []*uecontext.PduSessionContext) error {
+	items := make([]ies.PDUSessionResourceToModifyItem, 0, len(sessions))
+	for _, ps := range sessions {
+		mod := ps.Modification
+		item := ies.PDUSessionResourceToModifyItem{PDUSessionID: int64(ps.PduSessionId)}
+		if mod.Ambr != nil {
+			item.PDUSessionResourceDLAMBR = &mod.Ambr.PDUSessionAggregateMaximumBitRateDL
+		}
+		for _, drb := range ps.Drbs {
+			if slices.Contains(mod.NewDrbs, drb.DrbId) {
+				item.DRBToSetupListNGRAN = append(item.DRBToSetupListNGRAN, buildDrbToSetup(drb))
+			} else if slices.Contains(mod.ModifiedDrbs, drb.DrbId) {
+				item.DRBToModifyListNGRAN = append(item.DRBToModifyListNGRAN, ies.DRBToModifyItemNGRAN{
+					DRBID:                  int64(drb.DrbId),
+					FlowMappingInformation: e1FlowMapping(drb),
+				})
+			}
+		}
+		for _, drbId := range mod.ReleasedDrbs {
+			item.DRBToRemoveListNGRAN = append(item.DRBToRemoveListNGRAN, ies.DRBToRemoveItemNGRAN{DRBID: int64(drbId)})
+		}
+		items = append(items, item)
+	}
+	return cu.sendBearerContextModificationRequest(ue, &ies.NGRANBearerContextModificationRequest{
+		PDUSessionResourceToModifyList: items,
+	})
+}
+
+// removeBearerContextSessions drops PDU sessions from the bearer context,
+// releasing the whole context when the UE has no session left
+func (cu *CuCpContext) removeBearerContextSessions(ue *uecontext.GNBUe, pduSessionIds []uint8) error {
+	if !ue.HasBearerContext || len(pduSessionIds) == 0 {
+		return nil
+	}
+	if len(ue.PduSessions) == 0 {
+		return cu.sendBearerContextReleaseCommand(ue, ies.Cause{
+			Choice:       ies.CausePresentRadioNetwork,
+			RadioNetwork: &ies.CauseRadioNetwork{Value: ies.CauseRadioNetworkNormalrelease},
+		})
+	}
+
+	items := make([]ies.PDUSessionResourceToRemoveItem, 0, len(pduSessionIds))
+	for _, id := range pduSessionIds {
+		items = append(items, ies.PDUSessionResourceToRemoveItem{PDUSessionID: int64(id)})
+	}
+	return cu.sendBearerContextModificationRequest(ue, &ies.NGRANBearerContextModificationRequest{
+		PDUSessionResourceToRemoveList: items,
+	})
+}
+
+func (cu *CuCpContext) sendBearerContextModificationRequest(ue *uecontext.GNBUe, req *ies.NGRANBearerContextModificationRequest) error {
+	return cu.sendBearerContextModification(ue, &ies.BearerContextModificationRequest{
+		SystemBearerContextModificationRequest: &ies.SystemBearerContextModificationRequest{
+			Choice:                                ies.SystemBearerContextModificationRequestPresentNGRANBearerContextModificationRequest,
+			NGRANBearerContextModificationRequest: req,
+		},
+	})
+}
+
+// sendBearerContextModification sends a Bearer Context Modification Request
+// for the bearer context of the UE
+func (cu *CuCpContext) sendBearerContextModification(ue *uecontext.GNBUe, msg *ies.BearerContextModificationRequest) error {
+	cuupCtx, err := cu.GetCUUPById(ue.CuUpId)
+	if err != nil {
+		return err
+	}
+
+	msg.GNBCUCPUEE1APID = int64(ue.GnbCuCpUeE1apId)
+	msg.GNBCUUPUEE1APID = int64(ue.GnbCuUpUeE1apId)
+	buf, err := e1ap.E1apEncode(msg)
+	if err != nil {
+		return fmt.Errorf("encode Bearer Context Modification Request: %w", err)
+	}
+	if err = cuupCtx.SendE1ap(buf); err != nil {
+		return fmt.Errorf("send Bearer Context Modification Request to CU-UP %d: %w", cuupCtx.CuUpId, err)
+	}
+
+	cu.Info("Bearer Context Modification Request sent to CU-UP %d for UE CU-CP-E1AP-ID=%d", cuupCtx.CuUpId, ue.GnbCuCpUeE1apId)
+	return nil
+}
+
+func (cu *CuCpContext) sendBearerContextReleaseCommand(ue *uecontext.GNBUe, cause ies.Cause) error {
+	cuupCtx, err := cu.GetCUUPById(ue.CuUpId)
+	if err != nil {
+		return err
+	}
+
+	msg := ies.BearerContextReleaseCommand{
+		GNBCUCPUEE1APID: int64(ue.GnbCuCpUeE1apId),
+		GNBCUUPUEE1APID: int64(ue.GnbCuUpUeE1apId),
+		Cause:           cause,
+	}
+
+	buf, err := e1ap.E1apEncode(&msg)
+	if err != nil {
+		return fmt.Errorf("encode Bearer Context Release Command: %w", err)
+	}
+	if err = cuupCtx.SendE1ap(buf); err != nil {
+		return fmt.Errorf("send Bearer Context Release Command to CU-UP %d: %w", cuupCtx.CuUpId, err)
+	}
+
+	cu.Info("Bearer Context Release Command sent to CU-UP %d for UE CU-CP-E1AP-ID=%d", cuupCtx.CuUpId, ue.GnbCuCpUeE1apId)
+	return nil
+}
+
+func (cu *CuCpContext) handleBearerContextSetupResponse(msg *ies.BearerContextSetupResponse) {
+	ue, err := cu.GetUEByE1Id(msg.GNBCUCPUEE1APID)
+	if err != nil {
+		cu.Error("Bearer Context Setup Response for unknown UE: %v", err)
+		return
+	}
+
+	ue.GnbCuUpUeE1apId = uint64(msg.GNBCUUPUEE1APID)
+	ue.HasBearerContext = true
+	ue.BearerSetupSent = false
+	cu.Info("Bearer context established at CU-UP %d (CU-UP-E1AP-ID=%d)", ue.CuUpId, ue.GnbCuUpUeE1apId)
+
+	// the UE was released while the CU-UP was setting up its bearer context
+	if ue.State == uecontext.UE_DOWN {
+		ue.BearerSetupQueue = nil
+		err = cu.sendBearerContextReleaseCommand(ue, ies.Cause{
+			Choice:       ies.CausePresentRadioNetwork,
+			RadioNetwork: &ies.CauseRadioNetwork{Value: ies.CauseRadioNetworkNormalrelease},
+		})
+		if err != nil {
+			cu.Error("Failed to release the bearer context: %v", err)
+			cu.RemoveUE(ue)
+		}
+		return
+	}
+
+	resp := msg.SystemBearerContextSetupResponse.NGRANBearerContextSetupResponse
+	if resp == nil {
+		cu.Error("Bearer Context Setup Response carries no NG-RAN bearer context")
+		ue.BearerSetupQueue = nil
+		cu.failEstablishingPduSessions(ue, false)
+		return
+	}
+
+	// sessions released before the CU-UP answered are removed from it again
+	var stale []uint8
+	for _, item := range resp.PDUSessionResourceSetupList {
+		if _, ok := ue.PduSessions[uint8(item.PDUSessionID)]; !ok {
+			stale = append(stale, uint8(item.PDUSessionID))
+			continue
+		}
+		cu.applyBearerContextSetup(ue, item.PDUSessionID, item.NGDLUPTNLInformation, item.DRBSetupListNGRAN, item.DRBFailedListNGRAN)
+	}
+	for _, item := range resp.PDUSessionResourceFailedList {
+		cu.Error("CU-UP failed to set up PDU Session ID=%d", item.PDUSessionID)
+		cu.failPduSessionSetup(ue, uint8(item.PDUSessionID), userPlaneFailureCause())
+	}
+	if err = cu.removeBearerContextSessions(ue, stale); err != nil {
+		cu.Error("Failed to remove released PDU sessions from the bearer context: %v", err)
+	}
+	cu.addQueuedBearerSessions(ue)
+	cu.reportPduSessionSetupFailures(ue)
+}
+
+func (cu *CuCpContext) handleBearerContextSetupFailure(msg *ies.BearerContextSetupFailure) {
+	ue, err := cu.GetUEByE1Id(msg.GNBCUCPUEE1APID)
+	if err != nil {
+		cu.Error("Bearer Context Setup Failure for unknown UE: %v", err)
+		return
+	}
+
+	cu.Error("CU-UP %d rejected Bearer Context Setup for UE CU-CP-E1AP-ID=%d (cause choice %d)",
+		ue.CuUpId, ue.GnbCuCpUeE1apId, msg.Cause.Choice)
+	ue.BearerSetupSent = false
+	ue.BearerSetupQueue = nil
+	if ue.State == uecontext.UE_DOWN {
+		cu.RemoveUE(ue)
+		return
+	}
+	// the sessions that waited for the response are lost with the others
+	cu.failEstablishingPduSessions(ue, false)
+	cu.reportPduSessionSetupFailures(ue)
+}
+
+func (cu *CuCpContext) handleBearerContextModificationResponse(msg *ies.BearerContextModificationResponse) {
+	ue, err := cu.GetUEByE1Id(msg.GNBCUCPUEE1APID)
+	if err != nil {
+		cu.Error("Bearer Context Modification Response for unknown UE: %v", err)
+		return
+	}
+
+	if msg.SystemBearerContextModificationResponse == nil ||
+		msg.SystemBearerContextModificationResponse.NGRANBearerContextModificationResponse == nil {
+		cu.Info("Bearer Context Modification acknowledged by CU-UP %d", ue.CuUpId)
+		return
+	}
+	resp := msg.SystemBearerContextModificationResponse.NGRANBearerContextModificationResponse
+
+	for _, item := range resp.PDUSessionResourceSetupModList {
+		cu.applyBearerContextSetup(ue, item.PDUSessionID, item.NGDLUPTNLInformation, item.DRBSetupModListNGRAN, item.DRBFailedModListNGRAN)
+	}
+	for _, item := range resp.PDUSessionResourceFailedModList {
+		cu.Error("CU-UP failed to add PDU Session ID=%d to the bearer context", item.PDUSessionID)
+		cu.failPduSessionSetup(ue, uint8(item.PDUSessionID), userPlaneFailureCause())
+	}
+	for _, item := range resp.PDUSessionResourceModifiedList {
+		if ps := modifyingPduSession(ue, item.PDUSessionID, uecontext.PDU_SESSION_MODIFY_CUUP); ps != nil {
+			cu.applyBearerContextModify(ue, ps, &item)
+			continue
+		}
+		cu.Info("PDU Session ID=%d modified at CU-UP", item.PDUSessionID)
+	}
+	for _, item := range resp.PDUSessionResourceFailedToModifyList {
+		cu.Error("CU-UP failed to modify PDU Session ID=%d", item.PDUSessionID)
+		if ps := modifyingPduSession(ue, item.PDUSessionID, uecontext.PDU_SESSION_MODIFY_CUUP); ps != nil {
+			cu.abortPduSessionModify(ue, ps, userPlaneFailureCause())
+		}
+	}
+	cu.reportPduSessionSetupFailures(ue)
+	cu.reportPduSessionModify(ue)
+}
+
+func (cu *CuCpContext) handleBearerContextModificationFailure(msg *ies.BearerContextModificationFailure) {
+	ue, err := cu.GetUEByE1Id(msg.GNBCUCPUEE1APID)
+	if err != nil {
+		cu.Error("Bearer Context Modification Failure for unknown UE: %v", err)
+		return
+	}
+
+	cu.Error("CU-UP %d rejected Bearer Context Modification for UE CU-CP-E1AP-ID=%d (cause choice %d)",
+		ue.CuUpId, ue.GnbCuCpUeE1apId, msg.Cause.Choice)
+	// Only sessions still waiting for their CU-UP tunnels are lost, the ones
+	// already set up keep the user plane they have
+	cu.failEstablishingPduSessions(ue, true)
+	cu.reportPduSessionSetupFailures(ue)
+
+	for id := range ue.PduSessions {
+		if ps := modifyingPduSession(ue, int64(id), uecontext.PDU_SESSION_MODIFY_CUUP); ps != nil {
+			cu.abortPduSessionModify(ue, ps, userPlaneFailureCause())
+		}
+	}
+	cu.reportPduSessionModify(ue)
+}
+
+func (cu *CuCpContext) handleBearerContextReleaseComplete(msg *ies.BearerContextReleaseComplete) {
+	ue, err := cu.GetUEByE1Id(msg.GNBCUCPUEE1APID)
+	if err != nil {
+		cu.Error("Bearer Context Release Complete for unknown UE: %v", err)
+		return
+	}
+
+	ue.HasBearerContext = false
+	ue.GnbCuUpUeE1apId = 0
+	cu.Info("Bearer context of UE CU-CP-E1AP-ID=%d released at CU-UP %d", ue.GnbCuCpUeE1apId, ue.CuUpId)
+
+	// The rest of a released UE is gone already, the CU-UP was the last to answer
+	if ue.State == uecontext.UE_DOWN {
+		cu.RemoveUE(ue)
+	}
+}
+
+// applyBearerContextSetup stores the CU-UP tunnels of a PDU session and
+// continues its setup towards the DU. DRBs the CU-UP could not set up are
+// dropped from the session, which fails if its default DRB is among them.
+func (cu *CuCpContext) applyBearerContextSetup(
+	ue *uecontext.GNBUe,
+	pduSessionId int64,
+	ngDlTunnel ies.UPTNLInformation,
+	drbs []ies.DRBSetupItemNGRAN,
+	failedDrbs []ies.DRBFailedItemNGRAN,
+) {
+	pduSession, ok := ue.PduSessions[uint8(pduSessionId)]
+	if !ok {
+		cu.Warn("CU-UP set up unknown PDU Session ID=%d", pduSessionId)
+		return
+	}
+	if pduSession.State != uecontext.PDU_SESSION_ESTABLISHING {
+		cu.Warn("PDU Session ID=%d is no longer being set up, ignoring CU-UP tunnels", pduSessionId)
+		return
+	}
+
+	pduSession.DlNguTunnel = gtpTunnelFromE1(ngDlTunnel)
+	for _, item := range drbs {
+		drb := pduSession.GetDrb(uint8(item.DRBID))
+		if drb == nil {
+			cu.Warn("CU-UP set up unknown DRB ID=%d for PDU Session ID=%d", item.DRBID, pduSessionId)
+			continue
+		}
+		if len(item.ULUPTransportParameters) > 0 {
+			drb.UlF1uTunnel = gtpTunnelFromE1(item.ULUPTransportParameters[0].UPTNLInformation)
+		}
+		for _, failed := range item.FlowFailedList {
+			for _, flow := range drb.QosFlows {
+				if int64(flow.Qfi) == failed.QoSFlowIdentifier {
+					cu.Warn("CU-UP did not admit QoS flow %d of PDU Session ID=%d", flow.Qfi, pduSessionId)
+					flow.Failed = true
+				}
+			}
+		}
+	}
+	for _, item := range failedDrbs {
+		cu.Warn("CU-UP could not set up DRB ID=%d of PDU Session ID=%d", item.DRBID, pduSessionId)
+	}
+
+	// Keep the DRBs that got an F1-U tunnel and still carry an admitted flow
+	var removed []uint8
+	for _, drb := range slices.Clone(pduSession.Drbs) {
+		admitted := slices.ContainsFunc(drb.QosFlows, func(flow *uecontext.QosFlowContext) bool {
+			return !flow.Failed
+		})
+		if drb.UlF1uTunnel != nil && admitted {
+			continue
+		}
+		pduSession.RemoveDrb(drb.DrbId)
+		ue.ReleaseDrbId(drb.DrbId)
+		if drb.UlF1uTunnel != nil {
+			removed = append(removed, drb.DrbId)
+		}
+	}
+
+	var err error
+	if pduSession.DlNguTunnel == nil {
+		err = fmt.Errorf("CU-UP returned no NG-U tunnel")
+	} else if !pduSession.HasDefaultDrb() {
+		err = fmt.Errorf("CU-UP did not set up the default DRB")
+	} else {
+		if len(removed) > 0 {
+			if err := cu.removeBearerContextDrbs(ue, pduSession.PduSessionId, removed); err != nil {
+				cu.Error("Failed to remove DRBs of PDU Session ID=%d from the bearer context: %v", pduSessionId, err)
+			}
+		}
+		err = cu.sendF1UEContextModificationRequest(ue, pduSession)
+	}
+	if err == nil {
+		return
+	}
+
+	cu.Error("PDU Session ID=%d setup failed: %v", pduSessionId, err)
+	cu.failPduSessionSetup(ue, pduSession.PduSessionId, userPlaneFailureCause())
+	if err := cu.removeBearerContextSessions(ue, []uint8{pduSession.PduSessionId}); err != nil {
+		cu.Error("Failed to remove PDU Session ID=%d from the bearer context: %v", pduSessionId, err)
+	}
+}
+
+// applyBearerContextModify takes the CU-UP answer for a PDU session being
+// modified. Flows the CU-UP rejected are reported to the SMF, new DRBs left
+// without an F1-U tunnel or an admitted flow are dropped.
+func (cu *CuCpContext) applyBearerContextModify(
+	ue *uecontext.GNBUe,
+	pduSession *uecontext.PduSessionContext,
+	item *ies.PDUSessionResourceModifiedItem,
+) {
+	mod := pduSession.Modification
+	failDrbFlows := func(drbId int64) {
+		if drb := pduSession.GetDrb(uint8(drbId)); drb != nil {
+			for _, flow := range slices.Clone(drb.QosFlows) {
+				failModifiedQosFlow(pduSession, flow.Qfi, userPlaneFailureCause())
+			}
+		}
+	}
+
+	for _, setup := range item.DRBSetupListNGRAN {
+		drb := pduSession.GetDrb(uint8(setup.DRBID))
+		if drb == nil || !slices.Contains(mod.NewDrbs, drb.DrbId) {
+			cu.Warn("CU-UP set up unknown DRB ID=%d for PDU Session ID=%d", setup.DRBID, item.PDUSessionID)
+			continue
+		}
+		if len(setup.ULUPTransportParameters) > 0 {
+			drb.UlF1uTunnel = gtpTunnelFromE1(setup.ULUPTransportParameters[0].UPTNLInformation)
+		}
+		for _, failed := range setup.FlowFailedList {
+			failModifiedQosFlow(pduSession, uint8(failed.QoSFlowIdentifier), userPlaneFailureCause())
+		}
+	}
+	for _, failed := range item.DRBFailedListNGRAN {
+		cu.Warn("CU-UP could not set up DRB ID=%d of PDU Session ID=%d", failed.DRBID, item.PDUSessionID)
+		failDrbFlows(failed.DRBID)
+	}
+	for _, modified := range item.DRBModifiedListNGRAN {
+		for _, failed := range modified.FlowFailedList {
+			failModifiedQosFlow(pduSession, uint8(failed.QoSFlowIdentifier), userPlaneFailureCause())
+		}
+	}
+	for _, failed := range item.DRBFailedToModifyListNGRAN {
+		cu.Warn("CU-UP could not modify DRB ID=%d of PDU Session ID=%d", failed.DRBID, item.PDUSessionID)
+		failDrbFlows(failed.DRBID)
+	}
+
+	var removed []uint8
+	for _, drbId := range slices.Clone(mod.NewDrbs) {
+		drb := pduSession.GetDrb(drbId)
+		if drb.UlF1uTunnel != nil && len(drb.QosFlows) > 0 {
+			continue
+		}
+		for _, flow := range slices.Clone(drb.QosFlows) {
+			failModifiedQosFlow(pduSession, flow.Qfi, userPlaneFailureCause())
+		}
+		pduSession.RemoveDrb(drbId)
+		ue.ReleaseDrbId(drbId)
+		mod.NewDrbs = slices.DeleteFunc(mod.NewDrbs, func(id uint8) bool { return id == drbId })
+		if drb.UlF1uTunnel != nil {
+			removed = append(removed, drbId)
+		}
+	}
+	if len(removed) > 0 {
+		if err := cu.removeBearerContextDrbs(ue, pduSession.PduSessionId, removed); err != nil {
+			cu.Error("Failed to remove DRBs of PDU Session ID=%d from the bearer context: %v", item.PDUSessionID, err)
+		}
+	}
+
+	cu.continuePduSessionModify(ue, pduSession)
+}
+
+// removeBearerContextDrbs drops DRBs of a PDU session that stays set up
+func (cu *CuCpContext) removeBearerContextDrbs(ue *uecontext.GNBUe, pduSessionId uint8, drbIds []uint8) error {
+	items := make([]ies.DRBToRemoveItemNGRAN, 0, len(drbIds))
+	for _, id := range drbIds {
+		items = append(items, ies.DRBToRemoveItemNGRAN{DRBID: int64(id)})
+	}
+	return cu.sendBearerContextModificationRequest(ue, &ies.NGRANBearerContextModificationRequest{
+		PDUSessionResourceToModifyList: []ies.PDUSessionResourceToModifyItem{{
+			PDUSessionID:         int64(pduSessionId),
+			DRBToRemoveListNGRAN: items,
+		}},
+	})
+}
+
+// failEstablishingPduSessions fails the PDU sessions still being set up. With
+// pendingOnly, sessions the CU-UP already answered for are left alone.
+func (cu *CuCpContext) failEstablishingPduSessions(ue *uecontext.GNBUe, pendingOnly bool) {
+	for id, ps := range ue.PduSessions {
+		if ps.State != uecontext.PDU_SESSION_ESTABLISHING || (pendingOnly && ps.DlNguTunnel != nil) {
+			continue
+		}
+		cu.failPduSessionSetup(ue, id, userPlaneFailureCause())
+	}
+}
+
+func userPlaneFailureCause() ngapies.Cause {
+	return ngapies.Cause{
+		Choice: ngapies.CausePresentMisc,
+		Misc:   &ngapies.CauseMisc{Value: ngapies.CauseMiscNotenoughuserplaneprocessingresources},
+	}
+}
+
+// e1SecurityInformation gives the CU-UP the UP algorithms and keys of the UE.
+// A UE without AS security gets an unciphered user plane.
+func e1SecurityInformation(asCtx *uecontext.AsContext) ies.SecurityInformation {
+	if asCtx == nil {
+		return ies.SecurityInformation{
+			SecurityAlgorithm: ies.SecurityAlgorithm{
+				CipheringAlgorithm: ies.CipheringAlgorithm{Value: ies.CipheringAlgorithmNEA0},
+			},
+			UPSecuritykey: ies.UPSecuritykey{
+				EncryptionKey: make([]byte, 16),
+			},
+		}
+	}
+	return ies.SecurityInformation{
+		SecurityAlgorithm: ies.SecurityAlgorithm{
+			CipheringAlgorithm:           ies.CipheringAlgorithm{Value: aper.Enumerated(asCtx.CipheringAlg)},
+			IntegrityProtectionAlgorithm: &ies.IntegrityProtectionAlgorithm{Value: aper.Enumerated(asCtx.IntegrityAlg)},
+		},
+		UPSecuritykey: ies.UPSecuritykey{
+			EncryptionKey:          asCtx.KupEnc(),
+			IntegrityProtectionKey: asCtx.KupInt(),
+		},
+	}
+}
+
+func e1Snssai(snssai *ngapies.SNSSAI) ies.SNSSAI {
+	if snssai == nil {
+		return ies.SNSSAI{SST: []byte{0x01}}
+	}
+	return ies.SNSSAI{SST: snssai.SST, SD: snssai.SD}
+}
+
+// e1SecurityIndication maps the NGAP UP security policy, both enumerations
+// share the same values. Without a policy no UP protection is requested.
+func e1SecurityIndication(ind *ngapies.SecurityIndication) ies.SecurityIndication {
+	if ind == nil {
+		return ies.SecurityIndication{
+			IntegrityProtectionIndication:       ies.IntegrityProtectionIndication{Value: ies.IntegrityProtectionIndicationNotneeded},
+			ConfidentialityProtectionIndication: ies.ConfidentialityProtectionIndication{Value: ies.ConfidentialityProtectionIndicationNotneeded},
+		}
+	}
+	return ies.SecurityIndication{
+		IntegrityProtectionIndication:       ies.IntegrityProtectionIndication{Value: ind.IntegrityProtectionIndication.Value},
+		ConfidentialityProtectionIndication: ies.ConfidentialityProtectionIndication{Value: ind.ConfidentialityProtectionIndication.Value},
+	}
+}
+
+func e1Tunnel(tunnel *uecontext.GtpTunnel) ies.UPTNLInformation {
+	teid := make([]byte, 4)
+	binary.BigEndian.PutUint32(teid, tunnel.Teid)
+	return ies.UPTNLInformation{
+		Choice: ies.UPTNLInformationPresentGTPTunnel,
+		GTPTunnel: &ies.GTPTunnel{
+			TransportLayerAddress: aper.BitString{
+				Bytes:   tunnel.Address,
+				NumBits: uint64(len(tunnel.Address) * 8),
+			},
+			GTPTEID: teid,
+		},
+	}
+}
+
+func gtpTunnelFromE1(info ies.UPTNLInformation) *uecontext.GtpTunnel {
+	if info.GTPTunnel == nil || len(info.GTPTunnel.GTPTEID) != 4 {
+		return nil
+	}
+	return &uecontext.GtpTunnel{
+		Address: info.GTPTunnel.TransportLayerAddress.Bytes,
+		Teid:    binary.BigEndian.Uint32(info.GTPTunnel.GTPTEID),
+	}
+}
+
+// e1QosFlowParameters converts the QoS flow parameters received from the SMF.
+// NGAP and E1AP share the ARP and delay critical enumerations.
+func e1QosFlowParameters(flow *uecontext.QosFlowContext) ies.QoSFlowLevelQoSParameters {
+	params := ies.QoSFlowLevelQoSParameters{
+		NGRANAllocationRetentionPriority: ies.NGRANAllocationAndRetentionPriority{
+			PriorityLevel:           int64(flow.Priority),
+			PreEmptionCapability:    ies.PreEmptionCapability{Value: flow.PreemptionCapability},
+			PreEmptionVulnerability: ies.PreEmptionVulnerability{Value: flow.PreemptionVulnerability},
+		},
+	}
+
+	if dynamic := flow.Dynamic5Qi; dynamic != nil {
+		descriptor := &ies.Dynamic5QIDescriptor{
+			QoSPriorityLevel:   dynamic.PriorityLevelQos,
+			PacketDelayBudget:  dynamic.PacketDelayBudget,
+			PacketErrorRate:    ies.PacketErrorRate{PERScalar: dynamic.PacketErrorRate.PERScalar, PERExponent: dynamic.PacketErrorRate.PERExponent},
+			FiveQI:             dynamic.FiveQI,
+			AveragingWindow:    dynamic.AveragingWindow,
+			MaxDataBurstVolume: dynamic.MaximumDataBurstVolume,
+		}
+		if dynamic.DelayCritical != nil {
+			descriptor.DelayCritical = &ies.DelayCritical{Value: dynamic.DelayCritical.Value}
+		}
+		params.QoSCharacteristics = ies.QoSCharacteristics{
+			Choice:     ies.QoSCharacteristicsPresentDynamic5QI,
+			Dynamic5QI: descriptor,
+		}
+	} else {
+		params.QoSCharacteristics = ies.QoSCharacteristics{
+			Choice: ies.QoSCharacteristicsPresentNonDynamic5QI,
+			NonDynamic5QI: &ies.NonDynamic5QIDescriptor{
+				FiveQI:           flow.FiveQi,
+				QoSPriorityLevel: flow.PriorityLevelQos,
+			},
+		}
+	}
+
+	if gbr := flow.Gbr; gbr != nil {
+		params.GBRQoSFlowInformation = &ies.GBRQoSFlowInformation{
+			MaxFlowBitRateDownlink:        gbr.MaximumFlowBitRateDL,
+			MaxFlowBitRateUplink:          gbr.MaximumFlowBitRateUL,
+			GuaranteedFlowBitRateDownlink: gbr.GuaranteedFlowBitRateDL,
+			GuaranteedFlowBitRateUplink:   gbr.GuaranteedFlowBitRateUL,
+			MaxPacketLossRateDownlink:     gbr.MaximumPacketLossRateDL,
+			MaxPacketLossRateUplink:       gbr.MaximumPacketLossRateUL,
+		}
+	}
+	return params
+}
+
+// buildDrbsToSetup describes the DRBs of a PDU session and the QoS flows
+// mapped to each of them
+func buildDrbsToSetup(ps *uecontext.PduSessionContext) []ies.DRBToSetupItemNGRAN {
+	items := make([]ies.DRBToSetupItemNGRAN, 0, len(ps.Drbs))
+	for _, drb := range ps.Drbs {
+		items = append(items, buildDrbToSetup(drb))
+	}
+	return items
+}
+
+func buildDrbToSetup(drb *uecontext.DrbContext) ies.DRBToSetupItemNGRAN {
+	defaultDrb := ies.DefaultDRB{Value: ies.DefaultDRBFalse}
+	if drb.Default {
+		defaultDrb.Value = ies.DefaultDRBTrue
+	}
+
+	return ies.DRBToSetupItemNGRAN{
+		DRBID: int64(drb.DrbId),
+		SDAPConfiguration: ies.SDAPConfiguration{
+			DefaultDRB:   defaultDrb,
+			SDAPHeaderUL: ies.SDAPHeaderUL{Value: ies.SDAPHeaderULAbsent},
+			SDAPHeaderDL: ies.SDAPHeaderDL{Value: ies.SDAPHeaderDLAbsent},
+		},
+		PDCPConfiguration:           e1PdcpConfiguration(),
+		CellGroupInformation:        []ies.CellGroupInformationItem{{CellGroupID: 0}},
+		QoSFlowInformationToBeSetup: e1FlowMapping(drb),
+	}
+}
+
+// e1PdcpConfiguration mirrors the PDCP configuration of the DRBs sent to the
+// UE in RRC Reconfiguration
+func e1PdcpConfiguration() ies.PDCPConfiguration {
+	return ies.PDCPConfiguration{
+		PDCPSNSizeUL: ies.PDCPSNSize{Value: ies.PDCPSNSizeS18},
+		PDCPSNSizeDL: ies.PDCPSNSize{Value: ies.PDCPSNSizeS18},
+		RLCMode:      ies.RLCMode{Value: ies.RLCModeRlcam},
+		TReorderingTimer: &ies.TReorderingTimer{
+			TReordering: ies.TReordering{Value: ies.TReorderingMs100},
+		},
+	}
+}
+
+// e1FlowMapping lists the QoS flows of a DRB with their parameters
+func e1FlowMapping(drb *uecontext.DrbContext) []ies.QoSFlowQoSParameterItem {
+	flows := make([]ies.QoSFlowQoSParameterItem, 0, len(drb.QosFlows))
+	for _, flow := range drb.QosFlows {
+		flows = append(flows, ies.QoSFlowQoSParameterItem{
+			QoSFlowIdentifier:         int64(flow.Qfi),
+			QoSFlowLevelQoSParameters: e1QosFlowParameters(flow),
+		})
+	}
+	return flows
+}
@@ -1,12 +1,18 @@
 package context
 
 import (
+	"bytes"
 	"central-unit/internal/common/logger"
+	"central-unit/internal/common/utils"
 	"central-unit/internal/context/du"
+	"central-unit/pkg/pdcp"
+	"errors"
 	"fmt"
 
 	"github.com/JocelynWS/f1-gen/ies"
 	"github.com/ishidawataru/sctp"
+	"github.com/lvdund/ngap/aper"
+	ngaputils "github.com/lvdund/ngap/utils"
 	"github.com/lvdund/rrc"
 	rrcies "github.com/lvdund/rrc/ies"
 )
@@ -15,112 +21,224 @@ func (cu *CuCpContext) handleF1SetupRequest(setupReq *ies.F1SetupRequest, conn *
 	transactionID := setupReq.TransactionID
 	duId := setupReq.GNBDUID
 	duName := string(setupReq.GNBDUName)
-	cellItem := setupReq.GNBDUServedCellsList[0]
-	cellInfo := cellItem.ServedCellInformation
-	cellID := cellInfo.NRCGI.NRCellIdentity
-	pci := int64(cellInfo.NRPCI.Value)
-	mtc := cellInfo.MeasurementTimingConfiguration
-	cu.Info("Received F1 Setup Request from gNB_DU %d (%s)", duId, duName)
-
-	numCells := len(setupReq.GNBDUServedCellsList)
-	if numCells != 1 {
-		cu.Error("can only handle one DU cell, but gNB_DU %d has %d", duId, numCells)
-		return
-	}
+	cu.Info("Received F1 Setup Request from gNB_DU %d (%s) with %d cell(s)",
+		duId, duName, len(setupReq.GNBDUServedCellsList))
 
-	if len(cellInfo.ServedPLMNs) == 0 {
-		cu.Error("No PLMN in served cell information")
-		return
-	}
-	cellPLMNBytes := cellInfo.ServedPLMNs[0].PLMNIdentity
-	// 10011001 11111001 00110111
-	// 10011001 11111001 00000111
+	var duCtx *du.GNBDU = &du.GNBDU{}
+	duCtx.Logger = logger.InitLogger("info", map[string]string{"mod": "du"})
+	duCtx.DuId = duId
+	duCtx.DuName = duName
+	duCtx.SetupReq = setupReq
+	duCtx.SctpConn = conn
 
-	cuPLMNBytes := cu.GetMccAndMncInOctets()
-	if !cu.plmnMatches(cellPLMNBytes, cuPLMNBytes) {
-		cu.Error("PLMN mismatch: CU %s.%s, DU %.8b", cu.ControlInfo.mcc, cu.ControlInfo.mnc, cuPLMNBytes)
+	if existing, err := cu.GetDUById(duId); err == nil && existing.SctpConn != conn && cu.isDUConnected(existing) {
+		cu.Error("gNB-DU ID: existing DU %s already has ID %d, rejecting requesting gNB-DU", existing.DuName, duId)
+		if err := duCtx.SendF1SetupFailure(transactionID, f1MiscCause(ies.CauseMiscUnspecified)); err != nil {
+			cu.Error("Error sending F1 Setup Failure: %v", err)
+		}
 		return
 	}
 
-	// existingDUs := make(map[int64]*du.GNBDU)
-	// cu.DuPool.Range(func(key, value any) bool {
-	// 	if d, ok := value.(*du.GNBDU); ok {
-	// 		existingDUs[d.DuId] = d
-	// 	}
-	// 	return true
-	// })
-	// if existingDU, exists := existingDUs[duId]; exists {
-	// 	cu.Error("gNB-DU ID: existing DU %s already has ID %d, rejecting requesting gNB-DU",
-	// 		existingDU.DuName, duId)
-	// 	return
-	// }
-
-	// for _, existingDU := range existingDUs {
-	// 	if len(existingDU.ServedCells) > 0 {
-	// 		existingCell := existingDU.ServedCells[0]
-	// 		if cu.cellIDsMatch(cellID, existingCell.CellID) || int64(existingCell.PCI) == pci {
-	// 			cu.Error("existing DU %s on already has cellID %d/physCellId %d, rejecting requesting gNB-DU with cellID %d/physCellId %d",
-	// 				existingDU.DuName, existingCell.CellID, existingCell.PCI, cellID, pci)
-	// 			return
-	// 		}
-	// 	}
-	// }
+	var cellsToActivate []ies.CellstobeActivatedListItem
+	var rejectedCells []ies.CellsFailedToBeActivatedListItem
+	for _, item := range setupReq.GNBDUServedCellsList {
+		cell := cu.decodeServedCell(item)
+		if cause, ok := cu.checkServedCell(duCtx, &cell); !ok {
+			rejectedCells = append(rejectedCells, ies.CellsFailedToBeActivatedListItem{NRCGI: cell.NRCGI, Cause: cause})
+			continue
+		}
+		cell.Active = true
+		duCtx.ServedCells = append(duCtx.ServedCells, cell)
+		cellsToActivate = append(cellsToActivate, ies.CellstobeActivatedListItem{
+			NRCGI: cell.NRCGI,
+			NRPCI: &ies.NRPCI{Value: int64(cell.PCI)},
+		})
+	}
 
-	var mib []byte
-	var sib1 []byte
-	if cellItem.GNBDUSystemInformation != nil {
-		mib = cellItem.GNBDUSystemInformation.MIBMessage
-		sib1 = cellItem.GNBDUSystemInformation.SIB1Message
+	// A DU may come up without cells, but not with none of them usable
+	if len(cellsToActivate) == 0 && len(rejectedCells) > 0 {
+		cu.Error("None of the %d cell(s) of DU %d (%s) can be activated", len(rejectedCells), duId, duName)
+		if err := duCtx.SendF1SetupFailure(transactionID, rejectedCells[0].Cause); err != nil {
+			cu.Error("Error sending F1 Setup Failure: %v", err)
+		}
+		return
 	}
 
-	cu.Info("Accepting DU %d (%s), sending F1 Setup Response", duId, duName)
+	cu.Info("Accepting DU %d (%s): %d cell(s) activated, %d rejected",
+		duId, duName, len(cellsToActivate), len(rejectedCells))
 	cu.Info("DU uses RRC version %x", setupReq.GNBDURRCVersion.LatestRRCVersion.Bytes)
 
-	var duCtx *du.GNBDU = &du.GNBDU{}
-	duCtx.Logger = logger.InitLogger("info", map[string]string{"mod": "du"})
-	duCtx.DuId = duId
-	duCtx.DuName = duName
-	duCtx.State = du.DU_ACTIVE
-	duCtx.SetupReq = setupReq
-	duCtx.MIB = mib
-	duCtx.SIB1 = sib1
-	duCtx.MTC = mtc
-	cellIDValue := cu.extractCellIDValue(cellID)
-	duCtx.ServedCells = []du.ServedCell{
-		{
-			CellID:       cellIDValue,
-			GlobalCellID: fmt.Sprintf("%x-%x", cellPLMNBytes, cellIDValue),
-			PLMN: du.PLMNInfo{
-				MCC: cu.ControlInfo.mcc,
-				MNC: cu.ControlInfo.mnc,
-			},
-			PCI: uint16(pci),
-			TAC: cellInfo.FiveGSTAC,
-		},
+	if existing, err := cu.GetDUById(duId); err == nil && existing.State == du.DU_LOST {
+		if existing.LostTimer != nil {
+			existing.LostTimer.Stop()
+		}
+		cu.Info("Lost DU %d reconnected", duId)
 	}
-
+	duCtx.State = du.DU_ACTIVE
 	cu.DuPool.Store(duId, duCtx)
 	cu.Info("==== Store DU %d ====", duId)
-
-	duCtx.SctpConn = conn
 	cu.F1ConnMap.Store(conn, duId)
 
-	cellToActivate := ies.CellstobeActivatedListItem{
-		NRCGI: ies.NRCGI{
-			PLMNIdentity:   cellPLMNBytes,
-			NRCellIdentity: cellID,
-		},
-		NRPCI: &ies.NRPCI{
-			Value: pci,
-		},
-	}
-
 	cu.Info("Create F1 SetupResponse")
-	if err := duCtx.SendF1SetupResponse(transactionID, setupReq.GNBDURRCVersion, []ies.CellstobeActivatedListItem{cellToActivate}, conn); err != nil {
+	if err := duCtx.SendF1SetupResponse(transactionID, setupReq.GNBDURRCVersion, cellsToActivate, conn); err != nil {
 		cu.Error("Error sending F1 Setup Response: %v", err)
 	} else {
 		cu.Info("F1 Setup Procedure successfully with DU %d (%s)", duCtx.DuId, duCtx.DuName)
 	}
+	cu.updateRanConfiguration()
+}
+
+// decodeServedCell turns a served cell of the DU into its context. f1-gen
+// drops the extensions of the served PLMNs, so the slices supported in the
+// cell are not known.
+func (cu *CuCpContext) decodeServedCell(item ies.GNBDUServedCellsItem) du.ServedCell {
+	info := item.ServedCellInformation
+	cellID := cu.extractCellIDValue(info.NRCGI.NRCellIdentity)
+	cell := du.ServedCell{
+		CellID:       cellID,
+		GlobalCellID: fmt.Sprintf("%x-%x", info.NRCGI.PLMNIdentity, cellID),
+		NRCGI:        info.NRCGI,
+		PLMN:         decodePLMN(info.NRCGI.PLMNIdentity),
+		PCI:          uint16(info.NRPCI.Value),
+		TAC:          info.FiveGSTAC,
+		Mode:         decodeNRMode(info.NRModeInfo),
+		MTC:          info.MeasurementTimingConfiguration,
+	}
+	for _, plmn := range info.ServedPLMNs {
+		cell.ServedPLMNs = append(cell.ServedPLMNs, decodePLMN(plmn.PLMNIdentity))
+	}
+	if item.GNBDUSystemInformation != nil {
+		cell.MIB = item.GNBDUSystemInformation.MIBMessage
+		cell.SIB1 = item.GNBDUSystemInformation.SIB1Message
+	}
+	return cell
+}
+
+// checkServedCell accepts a cell that broadcasts the PLMN and TAC of the
+// CU-CP and is not already served by another DU
+func (cu *CuCpContext) checkServedCell(duCtx *du.GNBDU, cell *du.ServedCell) (ies.Cause, bool) {
+	servesPlmn := false
+	cuPLMNBytes := cu.GetMccAndMncInOctets()
+	for _, plmn := range cell.ServedPLMNs {
+		if cu.plmnMatches(plmn.Identity, cuPLMNBytes) {
+			servesPlmn = true
+			break
+		}
+	}
+	if !servesPlmn {
+		cu.Error("Cell %s of DU %d: PLMN mismatch, CU serves %s.%s",
+			cell.GlobalCellID, duCtx.DuId, cu.ControlInfo.mcc, cu.ControlInfo.mnc)
+		return f1RadioNetworkCause(ies.CauseRadioNetworkPlmnnotservedbythegnbcu), false
+	}
+
+	if !bytes.Equal(cell.TAC, cu.getTacInBytes()) {
+		cu.Error("Cell %s of DU %d: TAC %x does not match the CU TAC %s",
+			cell.GlobalCellID, duCtx.DuId, cell.TAC, cu.ControlInfo.tac)
+		return f1RadioNetworkCause(ies.CauseRadioNetworkCellnotavailable), false
+	}
+
+	if duCtx.GetCellByID(cell.CellID) != nil {
+		cu.Error("Cell %s announced twice by DU %d", cell.GlobalCellID, duCtx.DuId)
+		return f1RadioNetworkCause(ies.CauseRadioNetworkCellnotavailable), false
+	}
+	var conflict bool
+	cu.DuPool.Range(func(_, value any) bool {
+		other, ok := value.(*du.GNBDU)
+		if !ok || other.DuId == duCtx.DuId || !cu.isDUConnected(other) {
+			return true
+		}
+		if existing := other.GetActiveCell(cell.CellID); existing != nil {
+			cu.Error("existing DU %s already has cellID %d, rejecting cell %s of DU %d",
+				other.DuName, existing.CellID, cell.GlobalCellID, duCtx.DuId)
+			conflict = true
+			return false
+		}
+		for _, existing := range other.ServedCells {
+			if existing.Active && existing.PCI == cell.PCI {
+				cu.Warn("Cell %s of DU %d reuses physCellId %d of cell %s of DU %s",
+					cell.GlobalCellID, duCtx.DuId, cell.PCI, existing.GlobalCellID, other.DuName)
+			}
+		}
+		return true
+	})
+	if conflict {
+		return f1RadioNetworkCause(ies.CauseRadioNetworkCellnotavailable), false
+	}
+	return ies.Cause{}, true
+}
+
+// isDUConnected tells whether the F1 association of a DU is still up
+func (cu *CuCpContext) isDUConnected(duCtx *du.GNBDU) bool {
+	if duCtx.SctpConn == nil {
+		return false
+	}
+	duId, ok := cu.F1ConnMap.Load(duCtx.SctpConn)
+	return ok && duId.(int64) == duCtx.DuId
+}
+
+func decodePLMN(identity []byte) du.PLMNInfo {
+	plmn := du.PLMNInfo{Identity: identity}
+	if len(identity) == 3 {
+		id := ngaputils.PlmnIdToModels(identity)
+		plmn.MCC, plmn.MNC = id.Mcc, id.Mnc
+	}
+	return plmn
+}
+
+// nrNrbValues maps the NRNRB enumeration of TS 38.473 to numbers of RBs
+var nrNrbValues = []uint16{
+	11, 18, 24, 25, 31, 32, 38, 51, 52, 65, 66, 78, 79, 93, 106, 107, 121,
+	132, 133, 135, 160, 162, 189, 216, 217, 245, 264, 270, 273,
+}
+
+func decodeNRMode(info ies.NRModeInfo) du.NRMode {
+	var mode du.NRMode
+	var bandwidth ies.TransmissionBandwidth
+	var freqBands []ies.FreqBandNrItem
+	switch info.Choice {
+	case ies.NRModeInfoPresentFDD:
+		if info.FDD == nil {
+			return mode
+		}
+		mode.DlArfcn = info.FDD.DLNRFreqInfo.NRARFCN
+		mode.UlArfcn = info.FDD.ULNRFreqInfo.NRARFCN
+		bandwidth = info.FDD.DLTransmissionBandwidth
+		freqBands = info.FDD.DLNRFreqInfo.FreqBandListNr
+	case ies.NRModeInfoPresentTDD:
+		if info.TDD == nil {
+			return mode
+		}
+		mode.TDD = true
+		mode.DlArfcn = info.TDD.NRFreqInfo.NRARFCN
+		mode.UlArfcn = mode.DlArfcn
+		bandwidth = info.TDD.TransmissionBandwidth
+		freqBands = info.TDD.NRFreqInfo.FreqBandListNr
+	default:
+		return mode
+	}
+
+	for _, band := range freqBands {
+		mode.Bands = append(mode.Bands, band.FreqBandIndicatorNr)
+	}
+	mode.ScsKHz = 15 << uint(bandwidth.NRSCS.Value)
+	if int(bandwidth.NRNRB.Value) < len(nrNrbValues) {
+		mode.Nrb = nrNrbValues[bandwidth.NRNRB.Value]
+	}
+	return mode
+}
+
+func f1RadioNetworkCause(value aper.Enumerated) ies.Cause {
+	return ies.Cause{
+		Choice:       ies.CausePresentRadioNetwork,
+		RadioNetwork: &ies.CauseRadioNetwork{Value: value},
+	}
+}
+
+func f1MiscCause(value aper.Enumerated) ies.Cause {
+	return ies.Cause{
+		Choice: ies.CausePresentMisc,
+		Misc:   &ies.CauseMisc{Value: value},
+	}
 }
 
 func (cu *CuCpContext) handleInitialULRRCMessageTransfer(msg *ies.InitialULRRCMessageTransfer, conn *sctp.SCTPConn) {
@@ -131,6 +249,18 @@ func (cu *CuCpContext) handleInitialULRRCMessageTransfer(msg *ies.InitialULRRCMe
 		cu.Error("DU not found for connection: %v", err)
 		return
 	}
+	if duCtx.GetActiveCell(cu.extractCellIDValue(msg.NRCGI.NRCellIdentity)) == nil {
+		cu.Error("Initial UL RRC Message Transfer from cell %x, which is not active at DU %d",
+			msg.NRCGI.NRCellIdentity.Bytes, duCtx.DuId)
+		return
+	}
+
+	if len(msg.RRCContainer) == ulCcch1MessageSize {
+		if err := cu.handleUlCcch1Message(duCtx, msg); err != nil {
+			cu.Error("Error handling RRC Resume Request1: %s", err.Error())
+		}
+		return
+	}
 
 	ulCcchMsg := rrcies.UL_CCCH_Message{}
 	err = rrc.Decode(msg.RRCContainer, &ulCcchMsg)
@@ -138,17 +268,54 @@ func (cu *CuCpContext) handleInitialULRRCMessageTransfer(msg *ies.InitialULRRCMe
 		cu.Error("Error decoding RRC container: %s", err.Error())
 		return
 	}
-	rrcSetupRequest := ulCcchMsg.Message.C1.RrcSetupRequest
-	if err := cu.handleRRCSetupRequest(
-		duCtx,
-		&rrcSetupRequest.RrcSetupRequest,
-		msg,
-	); err != nil {
-		cu.Error("Error handling RRC Setup Request: %s", err.Error())
+	c1 := ulCcchMsg.Message.C1
+	if ulCcchMsg.Message.Choice != rrcies.UL_CCCH_MessageType_Choice_C1 || c1 == nil {
+		cu.Warn("Initial UL RRC Message Transfer: unsupported UL-CCCH message type %d", ulCcchMsg.Message.Choice)
+		return
 	}
 
-	//WARN: now only support RrcSetupRequest
-	//TODO: RRCResumeRequest, RRCReestablishmentRequest, RRCSystemInfoRequest
+	switch c1.Choice {
+	case rrcies.UL_CCCH_MessageType_C1_Choice_RrcSetupRequest:
+		if c1.RrcSetupRequest == nil {
+			cu.Error("Initial UL RRC Message Transfer: RrcSetupRequest is nil")
+			return
+		}
+		if err := cu.handleRRCSetupRequest(
+			duCtx,
+			&c1.RrcSetupRequest.RrcSetupRequest,
+			msg,
+		); err != nil {
+			cu.Error("Error handling RRC Setup Request: %s", err.Error())
+		}
+	case rrcies.UL_CCCH_MessageType_C1_Choice_RrcResumeRequest:
+		if c1.RrcResumeRequest == nil {
+			cu.Error("Initial UL RRC Message Transfer: RrcResumeRequest is nil")
+			return
+		}
+		req := c1.RrcResumeRequest.RrcResumeRequest
+		if err := cu.handleRRCResumeRequest(duCtx, msg, resumeRequest{
+			iRnti:     utils.BitStringToUint64((*aper.BitString)(&req.ResumeIdentity.Value)),
+			resumeMac: req.ResumeMAC_I,
+			cause:     req.ResumeCause,
+		}); err != nil {
+			cu.Error("Error handling RRC Resume Request: %s", err.Error())
+		}
+	case rrcies.UL_CCCH_MessageType_C1_Choice_RrcReestablishmentRequest:
+		if c1.RrcReestablishmentRequest == nil {
+			cu.Error("Initial UL RRC Message Transfer: RrcReestablishmentRequest is nil")
+			return
+		}
+		if err := cu.handleRRCReestablishmentRequest(
+			duCtx,
+			msg,
+			&c1.RrcReestablishmentRequest.RrcReestablishmentRequest,
+		); err != nil {
+			cu.Error("Error handling RRC Reestablishment Request: %s", err.Error())
+		}
+	default:
+		//TODO: RRCSystemInfoRequest
+		cu.Warn("Initial UL RRC Message Transfer: Unsupported C1 message type %d", c1.Choice)
+	}
 }
 
 func (cu *CuCpContext) handleULRRCMessageTransfer(msg *ies.ULRRCMessageTransfer) {
@@ -181,10 +348,24 @@ func (cu *CuCpContext) handleULRRCMessageTransfer(msg *ies.ULRRCMessageTransfer)
 		return
 	}
 
+	// Validation check 4: the PDCP PDU must pass the integrity check
+	rrcBytes, err := unprotectSrb(ue, msg.SRBID, msg.RRCContainer)
+	unprotected := false
+	if errors.Is(err, pdcp.ErrIntegrity) && ue.AsSecurity != nil && !ue.AsSecurity.Active {
+		// a UE rejecting the Security Mode Command answers without protection
+		rrcBytes, err = pdcp.SrbPayload(msg.RRCContainer)
+		unprotected = true
+	}
+	if err != nil {
+		cu.Error("UL RRC Message Transfer: SRB%d of UE CU-UE-F1AP-ID=%d discarded: %v",
+			msg.SRBID, ue.GnbCuUeF1apId, err)
+		return
+	}
+
 	ulDcchMsg := rrcies.UL_DCCH_Message{}
-	err = rrc.Decode(msg.RRCContainer, &ulDcchMsg)
+	err = rrc.Decode(rrcBytes, &ulDcchMsg)
 	if err != nil {
-		cu.Error("Err decode RRC from UL RRC Message Transfer: %s - %v", err.Error(), msg.RRCContainer)
+		cu.Error("Err decode RRC from UL RRC Message Transfer: %s - %v", err.Error(), rrcBytes)
 		return
 	}
 
@@ -199,6 +380,12 @@ func (cu *CuCpContext) handleULRRCMessageTransfer(msg *ies.ULRRCMessageTransfer)
 		return
 	}
 
+	if unprotected && ulDcchMsg.Message.C1.Choice != rrcies.UL_DCCH_MessageType_C1_Choice_SecurityModeFailure {
+		cu.Error("UL RRC Message Transfer: integrity check failed on SRB%d of UE CU-UE-F1AP-ID=%d",
+			msg.SRBID, ue.GnbCuUeF1apId)
+		return
+	}
+
 	// Switch on C1 message type
 	switch ulDcchMsg.Message.C1.Choice {
 	case rrcies.UL_DCCH_MessageType_C1_Choice_RrcSetupComplete:
@@ -230,6 +417,14 @@ func (cu *CuCpContext) handleULRRCMessageTransfer(msg *ies.ULRRCMessageTransfer)
 		if err := cu.handleRRCSecurityModeComplete(ue, ulDcchMsg.Message.C1.SecurityModeComplete); err != nil {
 			cu.Error("Error handling Security Mode Complete: %s", err.Error())
 		}
+	case rrcies.UL_DCCH_MessageType_C1_Choice_SecurityModeFailure:
+		if ulDcchMsg.Message.C1.SecurityModeFailure == nil {
+			cu.Error("UL RRC Message Transfer: SecurityModeFailure is nil")
+			return
+		}
+		if err := cu.handleRRCSecurityModeFailure(ue, ulDcchMsg.Message.C1.SecurityModeFailure); err != nil {
+			cu.Error("Error handling Security Mode Failure: %s", err.Error())
+		}
 	case rrcies.UL_DCCH_MessageType_C1_Choice_RrcReconfigurationComplete:
 		// Handle RRC Reconfiguration Complete
 		if ulDcchMsg.Message.C1.RrcReconfigurationComplete == nil {
@@ -239,76 +434,31 @@ func (cu *CuCpContext) handleULRRCMessageTransfer(msg *ies.ULRRCMessageTransfer)
 		if err := cu.handleRRCReconfigurationComplete(ue, ulDcchMsg.Message.C1.RrcReconfigurationComplete); err != nil {
 			cu.Error("Error handling RRC Reconfiguration Complete: %s", err.Error())
 		}
+	case rrcies.UL_DCCH_MessageType_C1_Choice_RrcResumeComplete:
+		if ulDcchMsg.Message.C1.RrcResumeComplete == nil {
+			cu.Error("UL RRC Message Transfer: RrcResumeComplete is nil")
+			return
+		}
+		if err := cu.handleRRCResumeComplete(ue, ulDcchMsg.Message.C1.RrcResumeComplete); err != nil {
+			cu.Error("Error handling RRC Resume Complete: %s", err.Error())
+		}
+	case rrcies.UL_DCCH_MessageType_C1_Choice_RrcReestablishmentComplete:
+		if ulDcchMsg.Message.C1.RrcReestablishmentComplete == nil {
+			cu.Error("UL RRC Message Transfer: RrcReestablishmentComplete is nil")
+			return
+		}
+		if err := cu.handleRRCReestablishmentComplete(ue, ulDcchMsg.Message.C1.RrcReestablishmentComplete); err != nil {
+			cu.Error("Error handling RRC Reestablishment Complete: %s", err.Error())
+		}
+	case rrcies.UL_DCCH_MessageType_C1_Choice_MeasurementReport:
+		if ulDcchMsg.Message.C1.MeasurementReport == nil {
+			cu.Error("UL RRC Message Transfer: MeasurementReport is nil")
+			return
+		}
+		if err := cu.handleMeasurementReport(ue, ulDcchMsg.Message.C1.MeasurementReport); err != nil {
+			cu.Error("Error handling Measurement Report: %s", err.Error())
+		}
 	default:
 		cu.Warn("UL RRC Message Transfer: Unsupported C1 message type %d", ulDcchMsg.Message.C1.Choice)
 	}
 }
-
-func (cu *CuCpContext) handleRRCUEContextSetupResponse(msg *ies.UEContextSetupResponse) {
-	ue, err := cu.GetUEByF1Id(msg.GNBCUUEF1APID)
-	if err != nil {
-		cu.Error("UE not found for CU-UE-F1AP-ID %d: %v", msg.GNBCUUEF1APID, err)
-		return
-	}
-
-	masterCellGroupBytes, err := rrc.Encode(ue.MasterCellGroup)
-	if err != nil {
-		cu.Error("Error encoding MasterCellGroup: %s", err.Error())
-		return
-	}
-
-	rrcmsg := rrcies.RRCReconfiguration{
-		Rrc_TransactionIdentifier: rrcies.RRC_TransactionIdentifier{Value: 0},
-		CriticalExtensions: rrcies.RRCReconfiguration_CriticalExtensions{
-			Choice: rrcies.RRCReconfiguration_CriticalExtensions_Choice_RrcReconfiguration,
-			RrcReconfiguration: &rrcies.RRCReconfiguration_IEs{
-				RadioBearerConfig: &rrcies.RadioBearerConfig{
-					Srb_ToAddModList: &rrcies.SRB_ToAddModList{
-						Value: []rrcies.SRB_ToAddMod{
-							{
-								Srb_Identity: rrcies.SRB_Identity{Value: 2},
-							},
-						},
-					},
-					Drb_ToAddModList: &rrcies.DRB_ToAddModList{
-						Value: []rrcies.DRB_ToAddMod{rrcies.DRB_ToAddMod{
-							Drb_Identity: rrcies.DRB_Identity{Value: 0},
-						}},
-					},
-				},
-				SecondaryCellGroup: &masterCellGroupBytes,
-				MeasConfig:         &rrcies.MeasConfig{},
-				NonCriticalExtension: &rrcies.RRCReconfiguration_v1530_IEs{
-					MasterCellGroup: &masterCellGroupBytes,
-					DedicatedNAS_MessageList: []rrcies.DedicatedNAS_Message{rrcies.DedicatedNAS_Message{
-						Value: ue.RegistrationAccept,
-					}},
-				},
-			},
-		},
-	}
-
-	dlDcchMsg := rrcies.DL_DCCH_Message{
-		Message: rrcies.DL_DCCH_MessageType{
-			Choice: rrcies.DL_DCCH_MessageType_Choice_C1,
-			C1: &rrcies.DL_DCCH_MessageType_C1{
-				Choice:             rrcies.DL_DCCH_MessageType_C1_Choice_RrcReconfiguration,
-				RrcReconfiguration: &rrcmsg,
-			},
-		},
-	}
-
-	buf, err := rrc.Encode(&dlDcchMsg)
-	if err != nil {
-		cu.Error("Error encoding DL DCCH Message: %s", err.Error())
-		return
-	}
-
-	duCtx, err := cu.GetDUForUE(ue)
-	if err != nil {
-		cu.Error("DU not found for UE (DuId=%d): %v", ue.DuId, err)
-		return
-	}
-	duCtx.SendF1ap(buf)
-	cu.Info("RRC Reconfiguration sent successfully")
-}
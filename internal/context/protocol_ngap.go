@@ -3,6 +3,7 @@ package context
 import (
 	"central-unit/internal/context/amfcontext"
 	"central-unit/internal/context/uecontext"
+	"fmt"
 
 	"github.com/lvdund/ngap"
 	"github.com/lvdund/ngap/aper"
@@ -61,20 +62,12 @@ func (cu *CuCpContext) SendNgSetupRequest(amf *amfcontext.GNBAmf) {
 
 	msg.RANNodeName = []byte("cu-cp")
 
-	sst, sd := cu.getSliceInBytes()
-	msg.SupportedTAList = []ies.SupportedTAItem{
-		{
-			TAC: cu.getTacInBytes(),
-			BroadcastPLMNList: []ies.BroadcastPLMNItem{
-				{
-					PLMNIdentity: cu.GetMccAndMncInOctets(),
-					TAISliceSupportList: []ies.SliceSupportItem{
-						{SNSSAI: ies.SNSSAI{SST: sst, SD: sd}},
-					},
-				},
-			},
-		},
+	cu.Mu.Lock()
+	if cu.servedRan.tacs == nil {
+		cu.servedRan = cu.currentRanConfiguration(nil)
 	}
+	msg.SupportedTAList = cu.supportedTAList(cu.servedRan)
+	cu.Mu.Unlock()
 
 	msg.DefaultPagingDRX = ies.PagingDRX{Value: ies.PagingDRXV128}
 
@@ -90,6 +83,45 @@ func (cu *CuCpContext) SendNgSetupRequest(amf *amfcontext.GNBAmf) {
 	}
 }
 
+// SendRanConfigurationUpdate announces the tracking areas, PLMN and slices now
+// served by the CU-CP to an AMF
+func (cu *CuCpContext) SendRanConfigurationUpdate(amf *amfcontext.GNBAmf, ranCfg ranConfiguration) error {
+	msg := ies.RANConfigurationUpdate{
+		SupportedTAList: cu.supportedTAList(ranCfg),
+	}
+
+	ngapPdu, err := ngap.NgapEncode(&msg)
+	if err != nil {
+		return fmt.Errorf("encode RAN Configuration Update: %w", err)
+	}
+
+	cu.Info("Sending RAN Configuration Update to AMF %s with %d TA(s) and %d slice(s)",
+		amf.Name, len(ranCfg.tacs), len(ranCfg.slices))
+	return amf.SendNgap(ngapPdu)
+}
+
+// supportedTAList broadcasts the PLMN and slices of the CU-CP in every TA
+func (cu *CuCpContext) supportedTAList(ranCfg ranConfiguration) []ies.SupportedTAItem {
+	sliceList := make([]ies.SliceSupportItem, 0, len(ranCfg.slices))
+	for _, slice := range ranCfg.slices {
+		sst, sd := cu.getSliceInBytes(slice)
+		sliceList = append(sliceList, ies.SliceSupportItem{SNSSAI: ies.SNSSAI{SST: sst, SD: sd}})
+	}
+	list := make([]ies.SupportedTAItem, 0, len(ranCfg.tacs))
+	for _, tac := range ranCfg.tacs {
+		list = append(list, ies.SupportedTAItem{
+			TAC: tac,
+			BroadcastPLMNList: []ies.BroadcastPLMNItem{
+				{
+					PLMNIdentity:        ranCfg.plmn,
+					TAISliceSupportList: sliceList,
+				},
+			},
+		})
+	}
+	return list
+}
+
 func (cu *CuCpContext) ngInitialUEMessage(
 	nasPdu []byte,
 	ue *uecontext.GNBUe,
@@ -2,21 +2,108 @@ package context
 
 import (
 	"central-unit/internal/common/utils"
+	"central-unit/internal/context/amfcontext"
 	"central-unit/internal/context/du"
 	"central-unit/internal/context/uecontext"
+	f1ext "central-unit/pkg/f1ap/ies"
+	rrcext "central-unit/pkg/rrc/ies"
 	"fmt"
 
 	f1ap "github.com/JocelynWS/f1-gen"
 	"github.com/JocelynWS/f1-gen/ies"
 	f1ies "github.com/JocelynWS/f1-gen/ies"
 	asn1aper "github.com/lvdund/asn1go/aper"
-	"github.com/lvdund/ngap"
 	"github.com/lvdund/ngap/aper"
-	ngapies "github.com/lvdund/ngap/ies"
 	"github.com/lvdund/rrc"
 	rrcies "github.com/lvdund/rrc/ies"
 )
 
+// RRC transaction identifiers of the RRCReconfigurations sent by the CU-CP,
+// echoed back by the UE in RRCReconfigurationComplete. The identifier has
+// two bits only: the reconfigurations ending a re-establishment or carrying a
+// handover share the one of the initial context and are told apart by the UE
+// context.
+const (
+	rrcTransactionInitialContext    uint64 = 0
+	rrcTransactionPduSessionSetup   uint64 = 1
+	rrcTransactionPduSessionRelease uint64 = 2
+	rrcTransactionPduSessionModify  uint64 = 3
+)
+
+// encodeRrcReconfiguration wraps the RRCReconfiguration IEs in a DL-DCCH message
+func encodeRrcReconfiguration(transactionId uint64, reconfig *rrcies.RRCReconfiguration_IEs) ([]byte, error) {
+	if reconfig.MeasConfig != nil {
+		// the rrc library cannot encode a measConfig
+		rrcBytes, err := rrcext.Encode(&rrcext.RRCReconfiguration{TransactionId: transactionId, IEs: reconfig})
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode RRC Reconfiguration: %w", err)
+		}
+		return rrcBytes, nil
+	}
+	dlDcchMsg := rrcies.DL_DCCH_Message{
+		Message: rrcies.DL_DCCH_MessageType{
+			Choice: rrcies.DL_DCCH_MessageType_Choice_C1,
+			C1: &rrcies.DL_DCCH_MessageType_C1{
+				Choice: rrcies.DL_DCCH_MessageType_C1_Choice_RrcReconfiguration,
+				RrcReconfiguration: &rrcies.RRCReconfiguration{
+					Rrc_TransactionIdentifier: rrcies.RRC_TransactionIdentifier{Value: transactionId},
+					CriticalExtensions: rrcies.RRCReconfiguration_CriticalExtensions{
+						Choice:             rrcies.RRCReconfiguration_CriticalExtensions_Choice_RrcReconfiguration,
+						RrcReconfiguration: reconfig,
+					},
+				},
+			},
+		},
+	}
+
+	rrcBytes, err := rrc.Encode(&dlDcchMsg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode RRC Reconfiguration: %w", err)
+	}
+	return rrcBytes, nil
+}
+
+// sendRrcReconfiguration delivers an encoded RRCReconfiguration to the UE on SRB1
+func (cu *CuCpContext) sendRrcReconfiguration(ue *uecontext.GNBUe, rrcBytes []byte) error {
+	if err := cu.sendDlRrcMessage(ue, rrcBytes); err != nil {
+		return err
+	}
+	cu.Info("RRC Reconfiguration sent to DU %d", ue.DuId)
+	return nil
+}
+
+// sendDlRrcMessage carries an encoded DL-DCCH message to the UE on SRB1
+func (cu *CuCpContext) sendDlRrcMessage(ue *uecontext.GNBUe, rrcBytes []byte) error {
+	duCtx, err := cu.GetDUForUE(ue)
+	if err != nil {
+		return fmt.Errorf("DU not found for UE: %v", err)
+	}
+
+	pdcpPdu, err := protectSrb(ue, 1, rrcBytes)
+	if err != nil {
+		return err
+	}
+
+	f1rrcdl := f1ies.DLRRCMessageTransfer{
+		GNBCUUEF1APID:      int64(ue.GnbCuUeF1apId),
+		GNBDUUEF1APID:      int64(ue.DuUeId),
+		SRBID:              1,
+		RRCContainer:       pdcpPdu,
+		ExecuteDuplication: &f1ies.ExecuteDuplication{Value: 0},
+	}
+
+	f1apBytes, err := f1ap.F1apEncode(&f1rrcdl)
+	if err != nil {
+		return fmt.Errorf("failed to encode F1AP DL RRC Message Transfer: %w", err)
+	}
+
+	err = duCtx.SendF1ap(f1apBytes)
+	if err != nil {
+		return fmt.Errorf("failed to send DL RRC Message Transfer to DU: %w", err)
+	}
+	return nil
+}
+
 // Based on rrc_handle_RRCSetupRequest from OAI
 func (cu *CuCpContext) handleRRCSetupRequest(
 	duCtx *du.GNBDU,
@@ -24,33 +111,68 @@ func (cu *CuCpContext) handleRRCSetupRequest(
 	f1apMsg *ies.InitialULRRCMessageTransfer,
 ) error {
 	cu.Info("handle RRC Setup Request")
-	var ue *uecontext.GNBUe
-	var err error
-
+	var tmsiPart1 *asn1aper.BitString
 	switch rrcSetupRequest.Ue_Identity.Choice {
 	case rrcies.InitialUE_Identity_Choice_RandomValue:
-		ue = cu.createUE(duCtx.DuId, f1apMsg.CRNTI, asn1aper.BitString{}, f1apMsg.GNBDUUEF1APID)
 	case rrcies.InitialUE_Identity_Choice_Ng_5G_S_TMSI_Part1:
-		ue = cu.createUE(duCtx.DuId, f1apMsg.CRNTI, rrcSetupRequest.Ue_Identity.Ng_5G_S_TMSI_Part1, f1apMsg.GNBDUUEF1APID)
-		ue.Tmsi5gs_part1 = (*aper.BitString)(&rrcSetupRequest.Ue_Identity.Ng_5G_S_TMSI_Part1)
+		tmsiPart1 = &rrcSetupRequest.Ue_Identity.Ng_5G_S_TMSI_Part1
 	default:
-		ue = cu.createUE(duCtx.DuId, f1apMsg.CRNTI, asn1aper.BitString{}, f1apMsg.GNBDUUEF1APID)
-		//TODO: rrc setup reject
-		return fmt.Errorf("invalid UE identity choice")
+		cu.Warn("RRC Setup Request with invalid UE identity choice %d", rrcSetupRequest.Ue_Identity.Choice)
+		return cu.sendRRCReject(duCtx, f1apMsg)
+	}
+
+	ueIdentity := asn1aper.BitString{}
+	if tmsiPart1 != nil {
+		ueIdentity = *tmsiPart1
+	}
+	ue, err := cu.createUE(duCtx.DuId, f1apMsg.CRNTI, ueIdentity, f1apMsg.GNBDUUEF1APID, rrcSetupRequest.EstablishmentCause)
+	if err != nil {
+		cu.Warn("UE with C-RNTI %d not admitted: %v", f1apMsg.CRNTI, err)
+		return cu.sendRRCReject(duCtx, f1apMsg)
+	}
+	if tmsiPart1 != nil {
+		ue.Tmsi5gs_part1 = (*aper.BitString)(tmsiPart1)
+	}
+	ue.EstablishmentCause = &rrcSetupRequest.EstablishmentCause
+	return cu.sendRRCSetup(duCtx, ue, f1apMsg)
+}
+
+// fallbackToRRCSetup answers the resume or re-establishment of a UE context
+// the CU-CP cannot use with an RRCSetup, as for a new UE
+func (cu *CuCpContext) fallbackToRRCSetup(
+	duCtx *du.GNBDU,
+	f1apMsg *ies.InitialULRRCMessageTransfer,
+	cause rrcies.EstablishmentCause,
+) error {
+	ue, err := cu.createUE(duCtx.DuId, f1apMsg.CRNTI, asn1aper.BitString{}, f1apMsg.GNBDUUEF1APID, cause)
+	if err != nil {
+		cu.Warn("UE with C-RNTI %d not admitted: %v", f1apMsg.CRNTI, err)
+		return cu.sendRRCReject(duCtx, f1apMsg)
 	}
+	ue.EstablishmentCause = &cause
+	return cu.sendRRCSetup(duCtx, ue, f1apMsg)
+}
 
+// sendRRCSetup establishes SRB1 with a new UE, in the cell group the DU
+// gave for it
+func (cu *CuCpContext) sendRRCSetup(
+	duCtx *du.GNBDU,
+	ue *uecontext.GNBUe,
+	f1apMsg *ies.InitialULRRCMessageTransfer,
+) error {
 	if f1apMsg.DUtoCURRCContainer == nil {
-		//TODO: rrc setup reject
-		return fmt.Errorf("DUtoCURRCContainer nil")
+		// the DU cannot serve the UE (TS 38.473 8.4.1.2)
+		cu.Warn("DU %d gave no cell group for C-RNTI %d", duCtx.DuId, f1apMsg.CRNTI)
+		cu.RemoveUE(ue)
+		return cu.sendRRCReject(duCtx, f1apMsg)
 	} else {
 		rrc_temp := rrcies.CellGroupConfig{}
-		err = rrc.Decode(f1apMsg.DUtoCURRCContainer, &rrc_temp)
+		err := rrc.Decode(f1apMsg.DUtoCURRCContainer, &rrc_temp)
 		if err != nil {
 			return err
 		}
 		ue.MasterCellGroup = &rrc_temp
 	}
-	ue.EstablishmentCause = &rrcSetupRequest.EstablishmentCause
 	ue.NrCellId = &f1apMsg.NRCGI.NRCellIdentity
 
 	// Send RRC Setup -> DU
@@ -89,7 +211,7 @@ func (cu *CuCpContext) handleRRCSetupRequest(
 
 	// Create DL RRC Message Transfer message
 	dlRrcMsg := f1ies.DLRRCMessageTransfer{
-		GNBCUUEF1APID:        int64(ue.RrcUeId),
+		GNBCUUEF1APID:        int64(ue.GnbCuUeF1apId),
 		GNBDUUEF1APID:        int64(ue.DuUeId),
 		SRBID:                0, //= 0 (SRB0, used before SRB1 is established)
 		RRCContainer:         rrcSetupBytes,
@@ -109,6 +231,59 @@ func (cu *CuCpContext) handleRRCSetupRequest(
 	return duCtx.SendF1ap(f1apBytes)
 }
 
+// rejectedUeF1apId is the gNB-CU UE F1AP ID given to the DU for a UE that is
+// rejected, one the generator never hands out
+const rejectedUeF1apId int64 = 0
+
+// sendRRCReject turns down the request of an Initial UL RRC Message Transfer
+// on SRB0, the UE waiting the configured time before it tries again. The
+// RRCReject goes in a UE Context Release Command so that the DU also drops
+// the UE context it created for the request.
+func (cu *CuCpContext) sendRRCReject(
+	duCtx *du.GNBDU,
+	f1apMsg *ies.InitialULRRCMessageTransfer,
+) error {
+	rrcmsg := rrcies.DL_CCCH_Message{
+		Message: rrcies.DL_CCCH_MessageType{
+			Choice: rrcies.DL_CCCH_MessageType_Choice_C1,
+			C1: &rrcies.DL_CCCH_MessageType_C1{
+				Choice: rrcies.DL_CCCH_MessageType_C1_Choice_RrcReject,
+				RrcReject: &rrcies.RRCReject{
+					CriticalExtensions: rrcies.RRCReject_CriticalExtensions{
+						Choice: rrcies.RRCReject_CriticalExtensions_Choice_RrcReject,
+						RrcReject: &rrcies.RRCReject_IEs{
+							WaitTime: &rrcies.RejectWaitTime{Value: cu.rejectWaitTime},
+						},
+					},
+				},
+			},
+		},
+	}
+	rrcRejectBytes, err := rrc.Encode(&rrcmsg)
+	if err != nil {
+		return fmt.Errorf("failed to generate RRC Reject message: %v", err)
+	}
+
+	srbId := int64(0)
+	releaseMsg := f1ext.UEContextReleaseCommand{
+		GNBCUUEF1APID: rejectedUeF1apId,
+		GNBDUUEF1APID: f1apMsg.GNBDUUEF1APID,
+		Cause: f1ies.Cause{
+			Choice:       f1ies.CausePresentRadioNetwork,
+			RadioNetwork: &f1ies.CauseRadioNetwork{Value: f1ies.CauseRadioNetworkUnspecified},
+		},
+		RRCContainer: rrcRejectBytes,
+		SRBID:        &srbId,
+	}
+	f1apBytes, err := f1ap.F1apEncode(&releaseMsg)
+	if err != nil {
+		return fmt.Errorf("failed to encode F1AP UE Context Release Command: %v", err)
+	}
+
+	cu.Warn("Send RrcReject to DU %d for C-RNTI %d, wait time %ds", duCtx.DuId, f1apMsg.CRNTI, cu.rejectWaitTime)
+	return duCtx.SendF1ap(f1apBytes)
+}
+
 func (cu *CuCpContext) handleRrcSetupComplete(
 	ue *uecontext.GNBUe,
 	msg *rrcies.RRCSetupComplete,
@@ -136,11 +311,19 @@ func (cu *CuCpContext) handleRrcSetupComplete(
 
 	ue.State = uecontext.UE_INITIALIZED
 
-	cu.Info("Send NAS Registration Request to AMF")
-	amf, err := cu.GetAMFById(ue.AmfId)
+	var amf *amfcontext.GNBAmf
+	if ue.Tmsi5gs != nil {
+		amf, err = cu.SelectAMF(ue.Tmsi5gs, *ue.EstablishmentCause)
+		if err == nil {
+			ue.AmfId = amf.AmfId
+		}
+	} else {
+		amf, err = cu.GetAMFById(ue.AmfId)
+	}
 	if err != nil {
 		return fmt.Errorf("AMF not found for UE: %v", err)
 	}
+	cu.Info("Send NAS Registration Request to AMF %d", amf.AmfId)
 	cu.SendNasPdu(msg.CriticalExtensions.RrcSetupComplete.DedicatedNAS_Message.Value, ue, amf)
 	return nil
 }
@@ -162,49 +345,15 @@ func (cu *CuCpContext) handleRRCSecurityModeComplete(
 	ue *uecontext.GNBUe,
 	securityModeComplete *rrcies.SecurityModeComplete,
 ) error {
-
-	duUeId := int64(ue.DuUeId)
-	msg := f1ies.UEContextSetupRequest{
-		GNBCUUEF1APID: int64(ue.RrcUeId),
-		GNBDUUEF1APID: &duUeId,
-		SpCellID: f1ies.NRCGI{
-			PLMNIdentity:   cu.GetMccAndMncInOctets(),
-			NRCellIdentity: aper.BitString(*ue.NrCellId),
-		},
-		ServCellIndex: 0,
-		CUtoDURRCInformation: &f1ies.CUtoDURRCInformation{
-			CGConfigInfo: []byte{0x00}, //FIX: this field is not mandatory
-		},
-		SRBsToBeSetupList: []f1ies.SRBsToBeSetupItem{{
-			SRBID: 2, //SRB2
-		}},
-		DRBsToBeSetupList: []f1ies.DRBsToBeSetupItem{{
-			DRBID: 0, //because no pdu session is established yet
-		}},
-		NRUESidelinkAggregateMaximumBitrate: &f1ies.NRUESidelinkAggregateMaximumBitrate{
-			UENRSidelinkAggregateMaximumBitrate: 1000000000,
-		},
-		ConditionalInterDUMobilityInformation: &f1ies.ConditionalInterDUMobilityInformation{
-			CHOTrigger: f1ies.CHOTriggerInterDU{
-				Value: f1ies.CHOtriggerInterDUChoinitiation,
-			},
-		},
-	}
-
-	f1apBytes, err := f1ap.F1apEncode(&msg)
-	if err != nil {
-		return fmt.Errorf("failed to encode UE Context Setup Request: %w", err)
-	}
-
-	duCtx, err := cu.GetDUForUE(ue)
-	if err != nil {
-		return fmt.Errorf("DU not found for UE: %v", err)
-	}
-	err = duCtx.SendF1ap(f1apBytes)
-	if err != nil {
-		return fmt.Errorf("failed to send UE Context Setup Request: %w", err)
+	if ue.AsSecurity == nil {
+		return fmt.Errorf("no Security Mode Command was sent to the UE")
 	}
+	ue.AsSecurity.Active = true
+	// the UE ciphers on SRB1 from the message after the Security Mode Complete
+	ue.SrbPdcp[1].Rx = srbSecurity(ue.AsSecurity, true)
+	cu.Info("AS security activated for UE RAN-UE-NGAP-ID=%d", ue.RanUeNgapId)
 
+	cu.continueInitialContextSetup(ue)
 	return nil
 }
 
@@ -213,46 +362,43 @@ func (cu *CuCpContext) handleRRCReconfigurationComplete(
 	rrcReconfigurationComplete *rrcies.RRCReconfigurationComplete,
 ) error {
 	ue.State = uecontext.UE_READY
+	if ue.Reestablishing {
+		cu.completeReestablishment(ue)
+		return nil
+	}
+	if ho := ue.Handover; ho != nil && ho.ReconfigSent &&
+		rrcReconfigurationComplete.Rrc_TransactionIdentifier.Value == rrcTransactionInitialContext {
+		cu.completeHandover(ue)
+		return nil
+	}
+
+	switch rrcReconfigurationComplete.Rrc_TransactionIdentifier.Value {
+	case rrcTransactionInitialContext:
+		return cu.completeInitialContextSetup(ue)
+	case rrcTransactionPduSessionRelease:
+		cu.completePduSessionRelease(ue)
+		return nil
+	case rrcTransactionPduSessionModify:
+		cu.completePduSessionModify(ue)
+		return nil
+	}
 
 	// Check if this RRC Reconfiguration Complete is for PDU session establishment
-	hasPduSessions := false
+	var established []*uecontext.PduSessionContext
 	for _, pduSession := range ue.PduSessions {
-		if pduSession.State == uecontext.PDU_SESSION_ESTABLISHING {
+		// Sessions still waiting for the CU-UP or the DU were not part of this reconfiguration
+		if pduSession.State == uecontext.PDU_SESSION_ESTABLISHING && pduSession.DrbsSetupAtDu() {
 			pduSession.State = uecontext.PDU_SESSION_ACTIVE
-			hasPduSessions = true
+			established = append(established, pduSession)
 			cu.Info("PDU Session ID=%d is now ACTIVE", pduSession.PduSessionId)
 		}
 	}
 
-	// If PDU sessions were established, send PDU Session Resource Setup Response
-	if hasPduSessions {
+	if len(established) > 0 {
 		cu.Info("Sending PDU Session Resource Setup Response to AMF")
-		if err := cu.sendPduSessionResourceSetupResponse(ue); err != nil {
+		if err := cu.sendPduSessionResourceSetupResponse(ue, established); err != nil {
 			return fmt.Errorf("failed to send PDU Session Resource Setup Response: %w", err)
 		}
-		return nil
-	}
-
-	// Otherwise, this is Initial Context Setup Response
-	msg := &ngapies.InitialContextSetupResponse{
-		AMFUENGAPID: ue.AmfUeNgapId,
-		RANUENGAPID: ue.RanUeNgapId,
 	}
-
-	ngapBytes, err := ngap.NgapEncode(msg)
-	if err != nil {
-		return fmt.Errorf("failed to encode Initial Context Setup Response: %w", err)
-	}
-
-	amf, err := cu.GetAMFById(ue.AmfId)
-	if err != nil {
-		return fmt.Errorf("AMF not found for UE: %v", err)
-	}
-	err = amf.SendNgap(ngapBytes)
-	if err != nil {
-		return fmt.Errorf("failed to send NGAP Initial Context Setup Response: %w", err)
-	}
-
-	cu.Info("NGAP Initial Context Setup Response sent successfully")
 	return nil
 }
@@ -0,0 +1,189 @@
+package context
+
+import (
+	"bytes"
+	"central-unit/internal/context/amfcontext"
+	"central-unit/internal/context/du"
+	"central-unit/pkg/config"
+	"slices"
+	"time"
+
+	"github.com/lvdund/ngap/ies"
+)
+
+// ranConfiguration is what the CU-CP announces to the AMFs in NG Setup and
+// RAN Configuration Update
+type ranConfiguration struct {
+	tacs   [][]byte // sorted
+	plmn   []byte
+	slices []Slice
+}
+
+func (c ranConfiguration) equal(other ranConfiguration) bool {
+	return slices.EqualFunc(c.tacs, other.tacs, bytes.Equal) &&
+		bytes.Equal(c.plmn, other.plmn) &&
+		slices.Equal(c.slices, other.slices)
+}
+
+// currentRanConfiguration builds the configuration to announce from the TACs
+// of the cells in service. Without any, the previous TACs are kept, or the
+// configured one before the first announcement. Called with cu.Mu held.
+func (cu *CuCpContext) currentRanConfiguration(tacs [][]byte) ranConfiguration {
+	if len(tacs) == 0 {
+		tacs = cu.servedRan.tacs
+	}
+	if len(tacs) == 0 {
+		tacs = [][]byte{cu.getTacInBytes()}
+	}
+	return ranConfiguration{
+		tacs:   tacs,
+		plmn:   cu.GetMccAndMncInOctets(),
+		slices: slices.Clone(cu.slices),
+	}
+}
+
+// servedTACs lists the distinct TACs of the active, in service cells of the
+// connected DUs
+func (cu *CuCpContext) servedTACs() [][]byte {
+	var tacs [][]byte
+	cu.DuPool.Range(func(_, value any) bool {
+		duCtx, ok := value.(*du.GNBDU)
+		if !ok || !cu.isDUConnected(duCtx) {
+			return true
+		}
+		for _, cell := range duCtx.ServedCells {
+			if !cell.Active || cell.OutOfService {
+				continue
+			}
+			if !slices.ContainsFunc(tacs, func(tac []byte) bool { return bytes.Equal(tac, cell.TAC) }) {
+				tacs = append(tacs, cell.TAC)
+			}
+		}
+		return true
+	})
+	slices.SortFunc(tacs, bytes.Compare)
+	return tacs
+}
+
+// updateRanConfiguration sends a RAN Configuration Update to the AMFs when the
+// served TAs, the PLMN or the slices have changed. An empty TA list is never
+// announced.
+func (cu *CuCpContext) updateRanConfiguration() {
+	tacs := cu.servedTACs()
+	if len(tacs) == 0 {
+		cu.Warn("No cell in service, the TAs announced to the AMFs are kept")
+	}
+
+	cu.Mu.Lock()
+	ranCfg := cu.currentRanConfiguration(tacs)
+	if ranCfg.equal(cu.servedRan) {
+		cu.Mu.Unlock()
+		return
+	}
+	cu.servedRan = ranCfg
+	cu.Mu.Unlock()
+
+	cu.AmfPool.Range(func(_, value any) bool {
+		amf, ok := value.(*amfcontext.GNBAmf)
+		if !ok || !amf.IsSetUp() {
+			return true
+		}
+		if amf.RanConfigTimer != nil {
+			amf.RanConfigTimer.Stop()
+		}
+		if err := cu.SendRanConfigurationUpdate(amf, ranCfg); err != nil {
+			cu.Error("Error sending RAN Configuration Update to AMF %s: %v", amf.Name, err)
+		}
+		return true
+	})
+}
+
+func (cu *CuCpContext) handleRanConfigurationUpdateAcknowledge(amf *amfcontext.GNBAmf) {
+	cu.Info("AMF %s accepted the RAN configuration", amf.Name)
+}
+
+// handleRanConfigurationUpdateFailure sends the configuration again once the
+// Time To Wait of the AMF is over. Without it the AMF keeps the previous
+// configuration until the next change.
+func (cu *CuCpContext) handleRanConfigurationUpdateFailure(amf *amfcontext.GNBAmf, msg *ies.RANConfigurationUpdateFailure) {
+	if msg.TimeToWait == nil {
+		cu.Error("AMF %s rejected the RAN configuration (cause choice %d)", amf.Name, msg.Cause.Choice)
+		return
+	}
+
+	delay := ngTimeToWait(msg.TimeToWait)
+	cu.Error("AMF %s rejected the RAN configuration (cause choice %d), retrying in %v",
+		amf.Name, msg.Cause.Choice, delay)
+	conn := amf.Tnla.SctpConn
+	amf.RanConfigTimer = time.AfterFunc(delay, func() {
+		if amf.Tnla.SctpConn != conn || !amf.IsSetUp() || cu.Ctx.Err() != nil {
+			return
+		}
+		cu.Mu.Lock()
+		ranCfg := cu.servedRan
+		cu.Mu.Unlock()
+		if err := cu.SendRanConfigurationUpdate(amf, ranCfg); err != nil {
+			cu.Error("Error sending RAN Configuration Update to AMF %s: %v", amf.Name, err)
+		}
+	})
+}
+
+// AddSlice supports one more slice in every TA and announces it to the AMFs
+func (cu *CuCpContext) AddSlice(sst, sd string) bool {
+	slice := newSlice(sst, sd)
+	cu.Mu.Lock()
+	if slices.Contains(cu.slices, slice) {
+		cu.Mu.Unlock()
+		return false
+	}
+	cu.slices = append(cu.slices, slice)
+	cu.Mu.Unlock()
+
+	cu.updateRanConfiguration()
+	return true
+}
+
+// RemoveSlice stops supporting a slice and announces it to the AMFs
+func (cu *CuCpContext) RemoveSlice(sst, sd string) bool {
+	slice := newSlice(sst, sd)
+	cu.Mu.Lock()
+	i := slices.Index(cu.slices, slice)
+	if i < 0 {
+		cu.Mu.Unlock()
+		return false
+	}
+	cu.slices = slices.Delete(cu.slices, i, i+1)
+	cu.Mu.Unlock()
+
+	cu.updateRanConfiguration()
+	return true
+}
+
+// ReloadConfig applies the PLMN, TAC and slices of a reloaded configuration
+// and announces the change to the AMFs. The cells already active keep the
+// PLMN and TAC they were accepted with, the other settings need a restart.
+func (cu *CuCpContext) ReloadConfig(cfg config.Config) {
+	cu.Mu.Lock()
+	cu.ControlInfo.mcc = cfg.CUCP.PLMN.MCC
+	cu.ControlInfo.mnc = cfg.CUCP.PLMN.MNC
+	cu.ControlInfo.tac = cfg.CUCP.TAC
+	cu.slices = configSlices(cfg.CUCP.Slices)
+	cu.Mu.Unlock()
+	if len(cfg.CUCP.Slices) > 0 {
+		cu.SetSliceInfoFromConfig(cfg.CUCP.Slices[0].SST, cfg.CUCP.Slices[0].SD)
+	}
+
+	cu.Info("Configuration reloaded: plmn %s.%s, tac %s, %d slice(s)",
+		cfg.CUCP.PLMN.MCC, cfg.CUCP.PLMN.MNC, cfg.CUCP.TAC, len(cfg.CUCP.Slices))
+	cu.updateRanConfiguration()
+}
+
+func configSlices(cfgSlices []config.Slice) []Slice {
+	var list []Slice
+	for _, s := range cfgSlices {
+		if slice := newSlice(s.SST, s.SD); !slices.Contains(list, slice) {
+			list = append(list, slice)
+		}
+	}
+	return list
+}
@@ -0,0 +1,516 @@
+package context
+
+import (
+	"bytes"
+	"central-unit/internal/common/utils"
+	"central-unit/internal/context/du"
+	"central-unit/internal/context/uecontext"
+	"central-unit/pkg/config"
+	e1ies "central-unit/pkg/e1ap/ies"
+	f1ext "central-unit/pkg/f1ap/ies"
+	"central-unit/pkg/pdcp"
+	rrcext "central-unit/pkg/rrc/ies"
+	"encoding/binary"
+	"fmt"
+	"slices"
+
+	f1ies "github.com/JocelynWS/f1-gen/ies"
+	asn1aper "github.com/lvdund/asn1go/aper"
+	"github.com/lvdund/ngap"
+	"github.com/lvdund/ngap/aper"
+	"github.com/lvdund/ngap/ies"
+	"github.com/lvdund/rrc"
+	rrcies "github.com/lvdund/rrc/ies"
+)
+
+// InactivePolicy is the part of the suspendConfig that is the same for every
+// UE released to RRC_INACTIVE
+type InactivePolicy struct {
+	PagingCycle rrcies.PagingCycle
+	T380        rrcies.PeriodicRNAU_TimerValue
+}
+
+func newInactivePolicy(cfg config.InactiveConfig) (*InactivePolicy, error) {
+	cycle := slices.Index(config.RanPagingCycles, cfg.RanPagingCycle)
+	if cycle < 0 {
+		return nil, fmt.Errorf("unsupported RAN paging cycle of %d radio frames", cfg.RanPagingCycle)
+	}
+	t380 := slices.Index(config.PeriodicRnaUpdateTimers, cfg.PeriodicRnaUpdateTimer)
+	if t380 < 0 {
+		return nil, fmt.Errorf("unsupported periodic RNA update timer %v", cfg.PeriodicRnaUpdateTimer)
+	}
+	return &InactivePolicy{
+		PagingCycle: rrcies.PagingCycle{Value: asn1aper.Enumerated(cycle)},
+		T380:        rrcies.PeriodicRNAU_TimerValue{Value: asn1aper.Enumerated(t380)},
+	}, nil
+}
+
+// canSuspend tells whether a UE the DU found inactive may go to RRC_INACTIVE
+// rather than be released: the AMF gave the assistance information, AS
+// security is active and no procedure is in progress for the UE
+func (cu *CuCpContext) canSuspend(ue *uecontext.GNBUe, cause f1ies.Cause) bool {
+	if cu.inactivePolicy == nil || ue.Suspension != nil || ue.InitialContextSetup != nil {
+		return false
+	}
+	if cause.Choice != f1ies.CausePresentRadioNetwork || cause.RadioNetwork == nil ||
+		cause.RadioNetwork.Value != f1ies.CauseRadioNetworkNormalrelease {
+		return false
+	}
+	if ue.State != uecontext.UE_ONGOING && ue.State != uecontext.UE_READY {
+		return false
+	}
+	if assistance := ue.InactiveAssistance; assistance == nil || assistance.UEIdentityIndexValue.IndexLength10 == nil {
+		return false
+	}
+	if ue.AsSecurity == nil || !ue.AsSecurity.Active {
+		return false
+	}
+	for _, pduSession := range ue.PduSessions {
+		if pduSession.State != uecontext.PDU_SESSION_ACTIVE {
+			return false
+		}
+	}
+	return true
+}
+
+// suspendUE sends the UE to RRC_INACTIVE with an RRCRelease carrying a
+// suspendConfig (TS 38.331 5.3.8). The UE context is released at the DU, the
+// NG connection and the bearer context are kept.
+func (cu *CuCpContext) suspendUE(ue *uecontext.GNBUe) error {
+	duCtx, err := cu.GetDUForUE(ue)
+	if err != nil {
+		return fmt.Errorf("DU not found for UE: %v", err)
+	}
+	cell := duCtx.GetActiveCell(cu.extractCellIDValue(*ue.NrCellId))
+	if cell == nil {
+		return fmt.Errorf("cell %x is no longer active at DU %d", ue.NrCellId.Bytes, duCtx.DuId)
+	}
+
+	iRnti := cu.iRnti(ue)
+	tacs := cu.ranNotificationArea(ue, cell)
+	rrcBytes, err := encodeRrcRelease(cu.suspendConfig(iRnti, tacs, ue.AsSecurity.Ncc))
+	if err == nil {
+		rrcBytes, err = protectSrb(ue, 1, rrcBytes)
+	}
+	if err != nil {
+		return err
+	}
+
+	srbId := int64(1)
+	msg := f1ext.UEContextReleaseCommand{
+		GNBCUUEF1APID: int64(ue.GnbCuUeF1apId),
+		GNBDUUEF1APID: int64(ue.DuUeId),
+		Cause: f1ies.Cause{
+			Choice:       f1ies.CausePresentRadioNetwork,
+			RadioNetwork: &f1ies.CauseRadioNetwork{Value: f1ies.CauseRadioNetworkNormalrelease},
+		},
+		RRCContainer: rrcBytes,
+		SRBID:        &srbId,
+	}
+	if err = cu.sendF1UEContextReleaseCommand(ue, &msg); err != nil {
+		return err
+	}
+
+	// a UE suspended again right after an RNA update is already known as
+	// inactive to the CU-UP and the AMF
+	firstSuspension := ue.Suspension == nil
+	assistance := ue.InactiveAssistance
+	ue.Suspension = &uecontext.Suspension{
+		IRnti:      iRnti,
+		SourcePci:  cell.PCI,
+		SourceRnti: ue.Rnti,
+	}
+	ue.RanPaging = &uecontext.RanPaging{
+		IRnti:     aper.BitString{Bytes: binary.BigEndian.AppendUint64(nil, iRnti<<24)[:5], NumBits: 40},
+		UeIdIndex: *assistance.UEIdentityIndexValue.IndexLength10,
+		PagingDRX: assistance.UESpecificDRX,
+		Tacs:      tacs,
+	}
+	ue.State = uecontext.UE_INACTIVE
+	ue.SrbPdcp[2] = nil
+	cu.Info("UE RAN-UE-NGAP-ID=%d suspended to RRC_INACTIVE with I-RNTI %010x in %d TA(s)",
+		ue.RanUeNgapId, iRnti, len(tacs))
+	if !firstSuspension {
+		return nil
+	}
+
+	if ue.HasBearerContext {
+		err = cu.sendBearerContextModification(ue, &e1ies.BearerContextModificationRequest{
+			BearerContextStatusChange: &e1ies.BearerContextStatusChange{Value: e1ies.BearerContextStatusChangeSuspend},
+		})
+		if err != nil {
+			cu.Error("Failed to suspend the bearer context: %v", err)
+		}
+	}
+	cu.reportRrcState(ue, ies.RRCStateInactive)
+	return nil
+}
+
+// iRnti builds the I-RNTI of a UE from the 16 least significant bits of the
+// gNB ID and the 24 of its RAN UE NGAP ID, the latter being the short I-RNTI
+func (cu *CuCpContext) iRnti(ue *uecontext.GNBUe) uint64 {
+	var gnbId uint64
+	for _, b := range cu.getGnbIdInBytes() {
+		gnbId = gnbId<<8 | uint64(b)
+	}
+	return (gnbId&0xFFFF)<<24 | uint64(ue.RanUeNgapId)&0xFFFFFF
+}
+
+// ranNotificationArea lists the TAs of the CU-CP PLMN in which the AMF lets
+// the UE move in RRC_INACTIVE, or else the TA of the cell the UE is released in
+func (cu *CuCpContext) ranNotificationArea(ue *uecontext.GNBUe, cell *du.ServedCell) [][]byte {
+	var tacs [][]byte
+	plmn := cu.GetMccAndMncInOctets()
+	for _, item := range ue.InactiveAssistance.TAIListForInactive {
+		if cu.plmnMatches(item.TAI.PLMNIdentity, plmn) &&
+			!slices.ContainsFunc(tacs, func(tac []byte) bool { return bytes.Equal(tac, item.TAI.TAC) }) {
+			tacs = append(tacs, item.TAI.TAC)
+		}
+	}
+	if len(tacs) == 0 {
+		tacs = append(tacs, cell.TAC)
+	}
+	return tacs
+}
+
+// suspendConfig gives the UE its I-RNTI and its RAN notification area. The
+// NCC stays the one of the K_gNB, the next K_gNB being derived from it.
+func (cu *CuCpContext) suspendConfig(iRnti uint64, tacs [][]byte, ncc uint8) *rrcies.SuspendConfig {
+	var areas []rrcies.RAN_AreaConfig
+	for _, tac := range tacs {
+		areas = append(areas, rrcies.RAN_AreaConfig{
+			TrackingAreaCode: rrcies.TrackingAreaCode{Value: asn1aper.BitString{Bytes: tac, NumBits: 24}},
+		})
+	}
+	t380 := cu.inactivePolicy.T380
+	return &rrcies.SuspendConfig{
+		FullI_RNTI: rrcies.I_RNTI_Value{
+			Value: asn1aper.BitString{Bytes: binary.BigEndian.AppendUint64(nil, iRnti<<24)[:5], NumBits: 40},
+		},
+		ShortI_RNTI: rrcies.ShortI_RNTI_Value{
+			Value: asn1aper.BitString{Bytes: binary.BigEndian.AppendUint32(nil, uint32(iRnti)<<8)[:3], NumBits: 24},
+		},
+		Ran_PagingCycle: cu.inactivePolicy.PagingCycle,
+		Ran_NotificationAreaInfo: &rrcies.RAN_NotificationAreaInfo{
+			Choice: rrcies.RAN_NotificationAreaInfo_Choice_Ran_AreaConfigList,
+			Ran_AreaConfigList: &rrcies.PLMN_RAN_AreaConfigList{
+				Value: []rrcies.PLMN_RAN_AreaConfig{{Ran_Area: areas}},
+			},
+		},
+		T380:                 &t380,
+		NextHopChainingCount: rrcies.NextHopChainingCount{Value: uint64(ncc)},
+	}
+}
+
+// resumeRequest is what RRCResumeRequest and RRCResumeRequest1 have in common
+type resumeRequest struct {
+	iRnti     uint64 // short I-RNTI unless full
+	full      bool
+	resumeMac asn1aper.BitString
+	cause     rrcies.ResumeCause
+}
+
+// handleRRCResumeRequest brings a UE back from RRC_INACTIVE in the cell of
+// the Initial UL RRC Message Transfer (TS 38.331 5.3.13). The UE context is
+// set up at the DU of that cell before the UE gets its RRCResume.
+func (cu *CuCpContext) handleRRCResumeRequest(
+	duCtx *du.GNBDU,
+	f1apMsg *f1ies.InitialULRRCMessageTransfer,
+	req resumeRequest,
+) error {
+	ue := cu.findSuspendedUE(req)
+	if ue == nil {
+		cu.Warn("RRC Resume Request with unknown I-RNTI %x, falling back to RRC Setup", req.iRnti)
+		return cu.fallbackToRRCSetup(duCtx, f1apMsg, establishmentCause(req.cause))
+	}
+
+	valid, err := resumeMacValid(ue, f1apMsg.NRCGI.NRCellIdentity, req.resumeMac)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		// the UE stays in RRC_INACTIVE and may try again
+		cu.Error("RRC Resume Request of UE RAN-UE-NGAP-ID=%d: resumeMAC-I check failed", ue.RanUeNgapId)
+		return cu.sendRRCReject(duCtx, f1apMsg)
+	}
+
+	// the UE refreshes its keys for the target cell along with its request
+	cell := duCtx.GetActiveCell(cu.extractCellIDValue(f1apMsg.NRCGI.NRCellIdentity))
+	asCtx, err := rekeyForCell(ue.AsSecurity, cell)
+	if err != nil {
+		return err
+	}
+	if err = decodeCellGroup(ue, f1apMsg.DUtoCURRCContainer); err != nil {
+		return err
+	}
+	cu.F1UePool.Delete(int64(cu.attachToCell(ue, duCtx, f1apMsg).GnbCuUeF1apId))
+
+	ue.AsSecurity = asCtx
+	srb1 := pdcp.NewSrbEntity(1)
+	srb1.Tx = srbSecurity(asCtx, true)
+	srb1.Rx = srbSecurity(asCtx, true)
+	ue.SrbPdcp[1] = srb1
+	ue.RanPaging = nil
+	ue.Suspension.Resuming = true
+	ue.State = uecontext.UE_ONGOING
+	cu.Info("UE RAN-UE-NGAP-ID=%d resuming in cell %x of DU %d (cause %d)",
+		ue.RanUeNgapId, f1apMsg.NRCGI.NRCellIdentity.Bytes, duCtx.DuId, req.cause.Value)
+
+	// nothing to deliver to a UE that only updates its RAN notification area
+	if req.cause.Value == rrcies.ResumeCause_Enum_rna_Update && len(ue.Suspension.PendingNas) == 0 &&
+		len(ue.Suspension.PendingNgap) == 0 {
+		return cu.suspendUE(ue)
+	}
+	if err = cu.sendF1UEContextSetupRequest(ue, nil); err != nil {
+		cu.failBearerRestore(ue)
+		return err
+	}
+	return nil
+}
+
+// findSuspendedUE looks up the UE in RRC_INACTIVE that has the I-RNTI of a
+// resume request
+func (cu *CuCpContext) findSuspendedUE(req resumeRequest) *uecontext.GNBUe {
+	mask := uint64(0xFFFFFF)
+	if req.full {
+		mask = 1<<40 - 1
+	}
+	var found *uecontext.GNBUe
+	cu.NgapUePool.Range(func(_, value any) bool {
+		ue, ok := value.(*uecontext.GNBUe)
+		if ok && ue.State == uecontext.UE_INACTIVE && ue.Suspension != nil && ue.Suspension.IRnti&mask == req.iRnti {
+			found = ue
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// resumeMacValid checks the resumeMAC-I the UE computes over the source and
+// target cells with the integrity key it was suspended with (TS 38.331
+// 5.3.13.3)
+func resumeMacValid(ue *uecontext.GNBUe, targetCell aper.BitString, resumeMac asn1aper.BitString) (bool, error) {
+	input := rrcies.VarResumeMAC_Input{
+		SourcePhysCellId:   rrcies.PhysCellId{Value: uint64(ue.Suspension.SourcePci)},
+		TargetCellIdentity: rrcies.CellIdentity{Value: asn1aper.BitString(targetCell)},
+		Source_c_RNTI:      rrcies.RNTI_Value{Value: uint64(ue.Suspension.SourceRnti)},
+	}
+	inputBytes, err := rrc.Encode(&input)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode VarResumeMAC-Input: %w", err)
+	}
+	return shortMacValid(ue.AsSecurity, inputBytes, resumeMac)
+}
+
+// establishmentCause maps the cause of a resume onto the one of an RRC setup,
+// an RNA update being mobile originated signalling
+func establishmentCause(cause rrcies.ResumeCause) rrcies.EstablishmentCause {
+	switch {
+	case cause.Value <= rrcies.ResumeCause_Enum_mo_SMS:
+		return rrcies.EstablishmentCause{Value: cause.Value}
+	case cause.Value == rrcies.ResumeCause_Enum_mps_PriorityAccess:
+		return rrcies.EstablishmentCause{Value: rrcies.EstablishmentCause_Enum_mps_PriorityAccess}
+	case cause.Value == rrcies.ResumeCause_Enum_mcs_PriorityAccess:
+		return rrcies.EstablishmentCause{Value: rrcies.EstablishmentCause_Enum_mcs_PriorityAccess}
+	}
+	return rrcies.EstablishmentCause{Value: rrcies.EstablishmentCause_Enum_mo_Signalling}
+}
+
+// sendRrcResume gives the UE the cell group of its new DU, its measurements in
+// the new cell, SRB2 and the DRBs being restored from its stored configuration
+func (cu *CuCpContext) sendRrcResume(ue *uecontext.GNBUe) error {
+	measConfig, meas := cu.measConfigFor(ue, cu.servingCell(ue))
+	resume := &rrcies.RRCResume_IEs{MeasConfig: measConfig}
+	if ue.MasterCellGroup != nil {
+		masterCellGroupBytes, err := rrc.Encode(ue.MasterCellGroup)
+		if err != nil {
+			return fmt.Errorf("failed to encode MasterCellGroup: %w", err)
+		}
+		resume.MasterCellGroup = &masterCellGroupBytes
+	}
+	// the rrc library cannot encode a measConfig
+	rrcBytes, err := rrcext.Encode(&rrcext.RRCResume{TransactionId: 0, IEs: resume})
+	if err != nil {
+		return fmt.Errorf("failed to encode RRC Resume: %w", err)
+	}
+
+	srb2 := pdcp.NewSrbEntity(2)
+	srb2.Tx = srbSecurity(ue.AsSecurity, true)
+	srb2.Rx = srbSecurity(ue.AsSecurity, true)
+	ue.SrbPdcp[2] = srb2
+
+	if err = cu.sendDlRrcMessage(ue, rrcBytes); err != nil {
+		return err
+	}
+	ue.Measurements = meas
+	cu.Info("RRC Resume sent to UE RAN-UE-NGAP-ID=%d through DU %d", ue.RanUeNgapId, ue.DuId)
+	return nil
+}
+
+// handleRRCResumeComplete ends the resume: the UE is back in RRC_CONNECTED,
+// the NAS PDUs and PDU session requests held while it was paged are delivered
+func (cu *CuCpContext) handleRRCResumeComplete(
+	ue *uecontext.GNBUe,
+	rrcResumeComplete *rrcies.RRCResumeComplete,
+) error {
+	if ue.Suspension == nil || !ue.Suspension.Resuming {
+		return fmt.Errorf("no RRC Resume was sent to the UE")
+	}
+	pendingNas := ue.Suspension.PendingNas
+	pendingNgap := ue.Suspension.PendingNgap
+	ue.Suspension = nil
+	ue.State = uecontext.UE_READY
+	cu.Info("UE RAN-UE-NGAP-ID=%d resumed to RRC_CONNECTED", ue.RanUeNgapId)
+	cu.reportRrcState(ue, ies.RRCStateConnected)
+
+	amf, err := cu.GetAMFById(ue.AmfId)
+	if err != nil {
+		return fmt.Errorf("AMF not found for UE: %v", err)
+	}
+	if complete := rrcResumeComplete.CriticalExtensions.RrcResumeComplete; complete != nil && complete.DedicatedNAS_Message != nil {
+		cu.SendNasPdu(complete.DedicatedNAS_Message.Value, ue, amf)
+	}
+	for _, nasPdu := range pendingNas {
+		if err = cu.sendDlNasPdu(ue, nasPdu); err != nil {
+			return fmt.Errorf("failed to deliver a NAS PDU held during paging: %w", err)
+		}
+	}
+	for _, request := range pendingNgap {
+		switch request := request.(type) {
+		case *ies.PDUSessionResourceSetupRequest:
+			cu.handlePduSessionResourceSetupRequest(amf, request)
+		case *ies.PDUSessionResourceModifyRequest:
+			cu.handlePduSessionResourceModifyRequest(amf, request)
+		case *ies.PDUSessionResourceReleaseCommand:
+			cu.handlePduSessionResourceReleaseCommand(amf, request)
+		}
+	}
+	return nil
+}
+
+// holdForResume keeps a PDU session request of the AMF for a UE in
+// RRC_INACTIVE, or still resuming, until it is back in RRC_CONNECTED: its DU
+// leg and SRB2 are gone until then. A UE in RRC_INACTIVE is paged, false is
+// returned when it cannot be.
+func (cu *CuCpContext) holdForResume(ue *uecontext.GNBUe, request any) bool {
+	if ue.State == uecontext.UE_INACTIVE {
+		if err := cu.SendRanPaging(ue); err != nil {
+			cu.Error("Failed to page UE RAN-UE-NGAP-ID=%d for a PDU session request: %v", ue.RanUeNgapId, err)
+			return false
+		}
+	}
+	ue.Suspension.PendingNgap = append(ue.Suspension.PendingNgap, request)
+	cu.Info("PDU session request for UE RAN-UE-NGAP-ID=%d held until it resumes", ue.RanUeNgapId)
+	return true
+}
+
+// unreachableCause fails what is asked for a UE in RRC_INACTIVE that cannot
+// be paged
+func unreachableCause() ies.Cause {
+	return radioNetworkCause(ies.CauseRadioNetworkUeinrrcinactivestatenotreachable)
+}
+
+// handleDLDataNotification pages a UE in RRC_INACTIVE the CU-UP has downlink
+// data for (TS 38.463 8.3.5)
+func (cu *CuCpContext) handleDLDataNotification(msg *e1ies.DLDataNotification) {
+	ue, err := cu.GetUEByE1Id(msg.GNBCUCPUEE1APID)
+	if err != nil {
+		cu.Error("DL Data Notification for unknown UE: %v", err)
+		return
+	}
+	if ue.State != uecontext.UE_INACTIVE {
+		cu.Info("DL Data Notification for UE RAN-UE-NGAP-ID=%d, which is not in RRC_INACTIVE", ue.RanUeNgapId)
+		return
+	}
+	if err = cu.SendRanPaging(ue); err != nil {
+		cu.Error("Failed to page UE RAN-UE-NGAP-ID=%d for DL data: %v", ue.RanUeNgapId, err)
+	}
+}
+
+// reportRrcState sends the RRC Inactive Transition Report the AMF asked for in
+// the Initial Context Setup Request: at every transition, or once at the next
+// return to RRC_CONNECTED
+func (cu *CuCpContext) reportRrcState(ue *uecontext.GNBUe, state aper.Enumerated) {
+	if ue.InactiveReport == nil {
+		return
+	}
+	switch ue.InactiveReport.Value {
+	case ies.RRCInactiveTransitionReportRequestSubsequentstatetransitionreport:
+	case ies.RRCInactiveTransitionReportRequestSinglerrcconnectedstatereport:
+		if state != ies.RRCStateConnected {
+			return
+		}
+		ue.InactiveReport = nil
+	default:
+		return
+	}
+	if err := cu.sendRrcInactiveTransitionReport(ue, state); err != nil {
+		cu.Error("Failed to send RRC Inactive Transition Report: %v", err)
+	}
+}
+
+func (cu *CuCpContext) sendRrcInactiveTransitionReport(ue *uecontext.GNBUe, state aper.Enumerated) error {
+	cellId := cu.GetNRCellIdentity()
+	if ue.NrCellId != nil {
+		cellId = *ue.NrCellId
+	}
+	msg := ies.RRCInactiveTransitionReport{
+		AMFUENGAPID: ue.AmfUeNgapId,
+		RANUENGAPID: ue.RanUeNgapId,
+		RRCState:    ies.RRCState{Value: state},
+		UserLocationInformation: ies.UserLocationInformation{
+			Choice: ies.UserLocationInformationPresentUserlocationinformationnr,
+			UserLocationInformationNR: &ies.UserLocationInformationNR{
+				NRCGI: ies.NRCGI{
+					NRCellIdentity: cellId,
+					PLMNIdentity:   cu.GetPLMNIdentity(),
+				},
+				TAI: ies.TAI{
+					PLMNIdentity: cu.GetPLMNIdentity(),
+					TAC:          cu.getTacInBytes(),
+				},
+			},
+		},
+	}
+
+	ngapBytes, err := ngap.NgapEncode(&msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode RRC Inactive Transition Report: %w", err)
+	}
+	amf, err := cu.GetAMFById(ue.AmfId)
+	if err != nil {
+		return fmt.Errorf("AMF not found: %v", err)
+	}
+	if err = amf.SendNgap(ngapBytes); err != nil {
+		return fmt.Errorf("failed to send NGAP message: %w", err)
+	}
+
+	cu.Info("NGAP RRC Inactive Transition Report sent to AMF (state %d)", state)
+	return nil
+}
+
+// ulCcch1MessageSize tells the UL-CCCH1 messages, RRCResumeRequest1 being the
+// only one, from the 48-bit UL-CCCH ones (TS 38.331 6.2.1)
+const ulCcch1MessageSize = 8
+
+// handleUlCcch1Message handles the RRCResumeRequest1 of a UE that was given a
+// full I-RNTI
+func (cu *CuCpContext) handleUlCcch1Message(duCtx *du.GNBDU, msg *f1ies.InitialULRRCMessageTransfer) error {
+	ulCcch1Msg := rrcies.UL_CCCH1_Message{}
+	if err := rrc.Decode(msg.RRCContainer, &ulCcch1Msg); err != nil {
+		return err
+	}
+	c1 := ulCcch1Msg.Message.C1
+	if ulCcch1Msg.Message.Choice != rrcies.UL_CCCH1_MessageType_Choice_C1 || c1 == nil ||
+		c1.Choice != rrcies.UL_CCCH1_MessageType_C1_Choice_RrcResumeRequest1 || c1.RrcResumeRequest1 == nil {
+		return fmt.Errorf("unsupported UL-CCCH1 message")
+	}
+	req := c1.RrcResumeRequest1.RrcResumeRequest1
+	return cu.handleRRCResumeRequest(duCtx, msg, resumeRequest{
+		iRnti:     utils.BitStringToUint64((*aper.BitString)(&req.ResumeIdentity.Value)),
+		full:      true,
+		resumeMac: req.ResumeMAC_I,
+		cause:     req.ResumeCause,
+	})
+}
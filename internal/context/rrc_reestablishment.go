@@ -0,0 +1,248 @@
+package context
+
+import (
+	"central-unit/internal/context/du"
+	"central-unit/internal/context/uecontext"
+	"central-unit/pkg/pdcp"
+	"fmt"
+
+	f1ies "github.com/JocelynWS/f1-gen/ies"
+	asn1aper "github.com/lvdund/asn1go/aper"
+	"github.com/lvdund/rrc"
+	rrcies "github.com/lvdund/rrc/ies"
+)
+
+// handleRRCReestablishmentRequest takes back a UE that lost its radio link
+// in the cell of the Initial UL RRC Message Transfer, at the same DU or
+// another one (TS 38.331 5.3.7, TS 38.401 8.7). A UE context that cannot be
+// found or used falls back to RRC Setup.
+func (cu *CuCpContext) handleRRCReestablishmentRequest(
+	duCtx *du.GNBDU,
+	f1apMsg *f1ies.InitialULRRCMessageTransfer,
+	req *rrcies.RRCReestablishmentRequest_IEs,
+) error {
+	identity := req.Ue_Identity
+	fallbackCause := rrcies.EstablishmentCause{Value: rrcies.EstablishmentCause_Enum_mo_Signalling}
+	ue := cu.findReestablishingUE(identity.C_RNTI.Value, identity.PhysCellId.Value)
+	if ue == nil {
+		cu.Warn("RRC Reestablishment Request of unknown C-RNTI %d in PCI %d, falling back to RRC Setup",
+			identity.C_RNTI.Value, identity.PhysCellId.Value)
+		return cu.fallbackToRRCSetup(duCtx, f1apMsg, fallbackCause)
+	}
+	if reason := cu.mobilityBlocker(ue); reason != "" {
+		cu.Warn("UE RAN-UE-NGAP-ID=%d cannot re-establish (%s), falling back to RRC Setup", ue.RanUeNgapId, reason)
+		return cu.fallbackToRRCSetup(duCtx, f1apMsg, fallbackCause)
+	}
+
+	input := rrcies.VarShortMAC_Input{
+		SourcePhysCellId:   identity.PhysCellId,
+		TargetCellIdentity: rrcies.CellIdentity{Value: asn1aper.BitString(f1apMsg.NRCGI.NRCellIdentity)},
+		Source_c_RNTI:      identity.C_RNTI,
+	}
+	inputBytes, err := rrc.Encode(&input)
+	if err != nil {
+		return fmt.Errorf("failed to encode VarShortMAC-Input: %w", err)
+	}
+	valid, err := shortMacValid(ue.AsSecurity, inputBytes, identity.ShortMAC_I.Value)
+	if err != nil {
+		return err
+	}
+	if !valid {
+		// the UE context is left to the UE it belongs to
+		cu.Error("RRC Reestablishment Request for UE RAN-UE-NGAP-ID=%d: shortMAC-I check failed", ue.RanUeNgapId)
+		return cu.fallbackToRRCSetup(duCtx, f1apMsg, fallbackCause)
+	}
+
+	cell := duCtx.GetActiveCell(cu.extractCellIDValue(f1apMsg.NRCGI.NRCellIdentity))
+	asCtx, err := rekeyForCell(ue.AsSecurity, cell)
+	if err != nil {
+		return err
+	}
+	if err = decodeCellGroup(ue, f1apMsg.DUtoCURRCContainer); err != nil {
+		return err
+	}
+	source := cu.attachToCell(ue, duCtx, f1apMsg)
+	ue.SourceDu = &source
+
+	// the RRCReestablishment is integrity protected only, SRB1 is ciphered
+	// again from the next message on
+	ue.AsSecurity = asCtx
+	srb1 := pdcp.NewSrbEntity(1)
+	srb1.Tx = srbSecurity(asCtx, false)
+	srb1.Rx = srbSecurity(asCtx, true)
+	ue.SrbPdcp[1] = srb1
+	ue.SrbPdcp[2] = nil
+	ue.Reestablishing = true
+	ue.State = uecontext.UE_ONGOING
+	cu.Info("UE RAN-UE-NGAP-ID=%d re-establishing in cell %x of DU %d, coming from DU %d (cause %d)",
+		ue.RanUeNgapId, f1apMsg.NRCGI.NRCellIdentity.Bytes, duCtx.DuId, source.DuId, req.ReestablishmentCause.Value)
+
+	err = cu.sendRrcReestablishment(ue)
+	srb1.Tx = srbSecurity(asCtx, true)
+	if err != nil {
+		cu.failBearerRestore(ue)
+		return err
+	}
+	return nil
+}
+
+// findReestablishingUE looks up the connected UE that had a C-RNTI in the
+// cell with a PCI
+func (cu *CuCpContext) findReestablishingUE(cRnti, pci uint64) *uecontext.GNBUe {
+	var found *uecontext.GNBUe
+	cu.F1UePool.Range(func(key, value any) bool {
+		ue, ok := value.(*uecontext.GNBUe)
+		if !ok || key != int64(ue.GnbCuUeF1apId) || uint64(ue.Rnti) != cRnti {
+			return true
+		}
+		if ue.State != uecontext.UE_ONGOING && ue.State != uecontext.UE_READY {
+			return true
+		}
+		if cell := cu.servingCell(ue); cell != nil && uint64(cell.PCI) == pci {
+			found = ue
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// mobilityBlocker tells why a UE context cannot move to another cell by
+// re-establishment or handover. AS security has to be active, and the CU-CP
+// moves SRB2 and the DRBs only when no procedure is changing them and the
+// UE context left at a previous DU is gone.
+func (cu *CuCpContext) mobilityBlocker(ue *uecontext.GNBUe) string {
+	if ue.AsSecurity == nil || !ue.AsSecurity.Active {
+		return "AS security not active"
+	}
+	if ue.InitialContextSetup != nil {
+		return "Initial Context Setup in progress"
+	}
+	if restoringBearers(ue) {
+		return "bearers already being restored"
+	}
+	if ue.Handover != nil {
+		return "handover in progress"
+	}
+	if ue.SourceDu != nil {
+		return "previous DU context not released yet"
+	}
+	for _, pduSession := range ue.PduSessions {
+		if pduSession.State != uecontext.PDU_SESSION_ACTIVE {
+			return fmt.Sprintf("PDU Session ID=%d not active", pduSession.PduSessionId)
+		}
+	}
+	return ""
+}
+
+// sendRrcReestablishment gives the UE the NCC of its new K_gNB on SRB1
+func (cu *CuCpContext) sendRrcReestablishment(ue *uecontext.GNBUe) error {
+	dlDcchMsg := rrcies.DL_DCCH_Message{
+		Message: rrcies.DL_DCCH_MessageType{
+			Choice: rrcies.DL_DCCH_MessageType_Choice_C1,
+			C1: &rrcies.DL_DCCH_MessageType_C1{
+				Choice: rrcies.DL_DCCH_MessageType_C1_Choice_RrcReestablishment,
+				RrcReestablishment: &rrcies.RRCReestablishment{
+					Rrc_TransactionIdentifier: rrcies.RRC_TransactionIdentifier{Value: 0},
+					CriticalExtensions: rrcies.RRCReestablishment_CriticalExtensions{
+						Choice: rrcies.RRCReestablishment_CriticalExtensions_Choice_RrcReestablishment,
+						RrcReestablishment: &rrcies.RRCReestablishment_IEs{
+							NextHopChainingCount: rrcies.NextHopChainingCount{Value: uint64(ue.AsSecurity.Ncc)},
+						},
+					},
+				},
+			},
+		},
+	}
+	rrcBytes, err := rrc.Encode(&dlDcchMsg)
+	if err != nil {
+		return fmt.Errorf("failed to encode RRC Reestablishment: %w", err)
+	}
+	if err = cu.sendDlRrcMessage(ue, rrcBytes); err != nil {
+		return err
+	}
+	cu.Info("RRC Reestablishment sent to UE RAN-UE-NGAP-ID=%d through DU %d", ue.RanUeNgapId, ue.DuId)
+	return nil
+}
+
+// handleRRCReestablishmentComplete releases the UE context left at the
+// previous DU and has the new DU set up SRB2, the DRBs following once it
+// answers
+func (cu *CuCpContext) handleRRCReestablishmentComplete(
+	ue *uecontext.GNBUe,
+	_ *rrcies.RRCReestablishmentComplete,
+) error {
+	if !ue.Reestablishing {
+		return fmt.Errorf("no RRC Reestablishment was sent to the UE")
+	}
+	cu.Info("UE RAN-UE-NGAP-ID=%d re-established its RRC connection", ue.RanUeNgapId)
+	cu.releaseSourceDu(ue)
+
+	if err := cu.sendF1UEContextSetupRequest(ue, nil); err != nil {
+		cu.failBearerRestore(ue)
+		return err
+	}
+	return nil
+}
+
+// sendReestablishmentReconfiguration resumes SRB2 and the DRBs the UE
+// suspended, with their PDCP entities re-established under the new keys
+func (cu *CuCpContext) sendReestablishmentReconfiguration(ue *uecontext.GNBUe) error {
+	measConfig, meas := cu.measConfigFor(ue, cu.servingCell(ue))
+	reconfig := &rrcies.RRCReconfiguration_IEs{RadioBearerConfig: reestablishedBearers(ue, 2), MeasConfig: measConfig}
+	if ue.MasterCellGroup != nil {
+		masterCellGroupBytes, err := rrc.Encode(ue.MasterCellGroup)
+		if err != nil {
+			return fmt.Errorf("failed to encode MasterCellGroup: %w", err)
+		}
+		reconfig.NonCriticalExtension = &rrcies.RRCReconfiguration_v1530_IEs{MasterCellGroup: &masterCellGroupBytes}
+	}
+	rrcBytes, err := encodeRrcReconfiguration(rrcTransactionInitialContext, reconfig)
+	if err != nil {
+		return err
+	}
+
+	srb2 := pdcp.NewSrbEntity(2)
+	srb2.Tx = srbSecurity(ue.AsSecurity, true)
+	srb2.Rx = srbSecurity(ue.AsSecurity, true)
+	ue.SrbPdcp[2] = srb2
+
+	if err = cu.sendRrcReconfiguration(ue, rrcBytes); err != nil {
+		return err
+	}
+	ue.Measurements = meas
+	return nil
+}
+
+// reestablishedBearers has the UE re-establish the PDCP entities of the given
+// SRBs and of all its DRBs
+func reestablishedBearers(ue *uecontext.GNBUe, srbIds ...uint64) *rrcies.RadioBearerConfig {
+	radioBearerConfig := &rrcies.RadioBearerConfig{Srb_ToAddModList: &rrcies.SRB_ToAddModList{}}
+	for _, srbId := range srbIds {
+		radioBearerConfig.Srb_ToAddModList.Value = append(radioBearerConfig.Srb_ToAddModList.Value, rrcies.SRB_ToAddMod{
+			Srb_Identity:    rrcies.SRB_Identity{Value: srbId},
+			ReestablishPDCP: &rrcies.SRB_ToAddMod_reestablishPDCP{Value: rrcies.SRB_ToAddMod_reestablishPDCP_Enum_true},
+		})
+	}
+	var drbToAddModList []rrcies.DRB_ToAddMod
+	for _, pduSession := range ue.PduSessions {
+		for _, drb := range pduSession.Drbs {
+			drbToAddModList = append(drbToAddModList, rrcies.DRB_ToAddMod{
+				Drb_Identity:    rrcies.DRB_Identity{Value: uint64(drb.DrbId)},
+				ReestablishPDCP: &rrcies.DRB_ToAddMod_reestablishPDCP{Value: rrcies.DRB_ToAddMod_reestablishPDCP_Enum_true},
+			})
+		}
+	}
+	if len(drbToAddModList) > 0 {
+		radioBearerConfig.Drb_ToAddModList = &rrcies.DRB_ToAddModList{Value: drbToAddModList}
+	}
+	return radioBearerConfig
+}
+
+// completeReestablishment ends the re-establishment once the UE has resumed
+// SRB2 and its DRBs
+func (cu *CuCpContext) completeReestablishment(ue *uecontext.GNBUe) {
+	ue.Reestablishing = false
+	cu.Info("UE RAN-UE-NGAP-ID=%d restored SRB2 and %d PDU session(s) after re-establishment",
+		ue.RanUeNgapId, len(ue.PduSessions))
+}
@@ -0,0 +1,68 @@
+package uecontext
+
+import "time"
+
+// Measurement events configured by the CU-CP (TS 38.331 5.5.4)
+const (
+	MEAS_EVENT_A2 uint8 = 2 // serving cell becomes worse than threshold
+	MEAS_EVENT_A3 uint8 = 3 // neighbour becomes offset better than the serving cell
+	MEAS_EVENT_A5 uint8 = 5 // serving cell worse than threshold1, neighbour better than threshold2
+)
+
+// Measurements is the measurement configuration of a UE under the identities
+// it was given, with the last report for each measurement identity
+type Measurements struct {
+	Objects       map[uint64]*MeasObject // by measObjectId
+	ReportConfigs map[uint64]uint8       // MEAS_EVENT_* by reportConfigId
+	MeasIds       map[uint64]MeasId      // by measId
+	Reports       map[uint64]*MeasReport // last report by measId
+}
+
+// MeasObject is an SSB frequency the UE measures, with the PCIs of the
+// neighbour cells listed on it
+type MeasObject struct {
+	SsbArfcn uint64
+	Pcis     []uint16
+}
+
+// MeasId links a measurement object to the report configuration of an event
+type MeasId struct {
+	ObjectId       uint64
+	ReportConfigId uint64
+}
+
+// MeasReport is a MeasurementReport of the UE. RSRP values are the
+// RSRP-Range of TS 38.133, 0 below -156 dBm and 127 from -30 dBm.
+type MeasReport struct {
+	MeasId      uint64
+	Event       uint8      // MEAS_EVENT_*
+	SsbArfcn    uint64     // frequency of the measurement object
+	ServingRsrp *uint64    // SSB RSRP of the serving cell, if reported
+	Neighbours  []CellRsrp // strongest first
+	Received    time.Time
+}
+
+// CellRsrp is the SSB RSRP of a cell
+type CellRsrp struct {
+	Pci  uint16
+	Rsrp uint64
+}
+
+// BestNeighbour is the strongest neighbour cell of the report, nil without any
+func (r *MeasReport) BestNeighbour() *CellRsrp {
+	if len(r.Neighbours) == 0 {
+		return nil
+	}
+	return &r.Neighbours[0]
+}
+
+// LastReport is the most recent report of an event, nil without any
+func (m *Measurements) LastReport(event uint8) *MeasReport {
+	var last *MeasReport
+	for _, report := range m.Reports {
+		if report.Event == event && (last == nil || report.Received.After(last.Received)) {
+			last = report
+		}
+	}
+	return last
+}
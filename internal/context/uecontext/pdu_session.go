@@ -1,6 +1,9 @@
 package uecontext
 
 import (
+	"slices"
+
+	"github.com/lvdund/ngap/aper"
 	"github.com/lvdund/ngap/ies"
 )
 
@@ -16,24 +19,157 @@ const (
 // PduSessionContext represents a PDU session associated with a UE
 type PduSessionContext struct {
 	// Session Identifiers
-	PduSessionId uint8 // PDU Session ID (1-15)
-	State        uint8 // PDU_SESSION_*
+	PduSessionId   uint8 // PDU Session ID (1-15)
+	State          uint8 // PDU_SESSION_*
+	PduSessionType aper.Enumerated
 
 	// QoS and Bearer Information
-	Snssai   *ies.SNSSAI       // S-NSSAI for this session
-	Dnn      string            // Data Network Name
-	QosFlows []*QosFlowContext // List of QoS flows in this session
+	Snssai             *ies.SNSSAI             // S-NSSAI for this session
+	Dnn                string                  // Data Network Name
+	QosFlows           []*QosFlowContext       // List of QoS flows in this session
+	SecurityIndication *ies.SecurityIndication // UP security policy requested by the SMF
+
+	// Session-AMBR, nil if the SMF did not provide one
+	Ambr *ies.PDUSessionAggregateMaximumBitRate
 
-	// Data Radio Bearer mapping
-	DrbId uint8 // DRB ID assigned to this session
+	// Data Radio Bearers carrying the QoS flows, the first one is the default DRB
+	Drbs []*DrbContext
 
-	// GTP Tunnel Information (for reference - actual tunneling out of scope)
-	// These would come from CU-UP via E1AP
-	UlTeid uint32 // Uplink GTP TEID
-	DlTeid uint32 // Downlink GTP TEID
+	// GTP Tunnel Information
+	UlNguTunnel *GtpTunnel // UPF endpoint, from the NGAP transfer
+	DlNguTunnel *GtpTunnel // CU-UP endpoint towards the UPF, reported to the AMF
 
 	// NAS PDU
 	NasPduSessionAccept []byte // PDU Session Establishment Accept NAS PDU
+
+	// Ongoing PDU Session Resource Modify, nil outside PDU_SESSION_MODIFYING
+	Modification *PduSessionModification
+}
+
+// Stages of a PDU Session Resource Modify
+const (
+	PDU_SESSION_MODIFY_CUUP uint8 = iota // waiting for the Bearer Context Modification Response
+	PDU_SESSION_MODIFY_DU                // waiting for the F1 UE Context Modification Response
+	PDU_SESSION_MODIFY_UE                // waiting for the RRCReconfigurationComplete
+)
+
+// PduSessionModification holds the changes requested by the SMF for a PDU
+// session until they are confirmed, so that they can be reported in the PDU
+// Session Resource Modify Response or undone when a node rejects them.
+type PduSessionModification struct {
+	Stage  uint8                                  // PDU_SESSION_MODIFY_*
+	NasPdu []byte                                 // PDU Session Modification Command NAS PDU
+	Ambr   *ies.PDUSessionAggregateMaximumBitRate // new Session-AMBR, applied once confirmed
+
+	AddedFlows    []*QosFlowContext
+	ModifiedFlows []*QosFlowContext
+	PreviousQos   map[uint8]QosFlowContext // parameters of the modified flows before the request
+	ReleasedFlows map[uint8][]uint8        // released QFIs, by the DRB that carried them
+
+	NewDrbs      []uint8 // DRBs set up for added flows
+	ModifiedDrbs []uint8 // DRBs whose flow mapping or QoS changed
+	ReleasedDrbs []uint8 // DRBs left without QoS flows
+
+	FailedFlows []ies.QosFlowWithCauseItem // flows that could not be added or modified
+}
+
+// DrbContext is a data radio bearer of a PDU session and the QoS flows mapped to it
+type DrbContext struct {
+	DrbId    uint8 // DRB ID (1-32), unique within the UE
+	Default  bool  // default DRB of the session, carries flows without a mapping
+	QosFlows []*QosFlowContext
+
+	UlF1uTunnel *GtpTunnel // CU-UP endpoint towards the DU, sent in F1 UE Context Modification
+	DlF1uTunnel *GtpTunnel // DU endpoint, pushed to the CU-UP with Bearer Context Modification
+}
+
+// GetDrb returns the DRB of the session with the given ID, nil if there is none
+func (ps *PduSessionContext) GetDrb(drbId uint8) *DrbContext {
+	for _, drb := range ps.Drbs {
+		if drb.DrbId == drbId {
+			return drb
+		}
+	}
+	return nil
+}
+
+// RemoveDrb drops a DRB from the session and marks its QoS flows as failed
+func (ps *PduSessionContext) RemoveDrb(drbId uint8) {
+	for i, drb := range ps.Drbs {
+		if drb.DrbId != drbId {
+			continue
+		}
+		for _, flow := range drb.QosFlows {
+			flow.Failed = true
+		}
+		ps.Drbs = append(ps.Drbs[:i], ps.Drbs[i+1:]...)
+		return
+	}
+}
+
+// GetQosFlow returns the QoS flow of the session with the given QFI, nil if
+// there is none
+func (ps *PduSessionContext) GetQosFlow(qfi uint8) *QosFlowContext {
+	for _, flow := range ps.QosFlows {
+		if flow.Qfi == qfi {
+			return flow
+		}
+	}
+	return nil
+}
+
+// DrbOfFlow returns the DRB carrying the QoS flow with the given QFI
+func (ps *PduSessionContext) DrbOfFlow(qfi uint8) *DrbContext {
+	for _, drb := range ps.Drbs {
+		for _, flow := range drb.QosFlows {
+			if flow.Qfi == qfi {
+				return drb
+			}
+		}
+	}
+	return nil
+}
+
+// RemoveQosFlow drops a QoS flow from the session and from its DRB, which is
+// returned so that the caller can tell whether it still carries flows
+func (ps *PduSessionContext) RemoveQosFlow(qfi uint8) *DrbContext {
+	isFlow := func(flow *QosFlowContext) bool { return flow.Qfi == qfi }
+	ps.QosFlows = slices.DeleteFunc(ps.QosFlows, isFlow)
+	drb := ps.DrbOfFlow(qfi)
+	if drb != nil {
+		drb.QosFlows = slices.DeleteFunc(drb.QosFlows, isFlow)
+	}
+	return drb
+}
+
+// HasDefaultDrb reports whether the default DRB of the session is still set up
+func (ps *PduSessionContext) HasDefaultDrb() bool {
+	for _, drb := range ps.Drbs {
+		if drb.Default {
+			return true
+		}
+	}
+	return false
+}
+
+// DrbsSetupAtDu reports whether the DU has returned its F1-U tunnel for
+// every DRB of the session
+func (ps *PduSessionContext) DrbsSetupAtDu() bool {
+	if len(ps.Drbs) == 0 {
+		return false
+	}
+	for _, drb := range ps.Drbs {
+		if drb.DlF1uTunnel == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// GtpTunnel is a GTP-U endpoint (transport address and TEID)
+type GtpTunnel struct {
+	Address []byte // IPv4 or IPv6 transport layer address
+	Teid    uint32
 }
 
 // QosFlowContext represents a QoS flow within a PDU session
@@ -41,8 +177,16 @@ type QosFlowContext struct {
 	QosFlowId uint8 // QoS Flow Identifier (0-63)
 	Qfi       uint8 // QoS Flow Identifier (same as above)
 	FiveQi    int64 // 5QI value (e.g., 9 for default, 1 for voice)
-	Priority  uint8 // Allocation and Retention Priority
+	Priority  uint8 // Allocation and Retention Priority level (1-15)
+
+	// Allocation and Retention Priority
+	PreemptionCapability    aper.Enumerated
+	PreemptionVulnerability aper.Enumerated
+
+	// QoS characteristics
+	PriorityLevelQos *int64                    // overrides the standardized 5QI priority
+	Dynamic5Qi       *ies.Dynamic5QIDescriptor // set for dynamically assigned 5QIs
+	Gbr              *ies.GBRQosInformation    // bit rates of a GBR flow, nil for non-GBR
 
-	// QoS parameters (simplified - full structure in NGAP spec)
-	// TODO: Add GBR (Guaranteed Bit Rate) parameters if needed
+	Failed bool // not admitted by the CU-UP
 }
@@ -14,7 +14,89 @@ const (
 	HDP_MOBILITY_UPDATE
 )
 
+// Algorithm type distinguishers of TS 33.501 Annex A.8
+const (
+	algTypeRrcEnc uint8 = 0x03
+	algTypeRrcInt uint8 = 0x04
+	algTypeUpEnc  uint8 = 0x05
+	algTypeUpInt  uint8 = 0x06
+)
+
+// AsContext is the AS security context shared by the gNB and the UE, keyed
+// by the K_gNB the AMF provides
 type AsContext struct {
+	CipheringAlg uint8 // NEA identity
+	IntegrityAlg uint8 // NIA identity
+	Active       bool  // Security Mode procedure completed
+	Ncc          uint8 // NextHopChainingCount the K_gNB is bound to
+
+	kgnb    []byte
+	krrcEnc []byte
+	krrcInt []byte
+	kupEnc  []byte
+	kupInt  []byte
+}
+
+// NewAsContext derives the RRC and UP keys for the selected algorithms
+func NewAsContext(kgnb []byte, cipheringAlg, integrityAlg uint8) (*AsContext, error) {
+	ctx := &AsContext{
+		CipheringAlg: cipheringAlg,
+		IntegrityAlg: integrityAlg,
+		kgnb:         make([]byte, len(kgnb)),
+	}
+	copy(ctx.kgnb, kgnb)
+
+	var err error
+	if ctx.krrcEnc, err = asAlgKey(kgnb, algTypeRrcEnc, cipheringAlg); err != nil {
+		return nil, err
+	}
+	if ctx.krrcInt, err = asAlgKey(kgnb, algTypeRrcInt, integrityAlg); err != nil {
+		return nil, err
+	}
+	if ctx.kupEnc, err = asAlgKey(kgnb, algTypeUpEnc, cipheringAlg); err != nil {
+		return nil, err
+	}
+	if ctx.kupInt, err = asAlgKey(kgnb, algTypeUpInt, integrityAlg); err != nil {
+		return nil, err
+	}
+	return ctx, nil
+}
+
+// asAlgKey keeps the 128 least significant bits of the KDF output
+func asAlgKey(kgnb []byte, algType, algId uint8) ([]byte, error) {
+	sum, err := AlgKey(kgnb, []byte{algType}, []byte{algId})
+	if err != nil {
+		return nil, err
+	}
+	return sum[16:], nil
+}
+
+// KgnbStar derives the K_gNB* of a horizontal key derivation towards a target
+// cell, from its PCI and downlink ARFCN (TS 33.501 A.11)
+func (ctx *AsContext) KgnbStar(pci uint16, dlArfcn uint32) ([]byte, error) {
+	p0 := binary.BigEndian.AppendUint16(nil, pci)
+	p1 := binary.BigEndian.AppendUint32(nil, dlArfcn)[1:]
+	return KgnbStarKey(ctx.kgnb, p0, p1)
+}
+
+func (ctx *AsContext) Kgnb() []byte {
+	return ctx.kgnb
+}
+
+func (ctx *AsContext) KrrcEnc() []byte {
+	return ctx.krrcEnc
+}
+
+func (ctx *AsContext) KrrcInt() []byte {
+	return ctx.krrcInt
+}
+
+func (ctx *AsContext) KupEnc() []byte {
+	return ctx.kupEnc
+}
+
+func (ctx *AsContext) KupInt() []byte {
+	return ctx.kupInt
 }
 
 type SecurityContext struct {
@@ -17,6 +17,7 @@ const (
 	FC_FOR_KAMF_PRIME_DERIVATION         = "72"
 	FC_FOR_KGNB_KN3IWF_DERIVATION        = "6E"
 	FC_FOR_NH_DERIVATION                 = "6F"
+	FC_FOR_KGNB_STAR_DERIVATION          = "70"
 )
 
 func kdfLen(input []byte) []byte {
@@ -65,6 +66,10 @@ func NhKey(key []byte, p ...[]byte) (sum []byte, err error) {
 	sum, err = KDF(key, FC_FOR_NH_DERIVATION, p...)
 	return
 }
+func KgnbStarKey(key []byte, p ...[]byte) (sum []byte, err error) {
+	sum, err = KDF(key, FC_FOR_KGNB_STAR_DERIVATION, p...)
+	return
+}
 
 func KAMF(kseaf, supi, abba []byte) (kamf []byte, err error) {
 	kamf, err = KDF(kseaf, FC_FOR_KAMF_DERIVATION, supi, abba)
@@ -4,8 +4,10 @@ import (
 	"central-unit/internal/common/logger"
 	"central-unit/internal/transport"
 	"central-unit/pkg/model"
+	"central-unit/pkg/pdcp"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/lvdund/ngap/aper"
 	"github.com/lvdund/ngap/ies"
@@ -13,31 +15,105 @@ import (
 	rrcies "github.com/lvdund/rrc/ies"
 )
 
+// MaxDrbId is the highest DRB ID (maxDRB in TS 38.331)
+const MaxDrbId = 32
+
 // UE main states in the GNB Context.
 const (
 	UE_INITIALIZED uint8 = iota
 	UE_ONGOING
 	UE_READY
 	UE_DOWN
+	UE_INACTIVE
 )
 
+// InitialContextSetup follows an Initial Context Setup Request until the UE
+// completes the RRCReconfiguration that ends it
+type InitialContextSetup struct {
+	NasPdu           []byte      // delivered with the RRCReconfiguration
+	DuReady          bool        // F1 UE Context Setup Response received
+	BearersRequested bool        // PDU sessions of the request sent to the CU-UP
+	ReconfigSent     bool        // RRCReconfiguration sent to the UE
+	Timer            *time.Timer // fails the procedure on expiry
+}
+
+// RanPaging is what the CU-CP needs to page a UE in RRC_INACTIVE
+type RanPaging struct {
+	IRnti     aper.BitString // full I-RNTI, 40 bits
+	UeIdIndex aper.BitString // UE Identity Index Value, 10 bits
+	PagingDRX *ies.PagingDRX // UE specific DRX, if any
+	Tacs      [][]byte       // TAs of the RAN notification area
+}
+
+// Suspension keeps what a UE in RRC_INACTIVE resumes with, until its
+// RRCResumeComplete
+type Suspension struct {
+	IRnti       uint64   // full I-RNTI, the 24 least significant bits are the short one
+	SourcePci   uint16   // PCI of the cell the UE was suspended in
+	SourceRnti  int64    // C-RNTI of the UE in that cell
+	Resuming    bool     // RRCResumeRequest accepted
+	PendingNas  [][]byte // downlink NAS PDUs received while the UE was paged
+	PendingNgap []any    // PDU session requests of the AMF held until the resume completes
+}
+
+// DuLeg is the UE context a UE leaves at a DU when it moves to another cell,
+// kept until that DU confirms its release
+type DuLeg struct {
+	DuId          uint64
+	DuUeId        uint64
+	GnbCuUeF1apId uint64
+}
+
+// Handover follows an intra-CU handover of a UE to a cell of another DU,
+// from the UE Context Setup Request to the target DU until the UE completes
+// the RRCReconfiguration there
+type Handover struct {
+	Target       DuLeg          // UE context being set up at the target DU
+	TargetCellId aper.BitString // NR Cell Identity of the target cell
+	DuReady      bool           // UE Context Setup Response received from the target DU
+	ReconfigSent bool           // RRCReconfiguration with sync sent, the UE now belongs to the target DU
+	Timer        *time.Timer    // fails the handover on expiry
+
+	MeasConfig   *rrcies.MeasConfig // sent with the RRCReconfiguration, nil to leave measurements alone
+	Measurements *Measurements      // the UE has in the target cell
+
+	Source *HandoverSource // until the source DU confirms it delivered the RRCReconfiguration
+}
+
+// HandoverSource is the UE context a UE had in its source cell, restored when
+// the source DU cannot deliver the handover command
+type HandoverSource struct {
+	Leg             DuLeg
+	NrCellId        *aper.BitString
+	Rnti            int64
+	MasterCellGroup *rrcies.CellGroupConfig
+	Measurements    *Measurements
+	AsSecurity      *AsContext
+	SrbPdcp         [3]*pdcp.SrbEntity
+	DlF1uTunnels    map[uint8]*GtpTunnel // by DRB ID
+}
+
 type GNBUe struct {
 	RanUeNgapId int64 // Identifier for UE in GNB Context.
 	AmfUeNgapId int64 // Identifier for UE in AMF Context.
 	AmfId       int64 // Identifier for AMF in UE/GNB Context.
 	State       uint8 // State of UE in NAS/GNB Context.
+	NgReleasing bool  // UE Context Release Command received from the AMF
 
 	SctpConnection *transport.SctpConn // Sctp ue vs amf.
 
-	Auth   AuthContext
-	SecCtx SecurityContext
+	Auth       AuthContext
+	SecCtx     SecurityContext
+	AsSecurity *AsContext // set up from the Initial Context Setup Request
+
+	SrbPdcp [3]*pdcp.SrbEntity // PDCP entities of SRB1 and SRB2, indexed by SRB ID
 
 	// check
 	*logger.Logger
 	Lock sync.Mutex
 
 	// oai
-	RrcUeId            uint64
+	RrcUeId            uint64 // CU-CP internal, the DU knows the UE by its GnbCuUeF1apId
 	DuId               uint64
 	DuUeId             uint64
 	GnbCuUeF1apId      uint64
@@ -49,7 +125,17 @@ type GNBUe struct {
 	MasterCellGroup    *rrcies.CellGroupConfig
 	EstablishmentCause *rrcies.EstablishmentCause
 
-	RegistrationAccept []byte
+	InitialContextSetup *InitialContextSetup // procedure in progress with the AMF
+	RanPaging           *RanPaging           // set while the UE is in RRC_INACTIVE
+	Suspension          *Suspension          // set from the suspension until the resume completes
+	Reestablishing      bool                 // from the RRCReestablishment until SRB2 and the DRBs are restored
+	SourceDu            *DuLeg               // UE context being released at the DU the UE left
+	Handover            *Handover            // intra-CU handover in progress
+	Measurements        *Measurements        // measurement configuration and last reports
+
+	// RRC_INACTIVE assistance and state reporting asked for by the AMF
+	InactiveAssistance *ies.CoreNetworkAssistanceInformationForInactive
+	InactiveReport     *ies.RRCInactiveTransitionReportRequest
 
 	// stormsim: UE context
 	MobilityInfo           utils.PlmnId
@@ -60,8 +146,23 @@ type GNBUe struct {
 	// PduSession             [16]*GnbPDUSession
 
 	// PDU Session Management
-	PduSessions       map[uint8]*PduSessionContext // key: PDU Session ID (1-15)
-	NumActiveSessions uint8
+	PduSessions               map[uint8]*PduSessionContext // key: PDU Session ID (1-15)
+	NumActiveSessions         uint8
+	UeAmbr                    *ies.UEAggregateMaximumBitRate // UE-AMBR signalled by the AMF
+	FailedPduSessions         []ies.PDUSessionResourceFailedToSetupItemSURes
+	ReleasedPduSessions       []ies.PDUSessionResourceReleasedItemRelRes
+	ModifiedPduSessions       []ies.PDUSessionResourceModifyItemModRes
+	FailedToModifyPduSessions []ies.PDUSessionResourceFailedToModifyItemModRes
+	drbIds                    uint64 // bit n set while DRB ID n is in use
+
+	// E1AP bearer context
+	CuUpId           int64  // gNB-CU-UP hosting the bearer context
+	GnbCuCpUeE1apId  uint64 // allocated by the CU-CP
+	GnbCuUpUeE1apId  uint64 // allocated by the CU-UP in Bearer Context Setup Response
+	HasBearerContext bool   // bearer context established at the CU-UP
+	BearerSetupSent  bool   // Bearer Context Setup Request awaiting its answer
+
+	BearerSetupQueue []*PduSessionContext // sessions requested meanwhile, added once the bearer context exists
 }
 
 func (ue *GNBUe) CreateUeContext(plmn string, imeisv string, allowednssai []model.Snssai, ueSecurityCapabilities *ies.UESecurityCapabilities) {
@@ -77,6 +178,33 @@ func (ue *GNBUe) CreateUeContext(plmn string, imeisv string, allowednssai []mode
 	ue.UeSecurityCapabilities = ueSecurityCapabilities
 }
 
+// AllocateDrbId returns the lowest DRB ID not in use by the UE, so that
+// released IDs are handed out again
+func (ue *GNBUe) AllocateDrbId() (uint8, error) {
+	for id := uint8(1); id <= MaxDrbId; id++ {
+		if ue.drbIds&(1<<id) == 0 {
+			ue.drbIds |= 1 << id
+			return id, nil
+		}
+	}
+	return 0, fmt.Errorf("all %d DRB IDs are in use", MaxDrbId)
+}
+
+// ReleaseDrbId makes a DRB ID available again
+func (ue *GNBUe) ReleaseDrbId(drbId uint8) {
+	ue.drbIds &^= 1 << drbId
+}
+
+// FindDrb looks up a DRB and the PDU session it belongs to
+func (ue *GNBUe) FindDrb(drbId uint8) (*PduSessionContext, *DrbContext) {
+	for _, ps := range ue.PduSessions {
+		if drb := ps.GetDrb(drbId); drb != nil {
+			return ps, drb
+		}
+	}
+	return nil, nil
+}
+
 func convertMccMnc(plmn string) (mcc string, mnc string) {
 	if plmn[2] == 'f' {
 		mcc = fmt.Sprintf("%c%c%c", plmn[1], plmn[0], plmn[3])
@@ -1,8 +1,11 @@
 package config
 
 import (
+	"central-unit/pkg/model"
 	"fmt"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
 
@@ -15,6 +18,10 @@ type Config struct {
 	F1AP     F1APConfig     `yaml:"f1ap"`
 	E1AP     E1APConfig     `yaml:"e1ap"`
 	NGAP     NGAPConfig     `yaml:"ngap"`
+	Bearers  BearerConfig   `yaml:"bearers"`
+	Security SecurityConfig `yaml:"security"`
+	RRC      RRCConfig      `yaml:"rrc"`
+	Mobility MobilityConfig `yaml:"mobility"`
 	Logging  LoggingConfig  `yaml:"logging"`
 	Features FeatureFlags   `yaml:"features"`
 	Tunables TunablesConfig `yaml:"tunables"`
@@ -45,7 +52,9 @@ type SCTPConfig struct {
 }
 
 type F1Timers struct {
-	F1Setup time.Duration `yaml:"f1_setup_timer"`
+	F1Setup     time.Duration `yaml:"f1_setup_timer"`
+	DuReconnect time.Duration `yaml:"du_reconnect_timer"`
+	Handover    time.Duration `yaml:"handover_timer"`
 }
 
 type F1APConfig struct {
@@ -61,15 +70,135 @@ type E1APConfig struct {
 	SCTP         SCTPConfig `yaml:"sctp"`
 }
 
+type NGTimers struct {
+	InitialContextSetup time.Duration `yaml:"initial_context_setup_timer"`
+}
+
+// NGAPConfig lists the AMFs in `amfs`, `amf_address` and `amf_port` being a
+// shorthand for a single one
 type NGAPConfig struct {
-	GnbId        string     `yaml:"gnb_id"`
-	AMFAddress   string     `yaml:"amf_address"`
-	AMFPort      int        `yaml:"amf_port"`
-	LocalAddress string     `yaml:"local_address"`
-	LocalPort    int        `yaml:"local_port"`
-	SCTP         SCTPConfig `yaml:"sctp"`
+	GnbId        string      `yaml:"gnb_id"`
+	AMFAddress   string      `yaml:"amf_address"`
+	AMFPort      int         `yaml:"amf_port"`
+	AMFs         []model.AMF `yaml:"amfs"`
+	LocalAddress string      `yaml:"local_address"`
+	LocalPort    int         `yaml:"local_port"`
+	SCTP         SCTPConfig  `yaml:"sctp"`
+	Timers       NGTimers    `yaml:"timers"`
+}
+
+// QoS flow to DRB mapping policies
+const (
+	DrbMappingPerSession = "per_session"  // one DRB carrying every flow of the session
+	DrbMappingPer5QI     = "per_5qi"      // one DRB per 5QI
+	DrbMappingPerGBRFlow = "per_gbr_flow" // one DRB per GBR flow, non-GBR flows share one
+)
+
+type BearerConfig struct {
+	DrbMapping string `yaml:"drb_mapping"`
 }
 
+// AS security algorithms, as named in TS 33.501
+var (
+	CipheringAlgorithms = []string{"nea0", "nea1", "nea2", "nea3"}
+	IntegrityAlgorithms = []string{"nia0", "nia1", "nia2", "nia3"}
+)
+
+// SecurityConfig lists the AS algorithms in order of preference, the first
+// one the UE supports is used
+type SecurityConfig struct {
+	Ciphering []string `yaml:"ciphering"`
+	Integrity []string `yaml:"integrity"`
+}
+
+// RAN paging cycles in radio frames and periodic RNA update timers (T380) a
+// UE in RRC_INACTIVE can be configured with, in the order of TS 38.331
+var (
+	RanPagingCycles         = []int{32, 64, 128, 256}
+	PeriodicRnaUpdateTimers = []time.Duration{
+		5 * time.Minute, 10 * time.Minute, 20 * time.Minute, 30 * time.Minute,
+		60 * time.Minute, 120 * time.Minute, 360 * time.Minute, 720 * time.Minute,
+	}
+)
+
+// RRCConfig sets how UEs are admitted and released. A UE turned down by an
+// RRCReject waits reject_wait_time, from 1 to 16 seconds, before it tries
+// again.
+type RRCConfig struct {
+	RejectWaitTime time.Duration   `yaml:"reject_wait_time"`
+	Admission      AdmissionConfig `yaml:"admission"`
+	Inactive       InactiveConfig  `yaml:"inactive"`
+}
+
+// AdmissionConfig caps the UE contexts the CU-CP holds, in all and per DU,
+// 0 leaving them uncapped
+type AdmissionConfig struct {
+	MaxUEs      int `yaml:"max_ues"`
+	MaxUEsPerDU int `yaml:"max_ues_per_du"`
+}
+
+// InactiveConfig is the suspendConfig of the UEs released to RRC_INACTIVE,
+// which features.connected_inactive turns on
+type InactiveConfig struct {
+	RanPagingCycle         int           `yaml:"ran_paging_cycle"`
+	PeriodicRnaUpdateTimer time.Duration `yaml:"periodic_rna_update_timer"`
+}
+
+// MobilityConfig is what connected UEs measure and report. Events are on SSB
+// RSRP, thresholds in dBm, offsets and hysteresis in dB by steps of 0.5.
+type MobilityConfig struct {
+	Neighbours     []NeighbourListConfig `yaml:"neighbours"`
+	A2             EventConfig           `yaml:"a2"`
+	A3             EventConfig           `yaml:"a3"`
+	A5             EventConfig           `yaml:"a5"`
+	ReportInterval time.Duration         `yaml:"report_interval"`
+}
+
+// NeighbourListConfig replaces the default neighbours of a cell, all the
+// other cells of the CU-CP, with the listed ones
+type NeighbourListConfig struct {
+	CellID     string            `yaml:"cell_id"` // NR Cell Identity, in hex
+	Neighbours []NeighbourConfig `yaml:"neighbours"`
+}
+
+// NeighbourConfig is a cell of the CU-CP by its NR Cell Identity, or a cell
+// of another gNB by its PCI and SSB frequency
+type NeighbourConfig struct {
+	CellID    string `yaml:"cell_id"`
+	PCI       int    `yaml:"pci"`
+	SsbArfcn  int    `yaml:"ssb_arfcn"`
+	SsbScsKHz int    `yaml:"ssb_scs_khz"`
+}
+
+// EventConfig is a measurement event. A2 reports a serving cell below
+// threshold, A3 a neighbour offset better than the serving cell and A5 a
+// serving cell below threshold with a neighbour above threshold2.
+type EventConfig struct {
+	Enabled       bool          `yaml:"enabled"`
+	Threshold     int           `yaml:"threshold"`
+	Threshold2    int           `yaml:"threshold2"`
+	Offset        float64       `yaml:"offset"`
+	Hysteresis    float64       `yaml:"hysteresis"`
+	TimeToTrigger time.Duration `yaml:"time_to_trigger"`
+}
+
+// Measurement report intervals and times to trigger of TS 38.331, in order
+var (
+	ReportIntervals = []time.Duration{
+		120 * time.Millisecond, 240 * time.Millisecond, 480 * time.Millisecond, 640 * time.Millisecond,
+		1024 * time.Millisecond, 2048 * time.Millisecond, 5120 * time.Millisecond, 10240 * time.Millisecond,
+		20480 * time.Millisecond, 40960 * time.Millisecond, time.Minute, 6 * time.Minute,
+		12 * time.Minute, 30 * time.Minute,
+	}
+	TimesToTrigger = []time.Duration{
+		0, 40 * time.Millisecond, 64 * time.Millisecond, 80 * time.Millisecond,
+		100 * time.Millisecond, 128 * time.Millisecond, 160 * time.Millisecond, 256 * time.Millisecond,
+		320 * time.Millisecond, 480 * time.Millisecond, 512 * time.Millisecond, 640 * time.Millisecond,
+		1024 * time.Millisecond, 1280 * time.Millisecond, 2560 * time.Millisecond, 5120 * time.Millisecond,
+	}
+	SsbSubcarrierSpacings = []int{15, 30, 60, 120, 240}
+)
+
 type LoggingConfig struct {
 	Level  string `yaml:"level"`
 	Format string `yaml:"format"`
@@ -126,6 +255,12 @@ func (c Config) Validate() error {
 	if c.F1AP.Timers.F1Setup <= 0 {
 		problems = append(problems, "f1ap.timers.f1_setup_timer must be >0")
 	}
+	if c.F1AP.Timers.DuReconnect < 0 {
+		problems = append(problems, "f1ap.timers.du_reconnect_timer must be >=0")
+	}
+	if c.F1AP.Timers.Handover <= 0 {
+		problems = append(problems, "f1ap.timers.handover_timer must be >0")
+	}
 
 	if err := validateEndpoint("e1ap", c.E1AP.LocalAddress, c.E1AP.LocalPort); err != nil {
 		problems = append(problems, err.Error())
@@ -134,8 +269,13 @@ func (c Config) Validate() error {
 		problems = append(problems, err.Error())
 	}
 
-	if c.NGAP.AMFAddress == "" || c.NGAP.AMFPort <= 0 {
-		problems = append(problems, "ngap.amf_address and ngap.amf_port are required")
+	if len(c.NGAP.AMFs) == 0 {
+		problems = append(problems, "ngap.amfs (or ngap.amf_address and ngap.amf_port) is required")
+	}
+	for i, amf := range c.NGAP.AMFs {
+		if amf.Ip == "" || amf.Port <= 0 {
+			problems = append(problems, fmt.Sprintf("ngap.amfs[%d]: ip and port are required", i))
+		}
 	}
 	if c.NGAP.LocalAddress == "" {
 		problems = append(problems, "ngap.local_address is required")
@@ -143,6 +283,39 @@ func (c Config) Validate() error {
 	if err := validateSCTP("ngap.sctp", c.NGAP.SCTP); err != nil {
 		problems = append(problems, err.Error())
 	}
+	if c.NGAP.Timers.InitialContextSetup <= 0 {
+		problems = append(problems, "ngap.timers.initial_context_setup_timer must be >0")
+	}
+
+	switch c.Bearers.DrbMapping {
+	case DrbMappingPerSession, DrbMappingPer5QI, DrbMappingPerGBRFlow:
+	default:
+		problems = append(problems, fmt.Sprintf("bearers.drb_mapping must be one of %q, %q or %q",
+			DrbMappingPerSession, DrbMappingPer5QI, DrbMappingPerGBRFlow))
+	}
+
+	if err := validateAlgorithms(c.Security.Ciphering, CipheringAlgorithms); err != nil {
+		problems = append(problems, fmt.Sprintf("security.ciphering: %v", err))
+	}
+	if err := validateAlgorithms(c.Security.Integrity, IntegrityAlgorithms); err != nil {
+		problems = append(problems, fmt.Sprintf("security.integrity: %v", err))
+	}
+
+	if c.RRC.RejectWaitTime < time.Second || c.RRC.RejectWaitTime > 16*time.Second ||
+		c.RRC.RejectWaitTime%time.Second != 0 {
+		problems = append(problems, "rrc.reject_wait_time must be a whole number of seconds from 1s to 16s")
+	}
+	if c.RRC.Admission.MaxUEs < 0 || c.RRC.Admission.MaxUEsPerDU < 0 {
+		problems = append(problems, "rrc.admission.max_ues and rrc.admission.max_ues_per_du must be >=0")
+	}
+	if !slices.Contains(RanPagingCycles, c.RRC.Inactive.RanPagingCycle) {
+		problems = append(problems, "rrc.inactive.ran_paging_cycle must be 32, 64, 128 or 256 radio frames")
+	}
+	if !slices.Contains(PeriodicRnaUpdateTimers, c.RRC.Inactive.PeriodicRnaUpdateTimer) {
+		problems = append(problems, "rrc.inactive.periodic_rna_update_timer must be one of 5m, 10m, 20m, 30m, 1h, 2h, 6h or 12h")
+	}
+
+	problems = append(problems, c.Mobility.validate()...)
 
 	if c.Logging.Level == "" {
 		problems = append(problems, "logging.level is required")
@@ -158,6 +331,36 @@ func (c Config) Validate() error {
 }
 
 func (c *Config) applyDefaults() {
+	if len(c.NGAP.AMFs) == 0 && c.NGAP.AMFAddress != "" {
+		c.NGAP.AMFs = []model.AMF{{Ip: c.NGAP.AMFAddress, Port: c.NGAP.AMFPort}}
+	}
+	if c.F1AP.Timers.Handover == 0 {
+		c.F1AP.Timers.Handover = 5 * time.Second
+	}
+	if c.NGAP.Timers.InitialContextSetup == 0 {
+		c.NGAP.Timers.InitialContextSetup = 10 * time.Second
+	}
+	if c.Bearers.DrbMapping == "" {
+		c.Bearers.DrbMapping = DrbMappingPerSession
+	}
+	if len(c.Security.Ciphering) == 0 {
+		c.Security.Ciphering = []string{"nea2", "nea1", "nea0"}
+	}
+	if len(c.Security.Integrity) == 0 {
+		c.Security.Integrity = []string{"nia2", "nia1"}
+	}
+	if c.RRC.RejectWaitTime == 0 {
+		c.RRC.RejectWaitTime = 10 * time.Second
+	}
+	if c.RRC.Inactive.RanPagingCycle == 0 {
+		c.RRC.Inactive.RanPagingCycle = 128
+	}
+	if c.RRC.Inactive.PeriodicRnaUpdateTimer == 0 {
+		c.RRC.Inactive.PeriodicRnaUpdateTimer = time.Hour
+	}
+	if c.Mobility.ReportInterval == 0 {
+		c.Mobility.ReportInterval = 480 * time.Millisecond
+	}
 	if c.Logging.Level == "" {
 		c.Logging.Level = "info"
 	}
@@ -193,9 +396,96 @@ func validateEndpoint(name, addr string, port int) error {
 	return nil
 }
 
+func validateAlgorithms(names []string, known []string) error {
+	if len(names) == 0 {
+		return fmt.Errorf("at least one algorithm is required")
+	}
+	for _, name := range names {
+		if !slices.Contains(known, name) {
+			return fmt.Errorf("unknown algorithm %q, expected one of %s", name, strings.Join(known, ", "))
+		}
+	}
+	return nil
+}
+
 func validateSCTP(name string, cfg SCTPConfig) error {
 	if cfg.InStreams == 0 || cfg.OutStreams == 0 {
 		return fmt.Errorf("%s.in_streams and %s.out_streams must be non-zero", name, name)
 	}
 	return nil
 }
+
+func (m MobilityConfig) validate() []string {
+	var problems []string
+	for i, list := range m.Neighbours {
+		if _, err := ParseCellID(list.CellID); err != nil {
+			problems = append(problems, fmt.Sprintf("mobility.neighbours[%d].cell_id: %v", i, err))
+		}
+		for j, neighbour := range list.Neighbours {
+			if err := neighbour.validate(); err != nil {
+				problems = append(problems, fmt.Sprintf("mobility.neighbours[%d].neighbours[%d]: %v", i, j, err))
+			}
+		}
+	}
+	if err := m.A2.validate(1); err != nil {
+		problems = append(problems, fmt.Sprintf("mobility.a2: %v", err))
+	}
+	if err := m.A3.validate(0); err != nil {
+		problems = append(problems, fmt.Sprintf("mobility.a3: %v", err))
+	}
+	if err := m.A5.validate(2); err != nil {
+		problems = append(problems, fmt.Sprintf("mobility.a5: %v", err))
+	}
+	if !slices.Contains(ReportIntervals, m.ReportInterval) {
+		problems = append(problems, "mobility.report_interval must be a report interval of TS 38.331, 120ms to 30m")
+	}
+	return problems
+}
+
+func (n NeighbourConfig) validate() error {
+	if n.CellID != "" {
+		_, err := ParseCellID(n.CellID)
+		return err
+	}
+	if n.PCI < 0 || n.PCI > 1007 {
+		return fmt.Errorf("pci must be from 0 to 1007")
+	}
+	if n.SsbArfcn <= 0 || n.SsbArfcn > 3279165 {
+		return fmt.Errorf("ssb_arfcn must be from 1 to 3279165")
+	}
+	if !slices.Contains(SsbSubcarrierSpacings, n.SsbScsKHz) {
+		return fmt.Errorf("ssb_scs_khz must be 15, 30, 60, 120 or 240")
+	}
+	return nil
+}
+
+// validate checks an event using its first thresholds only
+func (e EventConfig) validate(thresholds int) error {
+	if !e.Enabled {
+		return nil
+	}
+	// RSRP thresholds are signalled from -156 dBm, offsets up to 15 dB
+	if thresholds >= 1 && (e.Threshold < -156 || e.Threshold > -30) ||
+		thresholds >= 2 && (e.Threshold2 < -156 || e.Threshold2 > -30) {
+		return fmt.Errorf("thresholds must be from -156 to -30 dBm")
+	}
+	if e.Offset < -15 || e.Offset > 15 || e.Offset*2 != float64(int(e.Offset*2)) {
+		return fmt.Errorf("offset must be from -15 to 15 dB by steps of 0.5")
+	}
+	if e.Hysteresis < 0 || e.Hysteresis > 15 || e.Hysteresis*2 != float64(int(e.Hysteresis*2)) {
+		return fmt.Errorf("hysteresis must be from 0 to 15 dB by steps of 0.5")
+	}
+	if !slices.Contains(TimesToTrigger, e.TimeToTrigger) {
+		return fmt.Errorf("time_to_trigger must be a time to trigger of TS 38.331, 0 to 5120ms")
+	}
+	return nil
+}
+
+// ParseCellID reads a 36-bit NR Cell Identity written in hex
+func ParseCellID(cellID string) (uint64, error) {
+	id, err := strconv.ParseUint(cellID, 16, 64)
+	if err != nil || id >= 1<<36 {
+		return 0, fmt.Errorf("%q is not a 36-bit NR Cell Identity in hex", cellID)
+	}
+	return id, nil
+}
@@ -0,0 +1,62 @@
+package e1ap
+
+import (
+	"bytes"
+	"fmt"
+
+	"central-unit/pkg/e1ap/ies"
+	"github.com/lvdund/ngap/aper"
+)
+
+func E1apDecode(buf []byte) (pdu E1apPdu, err error, diagnostics *ies.CriticalityDiagnostics) {
+	r := aper.NewReader(bytes.NewBuffer(buf))
+	if _, err = r.ReadBool(); err != nil {
+		return
+	}
+	c, err := r.ReadChoice(2, false)
+	if err != nil {
+		return
+	}
+	present := uint8(c)
+	v, err := r.ReadInteger(&aper.Constraint{Lb: 0, Ub: 255}, false)
+	if err != nil {
+		return
+	}
+	var procedureCode = ies.ProcedureCode{Value: aper.Integer(v)}
+	e, err := r.ReadEnumerate(aper.Constraint{Lb: 0, Ub: 2}, false)
+	if err != nil {
+		return
+	}
+	var criticality = ies.Criticality{Value: aper.Enumerated(e)}
+	var containerBytes []byte
+	if containerBytes, err = r.ReadOpenType(); err != nil {
+		return
+	}
+	message := createMessage(present, procedureCode)
+	if message == nil {
+		err = fmt.Errorf("Unknown E1AP message (present=%d, procedureCode=%d)", present, int64(procedureCode.Value))
+		return
+	}
+
+	var diagnosticsItems []ies.CriticalityDiagnosticsIEItem
+	if err, diagnosticsItems = message.Decode(containerBytes); err != nil {
+		return
+	}
+
+	pdu = E1apPdu{
+		Present: present,
+		Message: E1apMessage{
+			ProcedureCode: ies.ProcedureCode{Value: procedureCode.Value},
+			Criticality:   ies.Criticality{Value: criticality.Value},
+			Msg:           message,
+		},
+	}
+	if len(diagnosticsItems) > 0 {
+		diagnostics = ies.BuildDiagnostics(present, procedureCode, criticality, int64(procedureCode.Value), diagnosticsItems)
+	}
+	return
+}
+
+type MessageUnmarshaller interface {
+	Decode(buf []byte) (error, []ies.CriticalityDiagnosticsIEItem)
+}
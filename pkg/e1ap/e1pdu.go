@@ -0,0 +1,55 @@
+package e1ap
+
+import "central-unit/pkg/e1ap/ies"
+
+type E1apPdu struct {
+	Present uint8
+	Message E1apMessage
+}
+
+type E1apMessage struct {
+	ProcedureCode ies.ProcedureCode
+	Criticality   ies.Criticality
+	Msg           MessageUnmarshaller
+}
+
+func createMessage(present uint8, procedureCode ies.ProcedureCode) MessageUnmarshaller {
+	switch present {
+	case ies.E1apPduInitiatingMessage:
+		switch int64(procedureCode.Value) {
+		case ies.ProcedureCode_GNBCUUPE1Setup:
+			return new(ies.GNBCUUPE1SetupRequest)
+		case ies.ProcedureCode_BearerContextSetup:
+			return new(ies.BearerContextSetupRequest)
+		case ies.ProcedureCode_BearerContextModification:
+			return new(ies.BearerContextModificationRequest)
+		case ies.ProcedureCode_BearerContextRelease:
+			return new(ies.BearerContextReleaseCommand)
+		case ies.ProcedureCode_BearerContextReleaseRequest:
+			return new(ies.BearerContextReleaseRequest)
+		case ies.ProcedureCode_DLDataNotification:
+			return new(ies.DLDataNotification)
+		}
+	case ies.E1apPduSuccessfulOutcome:
+		switch int64(procedureCode.Value) {
+		case ies.ProcedureCode_GNBCUUPE1Setup:
+			return new(ies.GNBCUUPE1SetupResponse)
+		case ies.ProcedureCode_BearerContextSetup:
+			return new(ies.BearerContextSetupResponse)
+		case ies.ProcedureCode_BearerContextModification:
+			return new(ies.BearerContextModificationResponse)
+		case ies.ProcedureCode_BearerContextRelease:
+			return new(ies.BearerContextReleaseComplete)
+		}
+	case ies.E1apPduUnsuccessfulOutcome:
+		switch int64(procedureCode.Value) {
+		case ies.ProcedureCode_GNBCUUPE1Setup:
+			return new(ies.GNBCUUPE1SetupFailure)
+		case ies.ProcedureCode_BearerContextSetup:
+			return new(ies.BearerContextSetupFailure)
+		case ies.ProcedureCode_BearerContextModification:
+			return new(ies.BearerContextModificationFailure)
+		}
+	}
+	return nil
+}
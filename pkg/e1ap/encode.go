@@ -0,0 +1,18 @@
+package e1ap
+
+import (
+	"bytes"
+	"io"
+)
+
+func E1apEncode(msg E1apMessageEncoder) (wire []byte, err error) {
+	var buf bytes.Buffer
+	if err = msg.Encode(&buf); err == nil {
+		wire = buf.Bytes()
+	}
+	return
+}
+
+type E1apMessageEncoder interface {
+	Encode(io.Writer) error
+}
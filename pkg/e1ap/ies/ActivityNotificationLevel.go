@@ -0,0 +1,23 @@
+package ies
+
+import "github.com/lvdund/ngap/aper"
+
+const (
+	ActivityNotificationLevelDrb        aper.Enumerated = 0
+	ActivityNotificationLevelPdusession aper.Enumerated = 1
+	ActivityNotificationLevelUe         aper.Enumerated = 2
+)
+
+type ActivityNotificationLevel struct {
+	Value aper.Enumerated
+}
+
+func (ie *ActivityNotificationLevel) Encode(w *aper.AperWriter) (err error) {
+	err = w.WriteEnumerate(uint64(ie.Value), aper.Constraint{Lb: 0, Ub: 2}, true)
+	return
+}
+func (ie *ActivityNotificationLevel) Decode(r *aper.AperReader) (err error) {
+	v, err := r.ReadEnumerate(aper.Constraint{Lb: 0, Ub: 2}, true)
+	ie.Value = aper.Enumerated(v)
+	return
+}
@@ -0,0 +1,176 @@
+package ies
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type BearerContextModificationRequest struct {
+	GNBCUCPUEE1APID                        int64
+	GNBCUUPUEE1APID                        int64
+	SecurityInformation                    *SecurityInformation
+	UEDLAggregateMaximumBitRate            *int64
+	BearerContextStatusChange              *BearerContextStatusChange
+	UEInactivityTimer                      *int64
+	SystemBearerContextModificationRequest *SystemBearerContextModificationRequest
+}
+
+func (msg *BearerContextModificationRequest) Encode(w io.Writer) (err error) {
+	var ies []E1apMessageIE
+	if ies, err = msg.toIes(); err != nil {
+		err = msgErrors(fmt.Errorf("BearerContextModificationRequest"), err)
+		return
+	}
+	return encodeMessage(w, E1apPduInitiatingMessage, ProcedureCode_BearerContextModification, Criticality_PresentReject, ies)
+}
+func (msg *BearerContextModificationRequest) toIes() (ies []E1apMessageIE, err error) {
+	ies = []E1apMessageIE{}
+	ies = append(ies, E1apMessageIE{
+		Id:          ProtocolIEID{Value: ProtocolIEID_GNBCUCPUEE1APID},
+		Criticality: Criticality{Value: Criticality_PresentReject},
+		Value: &INTEGER{
+			c:     aper.Constraint{Lb: 0, Ub: 4294967295},
+			ext:   false,
+			Value: aper.Integer(msg.GNBCUCPUEE1APID),
+		},
+	})
+	ies = append(ies, E1apMessageIE{
+		Id:          ProtocolIEID{Value: ProtocolIEID_GNBCUUPUEE1APID},
+		Criticality: Criticality{Value: Criticality_PresentReject},
+		Value: &INTEGER{
+			c:     aper.Constraint{Lb: 0, Ub: 4294967295},
+			ext:   false,
+			Value: aper.Integer(msg.GNBCUUPUEE1APID),
+		},
+	})
+	if msg.SecurityInformation != nil {
+		ies = append(ies, E1apMessageIE{
+			Id:          ProtocolIEID{Value: ProtocolIEID_SecurityInformation},
+			Criticality: Criticality{Value: Criticality_PresentReject},
+			Value:       msg.SecurityInformation,
+		})
+	}
+	if msg.UEDLAggregateMaximumBitRate != nil {
+		ies = append(ies, E1apMessageIE{
+			Id:          ProtocolIEID{Value: ProtocolIEID_UEDLAggregateMaximumBitRate},
+			Criticality: Criticality{Value: Criticality_PresentReject},
+			Value: &INTEGER{
+				c:     aper.Constraint{Lb: 0, Ub: 4000000000000},
+				ext:   true,
+				Value: aper.Integer(*msg.UEDLAggregateMaximumBitRate),
+			},
+		})
+	}
+	if msg.BearerContextStatusChange != nil {
+		ies = append(ies, E1apMessageIE{
+			Id:          ProtocolIEID{Value: ProtocolIEID_BearerContextStatusChange},
+			Criticality: Criticality{Value: Criticality_PresentReject},
+			Value:       msg.BearerContextStatusChange,
+		})
+	}
+	if msg.UEInactivityTimer != nil {
+		ies = append(ies, E1apMessageIE{
+			Id:          ProtocolIEID{Value: ProtocolIEID_UEInactivityTimer},
+			Criticality: Criticality{Value: Criticality_PresentReject},
+			Value: &INTEGER{
+				c:     aper.Constraint{Lb: 1, Ub: 7200},
+				ext:   true,
+				Value: aper.Integer(*msg.UEInactivityTimer),
+			},
+		})
+	}
+	if msg.SystemBearerContextModificationRequest != nil {
+		ies = append(ies, E1apMessageIE{
+			Id:          ProtocolIEID{Value: ProtocolIEID_SystemBearerContextModificationRequest},
+			Criticality: Criticality{Value: Criticality_PresentReject},
+			Value:       msg.SystemBearerContextModificationRequest,
+		})
+	}
+	return
+}
+func (msg *BearerContextModificationRequest) Decode(wire []byte) (err error, diagList []CriticalityDiagnosticsIEItem) {
+	defer func() {
+		if err != nil {
+			err = msgErrors(fmt.Errorf("BearerContextModificationRequest"), err)
+		}
+	}()
+	diagList, err = decodeMessage(wire, msg.decodeIE, []mandatoryIE{
+		{ProtocolIEID_GNBCUCPUEE1APID, "GNBCUCPUEE1APID", Criticality_PresentReject},
+		{ProtocolIEID_GNBCUUPUEE1APID, "GNBCUUPUEE1APID", Criticality_PresentReject},
+	})
+	return
+}
+func (msg *BearerContextModificationRequest) decodeIE(id aper.Integer, ieR *aper.AperReader) (known bool, err error) {
+	known = true
+	switch id {
+	case ProtocolIEID_GNBCUCPUEE1APID:
+		tmp := INTEGER{
+			c:   aper.Constraint{Lb: 0, Ub: 4294967295},
+			ext: false,
+		}
+		if err = tmp.Decode(ieR); err != nil {
+			err = utils.WrapError("Read GNBCUCPUEE1APID", err)
+			return
+		}
+		msg.GNBCUCPUEE1APID = int64(tmp.Value)
+	case ProtocolIEID_GNBCUUPUEE1APID:
+		tmp := INTEGER{
+			c:   aper.Constraint{Lb: 0, Ub: 4294967295},
+			ext: false,
+		}
+		if err = tmp.Decode(ieR); err != nil {
+			err = utils.WrapError("Read GNBCUUPUEE1APID", err)
+			return
+		}
+		msg.GNBCUUPUEE1APID = int64(tmp.Value)
+	case ProtocolIEID_SecurityInformation:
+		var tmp SecurityInformation
+		if err = tmp.Decode(ieR); err != nil {
+			err = utils.WrapError("Read SecurityInformation", err)
+			return
+		}
+		msg.SecurityInformation = &tmp
+	case ProtocolIEID_UEDLAggregateMaximumBitRate:
+		tmp := INTEGER{
+			c:   aper.Constraint{Lb: 0, Ub: 4000000000000},
+			ext: true,
+		}
+		if err = tmp.Decode(ieR); err != nil {
+			err = utils.WrapError("Read UEDLAggregateMaximumBitRate", err)
+			return
+		}
+		v := int64(tmp.Value)
+		msg.UEDLAggregateMaximumBitRate = &v
+	case ProtocolIEID_BearerContextStatusChange:
+		var tmp BearerContextStatusChange
+		if err = tmp.Decode(ieR); err != nil {
+			err = utils.WrapError("Read BearerContextStatusChange", err)
+			return
+		}
+		msg.BearerContextStatusChange = &tmp
+	case ProtocolIEID_UEInactivityTimer:
+		tmp := INTEGER{
+			c:   aper.Constraint{Lb: 1, Ub: 7200},
+			ext: true,
+		}
+		if err = tmp.Decode(ieR); err != nil {
+			err = utils.WrapError("Read UEInactivityTimer", err)
+			return
+		}
+		v := int64(tmp.Value)
+		msg.UEInactivityTimer = &v
+	case ProtocolIEID_SystemBearerContextModificationRequest:
+		var tmp SystemBearerContextModificationRequest
+		if err = tmp.Decode(ieR); err != nil {
+			err = utils.WrapError("Read SystemBearerContextModificationRequest", err)
+			return
+		}
+		msg.SystemBearerContextModificationRequest = &tmp
+	default:
+		known = false
+	}
+	return
+}
@@ -0,0 +1,100 @@
+package ies
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type BearerContextReleaseComplete struct {
+	GNBCUCPUEE1APID        int64
+	GNBCUUPUEE1APID        int64
+	CriticalityDiagnostics *CriticalityDiagnostics
+}
+
+func (msg *BearerContextReleaseComplete) Encode(w io.Writer) (err error) {
+	var ies []E1apMessageIE
+	if ies, err = msg.toIes(); err != nil {
+		err = msgErrors(fmt.Errorf("BearerContextReleaseComplete"), err)
+		return
+	}
+	return encodeMessage(w, E1apPduSuccessfulOutcome, ProcedureCode_BearerContextRelease, Criticality_PresentReject, ies)
+}
+func (msg *BearerContextReleaseComplete) toIes() (ies []E1apMessageIE, err error) {
+	ies = []E1apMessageIE{}
+	ies = append(ies, E1apMessageIE{
+		Id:          ProtocolIEID{Value: ProtocolIEID_GNBCUCPUEE1APID},
+		Criticality: Criticality{Value: Criticality_PresentReject},
+		Value: &INTEGER{
+			c:     aper.Constraint{Lb: 0, Ub: 4294967295},
+			ext:   false,
+			Value: aper.Integer(msg.GNBCUCPUEE1APID),
+		},
+	})
+	ies = append(ies, E1apMessageIE{
+		Id:          ProtocolIEID{Value: ProtocolIEID_GNBCUUPUEE1APID},
+		Criticality: Criticality{Value: Criticality_PresentReject},
+		Value: &INTEGER{
+			c:     aper.Constraint{Lb: 0, Ub: 4294967295},
+			ext:   false,
+			Value: aper.Integer(msg.GNBCUUPUEE1APID),
+		},
+	})
+	if msg.CriticalityDiagnostics != nil {
+		ies = append(ies, E1apMessageIE{
+			Id:          ProtocolIEID{Value: ProtocolIEID_CriticalityDiagnostics},
+			Criticality: Criticality{Value: Criticality_PresentIgnore},
+			Value:       msg.CriticalityDiagnostics,
+		})
+	}
+	return
+}
+func (msg *BearerContextReleaseComplete) Decode(wire []byte) (err error, diagList []CriticalityDiagnosticsIEItem) {
+	defer func() {
+		if err != nil {
+			err = msgErrors(fmt.Errorf("BearerContextReleaseComplete"), err)
+		}
+	}()
+	diagList, err = decodeMessage(wire, msg.decodeIE, []mandatoryIE{
+		{ProtocolIEID_GNBCUCPUEE1APID, "GNBCUCPUEE1APID", Criticality_PresentReject},
+		{ProtocolIEID_GNBCUUPUEE1APID, "GNBCUUPUEE1APID", Criticality_PresentReject},
+	})
+	return
+}
+func (msg *BearerContextReleaseComplete) decodeIE(id aper.Integer, ieR *aper.AperReader) (known bool, err error) {
+	known = true
+	switch id {
+	case ProtocolIEID_GNBCUCPUEE1APID:
+		tmp := INTEGER{
+			c:   aper.Constraint{Lb: 0, Ub: 4294967295},
+			ext: false,
+		}
+		if err = tmp.Decode(ieR); err != nil {
+			err = utils.WrapError("Read GNBCUCPUEE1APID", err)
+			return
+		}
+		msg.GNBCUCPUEE1APID = int64(tmp.Value)
+	case ProtocolIEID_GNBCUUPUEE1APID:
+		tmp := INTEGER{
+			c:   aper.Constraint{Lb: 0, Ub: 4294967295},
+			ext: false,
+		}
+		if err = tmp.Decode(ieR); err != nil {
+			err = utils.WrapError("Read GNBCUUPUEE1APID", err)
+			return
+		}
+		msg.GNBCUUPUEE1APID = int64(tmp.Value)
+	case ProtocolIEID_CriticalityDiagnostics:
+		var tmp CriticalityDiagnostics
+		if err = tmp.Decode(ieR); err != nil {
+			err = utils.WrapError("Read CriticalityDiagnostics", err)
+			return
+		}
+		msg.CriticalityDiagnostics = &tmp
+	default:
+		known = false
+	}
+	return
+}
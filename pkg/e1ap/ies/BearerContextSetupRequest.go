@@ -0,0 +1,194 @@
+package ies
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type BearerContextSetupRequest struct {
+	GNBCUCPUEE1APID                       int64
+	SecurityInformation                   SecurityInformation
+	UEDLAggregateMaximumBitRate           int64
+	UEDLMaximumIntegrityProtectedDataRate *int64
+	ServingPLMN                           []byte
+	ActivityNotificationLevel             ActivityNotificationLevel
+	UEInactivityTimer                     *int64
+	SystemBearerContextSetupRequest       SystemBearerContextSetupRequest
+}
+
+func (msg *BearerContextSetupRequest) Encode(w io.Writer) (err error) {
+	var ies []E1apMessageIE
+	if ies, err = msg.toIes(); err != nil {
+		err = msgErrors(fmt.Errorf("BearerContextSetupRequest"), err)
+		return
+	}
+	return encodeMessage(w, E1apPduInitiatingMessage, ProcedureCode_BearerContextSetup, Criticality_PresentReject, ies)
+}
+func (msg *BearerContextSetupRequest) toIes() (ies []E1apMessageIE, err error) {
+	ies = []E1apMessageIE{}
+	ies = append(ies, E1apMessageIE{
+		Id:          ProtocolIEID{Value: ProtocolIEID_GNBCUCPUEE1APID},
+		Criticality: Criticality{Value: Criticality_PresentReject},
+		Value: &INTEGER{
+			c:     aper.Constraint{Lb: 0, Ub: 4294967295},
+			ext:   false,
+			Value: aper.Integer(msg.GNBCUCPUEE1APID),
+		},
+	})
+	ies = append(ies, E1apMessageIE{
+		Id:          ProtocolIEID{Value: ProtocolIEID_SecurityInformation},
+		Criticality: Criticality{Value: Criticality_PresentReject},
+		Value:       &msg.SecurityInformation,
+	})
+	ies = append(ies, E1apMessageIE{
+		Id:          ProtocolIEID{Value: ProtocolIEID_UEDLAggregateMaximumBitRate},
+		Criticality: Criticality{Value: Criticality_PresentReject},
+		Value: &INTEGER{
+			c:     aper.Constraint{Lb: 0, Ub: 4000000000000},
+			ext:   true,
+			Value: aper.Integer(msg.UEDLAggregateMaximumBitRate),
+		},
+	})
+	if msg.UEDLMaximumIntegrityProtectedDataRate != nil {
+		ies = append(ies, E1apMessageIE{
+			Id:          ProtocolIEID{Value: ProtocolIEID_UEDLMaximumIntegrityProtectedDataRate},
+			Criticality: Criticality{Value: Criticality_PresentReject},
+			Value: &INTEGER{
+				c:     aper.Constraint{Lb: 0, Ub: 4000000000000},
+				ext:   true,
+				Value: aper.Integer(*msg.UEDLMaximumIntegrityProtectedDataRate),
+			},
+		})
+	}
+	ies = append(ies, E1apMessageIE{
+		Id:          ProtocolIEID{Value: ProtocolIEID_ServingPLMN},
+		Criticality: Criticality{Value: Criticality_PresentIgnore},
+		Value: &OCTETSTRING{
+			c:     aper.Constraint{Lb: 3, Ub: 3},
+			ext:   false,
+			Value: msg.ServingPLMN,
+		},
+	})
+	ies = append(ies, E1apMessageIE{
+		Id:          ProtocolIEID{Value: ProtocolIEID_ActivityNotificationLevel},
+		Criticality: Criticality{Value: Criticality_PresentReject},
+		Value:       &msg.ActivityNotificationLevel,
+	})
+	if msg.UEInactivityTimer != nil {
+		ies = append(ies, E1apMessageIE{
+			Id:          ProtocolIEID{Value: ProtocolIEID_UEInactivityTimer},
+			Criticality: Criticality{Value: Criticality_PresentReject},
+			Value: &INTEGER{
+				c:     aper.Constraint{Lb: 1, Ub: 7200},
+				ext:   true,
+				Value: aper.Integer(*msg.UEInactivityTimer),
+			},
+		})
+	}
+	ies = append(ies, E1apMessageIE{
+		Id:          ProtocolIEID{Value: ProtocolIEID_SystemBearerContextSetupRequest},
+		Criticality: Criticality{Value: Criticality_PresentReject},
+		Value:       &msg.SystemBearerContextSetupRequest,
+	})
+	return
+}
+func (msg *BearerContextSetupRequest) Decode(wire []byte) (err error, diagList []CriticalityDiagnosticsIEItem) {
+	defer func() {
+		if err != nil {
+			err = msgErrors(fmt.Errorf("BearerContextSetupRequest"), err)
+		}
+	}()
+	diagList, err = decodeMessage(wire, msg.decodeIE, []mandatoryIE{
+		{ProtocolIEID_GNBCUCPUEE1APID, "GNBCUCPUEE1APID", Criticality_PresentReject},
+		{ProtocolIEID_SecurityInformation, "SecurityInformation", Criticality_PresentReject},
+		{ProtocolIEID_UEDLAggregateMaximumBitRate, "UEDLAggregateMaximumBitRate", Criticality_PresentReject},
+		{ProtocolIEID_ServingPLMN, "ServingPLMN", Criticality_PresentIgnore},
+		{ProtocolIEID_ActivityNotificationLevel, "ActivityNotificationLevel", Criticality_PresentReject},
+		{ProtocolIEID_SystemBearerContextSetupRequest, "SystemBearerContextSetupRequest", Criticality_PresentReject},
+	})
+	return
+}
+func (msg *BearerContextSetupRequest) decodeIE(id aper.Integer, ieR *aper.AperReader) (known bool, err error) {
+	known = true
+	switch id {
+	case ProtocolIEID_GNBCUCPUEE1APID:
+		tmp := INTEGER{
+			c:   aper.Constraint{Lb: 0, Ub: 4294967295},
+			ext: false,
+		}
+		if err = tmp.Decode(ieR); err != nil {
+			err = utils.WrapError("Read GNBCUCPUEE1APID", err)
+			return
+		}
+		msg.GNBCUCPUEE1APID = int64(tmp.Value)
+	case ProtocolIEID_SecurityInformation:
+		var tmp SecurityInformation
+		if err = tmp.Decode(ieR); err != nil {
+			err = utils.WrapError("Read SecurityInformation", err)
+			return
+		}
+		msg.SecurityInformation = tmp
+	case ProtocolIEID_UEDLAggregateMaximumBitRate:
+		tmp := INTEGER{
+			c:   aper.Constraint{Lb: 0, Ub: 4000000000000},
+			ext: true,
+		}
+		if err = tmp.Decode(ieR); err != nil {
+			err = utils.WrapError("Read UEDLAggregateMaximumBitRate", err)
+			return
+		}
+		msg.UEDLAggregateMaximumBitRate = int64(tmp.Value)
+	case ProtocolIEID_UEDLMaximumIntegrityProtectedDataRate:
+		tmp := INTEGER{
+			c:   aper.Constraint{Lb: 0, Ub: 4000000000000},
+			ext: true,
+		}
+		if err = tmp.Decode(ieR); err != nil {
+			err = utils.WrapError("Read UEDLMaximumIntegrityProtectedDataRate", err)
+			return
+		}
+		v := int64(tmp.Value)
+		msg.UEDLMaximumIntegrityProtectedDataRate = &v
+	case ProtocolIEID_ServingPLMN:
+		tmp := OCTETSTRING{
+			c:   aper.Constraint{Lb: 3, Ub: 3},
+			ext: false,
+		}
+		if err = tmp.Decode(ieR); err != nil {
+			err = utils.WrapError("Read ServingPLMN", err)
+			return
+		}
+		msg.ServingPLMN = tmp.Value
+	case ProtocolIEID_ActivityNotificationLevel:
+		var tmp ActivityNotificationLevel
+		if err = tmp.Decode(ieR); err != nil {
+			err = utils.WrapError("Read ActivityNotificationLevel", err)
+			return
+		}
+		msg.ActivityNotificationLevel = tmp
+	case ProtocolIEID_UEInactivityTimer:
+		tmp := INTEGER{
+			c:   aper.Constraint{Lb: 1, Ub: 7200},
+			ext: true,
+		}
+		if err = tmp.Decode(ieR); err != nil {
+			err = utils.WrapError("Read UEInactivityTimer", err)
+			return
+		}
+		v := int64(tmp.Value)
+		msg.UEInactivityTimer = &v
+	case ProtocolIEID_SystemBearerContextSetupRequest:
+		var tmp SystemBearerContextSetupRequest
+		if err = tmp.Decode(ieR); err != nil {
+			err = utils.WrapError("Read SystemBearerContextSetupRequest", err)
+			return
+		}
+		msg.SystemBearerContextSetupRequest = tmp
+	default:
+		known = false
+	}
+	return
+}
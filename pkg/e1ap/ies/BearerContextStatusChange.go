@@ -0,0 +1,22 @@
+package ies
+
+import "github.com/lvdund/ngap/aper"
+
+const (
+	BearerContextStatusChangeSuspend aper.Enumerated = 0
+	BearerContextStatusChangeResume  aper.Enumerated = 1
+)
+
+type BearerContextStatusChange struct {
+	Value aper.Enumerated
+}
+
+func (ie *BearerContextStatusChange) Encode(w *aper.AperWriter) (err error) {
+	err = w.WriteEnumerate(uint64(ie.Value), aper.Constraint{Lb: 0, Ub: 1}, true)
+	return
+}
+func (ie *BearerContextStatusChange) Decode(r *aper.AperReader) (err error) {
+	v, err := r.ReadEnumerate(aper.Constraint{Lb: 0, Ub: 1}, true)
+	ie.Value = aper.Enumerated(v)
+	return
+}
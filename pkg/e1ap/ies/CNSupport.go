@@ -0,0 +1,23 @@
+package ies
+
+import "github.com/lvdund/ngap/aper"
+
+const (
+	CNSupportCepc aper.Enumerated = 0
+	CNSupportC5gc aper.Enumerated = 1
+	CNSupportBoth aper.Enumerated = 2
+)
+
+type CNSupport struct {
+	Value aper.Enumerated
+}
+
+func (ie *CNSupport) Encode(w *aper.AperWriter) (err error) {
+	err = w.WriteEnumerate(uint64(ie.Value), aper.Constraint{Lb: 0, Ub: 2}, true)
+	return
+}
+func (ie *CNSupport) Decode(r *aper.AperReader) (err error) {
+	v, err := r.ReadEnumerate(aper.Constraint{Lb: 0, Ub: 2}, true)
+	ie.Value = aper.Enumerated(v)
+	return
+}
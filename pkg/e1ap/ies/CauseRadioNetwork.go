@@ -0,0 +1,45 @@
+package ies
+
+import "github.com/lvdund/ngap/aper"
+
+const (
+	CauseRadioNetworkUnspecified                               aper.Enumerated = 0
+	CauseRadioNetworkUnknownoralreadyallocatedgnbcucpuee1apid  aper.Enumerated = 1
+	CauseRadioNetworkUnknownoralreadyallocatedgnbcuupuee1apid  aper.Enumerated = 2
+	CauseRadioNetworkUnknownorinconsistentpairofuee1apid       aper.Enumerated = 3
+	CauseRadioNetworkInteractionwithotherprocedure             aper.Enumerated = 4
+	CauseRadioNetworkPpdcpcountwraparound                      aper.Enumerated = 5
+	CauseRadioNetworkNotsupportedqcivalue                      aper.Enumerated = 6
+	CauseRadioNetworkNotsupported5qivalue                      aper.Enumerated = 7
+	CauseRadioNetworkEncryptionalgorithmsnotsupported          aper.Enumerated = 8
+	CauseRadioNetworkIntegrityprotectionalgorithmsnotsupported aper.Enumerated = 9
+	CauseRadioNetworkUpintegrityprotectionnotpossible          aper.Enumerated = 10
+	CauseRadioNetworkUpconfidentialityprotectionnotpossible    aper.Enumerated = 11
+	CauseRadioNetworkMultiplepdusessionidinstances             aper.Enumerated = 12
+	CauseRadioNetworkUnknownpdusessionid                       aper.Enumerated = 13
+	CauseRadioNetworkMultipleqosflowidinstances                aper.Enumerated = 14
+	CauseRadioNetworkUnknownqosflowid                          aper.Enumerated = 15
+	CauseRadioNetworkMultipledrbidinstances                    aper.Enumerated = 16
+	CauseRadioNetworkUnknowndrbid                              aper.Enumerated = 17
+	CauseRadioNetworkInvalidqoscombination                     aper.Enumerated = 18
+	CauseRadioNetworkProcedurecancelled                        aper.Enumerated = 19
+	CauseRadioNetworkNormalrelease                             aper.Enumerated = 20
+	CauseRadioNetworkNoradioresourcesavailable                 aper.Enumerated = 21
+	CauseRadioNetworkActiondesirableforradioreasons            aper.Enumerated = 22
+	CauseRadioNetworkResourcesnotavailablefortheslice          aper.Enumerated = 23
+	CauseRadioNetworkPdcpconfigurationnotsupported             aper.Enumerated = 24
+)
+
+type CauseRadioNetwork struct {
+	Value aper.Enumerated
+}
+
+func (ie *CauseRadioNetwork) Encode(w *aper.AperWriter) (err error) {
+	err = w.WriteEnumerate(uint64(ie.Value), aper.Constraint{Lb: 0, Ub: 24}, true)
+	return
+}
+func (ie *CauseRadioNetwork) Decode(r *aper.AperReader) (err error) {
+	v, err := r.ReadEnumerate(aper.Constraint{Lb: 0, Ub: 24}, true)
+	ie.Value = aper.Enumerated(v)
+	return
+}
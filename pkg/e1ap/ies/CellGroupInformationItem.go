@@ -0,0 +1,67 @@
+package ies
+
+import (
+	"fmt"
+
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type CellGroupInformationItem struct {
+	CellGroupID int64
+	// ULConfiguration *ULConfiguration `optional`
+	// DLTXStop *DLTXStop `optional`
+	// RATType *RATType `optional`
+	// IEExtensions *CellGroupInformationItemExtIEs `optional`
+}
+
+func (ie *CellGroupInformationItem) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	optionals := []byte{0x0}
+	w.WriteBits(optionals, 4)
+	tmp_CellGroupID := NewINTEGER(ie.CellGroupID, aper.Constraint{Lb: 0, Ub: 3}, true)
+	if err = tmp_CellGroupID.Encode(w); err != nil {
+		err = utils.WrapError("Encode CellGroupID", err)
+		return
+	}
+	return
+}
+func (ie *CellGroupInformationItem) Decode(r *aper.AperReader) (err error) {
+	if _, err = r.ReadBool(); err != nil {
+		return
+	}
+	var optionals []byte
+	if optionals, err = r.ReadBits(4); err != nil {
+		return
+	}
+	tmp_CellGroupID := INTEGER{
+		c:   aper.Constraint{Lb: 0, Ub: 3},
+		ext: true,
+	}
+	if err = tmp_CellGroupID.Decode(r); err != nil {
+		err = utils.WrapError("Read CellGroupID", err)
+		return
+	}
+	ie.CellGroupID = int64(tmp_CellGroupID.Value)
+	if aper.IsBitSet(optionals, 1) {
+		err = fmt.Errorf("Read ULConfiguration: not supported")
+		return
+	}
+	if aper.IsBitSet(optionals, 2) {
+		err = fmt.Errorf("Read DLTXStop: not supported")
+		return
+	}
+	if aper.IsBitSet(optionals, 3) {
+		err = fmt.Errorf("Read RATType: not supported")
+		return
+	}
+	if aper.IsBitSet(optionals, 4) {
+		if err = skipExtensions(r); err != nil {
+			err = utils.WrapError("Read IEExtensions", err)
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,24 @@
+package ies
+
+import "github.com/lvdund/ngap/aper"
+
+const (
+	CipheringAlgorithmNEA0     aper.Enumerated = 0
+	CipheringAlgorithmC128NEA1 aper.Enumerated = 1
+	CipheringAlgorithmC128NEA2 aper.Enumerated = 2
+	CipheringAlgorithmC128NEA3 aper.Enumerated = 3
+)
+
+type CipheringAlgorithm struct {
+	Value aper.Enumerated
+}
+
+func (ie *CipheringAlgorithm) Encode(w *aper.AperWriter) (err error) {
+	err = w.WriteEnumerate(uint64(ie.Value), aper.Constraint{Lb: 0, Ub: 3}, true)
+	return
+}
+func (ie *CipheringAlgorithm) Decode(r *aper.AperReader) (err error) {
+	v, err := r.ReadEnumerate(aper.Constraint{Lb: 0, Ub: 3}, true)
+	ie.Value = aper.Enumerated(v)
+	return
+}
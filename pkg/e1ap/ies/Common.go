@@ -0,0 +1,364 @@
+package ies
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/lvdund/ngap/aper"
+)
+
+func encodeMessage(w io.Writer, present uint8, procedureCode int64, criticality aper.Enumerated, ies []E1apMessageIE) (err error) {
+	aw := aper.NewWriter(w)
+	if err = aw.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	if err = aw.WriteChoice(uint64(present), 2, true); err != nil {
+		return
+	}
+	pCode := ProcedureCode{
+		Value: aper.Integer(procedureCode),
+	}
+	if err = pCode.Encode(aw); err != nil {
+		return
+	}
+	cr := Criticality{
+		Value: criticality,
+	}
+	if err = cr.Encode(aw); err != nil {
+		return
+	}
+	if len(ies) == 0 {
+		err = fmt.Errorf("empty message")
+		return
+	}
+
+	var buf bytes.Buffer
+	cW := aper.NewWriter(&buf)
+	cW.WriteBool(aper.Zero)
+	if err = aper.WriteSequenceOf[E1apMessageIE](ies, cW, &aper.Constraint{
+		Lb: 0,
+		Ub: int64(aper.POW_16 - 1),
+	}, false); err != nil {
+		return
+	}
+
+	if err = cW.Close(); err != nil {
+		return
+	}
+	if err = aw.WriteOpenType(buf.Bytes()); err != nil {
+		return
+	}
+	err = aw.Close()
+	return
+}
+
+// ieDecodeFn decodes the value of a single protocol IE. It returns false when
+// the IE id is not known by the message.
+type ieDecodeFn func(id aper.Integer, r *aper.AperReader) (known bool, err error)
+
+// mandatoryIE describes a protocol IE that must be present in a message.
+type mandatoryIE struct {
+	id          aper.Integer
+	name        string
+	criticality aper.Enumerated
+}
+
+// decodeMessage decodes the ProtocolIE-Container of an E1AP message.
+func decodeMessage(wire []byte, fn ieDecodeFn, mandatory []mandatoryIE) (diagList []CriticalityDiagnosticsIEItem, err error) {
+	r := aper.NewReader(bytes.NewReader(wire))
+	r.ReadBool()
+	return decodeContainer(r, fn, mandatory)
+}
+
+// decodeContainer walks a ProtocolIE-Container, handing every IE value to fn.
+// Duplicated IEs are rejected and IEs that are not comprehended are reported
+// in the returned diagnostics list unless their criticality is ignore.
+func decodeContainer(r *aper.AperReader, fn ieDecodeFn, mandatory []mandatoryIE) (diagList []CriticalityDiagnosticsIEItem, err error) {
+	list := make(map[aper.Integer]*E1apMessageIE)
+	decodeIE := func(r *aper.AperReader) (msgIe *E1apMessageIE, err error) {
+		var id int64
+		var c uint64
+		var buf []byte
+		if id, err = r.ReadInteger(&aper.Constraint{Lb: 0, Ub: int64(aper.POW_16) - 1}, false); err != nil {
+			return
+		}
+		msgIe = new(E1apMessageIE)
+		msgIe.Id.Value = aper.Integer(id)
+		if c, err = r.ReadEnumerate(aper.Constraint{Lb: 0, Ub: 2}, false); err != nil {
+			return
+		}
+		msgIe.Criticality.Value = aper.Enumerated(c)
+		if buf, err = r.ReadOpenType(); err != nil {
+			return
+		}
+		ieId := msgIe.Id.Value
+		if _, ok := list[ieId]; ok {
+			err = fmt.Errorf("Duplicated protocol IEID[%d] found", ieId)
+			return
+		}
+		list[ieId] = msgIe
+		var known bool
+		if known, err = fn(ieId, aper.NewReader(bytes.NewReader(buf))); err != nil {
+			return
+		}
+		if !known && msgIe.Criticality.Value != Criticality_PresentIgnore {
+			diagList = append(diagList, CriticalityDiagnosticsIEItem{
+				IECriticality: msgIe.Criticality,
+				IEID:          msgIe.Id,
+				TypeOfError:   TypeOfError{Value: TypeOfErrorNotunderstood},
+			})
+		}
+		return
+	}
+	if _, err = aper.ReadSequenceOf[E1apMessageIE](decodeIE, r, &aper.Constraint{Lb: 0, Ub: int64(aper.POW_16 - 1)}, false); err != nil {
+		return
+	}
+	for _, m := range mandatory {
+		if _, ok := list[m.id]; !ok {
+			err = fmt.Errorf("Mandatory field %s is missing", m.name)
+			diagList = append(diagList, CriticalityDiagnosticsIEItem{
+				IECriticality: Criticality{Value: m.criticality},
+				IEID:          ProtocolIEID{Value: m.id},
+				TypeOfError:   TypeOfError{Value: TypeOfErrorMissing},
+			})
+			return
+		}
+	}
+	return
+}
+
+// encodeContainer writes a ProtocolIE-Container nested inside another IE.
+func encodeContainer(w *aper.AperWriter, ies []E1apMessageIE) (err error) {
+	if err = w.WriteInteger(int64(len(ies)), &aper.Constraint{Lb: 0, Ub: int64(aper.POW_16 - 1)}, false); err != nil {
+		return
+	}
+	for _, ie := range ies {
+		if err = ie.Encode(w); err != nil {
+			return
+		}
+	}
+	return
+}
+
+type E1apMessageIE struct {
+	Id          ProtocolIEID
+	Criticality Criticality
+	Value       aper.AperMarshaller
+}
+
+func (ie E1apMessageIE) Encode(w *aper.AperWriter) (err error) {
+	if err = ie.Id.Encode(w); err != nil {
+		return
+	}
+	if err = ie.Criticality.Encode(w); err != nil {
+		return
+	}
+	var buf bytes.Buffer
+	ieW := aper.NewWriter(&buf)
+	if err = ie.Value.Encode(ieW); err != nil {
+		return
+	}
+	ieW.Close()
+	err = w.WriteOpenType(buf.Bytes())
+	return
+}
+
+type ProcedureCode struct {
+	Value aper.Integer
+}
+
+func (ie *ProcedureCode) Decode(r *aper.AperReader) error {
+	if v, err := r.ReadInteger(&aper.Constraint{Lb: 0, Ub: 255}, false); err != nil {
+		return err
+	} else {
+		ie.Value = aper.Integer(v)
+	}
+	return nil
+}
+func (ie *ProcedureCode) Encode(r *aper.AperWriter) (err error) {
+	if err = r.WriteInteger(int64(ie.Value), &aper.Constraint{Lb: 0, Ub: 255}, false); err != nil {
+		return err
+	}
+	return nil
+}
+
+type TriggeringMessage struct {
+	Value aper.Enumerated
+}
+
+func (ie *TriggeringMessage) Decode(r *aper.AperReader) error {
+	if v, err := r.ReadEnumerate(aper.Constraint{Lb: 0, Ub: 2}, false); err != nil {
+		return err
+	} else {
+		ie.Value = aper.Enumerated(v)
+	}
+	return nil
+}
+func (ie *TriggeringMessage) Encode(r *aper.AperWriter) (err error) {
+	if err = r.WriteEnumerate(uint64(ie.Value), aper.Constraint{Lb: 0, Ub: 2}, false); err != nil {
+		return err
+	}
+	return nil
+}
+
+type Criticality struct {
+	Value aper.Enumerated
+}
+
+func (ie *Criticality) Decode(r *aper.AperReader) error {
+	if v, err := r.ReadEnumerate(aper.Constraint{Lb: 0, Ub: 2}, false); err != nil {
+		return err
+	} else {
+		ie.Value = aper.Enumerated(v)
+	}
+	return nil
+}
+func (ie *Criticality) Encode(r *aper.AperWriter) (err error) {
+	if err = r.WriteEnumerate(uint64(ie.Value), aper.Constraint{Lb: 0, Ub: 2}, false); err != nil {
+		return err
+	}
+	return nil
+}
+
+type ProtocolIEID struct {
+	Value aper.Integer
+}
+
+func (ie *ProtocolIEID) Decode(r *aper.AperReader) error {
+	if v, err := r.ReadInteger(&aper.Constraint{Lb: 0, Ub: 65535}, false); err != nil {
+		return err
+	} else {
+		ie.Value = aper.Integer(v)
+	}
+	return nil
+}
+func (ie *ProtocolIEID) Encode(r *aper.AperWriter) (err error) {
+	if err = r.WriteInteger(int64(ie.Value), &aper.Constraint{Lb: 0, Ub: 65535}, false); err != nil {
+		return err
+	}
+	return nil
+}
+
+func BuildDiagnostics(present uint8, procedureCode ProcedureCode, criticality Criticality, transactionID int64, diagnosticsItems []CriticalityDiagnosticsIEItem) *CriticalityDiagnostics {
+	procCodeValue := int64(procedureCode.Value)
+	return &CriticalityDiagnostics{
+		ProcedureCode:             &procCodeValue,
+		TriggeringMessage:         &TriggeringMessage{Value: aper.Enumerated(present - 1)},
+		ProcedureCriticality:      &criticality,
+		TransactionID:             &transactionID,
+		IEsCriticalityDiagnostics: diagnosticsItems,
+	}
+}
+
+func msgErrors(err1, err2 error) error {
+	if err1 == nil && err2 == nil {
+		return nil
+	}
+	if err1 == nil {
+		return err2
+	}
+	if err2 == nil {
+		return err1
+	}
+	return fmt.Errorf("%v: %v", err1, err2)
+}
+
+const (
+	E1apPresentNothing uint8 = iota
+	E1apPduInitiatingMessage
+	E1apPduSuccessfulOutcome
+	E1apPduUnsuccessfulOutcome
+)
+
+// Procedure codes as defined in TS 37.483 E1AP-Constants
+const (
+	ProcedureCode_Reset                                = 0
+	ProcedureCode_ErrorIndication                      = 1
+	ProcedureCode_PrivateMessage                       = 2
+	ProcedureCode_GNBCUUPE1Setup                       = 3
+	ProcedureCode_GNBCUCPE1Setup                       = 4
+	ProcedureCode_GNBCUUPConfigurationUpdate           = 5
+	ProcedureCode_GNBCUCPConfigurationUpdate           = 6
+	ProcedureCode_E1Release                            = 7
+	ProcedureCode_BearerContextSetup                   = 8
+	ProcedureCode_BearerContextModification            = 9
+	ProcedureCode_BearerContextModificationRequired    = 10
+	ProcedureCode_BearerContextRelease                 = 11
+	ProcedureCode_BearerContextReleaseRequest          = 12
+	ProcedureCode_BearerContextInactivityNotification  = 13
+	ProcedureCode_DLDataNotification                   = 14
+	ProcedureCode_DataUsageReport                      = 15
+	ProcedureCode_GNBCUUPCounterCheck                  = 16
+	ProcedureCode_GNBCUUPStatusIndication              = 17
+	ProcedureCode_ULDataNotification                   = 18
+	ProcedureCode_MRDCDataUsageReport                  = 19
+	ProcedureCode_TraceStart                           = 20
+	ProcedureCode_DeactivateTrace                      = 21
+	ProcedureCode_ResourceStatusReportingInitiation    = 22
+	ProcedureCode_ResourceStatusReporting              = 23
+	ProcedureCode_IABUPTNLAddressUpdate                = 24
+	ProcedureCode_CellTrafficTrace                     = 25
+	ProcedureCode_EarlyForwardingSNTransfer            = 26
+	ProcedureCode_GNBCUCPMeasurementResultsInformation = 27
+	ProcedureCode_IABPSKNotification                   = 28
+)
+
+const (
+	Criticality_PresentReject aper.Enumerated = 0
+	Criticality_PresentIgnore aper.Enumerated = 1
+	Criticality_PresentNotify aper.Enumerated = 2
+)
+
+// Protocol IE identifiers as defined in TS 37.483 E1AP-Constants
+const (
+	ProtocolIEID_Cause                                   = 0
+	ProtocolIEID_CriticalityDiagnostics                  = 1
+	ProtocolIEID_GNBCUCPUEE1APID                         = 2
+	ProtocolIEID_GNBCUUPUEE1APID                         = 3
+	ProtocolIEID_ResetType                               = 4
+	ProtocolIEID_UEAssociatedLogicalE1ConnectionItem     = 5
+	ProtocolIEID_DataUsageReportList                     = 6
+	ProtocolIEID_GNBCUUPID                               = 7
+	ProtocolIEID_SupportedPLMNs                          = 8
+	ProtocolIEID_TimeToWait                              = 9
+	ProtocolIEID_GNBCUUPName                             = 10
+	ProtocolIEID_CNSupport                               = 11
+	ProtocolIEID_GNBCUCPName                             = 12
+	ProtocolIEID_SecurityInformation                     = 13
+	ProtocolIEID_UEDLAggregateMaximumBitRate             = 14
+	ProtocolIEID_SystemBearerContextSetupRequest         = 15
+	ProtocolIEID_SystemBearerContextSetupResponse        = 16
+	ProtocolIEID_BearerContextStatusChange               = 17
+	ProtocolIEID_SystemBearerContextModificationRequest  = 18
+	ProtocolIEID_SystemBearerContextModificationResponse = 19
+	ProtocolIEID_SystemBearerContextModificationConfirm  = 20
+	ProtocolIEID_SystemBearerContextModificationRequired = 21
+	ProtocolIEID_DRBStatusList                           = 22
+	ProtocolIEID_ActivityNotificationLevel               = 23
+	ProtocolIEID_ActivityInformation                     = 24
+	ProtocolIEID_NewULTNLInformationRequired             = 26
+	ProtocolIEID_GNBCUCPTNLAToAddList                    = 27
+	ProtocolIEID_GNBCUCPTNLAToRemoveList                 = 28
+	ProtocolIEID_GNBCUCPTNLAToUpdateList                 = 29
+	ProtocolIEID_GNBCUCPTNLASetupList                    = 30
+	ProtocolIEID_GNBCUCPTNLAFailedToSetupList            = 31
+	ProtocolIEID_PDUSessionResourceToSetupList           = 42
+	ProtocolIEID_PDUSessionResourceToModifyList          = 43
+	ProtocolIEID_PDUSessionResourceToRemoveList          = 44
+	ProtocolIEID_PDUSessionResourceRequiredToModifyList  = 45
+	ProtocolIEID_PDUSessionResourceSetupList             = 46
+	ProtocolIEID_PDUSessionResourceFailedList            = 47
+	ProtocolIEID_PDUSessionResourceModifiedList          = 48
+	ProtocolIEID_PDUSessionResourceFailedToModifyList    = 49
+	ProtocolIEID_PDUSessionResourceConfirmModifiedList   = 50
+	ProtocolIEID_PDUSessionResourceToSetupModList        = 51
+	ProtocolIEID_PDUSessionResourceSetupModList          = 52
+	ProtocolIEID_PDUSessionResourceFailedModList         = 53
+	ProtocolIEID_TransactionID                           = 57
+	ProtocolIEID_ServingPLMN                             = 58
+	ProtocolIEID_UEInactivityTimer                       = 59
+	ProtocolIEID_GNBCUUPCapacity                         = 64
+	ProtocolIEID_GNBCUUPOverloadInformation              = 65
+	ProtocolIEID_UEDLMaximumIntegrityProtectedDataRate   = 66
+)
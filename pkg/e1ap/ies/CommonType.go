@@ -0,0 +1,210 @@
+package ies
+
+import (
+	"github.com/lvdund/ngap/aper"
+)
+
+type ENUMERATED struct {
+	Value aper.Enumerated
+	c     aper.Constraint
+	ext   bool
+}
+
+func NewENUMERATED(v int64, c aper.Constraint, ext bool) ENUMERATED {
+	return ENUMERATED{
+		Value: aper.Enumerated(v),
+		c:     c,
+		ext:   ext,
+	}
+}
+func (t *ENUMERATED) Encode(w *aper.AperWriter) (err error) {
+	err = w.WriteEnumerate(uint64(t.Value), t.c, t.ext)
+	return
+}
+func (t *ENUMERATED) Decode(r *aper.AperReader) (err error) {
+	v, err := r.ReadEnumerate(t.c, t.ext)
+	t.Value = aper.Enumerated(v)
+	return
+}
+
+type BITSTRING struct {
+	Value aper.BitString
+	c     aper.Constraint
+	ext   bool
+}
+
+func NewBITSTRING(v aper.BitString, c aper.Constraint, ext bool) BITSTRING {
+	return BITSTRING{
+		Value: aper.BitString{
+			Bytes:   v.Bytes,
+			NumBits: v.NumBits,
+		},
+		c:   c,
+		ext: ext,
+	}
+}
+func (t *BITSTRING) Encode(w *aper.AperWriter) (err error) {
+	if t.c.Lb == t.c.Ub {
+		t.Value.NumBits = uint64(t.c.Lb)
+	} else if len(t.Value.Bytes)*8 < int(t.c.Lb) {
+		t.Value.NumBits = uint64(t.c.Lb)
+	}
+	err = w.WriteBitString(t.Value.Bytes, uint(t.Value.NumBits), &t.c, t.ext)
+	return
+}
+func (t *BITSTRING) Decode(r *aper.AperReader) (err error) {
+	var v []byte
+	var n uint
+	if v, n, err = r.ReadBitString(&t.c, t.ext); err != nil {
+		return
+	}
+	t.Value.Bytes = v
+	t.Value.NumBits = uint64(n)
+	return
+}
+
+type OCTETSTRING struct {
+	Value aper.OctetString
+	c     aper.Constraint
+	ext   bool
+}
+
+func NewOCTETSTRING(v []byte, c aper.Constraint, ext bool) OCTETSTRING {
+	return OCTETSTRING{
+		Value: v,
+		c:     c,
+		ext:   ext,
+	}
+}
+func (t *OCTETSTRING) Encode(w *aper.AperWriter) (err error) {
+	if t.c.Lb == t.c.Ub && t.c.Lb == 0 {
+		err = w.WriteOctetString(t.Value, nil, t.ext)
+	} else {
+		err = w.WriteOctetString(t.Value, &t.c, t.ext)
+	}
+	return
+}
+func (t *OCTETSTRING) Decode(r *aper.AperReader) (err error) {
+	var v aper.OctetString
+	if t.c.Lb == t.c.Ub && t.c.Lb == 0 {
+		if v, err = r.ReadOctetString(nil, t.ext); err != nil {
+			return
+		}
+	} else {
+		if v, err = r.ReadOctetString(&t.c, t.ext); err != nil {
+			return
+		}
+	}
+
+	t.Value = v
+	return
+}
+
+type INTEGER struct {
+	Value aper.Integer
+	c     aper.Constraint
+	ext   bool
+}
+
+func NewINTEGER(v int64, c aper.Constraint, ext bool) INTEGER {
+	return INTEGER{
+		Value: aper.Integer(v),
+		c:     c,
+		ext:   ext,
+	}
+}
+func (t *INTEGER) Encode(w *aper.AperWriter) (err error) {
+	err = w.WriteInteger(int64(t.Value), &t.c, t.ext)
+	return
+}
+func (t *INTEGER) Decode(r *aper.AperReader) (err error) {
+	v, err := r.ReadInteger(&t.c, t.ext)
+	t.Value = aper.Integer(v)
+	return
+}
+
+type Sequence[T aper.IE] struct {
+	Value []T
+	c     aper.Constraint
+	ext   bool
+}
+
+func NewSequence[T aper.IE](items []T, c aper.Constraint, ext bool) Sequence[T] {
+	return Sequence[T]{
+		Value: items,
+		c:     c,
+		ext:   ext,
+	}
+}
+
+// Encode writes the list length followed by the items. aper.WriteSequenceOf
+// is not used because it pads to an octet boundary after the last item, which
+// shifts every field that follows a list inside a SEQUENCE.
+func (s *Sequence[T]) Encode(w *aper.AperWriter) (err error) {
+	if s.ext {
+		if err = w.WriteBool(int64(len(s.Value)) > s.c.Ub); err != nil {
+			return
+		}
+	}
+	if err = w.WriteInteger(int64(len(s.Value)), &aper.Constraint{Lb: s.c.Lb, Ub: s.c.Ub}, false); err != nil {
+		return
+	}
+	for _, item := range s.Value {
+		if err = item.Encode(w); err != nil {
+			return
+		}
+	}
+	return
+}
+func (s *Sequence[T]) Decode(r *aper.AperReader, fn func() T) (err error) {
+	var newItems []T
+	newItems, err = aper.ReadSequenceOfEx(fn, r, &s.c, s.ext)
+	if err != nil {
+		return
+	}
+	s.Value = []T{}
+	s.Value = append(s.Value, newItems...)
+	return
+}
+
+// setOptional marks optional component i (1-based) of a SEQUENCE preamble.
+// aper.SetBit only handles the first seven components.
+func setOptional(optionals []byte, i uint) {
+	optionals[(i-1)/8] |= 0x80 >> ((i - 1) % 8)
+}
+
+// isOptionalSet reports whether optional component i (1-based) is present.
+func isOptionalSet(optionals []byte, i uint) bool {
+	return optionals[(i-1)/8]&(0x80>>((i-1)%8)) != 0
+}
+
+// skipExtensions consumes a ProtocolExtensionContainer. None of the
+// extensions are interpreted but their presence must not break decoding.
+func skipExtensions(r *aper.AperReader) (err error) {
+	skip := func(r *aper.AperReader) (*struct{}, error) {
+		if _, err := r.ReadInteger(&aper.Constraint{Lb: 0, Ub: int64(aper.POW_16) - 1}, false); err != nil {
+			return nil, err
+		}
+		if _, err := r.ReadEnumerate(aper.Constraint{Lb: 0, Ub: 2}, false); err != nil {
+			return nil, err
+		}
+		if _, err := r.ReadOpenType(); err != nil {
+			return nil, err
+		}
+		return &struct{}{}, nil
+	}
+	_, err = aper.ReadSequenceOf[struct{}](skip, r, &aper.Constraint{Lb: 1, Ub: int64(aper.POW_16) - 1}, false)
+	return
+}
+
+const (
+	maxnoofErrors        int64 = 256
+	maxnoofSPLMNs        int64 = 12
+	maxnoofSliceItems    int64 = 1024
+	maxnoofNRCGI         int64 = 512
+	maxnoofDRBs          int64 = 32
+	maxnoofPDUSessionRes int64 = 256
+	maxnoofQoSFlows      int64 = 64
+	maxnoofUPParameters  int64 = 8
+	maxnoofCellGroups    int64 = 4
+)
@@ -0,0 +1,59 @@
+package ies
+
+import (
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type DRBFailedItemNGRAN struct {
+	DRBID int64
+	Cause Cause
+	// IEExtensions *DRBFailedItemNGRANExtIEs `optional`
+}
+
+func (ie *DRBFailedItemNGRAN) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	optionals := []byte{0x0}
+	w.WriteBits(optionals, 1)
+	tmp_DRBID := NewINTEGER(ie.DRBID, aper.Constraint{Lb: 1, Ub: 32}, true)
+	if err = tmp_DRBID.Encode(w); err != nil {
+		err = utils.WrapError("Encode DRBID", err)
+		return
+	}
+	if err = ie.Cause.Encode(w); err != nil {
+		err = utils.WrapError("Encode Cause", err)
+		return
+	}
+	return
+}
+func (ie *DRBFailedItemNGRAN) Decode(r *aper.AperReader) (err error) {
+	if _, err = r.ReadBool(); err != nil {
+		return
+	}
+	var optionals []byte
+	if optionals, err = r.ReadBits(1); err != nil {
+		return
+	}
+	tmp_DRBID := INTEGER{
+		c:   aper.Constraint{Lb: 1, Ub: 32},
+		ext: true,
+	}
+	if err = tmp_DRBID.Decode(r); err != nil {
+		err = utils.WrapError("Read DRBID", err)
+		return
+	}
+	ie.DRBID = int64(tmp_DRBID.Value)
+	if err = ie.Cause.Decode(r); err != nil {
+		err = utils.WrapError("Read Cause", err)
+		return
+	}
+	if aper.IsBitSet(optionals, 1) {
+		if err = skipExtensions(r); err != nil {
+			err = utils.WrapError("Read IEExtensions", err)
+			return
+		}
+	}
+	return
+}
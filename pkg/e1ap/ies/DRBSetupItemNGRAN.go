@@ -0,0 +1,142 @@
+package ies
+
+import (
+	"fmt"
+
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type DRBSetupItemNGRAN struct {
+	DRBID int64
+	// DRBDataForwardingInformationResponse *DRBDataForwardingInformationResponse `optional`
+	ULUPTransportParameters []UPParametersItem
+	FlowSetupList           []QoSFlowItem
+	FlowFailedList          []QoSFlowFailedItem
+	// IEExtensions *DRBSetupItemNGRANExtIEs `optional`
+}
+
+func (ie *DRBSetupItemNGRAN) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	optionals := []byte{0x0}
+	if len(ie.FlowFailedList) > 0 {
+		aper.SetBit(optionals, 2)
+	}
+	w.WriteBits(optionals, 3)
+	tmp_DRBID := NewINTEGER(ie.DRBID, aper.Constraint{Lb: 1, Ub: 32}, true)
+	if err = tmp_DRBID.Encode(w); err != nil {
+		err = utils.WrapError("Encode DRBID", err)
+		return
+	}
+	tmp_ULUPTransportParameters := Sequence[*UPParametersItem]{
+		Value: []*UPParametersItem{},
+		c:     aper.Constraint{Lb: 1, Ub: maxnoofUPParameters},
+		ext:   false,
+	}
+	for i := range ie.ULUPTransportParameters {
+		tmp_ULUPTransportParameters.Value = append(tmp_ULUPTransportParameters.Value, &ie.ULUPTransportParameters[i])
+	}
+	if err = tmp_ULUPTransportParameters.Encode(w); err != nil {
+		err = utils.WrapError("Encode ULUPTransportParameters", err)
+		return
+	}
+	tmp_FlowSetupList := Sequence[*QoSFlowItem]{
+		Value: []*QoSFlowItem{},
+		c:     aper.Constraint{Lb: 1, Ub: maxnoofQoSFlows},
+		ext:   false,
+	}
+	for i := range ie.FlowSetupList {
+		tmp_FlowSetupList.Value = append(tmp_FlowSetupList.Value, &ie.FlowSetupList[i])
+	}
+	if err = tmp_FlowSetupList.Encode(w); err != nil {
+		err = utils.WrapError("Encode FlowSetupList", err)
+		return
+	}
+	if len(ie.FlowFailedList) > 0 {
+		tmp_FlowFailedList := Sequence[*QoSFlowFailedItem]{
+			Value: []*QoSFlowFailedItem{},
+			c:     aper.Constraint{Lb: 1, Ub: maxnoofQoSFlows},
+			ext:   false,
+		}
+		for i := range ie.FlowFailedList {
+			tmp_FlowFailedList.Value = append(tmp_FlowFailedList.Value, &ie.FlowFailedList[i])
+		}
+		if err = tmp_FlowFailedList.Encode(w); err != nil {
+			err = utils.WrapError("Encode FlowFailedList", err)
+			return
+		}
+	}
+	return
+}
+func (ie *DRBSetupItemNGRAN) Decode(r *aper.AperReader) (err error) {
+	if _, err = r.ReadBool(); err != nil {
+		return
+	}
+	var optionals []byte
+	if optionals, err = r.ReadBits(3); err != nil {
+		return
+	}
+	tmp_DRBID := INTEGER{
+		c:   aper.Constraint{Lb: 1, Ub: 32},
+		ext: true,
+	}
+	if err = tmp_DRBID.Decode(r); err != nil {
+		err = utils.WrapError("Read DRBID", err)
+		return
+	}
+	ie.DRBID = int64(tmp_DRBID.Value)
+	if aper.IsBitSet(optionals, 1) {
+		err = fmt.Errorf("Read DRBDataForwardingInformationResponse: not supported")
+		return
+	}
+	tmp_ULUPTransportParameters := Sequence[*UPParametersItem]{
+		c:   aper.Constraint{Lb: 1, Ub: maxnoofUPParameters},
+		ext: false,
+	}
+	fn_ULUPTransportParameters := func() *UPParametersItem { return new(UPParametersItem) }
+	if err = tmp_ULUPTransportParameters.Decode(r, fn_ULUPTransportParameters); err != nil {
+		err = utils.WrapError("Read ULUPTransportParameters", err)
+		return
+	}
+	ie.ULUPTransportParameters = []UPParametersItem{}
+	for _, i := range tmp_ULUPTransportParameters.Value {
+		ie.ULUPTransportParameters = append(ie.ULUPTransportParameters, *i)
+	}
+	tmp_FlowSetupList := Sequence[*QoSFlowItem]{
+		c:   aper.Constraint{Lb: 1, Ub: maxnoofQoSFlows},
+		ext: false,
+	}
+	fn_FlowSetupList := func() *QoSFlowItem { return new(QoSFlowItem) }
+	if err = tmp_FlowSetupList.Decode(r, fn_FlowSetupList); err != nil {
+		err = utils.WrapError("Read FlowSetupList", err)
+		return
+	}
+	ie.FlowSetupList = []QoSFlowItem{}
+	for _, i := range tmp_FlowSetupList.Value {
+		ie.FlowSetupList = append(ie.FlowSetupList, *i)
+	}
+	if aper.IsBitSet(optionals, 2) {
+		tmp_FlowFailedList := Sequence[*QoSFlowFailedItem]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofQoSFlows},
+			ext: false,
+		}
+		fn_FlowFailedList := func() *QoSFlowFailedItem { return new(QoSFlowFailedItem) }
+		if err = tmp_FlowFailedList.Decode(r, fn_FlowFailedList); err != nil {
+			err = utils.WrapError("Read FlowFailedList", err)
+			return
+		}
+		ie.FlowFailedList = []QoSFlowFailedItem{}
+		for _, i := range tmp_FlowFailedList.Value {
+			ie.FlowFailedList = append(ie.FlowFailedList, *i)
+		}
+	}
+	if aper.IsBitSet(optionals, 3) {
+		if err = skipExtensions(r); err != nil {
+			err = utils.WrapError("Read IEExtensions", err)
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,206 @@
+package ies
+
+import (
+	"fmt"
+
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type DRBToModifyItemNGRAN struct {
+	DRBID             int64
+	SDAPConfiguration *SDAPConfiguration
+	PDCPConfiguration *PDCPConfiguration
+	// DRBDataForwardingInformation *DRBDataForwardingInformation `optional`
+	// PDCPSNStatusRequest *PDCPSNStatusRequest `optional`
+	// PDCPSNStatusInformation *PDCPSNStatusInformation `optional`
+	DLUPParameters []UPParametersItem
+	// CellGroupToAdd *CellGroupToAdd `optional`
+	// CellGroupToModify *CellGroupToModify `optional`
+	// CellGroupToRemove *CellGroupToRemove `optional`
+	FlowMappingInformation []QoSFlowQoSParameterItem
+	DRBInactivityTimer     *int64
+	// IEExtensions *DRBToModifyItemNGRANExtIEs `optional`
+}
+
+func (ie *DRBToModifyItemNGRAN) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	optionals := []byte{0x0, 0x0}
+	if ie.SDAPConfiguration != nil {
+		setOptional(optionals, 1)
+	}
+	if ie.PDCPConfiguration != nil {
+		setOptional(optionals, 2)
+	}
+	if len(ie.DLUPParameters) > 0 {
+		setOptional(optionals, 6)
+	}
+	if len(ie.FlowMappingInformation) > 0 {
+		setOptional(optionals, 10)
+	}
+	if ie.DRBInactivityTimer != nil {
+		setOptional(optionals, 11)
+	}
+	w.WriteBits(optionals, 12)
+	tmp_DRBID := NewINTEGER(ie.DRBID, aper.Constraint{Lb: 1, Ub: 32}, true)
+	if err = tmp_DRBID.Encode(w); err != nil {
+		err = utils.WrapError("Encode DRBID", err)
+		return
+	}
+	if ie.SDAPConfiguration != nil {
+		if err = ie.SDAPConfiguration.Encode(w); err != nil {
+			err = utils.WrapError("Encode SDAPConfiguration", err)
+			return
+		}
+	}
+	if ie.PDCPConfiguration != nil {
+		if err = ie.PDCPConfiguration.Encode(w); err != nil {
+			err = utils.WrapError("Encode PDCPConfiguration", err)
+			return
+		}
+	}
+	if len(ie.DLUPParameters) > 0 {
+		tmp_DLUPParameters := Sequence[*UPParametersItem]{
+			Value: []*UPParametersItem{},
+			c:     aper.Constraint{Lb: 1, Ub: maxnoofUPParameters},
+			ext:   false,
+		}
+		for i := range ie.DLUPParameters {
+			tmp_DLUPParameters.Value = append(tmp_DLUPParameters.Value, &ie.DLUPParameters[i])
+		}
+		if err = tmp_DLUPParameters.Encode(w); err != nil {
+			err = utils.WrapError("Encode DLUPParameters", err)
+			return
+		}
+	}
+	if len(ie.FlowMappingInformation) > 0 {
+		tmp_FlowMappingInformation := Sequence[*QoSFlowQoSParameterItem]{
+			Value: []*QoSFlowQoSParameterItem{},
+			c:     aper.Constraint{Lb: 1, Ub: maxnoofQoSFlows},
+			ext:   false,
+		}
+		for i := range ie.FlowMappingInformation {
+			tmp_FlowMappingInformation.Value = append(tmp_FlowMappingInformation.Value, &ie.FlowMappingInformation[i])
+		}
+		if err = tmp_FlowMappingInformation.Encode(w); err != nil {
+			err = utils.WrapError("Encode FlowMappingInformation", err)
+			return
+		}
+	}
+	if ie.DRBInactivityTimer != nil {
+		tmp_DRBInactivityTimer := NewINTEGER(*ie.DRBInactivityTimer, aper.Constraint{Lb: 1, Ub: 7200}, true)
+		if err = tmp_DRBInactivityTimer.Encode(w); err != nil {
+			err = utils.WrapError("Encode DRBInactivityTimer", err)
+			return
+		}
+	}
+	return
+}
+func (ie *DRBToModifyItemNGRAN) Decode(r *aper.AperReader) (err error) {
+	if _, err = r.ReadBool(); err != nil {
+		return
+	}
+	var optionals []byte
+	if optionals, err = r.ReadBits(12); err != nil {
+		return
+	}
+	tmp_DRBID := INTEGER{
+		c:   aper.Constraint{Lb: 1, Ub: 32},
+		ext: true,
+	}
+	if err = tmp_DRBID.Decode(r); err != nil {
+		err = utils.WrapError("Read DRBID", err)
+		return
+	}
+	ie.DRBID = int64(tmp_DRBID.Value)
+	if isOptionalSet(optionals, 1) {
+		tmp := new(SDAPConfiguration)
+		if err = tmp.Decode(r); err != nil {
+			err = utils.WrapError("Read SDAPConfiguration", err)
+			return
+		}
+		ie.SDAPConfiguration = tmp
+	}
+	if isOptionalSet(optionals, 2) {
+		tmp := new(PDCPConfiguration)
+		if err = tmp.Decode(r); err != nil {
+			err = utils.WrapError("Read PDCPConfiguration", err)
+			return
+		}
+		ie.PDCPConfiguration = tmp
+	}
+	if isOptionalSet(optionals, 3) {
+		err = fmt.Errorf("Read DRBDataForwardingInformation: not supported")
+		return
+	}
+	if isOptionalSet(optionals, 4) {
+		err = fmt.Errorf("Read PDCPSNStatusRequest: not supported")
+		return
+	}
+	if isOptionalSet(optionals, 5) {
+		err = fmt.Errorf("Read PDCPSNStatusInformation: not supported")
+		return
+	}
+	if isOptionalSet(optionals, 6) {
+		tmp_DLUPParameters := Sequence[*UPParametersItem]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofUPParameters},
+			ext: false,
+		}
+		fn_DLUPParameters := func() *UPParametersItem { return new(UPParametersItem) }
+		if err = tmp_DLUPParameters.Decode(r, fn_DLUPParameters); err != nil {
+			err = utils.WrapError("Read DLUPParameters", err)
+			return
+		}
+		ie.DLUPParameters = []UPParametersItem{}
+		for _, i := range tmp_DLUPParameters.Value {
+			ie.DLUPParameters = append(ie.DLUPParameters, *i)
+		}
+	}
+	if isOptionalSet(optionals, 7) {
+		err = fmt.Errorf("Read CellGroupToAdd: not supported")
+		return
+	}
+	if isOptionalSet(optionals, 8) {
+		err = fmt.Errorf("Read CellGroupToModify: not supported")
+		return
+	}
+	if isOptionalSet(optionals, 9) {
+		err = fmt.Errorf("Read CellGroupToRemove: not supported")
+		return
+	}
+	if isOptionalSet(optionals, 10) {
+		tmp_FlowMappingInformation := Sequence[*QoSFlowQoSParameterItem]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofQoSFlows},
+			ext: false,
+		}
+		fn_FlowMappingInformation := func() *QoSFlowQoSParameterItem { return new(QoSFlowQoSParameterItem) }
+		if err = tmp_FlowMappingInformation.Decode(r, fn_FlowMappingInformation); err != nil {
+			err = utils.WrapError("Read FlowMappingInformation", err)
+			return
+		}
+		ie.FlowMappingInformation = []QoSFlowQoSParameterItem{}
+		for _, i := range tmp_FlowMappingInformation.Value {
+			ie.FlowMappingInformation = append(ie.FlowMappingInformation, *i)
+		}
+	}
+	if isOptionalSet(optionals, 11) {
+		tmp_DRBInactivityTimer := INTEGER{
+			c:   aper.Constraint{Lb: 1, Ub: 7200},
+			ext: true,
+		}
+		if err = tmp_DRBInactivityTimer.Decode(r); err != nil {
+			err = utils.WrapError("Read DRBInactivityTimer", err)
+			return
+		}
+		ie.DRBInactivityTimer = (*int64)(&tmp_DRBInactivityTimer.Value)
+	}
+	if isOptionalSet(optionals, 12) {
+		if err = skipExtensions(r); err != nil {
+			err = utils.WrapError("Read IEExtensions", err)
+			return
+		}
+	}
+	return
+}
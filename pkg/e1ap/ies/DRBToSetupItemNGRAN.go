@@ -0,0 +1,154 @@
+package ies
+
+import (
+	"fmt"
+
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type DRBToSetupItemNGRAN struct {
+	DRBID                       int64
+	SDAPConfiguration           SDAPConfiguration
+	PDCPConfiguration           PDCPConfiguration
+	CellGroupInformation        []CellGroupInformationItem
+	QoSFlowInformationToBeSetup []QoSFlowQoSParameterItem
+	// DRBDataForwardingInformationRequest *DRBDataForwardingInformationRequest `optional`
+	DRBInactivityTimer *int64
+	// PDCPSNStatusInformation *PDCPSNStatusInformation `optional`
+	// IEExtensions *DRBToSetupItemNGRANExtIEs `optional`
+}
+
+func (ie *DRBToSetupItemNGRAN) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	optionals := []byte{0x0}
+	if ie.DRBInactivityTimer != nil {
+		aper.SetBit(optionals, 2)
+	}
+	w.WriteBits(optionals, 4)
+	tmp_DRBID := NewINTEGER(ie.DRBID, aper.Constraint{Lb: 1, Ub: 32}, true)
+	if err = tmp_DRBID.Encode(w); err != nil {
+		err = utils.WrapError("Encode DRBID", err)
+		return
+	}
+	if err = ie.SDAPConfiguration.Encode(w); err != nil {
+		err = utils.WrapError("Encode SDAPConfiguration", err)
+		return
+	}
+	if err = ie.PDCPConfiguration.Encode(w); err != nil {
+		err = utils.WrapError("Encode PDCPConfiguration", err)
+		return
+	}
+	tmp_CellGroupInformation := Sequence[*CellGroupInformationItem]{
+		Value: []*CellGroupInformationItem{},
+		c:     aper.Constraint{Lb: 1, Ub: maxnoofCellGroups},
+		ext:   false,
+	}
+	for i := range ie.CellGroupInformation {
+		tmp_CellGroupInformation.Value = append(tmp_CellGroupInformation.Value, &ie.CellGroupInformation[i])
+	}
+	if err = tmp_CellGroupInformation.Encode(w); err != nil {
+		err = utils.WrapError("Encode CellGroupInformation", err)
+		return
+	}
+	tmp_QoSFlowInformationToBeSetup := Sequence[*QoSFlowQoSParameterItem]{
+		Value: []*QoSFlowQoSParameterItem{},
+		c:     aper.Constraint{Lb: 1, Ub: maxnoofQoSFlows},
+		ext:   false,
+	}
+	for i := range ie.QoSFlowInformationToBeSetup {
+		tmp_QoSFlowInformationToBeSetup.Value = append(tmp_QoSFlowInformationToBeSetup.Value, &ie.QoSFlowInformationToBeSetup[i])
+	}
+	if err = tmp_QoSFlowInformationToBeSetup.Encode(w); err != nil {
+		err = utils.WrapError("Encode QoSFlowInformationToBeSetup", err)
+		return
+	}
+	if ie.DRBInactivityTimer != nil {
+		tmp_DRBInactivityTimer := NewINTEGER(*ie.DRBInactivityTimer, aper.Constraint{Lb: 1, Ub: 7200}, true)
+		if err = tmp_DRBInactivityTimer.Encode(w); err != nil {
+			err = utils.WrapError("Encode DRBInactivityTimer", err)
+			return
+		}
+	}
+	return
+}
+func (ie *DRBToSetupItemNGRAN) Decode(r *aper.AperReader) (err error) {
+	if _, err = r.ReadBool(); err != nil {
+		return
+	}
+	var optionals []byte
+	if optionals, err = r.ReadBits(4); err != nil {
+		return
+	}
+	tmp_DRBID := INTEGER{
+		c:   aper.Constraint{Lb: 1, Ub: 32},
+		ext: true,
+	}
+	if err = tmp_DRBID.Decode(r); err != nil {
+		err = utils.WrapError("Read DRBID", err)
+		return
+	}
+	ie.DRBID = int64(tmp_DRBID.Value)
+	if err = ie.SDAPConfiguration.Decode(r); err != nil {
+		err = utils.WrapError("Read SDAPConfiguration", err)
+		return
+	}
+	if err = ie.PDCPConfiguration.Decode(r); err != nil {
+		err = utils.WrapError("Read PDCPConfiguration", err)
+		return
+	}
+	tmp_CellGroupInformation := Sequence[*CellGroupInformationItem]{
+		c:   aper.Constraint{Lb: 1, Ub: maxnoofCellGroups},
+		ext: false,
+	}
+	fn_CellGroupInformation := func() *CellGroupInformationItem { return new(CellGroupInformationItem) }
+	if err = tmp_CellGroupInformation.Decode(r, fn_CellGroupInformation); err != nil {
+		err = utils.WrapError("Read CellGroupInformation", err)
+		return
+	}
+	ie.CellGroupInformation = []CellGroupInformationItem{}
+	for _, i := range tmp_CellGroupInformation.Value {
+		ie.CellGroupInformation = append(ie.CellGroupInformation, *i)
+	}
+	tmp_QoSFlowInformationToBeSetup := Sequence[*QoSFlowQoSParameterItem]{
+		c:   aper.Constraint{Lb: 1, Ub: maxnoofQoSFlows},
+		ext: false,
+	}
+	fn_QoSFlowInformationToBeSetup := func() *QoSFlowQoSParameterItem { return new(QoSFlowQoSParameterItem) }
+	if err = tmp_QoSFlowInformationToBeSetup.Decode(r, fn_QoSFlowInformationToBeSetup); err != nil {
+		err = utils.WrapError("Read QoSFlowInformationToBeSetup", err)
+		return
+	}
+	ie.QoSFlowInformationToBeSetup = []QoSFlowQoSParameterItem{}
+	for _, i := range tmp_QoSFlowInformationToBeSetup.Value {
+		ie.QoSFlowInformationToBeSetup = append(ie.QoSFlowInformationToBeSetup, *i)
+	}
+	if aper.IsBitSet(optionals, 1) {
+		err = fmt.Errorf("Read DRBDataForwardingInformationRequest: not supported")
+		return
+	}
+	if aper.IsBitSet(optionals, 2) {
+		tmp_DRBInactivityTimer := INTEGER{
+			c:   aper.Constraint{Lb: 1, Ub: 7200},
+			ext: true,
+		}
+		if err = tmp_DRBInactivityTimer.Decode(r); err != nil {
+			err = utils.WrapError("Read DRBInactivityTimer", err)
+			return
+		}
+		ie.DRBInactivityTimer = (*int64)(&tmp_DRBInactivityTimer.Value)
+	}
+	if aper.IsBitSet(optionals, 3) {
+		err = fmt.Errorf("Read PDCPSNStatusInformation: not supported")
+		return
+	}
+	if aper.IsBitSet(optionals, 4) {
+		if err = skipExtensions(r); err != nil {
+			err = utils.WrapError("Read IEExtensions", err)
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,22 @@
+package ies
+
+import "github.com/lvdund/ngap/aper"
+
+const (
+	DefaultDRBTrue  aper.Enumerated = 0
+	DefaultDRBFalse aper.Enumerated = 1
+)
+
+type DefaultDRB struct {
+	Value aper.Enumerated
+}
+
+func (ie *DefaultDRB) Encode(w *aper.AperWriter) (err error) {
+	err = w.WriteEnumerate(uint64(ie.Value), aper.Constraint{Lb: 0, Ub: 1}, true)
+	return
+}
+func (ie *DefaultDRB) Decode(r *aper.AperReader) (err error) {
+	v, err := r.ReadEnumerate(aper.Constraint{Lb: 0, Ub: 1}, true)
+	ie.Value = aper.Enumerated(v)
+	return
+}
@@ -0,0 +1,158 @@
+package ies
+
+import (
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type Dynamic5QIDescriptor struct {
+	QoSPriorityLevel   int64
+	PacketDelayBudget  int64
+	PacketErrorRate    PacketErrorRate
+	FiveQI             *int64
+	DelayCritical      *DelayCritical
+	AveragingWindow    *int64
+	MaxDataBurstVolume *int64
+	// IEExtensions *Dynamic5QIDescriptorExtIEs `optional`
+}
+
+func (ie *Dynamic5QIDescriptor) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	optionals := []byte{0x0}
+	if ie.FiveQI != nil {
+		aper.SetBit(optionals, 1)
+	}
+	if ie.DelayCritical != nil {
+		aper.SetBit(optionals, 2)
+	}
+	if ie.AveragingWindow != nil {
+		aper.SetBit(optionals, 3)
+	}
+	if ie.MaxDataBurstVolume != nil {
+		aper.SetBit(optionals, 4)
+	}
+	w.WriteBits(optionals, 5)
+	tmp_QoSPriorityLevel := NewINTEGER(ie.QoSPriorityLevel, aper.Constraint{Lb: 0, Ub: 127}, false)
+	if err = tmp_QoSPriorityLevel.Encode(w); err != nil {
+		err = utils.WrapError("Encode QoSPriorityLevel", err)
+		return
+	}
+	tmp_PacketDelayBudget := NewINTEGER(ie.PacketDelayBudget, aper.Constraint{Lb: 0, Ub: 1023}, true)
+	if err = tmp_PacketDelayBudget.Encode(w); err != nil {
+		err = utils.WrapError("Encode PacketDelayBudget", err)
+		return
+	}
+	if err = ie.PacketErrorRate.Encode(w); err != nil {
+		err = utils.WrapError("Encode PacketErrorRate", err)
+		return
+	}
+	if ie.FiveQI != nil {
+		tmp_FiveQI := NewINTEGER(*ie.FiveQI, aper.Constraint{Lb: 0, Ub: 255}, true)
+		if err = tmp_FiveQI.Encode(w); err != nil {
+			err = utils.WrapError("Encode FiveQI", err)
+			return
+		}
+	}
+	if ie.DelayCritical != nil {
+		if err = ie.DelayCritical.Encode(w); err != nil {
+			err = utils.WrapError("Encode DelayCritical", err)
+			return
+		}
+	}
+	if ie.AveragingWindow != nil {
+		tmp_AveragingWindow := NewINTEGER(*ie.AveragingWindow, aper.Constraint{Lb: 0, Ub: 4095}, true)
+		if err = tmp_AveragingWindow.Encode(w); err != nil {
+			err = utils.WrapError("Encode AveragingWindow", err)
+			return
+		}
+	}
+	if ie.MaxDataBurstVolume != nil {
+		tmp_MaxDataBurstVolume := NewINTEGER(*ie.MaxDataBurstVolume, aper.Constraint{Lb: 0, Ub: 4095}, true)
+		if err = tmp_MaxDataBurstVolume.Encode(w); err != nil {
+			err = utils.WrapError("Encode MaxDataBurstVolume", err)
+			return
+		}
+	}
+	return
+}
+func (ie *Dynamic5QIDescriptor) Decode(r *aper.AperReader) (err error) {
+	if _, err = r.ReadBool(); err != nil {
+		return
+	}
+	var optionals []byte
+	if optionals, err = r.ReadBits(5); err != nil {
+		return
+	}
+	tmp_QoSPriorityLevel := INTEGER{
+		c:   aper.Constraint{Lb: 0, Ub: 127},
+		ext: false,
+	}
+	if err = tmp_QoSPriorityLevel.Decode(r); err != nil {
+		err = utils.WrapError("Read QoSPriorityLevel", err)
+		return
+	}
+	ie.QoSPriorityLevel = int64(tmp_QoSPriorityLevel.Value)
+	tmp_PacketDelayBudget := INTEGER{
+		c:   aper.Constraint{Lb: 0, Ub: 1023},
+		ext: true,
+	}
+	if err = tmp_PacketDelayBudget.Decode(r); err != nil {
+		err = utils.WrapError("Read PacketDelayBudget", err)
+		return
+	}
+	ie.PacketDelayBudget = int64(tmp_PacketDelayBudget.Value)
+	if err = ie.PacketErrorRate.Decode(r); err != nil {
+		err = utils.WrapError("Read PacketErrorRate", err)
+		return
+	}
+	if aper.IsBitSet(optionals, 1) {
+		tmp_FiveQI := INTEGER{
+			c:   aper.Constraint{Lb: 0, Ub: 255},
+			ext: true,
+		}
+		if err = tmp_FiveQI.Decode(r); err != nil {
+			err = utils.WrapError("Read FiveQI", err)
+			return
+		}
+		ie.FiveQI = (*int64)(&tmp_FiveQI.Value)
+	}
+	if aper.IsBitSet(optionals, 2) {
+		tmp := new(DelayCritical)
+		if err = tmp.Decode(r); err != nil {
+			err = utils.WrapError("Read DelayCritical", err)
+			return
+		}
+		ie.DelayCritical = tmp
+	}
+	if aper.IsBitSet(optionals, 3) {
+		tmp_AveragingWindow := INTEGER{
+			c:   aper.Constraint{Lb: 0, Ub: 4095},
+			ext: true,
+		}
+		if err = tmp_AveragingWindow.Decode(r); err != nil {
+			err = utils.WrapError("Read AveragingWindow", err)
+			return
+		}
+		ie.AveragingWindow = (*int64)(&tmp_AveragingWindow.Value)
+	}
+	if aper.IsBitSet(optionals, 4) {
+		tmp_MaxDataBurstVolume := INTEGER{
+			c:   aper.Constraint{Lb: 0, Ub: 4095},
+			ext: true,
+		}
+		if err = tmp_MaxDataBurstVolume.Decode(r); err != nil {
+			err = utils.WrapError("Read MaxDataBurstVolume", err)
+			return
+		}
+		ie.MaxDataBurstVolume = (*int64)(&tmp_MaxDataBurstVolume.Value)
+	}
+	if aper.IsBitSet(optionals, 5) {
+		if err = skipExtensions(r); err != nil {
+			err = utils.WrapError("Read IEExtensions", err)
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,139 @@
+package ies
+
+import (
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type GBRQoSFlowInformation struct {
+	MaxFlowBitRateDownlink        int64
+	MaxFlowBitRateUplink          int64
+	GuaranteedFlowBitRateDownlink int64
+	GuaranteedFlowBitRateUplink   int64
+	MaxPacketLossRateDownlink     *int64
+	MaxPacketLossRateUplink       *int64
+	// IEExtensions *GBRQoSFlowInformationExtIEs `optional`
+}
+
+func (ie *GBRQoSFlowInformation) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	optionals := []byte{0x0}
+	if ie.MaxPacketLossRateDownlink != nil {
+		aper.SetBit(optionals, 1)
+	}
+	if ie.MaxPacketLossRateUplink != nil {
+		aper.SetBit(optionals, 2)
+	}
+	w.WriteBits(optionals, 3)
+	tmp_MaxFlowBitRateDownlink := NewINTEGER(ie.MaxFlowBitRateDownlink, aper.Constraint{Lb: 0, Ub: 4000000000000}, true)
+	if err = tmp_MaxFlowBitRateDownlink.Encode(w); err != nil {
+		err = utils.WrapError("Encode MaxFlowBitRateDownlink", err)
+		return
+	}
+	tmp_MaxFlowBitRateUplink := NewINTEGER(ie.MaxFlowBitRateUplink, aper.Constraint{Lb: 0, Ub: 4000000000000}, true)
+	if err = tmp_MaxFlowBitRateUplink.Encode(w); err != nil {
+		err = utils.WrapError("Encode MaxFlowBitRateUplink", err)
+		return
+	}
+	tmp_GuaranteedFlowBitRateDownlink := NewINTEGER(ie.GuaranteedFlowBitRateDownlink, aper.Constraint{Lb: 0, Ub: 4000000000000}, true)
+	if err = tmp_GuaranteedFlowBitRateDownlink.Encode(w); err != nil {
+		err = utils.WrapError("Encode GuaranteedFlowBitRateDownlink", err)
+		return
+	}
+	tmp_GuaranteedFlowBitRateUplink := NewINTEGER(ie.GuaranteedFlowBitRateUplink, aper.Constraint{Lb: 0, Ub: 4000000000000}, true)
+	if err = tmp_GuaranteedFlowBitRateUplink.Encode(w); err != nil {
+		err = utils.WrapError("Encode GuaranteedFlowBitRateUplink", err)
+		return
+	}
+	if ie.MaxPacketLossRateDownlink != nil {
+		tmp_MaxPacketLossRateDownlink := NewINTEGER(*ie.MaxPacketLossRateDownlink, aper.Constraint{Lb: 0, Ub: 1000}, true)
+		if err = tmp_MaxPacketLossRateDownlink.Encode(w); err != nil {
+			err = utils.WrapError("Encode MaxPacketLossRateDownlink", err)
+			return
+		}
+	}
+	if ie.MaxPacketLossRateUplink != nil {
+		tmp_MaxPacketLossRateUplink := NewINTEGER(*ie.MaxPacketLossRateUplink, aper.Constraint{Lb: 0, Ub: 1000}, true)
+		if err = tmp_MaxPacketLossRateUplink.Encode(w); err != nil {
+			err = utils.WrapError("Encode MaxPacketLossRateUplink", err)
+			return
+		}
+	}
+	return
+}
+func (ie *GBRQoSFlowInformation) Decode(r *aper.AperReader) (err error) {
+	if _, err = r.ReadBool(); err != nil {
+		return
+	}
+	var optionals []byte
+	if optionals, err = r.ReadBits(3); err != nil {
+		return
+	}
+	tmp_MaxFlowBitRateDownlink := INTEGER{
+		c:   aper.Constraint{Lb: 0, Ub: 4000000000000},
+		ext: true,
+	}
+	if err = tmp_MaxFlowBitRateDownlink.Decode(r); err != nil {
+		err = utils.WrapError("Read MaxFlowBitRateDownlink", err)
+		return
+	}
+	ie.MaxFlowBitRateDownlink = int64(tmp_MaxFlowBitRateDownlink.Value)
+	tmp_MaxFlowBitRateUplink := INTEGER{
+		c:   aper.Constraint{Lb: 0, Ub: 4000000000000},
+		ext: true,
+	}
+	if err = tmp_MaxFlowBitRateUplink.Decode(r); err != nil {
+		err = utils.WrapError("Read MaxFlowBitRateUplink", err)
+		return
+	}
+	ie.MaxFlowBitRateUplink = int64(tmp_MaxFlowBitRateUplink.Value)
+	tmp_GuaranteedFlowBitRateDownlink := INTEGER{
+		c:   aper.Constraint{Lb: 0, Ub: 4000000000000},
+		ext: true,
+	}
+	if err = tmp_GuaranteedFlowBitRateDownlink.Decode(r); err != nil {
+		err = utils.WrapError("Read GuaranteedFlowBitRateDownlink", err)
+		return
+	}
+	ie.GuaranteedFlowBitRateDownlink = int64(tmp_GuaranteedFlowBitRateDownlink.Value)
+	tmp_GuaranteedFlowBitRateUplink := INTEGER{
+		c:   aper.Constraint{Lb: 0, Ub: 4000000000000},
+		ext: true,
+	}
+	if err = tmp_GuaranteedFlowBitRateUplink.Decode(r); err != nil {
+		err = utils.WrapError("Read GuaranteedFlowBitRateUplink", err)
+		return
+	}
+	ie.GuaranteedFlowBitRateUplink = int64(tmp_GuaranteedFlowBitRateUplink.Value)
+	if aper.IsBitSet(optionals, 1) {
+		tmp_MaxPacketLossRateDownlink := INTEGER{
+			c:   aper.Constraint{Lb: 0, Ub: 1000},
+			ext: true,
+		}
+		if err = tmp_MaxPacketLossRateDownlink.Decode(r); err != nil {
+			err = utils.WrapError("Read MaxPacketLossRateDownlink", err)
+			return
+		}
+		ie.MaxPacketLossRateDownlink = (*int64)(&tmp_MaxPacketLossRateDownlink.Value)
+	}
+	if aper.IsBitSet(optionals, 2) {
+		tmp_MaxPacketLossRateUplink := INTEGER{
+			c:   aper.Constraint{Lb: 0, Ub: 1000},
+			ext: true,
+		}
+		if err = tmp_MaxPacketLossRateUplink.Decode(r); err != nil {
+			err = utils.WrapError("Read MaxPacketLossRateUplink", err)
+			return
+		}
+		ie.MaxPacketLossRateUplink = (*int64)(&tmp_MaxPacketLossRateUplink.Value)
+	}
+	if aper.IsBitSet(optionals, 3) {
+		if err = skipExtensions(r); err != nil {
+			err = utils.WrapError("Read IEExtensions", err)
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,107 @@
+package ies
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type GNBCUUPE1SetupFailure struct {
+	TransactionID          int64
+	Cause                  Cause
+	TimeToWait             *TimeToWait
+	CriticalityDiagnostics *CriticalityDiagnostics
+}
+
+func (msg *GNBCUUPE1SetupFailure) Encode(w io.Writer) (err error) {
+	var ies []E1apMessageIE
+	if ies, err = msg.toIes(); err != nil {
+		err = msgErrors(fmt.Errorf("GNBCUUPE1SetupFailure"), err)
+		return
+	}
+	return encodeMessage(w, E1apPduUnsuccessfulOutcome, ProcedureCode_GNBCUUPE1Setup, Criticality_PresentReject, ies)
+}
+func (msg *GNBCUUPE1SetupFailure) toIes() (ies []E1apMessageIE, err error) {
+	ies = []E1apMessageIE{}
+	ies = append(ies, E1apMessageIE{
+		Id:          ProtocolIEID{Value: ProtocolIEID_TransactionID},
+		Criticality: Criticality{Value: Criticality_PresentReject},
+		Value: &INTEGER{
+			c:     aper.Constraint{Lb: 0, Ub: 255},
+			ext:   true,
+			Value: aper.Integer(msg.TransactionID),
+		}})
+	ies = append(ies, E1apMessageIE{
+		Id:          ProtocolIEID{Value: ProtocolIEID_Cause},
+		Criticality: Criticality{Value: Criticality_PresentIgnore},
+		Value:       &msg.Cause,
+	})
+	if msg.TimeToWait != nil {
+		ies = append(ies, E1apMessageIE{
+			Id:          ProtocolIEID{Value: ProtocolIEID_TimeToWait},
+			Criticality: Criticality{Value: Criticality_PresentIgnore},
+			Value:       msg.TimeToWait,
+		})
+	}
+	if msg.CriticalityDiagnostics != nil {
+		ies = append(ies, E1apMessageIE{
+			Id:          ProtocolIEID{Value: ProtocolIEID_CriticalityDiagnostics},
+			Criticality: Criticality{Value: Criticality_PresentIgnore},
+			Value:       msg.CriticalityDiagnostics,
+		})
+	}
+	return
+}
+func (msg *GNBCUUPE1SetupFailure) Decode(wire []byte) (err error, diagList []CriticalityDiagnosticsIEItem) {
+	defer func() {
+		if err != nil {
+			err = msgErrors(fmt.Errorf("GNBCUUPE1SetupFailure"), err)
+		}
+	}()
+	diagList, err = decodeMessage(wire, msg.decodeIE, []mandatoryIE{
+		{ProtocolIEID_TransactionID, "TransactionID", Criticality_PresentReject},
+		{ProtocolIEID_Cause, "Cause", Criticality_PresentIgnore},
+	})
+	return
+}
+func (msg *GNBCUUPE1SetupFailure) decodeIE(id aper.Integer, ieR *aper.AperReader) (known bool, err error) {
+	known = true
+	switch id {
+	case ProtocolIEID_TransactionID:
+		tmp := INTEGER{
+			c:   aper.Constraint{Lb: 0, Ub: 255},
+			ext: true,
+		}
+		if err = tmp.Decode(ieR); err != nil {
+			err = utils.WrapError("Read TransactionID", err)
+			return
+		}
+		msg.TransactionID = int64(tmp.Value)
+	case ProtocolIEID_Cause:
+		var tmp Cause
+		if err = tmp.Decode(ieR); err != nil {
+			err = utils.WrapError("Read Cause", err)
+			return
+		}
+		msg.Cause = tmp
+	case ProtocolIEID_TimeToWait:
+		var tmp TimeToWait
+		if err = tmp.Decode(ieR); err != nil {
+			err = utils.WrapError("Read TimeToWait", err)
+			return
+		}
+		msg.TimeToWait = &tmp
+	case ProtocolIEID_CriticalityDiagnostics:
+		var tmp CriticalityDiagnostics
+		if err = tmp.Decode(ieR); err != nil {
+			err = utils.WrapError("Read CriticalityDiagnostics", err)
+			return
+		}
+		msg.CriticalityDiagnostics = &tmp
+	default:
+		known = false
+	}
+	return
+}
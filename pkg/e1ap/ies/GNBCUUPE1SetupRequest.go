@@ -0,0 +1,173 @@
+package ies
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type GNBCUUPE1SetupRequest struct {
+	TransactionID   int64
+	GNBCUUPID       int64
+	GNBCUUPName     []byte
+	CNSupport       CNSupport
+	SupportedPLMNs  []SupportedPLMNsItem
+	GNBCUUPCapacity *int64
+}
+
+func (msg *GNBCUUPE1SetupRequest) Encode(w io.Writer) (err error) {
+	var ies []E1apMessageIE
+	if ies, err = msg.toIes(); err != nil {
+		err = msgErrors(fmt.Errorf("GNBCUUPE1SetupRequest"), err)
+		return
+	}
+	return encodeMessage(w, E1apPduInitiatingMessage, ProcedureCode_GNBCUUPE1Setup, Criticality_PresentReject, ies)
+}
+func (msg *GNBCUUPE1SetupRequest) toIes() (ies []E1apMessageIE, err error) {
+	ies = []E1apMessageIE{}
+	ies = append(ies, E1apMessageIE{
+		Id:          ProtocolIEID{Value: ProtocolIEID_TransactionID},
+		Criticality: Criticality{Value: Criticality_PresentReject},
+		Value: &INTEGER{
+			c:     aper.Constraint{Lb: 0, Ub: 255},
+			ext:   true,
+			Value: aper.Integer(msg.TransactionID),
+		}})
+	ies = append(ies, E1apMessageIE{
+		Id:          ProtocolIEID{Value: ProtocolIEID_GNBCUUPID},
+		Criticality: Criticality{Value: Criticality_PresentReject},
+		Value: &INTEGER{
+			c:     aper.Constraint{Lb: 0, Ub: 68719476735},
+			ext:   false,
+			Value: aper.Integer(msg.GNBCUUPID),
+		}})
+	if msg.GNBCUUPName != nil {
+		ies = append(ies, E1apMessageIE{
+			Id:          ProtocolIEID{Value: ProtocolIEID_GNBCUUPName},
+			Criticality: Criticality{Value: Criticality_PresentIgnore},
+			Value: &OCTETSTRING{
+				c:     aper.Constraint{Lb: 1, Ub: 150},
+				ext:   true,
+				Value: msg.GNBCUUPName,
+			}})
+	}
+	ies = append(ies, E1apMessageIE{
+		Id:          ProtocolIEID{Value: ProtocolIEID_CNSupport},
+		Criticality: Criticality{Value: Criticality_PresentReject},
+		Value:       &msg.CNSupport,
+	})
+	if len(msg.SupportedPLMNs) > 0 {
+		tmp_SupportedPLMNs := Sequence[*SupportedPLMNsItem]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofSPLMNs},
+			ext: false,
+		}
+		for i := range msg.SupportedPLMNs {
+			tmp_SupportedPLMNs.Value = append(tmp_SupportedPLMNs.Value, &msg.SupportedPLMNs[i])
+		}
+		ies = append(ies, E1apMessageIE{
+			Id:          ProtocolIEID{Value: ProtocolIEID_SupportedPLMNs},
+			Criticality: Criticality{Value: Criticality_PresentReject},
+			Value:       &tmp_SupportedPLMNs,
+		})
+	} else {
+		err = utils.WrapError("SupportedPLMNs is nil", err)
+		return
+	}
+	if msg.GNBCUUPCapacity != nil {
+		ies = append(ies, E1apMessageIE{
+			Id:          ProtocolIEID{Value: ProtocolIEID_GNBCUUPCapacity},
+			Criticality: Criticality{Value: Criticality_PresentIgnore},
+			Value: &INTEGER{
+				c:     aper.Constraint{Lb: 0, Ub: 255},
+				ext:   false,
+				Value: aper.Integer(*msg.GNBCUUPCapacity),
+			}})
+	}
+	return
+}
+func (msg *GNBCUUPE1SetupRequest) Decode(wire []byte) (err error, diagList []CriticalityDiagnosticsIEItem) {
+	defer func() {
+		if err != nil {
+			err = msgErrors(fmt.Errorf("GNBCUUPE1SetupRequest"), err)
+		}
+	}()
+	diagList, err = decodeMessage(wire, msg.decodeIE, []mandatoryIE{
+		{ProtocolIEID_TransactionID, "TransactionID", Criticality_PresentReject},
+		{ProtocolIEID_GNBCUUPID, "GNBCUUPID", Criticality_PresentReject},
+		{ProtocolIEID_CNSupport, "CNSupport", Criticality_PresentReject},
+		{ProtocolIEID_SupportedPLMNs, "SupportedPLMNs", Criticality_PresentReject},
+	})
+	return
+}
+func (msg *GNBCUUPE1SetupRequest) decodeIE(id aper.Integer, ieR *aper.AperReader) (known bool, err error) {
+	known = true
+	switch id {
+	case ProtocolIEID_TransactionID:
+		tmp := INTEGER{
+			c:   aper.Constraint{Lb: 0, Ub: 255},
+			ext: true,
+		}
+		if err = tmp.Decode(ieR); err != nil {
+			err = utils.WrapError("Read TransactionID", err)
+			return
+		}
+		msg.TransactionID = int64(tmp.Value)
+	case ProtocolIEID_GNBCUUPID:
+		tmp := INTEGER{
+			c:   aper.Constraint{Lb: 0, Ub: 68719476735},
+			ext: false,
+		}
+		if err = tmp.Decode(ieR); err != nil {
+			err = utils.WrapError("Read GNBCUUPID", err)
+			return
+		}
+		msg.GNBCUUPID = int64(tmp.Value)
+	case ProtocolIEID_GNBCUUPName:
+		tmp := OCTETSTRING{
+			c:   aper.Constraint{Lb: 1, Ub: 150},
+			ext: true,
+		}
+		if err = tmp.Decode(ieR); err != nil {
+			err = utils.WrapError("Read GNBCUUPName", err)
+			return
+		}
+		msg.GNBCUUPName = tmp.Value
+	case ProtocolIEID_CNSupport:
+		var tmp CNSupport
+		if err = tmp.Decode(ieR); err != nil {
+			err = utils.WrapError("Read CNSupport", err)
+			return
+		}
+		msg.CNSupport = tmp
+	case ProtocolIEID_SupportedPLMNs:
+		tmp := Sequence[*SupportedPLMNsItem]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofSPLMNs},
+			ext: false,
+		}
+		fn := func() *SupportedPLMNsItem { return new(SupportedPLMNsItem) }
+		if err = tmp.Decode(ieR, fn); err != nil {
+			err = utils.WrapError("Read SupportedPLMNs", err)
+			return
+		}
+		msg.SupportedPLMNs = []SupportedPLMNsItem{}
+		for _, i := range tmp.Value {
+			msg.SupportedPLMNs = append(msg.SupportedPLMNs, *i)
+		}
+	case ProtocolIEID_GNBCUUPCapacity:
+		tmp := INTEGER{
+			c:   aper.Constraint{Lb: 0, Ub: 255},
+			ext: false,
+		}
+		if err = tmp.Decode(ieR); err != nil {
+			err = utils.WrapError("Read GNBCUUPCapacity", err)
+			return
+		}
+		capacity := int64(tmp.Value)
+		msg.GNBCUUPCapacity = &capacity
+	default:
+		known = false
+	}
+	return
+}
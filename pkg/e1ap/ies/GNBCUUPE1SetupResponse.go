@@ -0,0 +1,84 @@
+package ies
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type GNBCUUPE1SetupResponse struct {
+	TransactionID int64
+	GNBCUCPName   []byte
+}
+
+func (msg *GNBCUUPE1SetupResponse) Encode(w io.Writer) (err error) {
+	var ies []E1apMessageIE
+	if ies, err = msg.toIes(); err != nil {
+		err = msgErrors(fmt.Errorf("GNBCUUPE1SetupResponse"), err)
+		return
+	}
+	return encodeMessage(w, E1apPduSuccessfulOutcome, ProcedureCode_GNBCUUPE1Setup, Criticality_PresentReject, ies)
+}
+func (msg *GNBCUUPE1SetupResponse) toIes() (ies []E1apMessageIE, err error) {
+	ies = []E1apMessageIE{}
+	ies = append(ies, E1apMessageIE{
+		Id:          ProtocolIEID{Value: ProtocolIEID_TransactionID},
+		Criticality: Criticality{Value: Criticality_PresentReject},
+		Value: &INTEGER{
+			c:     aper.Constraint{Lb: 0, Ub: 255},
+			ext:   true,
+			Value: aper.Integer(msg.TransactionID),
+		}})
+	if msg.GNBCUCPName != nil {
+		ies = append(ies, E1apMessageIE{
+			Id:          ProtocolIEID{Value: ProtocolIEID_GNBCUCPName},
+			Criticality: Criticality{Value: Criticality_PresentIgnore},
+			Value: &OCTETSTRING{
+				c:     aper.Constraint{Lb: 1, Ub: 150},
+				ext:   true,
+				Value: msg.GNBCUCPName,
+			}})
+	}
+	return
+}
+func (msg *GNBCUUPE1SetupResponse) Decode(wire []byte) (err error, diagList []CriticalityDiagnosticsIEItem) {
+	defer func() {
+		if err != nil {
+			err = msgErrors(fmt.Errorf("GNBCUUPE1SetupResponse"), err)
+		}
+	}()
+	diagList, err = decodeMessage(wire, msg.decodeIE, []mandatoryIE{
+		{ProtocolIEID_TransactionID, "TransactionID", Criticality_PresentReject},
+	})
+	return
+}
+func (msg *GNBCUUPE1SetupResponse) decodeIE(id aper.Integer, ieR *aper.AperReader) (known bool, err error) {
+	known = true
+	switch id {
+	case ProtocolIEID_TransactionID:
+		tmp := INTEGER{
+			c:   aper.Constraint{Lb: 0, Ub: 255},
+			ext: true,
+		}
+		if err = tmp.Decode(ieR); err != nil {
+			err = utils.WrapError("Read TransactionID", err)
+			return
+		}
+		msg.TransactionID = int64(tmp.Value)
+	case ProtocolIEID_GNBCUCPName:
+		tmp := OCTETSTRING{
+			c:   aper.Constraint{Lb: 1, Ub: 150},
+			ext: true,
+		}
+		if err = tmp.Decode(ieR); err != nil {
+			err = utils.WrapError("Read GNBCUCPName", err)
+			return
+		}
+		msg.GNBCUCPName = tmp.Value
+	default:
+		known = false
+	}
+	return
+}
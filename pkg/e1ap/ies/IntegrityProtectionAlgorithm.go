@@ -0,0 +1,24 @@
+package ies
+
+import "github.com/lvdund/ngap/aper"
+
+const (
+	IntegrityProtectionAlgorithmNIA0     aper.Enumerated = 0
+	IntegrityProtectionAlgorithmI128NIA1 aper.Enumerated = 1
+	IntegrityProtectionAlgorithmI128NIA2 aper.Enumerated = 2
+	IntegrityProtectionAlgorithmI128NIA3 aper.Enumerated = 3
+)
+
+type IntegrityProtectionAlgorithm struct {
+	Value aper.Enumerated
+}
+
+func (ie *IntegrityProtectionAlgorithm) Encode(w *aper.AperWriter) (err error) {
+	err = w.WriteEnumerate(uint64(ie.Value), aper.Constraint{Lb: 0, Ub: 3}, true)
+	return
+}
+func (ie *IntegrityProtectionAlgorithm) Decode(r *aper.AperReader) (err error) {
+	v, err := r.ReadEnumerate(aper.Constraint{Lb: 0, Ub: 3}, true)
+	ie.Value = aper.Enumerated(v)
+	return
+}
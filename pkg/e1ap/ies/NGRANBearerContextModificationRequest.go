@@ -0,0 +1,128 @@
+package ies
+
+import (
+	"fmt"
+
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type NGRANBearerContextModificationRequest struct {
+	PDUSessionResourceToSetupModList []PDUSessionResourceToSetupModItem
+	PDUSessionResourceToModifyList   []PDUSessionResourceToModifyItem
+	PDUSessionResourceToRemoveList   []PDUSessionResourceToRemoveItem
+}
+
+func (msg *NGRANBearerContextModificationRequest) Encode(w *aper.AperWriter) (err error) {
+	var ies []E1apMessageIE
+	if ies, err = msg.toIes(); err != nil {
+		err = msgErrors(fmt.Errorf("NGRANBearerContextModificationRequest"), err)
+		return
+	}
+	return encodeContainer(w, ies)
+}
+func (msg *NGRANBearerContextModificationRequest) toIes() (ies []E1apMessageIE, err error) {
+	ies = []E1apMessageIE{}
+	if len(msg.PDUSessionResourceToSetupModList) > 0 {
+		tmp_PDUSessionResourceToSetupModList := Sequence[*PDUSessionResourceToSetupModItem]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofPDUSessionRes},
+			ext: false,
+		}
+		for i := range msg.PDUSessionResourceToSetupModList {
+			tmp_PDUSessionResourceToSetupModList.Value = append(tmp_PDUSessionResourceToSetupModList.Value, &msg.PDUSessionResourceToSetupModList[i])
+		}
+		ies = append(ies, E1apMessageIE{
+			Id:          ProtocolIEID{Value: ProtocolIEID_PDUSessionResourceToSetupModList},
+			Criticality: Criticality{Value: Criticality_PresentReject},
+			Value:       &tmp_PDUSessionResourceToSetupModList,
+		})
+	}
+	if len(msg.PDUSessionResourceToModifyList) > 0 {
+		tmp_PDUSessionResourceToModifyList := Sequence[*PDUSessionResourceToModifyItem]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofPDUSessionRes},
+			ext: false,
+		}
+		for i := range msg.PDUSessionResourceToModifyList {
+			tmp_PDUSessionResourceToModifyList.Value = append(tmp_PDUSessionResourceToModifyList.Value, &msg.PDUSessionResourceToModifyList[i])
+		}
+		ies = append(ies, E1apMessageIE{
+			Id:          ProtocolIEID{Value: ProtocolIEID_PDUSessionResourceToModifyList},
+			Criticality: Criticality{Value: Criticality_PresentReject},
+			Value:       &tmp_PDUSessionResourceToModifyList,
+		})
+	}
+	if len(msg.PDUSessionResourceToRemoveList) > 0 {
+		tmp_PDUSessionResourceToRemoveList := Sequence[*PDUSessionResourceToRemoveItem]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofPDUSessionRes},
+			ext: false,
+		}
+		for i := range msg.PDUSessionResourceToRemoveList {
+			tmp_PDUSessionResourceToRemoveList.Value = append(tmp_PDUSessionResourceToRemoveList.Value, &msg.PDUSessionResourceToRemoveList[i])
+		}
+		ies = append(ies, E1apMessageIE{
+			Id:          ProtocolIEID{Value: ProtocolIEID_PDUSessionResourceToRemoveList},
+			Criticality: Criticality{Value: Criticality_PresentReject},
+			Value:       &tmp_PDUSessionResourceToRemoveList,
+		})
+	}
+	return
+}
+func (msg *NGRANBearerContextModificationRequest) Decode(r *aper.AperReader) (err error) {
+	defer func() {
+		if err != nil {
+			err = msgErrors(fmt.Errorf("NGRANBearerContextModificationRequest"), err)
+		}
+	}()
+	_, err = decodeContainer(r, msg.decodeIE, []mandatoryIE{})
+	return
+}
+func (msg *NGRANBearerContextModificationRequest) decodeIE(id aper.Integer, ieR *aper.AperReader) (known bool, err error) {
+	known = true
+	switch id {
+	case ProtocolIEID_PDUSessionResourceToSetupModList:
+		tmp := Sequence[*PDUSessionResourceToSetupModItem]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofPDUSessionRes},
+			ext: false,
+		}
+		fn := func() *PDUSessionResourceToSetupModItem { return new(PDUSessionResourceToSetupModItem) }
+		if err = tmp.Decode(ieR, fn); err != nil {
+			err = utils.WrapError("Read PDUSessionResourceToSetupModList", err)
+			return
+		}
+		msg.PDUSessionResourceToSetupModList = []PDUSessionResourceToSetupModItem{}
+		for _, i := range tmp.Value {
+			msg.PDUSessionResourceToSetupModList = append(msg.PDUSessionResourceToSetupModList, *i)
+		}
+	case ProtocolIEID_PDUSessionResourceToModifyList:
+		tmp := Sequence[*PDUSessionResourceToModifyItem]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofPDUSessionRes},
+			ext: false,
+		}
+		fn := func() *PDUSessionResourceToModifyItem { return new(PDUSessionResourceToModifyItem) }
+		if err = tmp.Decode(ieR, fn); err != nil {
+			err = utils.WrapError("Read PDUSessionResourceToModifyList", err)
+			return
+		}
+		msg.PDUSessionResourceToModifyList = []PDUSessionResourceToModifyItem{}
+		for _, i := range tmp.Value {
+			msg.PDUSessionResourceToModifyList = append(msg.PDUSessionResourceToModifyList, *i)
+		}
+	case ProtocolIEID_PDUSessionResourceToRemoveList:
+		tmp := Sequence[*PDUSessionResourceToRemoveItem]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofPDUSessionRes},
+			ext: false,
+		}
+		fn := func() *PDUSessionResourceToRemoveItem { return new(PDUSessionResourceToRemoveItem) }
+		if err = tmp.Decode(ieR, fn); err != nil {
+			err = utils.WrapError("Read PDUSessionResourceToRemoveList", err)
+			return
+		}
+		msg.PDUSessionResourceToRemoveList = []PDUSessionResourceToRemoveItem{}
+		for _, i := range tmp.Value {
+			msg.PDUSessionResourceToRemoveList = append(msg.PDUSessionResourceToRemoveList, *i)
+		}
+	default:
+		known = false
+	}
+	return
+}
@@ -0,0 +1,157 @@
+package ies
+
+import (
+	"fmt"
+
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type NGRANBearerContextModificationResponse struct {
+	PDUSessionResourceSetupModList       []PDUSessionResourceSetupModItem
+	PDUSessionResourceFailedModList      []PDUSessionResourceFailedItem
+	PDUSessionResourceModifiedList       []PDUSessionResourceModifiedItem
+	PDUSessionResourceFailedToModifyList []PDUSessionResourceFailedItem
+}
+
+func (msg *NGRANBearerContextModificationResponse) Encode(w *aper.AperWriter) (err error) {
+	var ies []E1apMessageIE
+	if ies, err = msg.toIes(); err != nil {
+		err = msgErrors(fmt.Errorf("NGRANBearerContextModificationResponse"), err)
+		return
+	}
+	return encodeContainer(w, ies)
+}
+func (msg *NGRANBearerContextModificationResponse) toIes() (ies []E1apMessageIE, err error) {
+	ies = []E1apMessageIE{}
+	if len(msg.PDUSessionResourceSetupModList) > 0 {
+		tmp_PDUSessionResourceSetupModList := Sequence[*PDUSessionResourceSetupModItem]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofPDUSessionRes},
+			ext: false,
+		}
+		for i := range msg.PDUSessionResourceSetupModList {
+			tmp_PDUSessionResourceSetupModList.Value = append(tmp_PDUSessionResourceSetupModList.Value, &msg.PDUSessionResourceSetupModList[i])
+		}
+		ies = append(ies, E1apMessageIE{
+			Id:          ProtocolIEID{Value: ProtocolIEID_PDUSessionResourceSetupModList},
+			Criticality: Criticality{Value: Criticality_PresentReject},
+			Value:       &tmp_PDUSessionResourceSetupModList,
+		})
+	}
+	if len(msg.PDUSessionResourceFailedModList) > 0 {
+		tmp_PDUSessionResourceFailedModList := Sequence[*PDUSessionResourceFailedItem]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofPDUSessionRes},
+			ext: false,
+		}
+		for i := range msg.PDUSessionResourceFailedModList {
+			tmp_PDUSessionResourceFailedModList.Value = append(tmp_PDUSessionResourceFailedModList.Value, &msg.PDUSessionResourceFailedModList[i])
+		}
+		ies = append(ies, E1apMessageIE{
+			Id:          ProtocolIEID{Value: ProtocolIEID_PDUSessionResourceFailedModList},
+			Criticality: Criticality{Value: Criticality_PresentReject},
+			Value:       &tmp_PDUSessionResourceFailedModList,
+		})
+	}
+	if len(msg.PDUSessionResourceModifiedList) > 0 {
+		tmp_PDUSessionResourceModifiedList := Sequence[*PDUSessionResourceModifiedItem]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofPDUSessionRes},
+			ext: false,
+		}
+		for i := range msg.PDUSessionResourceModifiedList {
+			tmp_PDUSessionResourceModifiedList.Value = append(tmp_PDUSessionResourceModifiedList.Value, &msg.PDUSessionResourceModifiedList[i])
+		}
+		ies = append(ies, E1apMessageIE{
+			Id:          ProtocolIEID{Value: ProtocolIEID_PDUSessionResourceModifiedList},
+			Criticality: Criticality{Value: Criticality_PresentReject},
+			Value:       &tmp_PDUSessionResourceModifiedList,
+		})
+	}
+	if len(msg.PDUSessionResourceFailedToModifyList) > 0 {
+		tmp_PDUSessionResourceFailedToModifyList := Sequence[*PDUSessionResourceFailedItem]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofPDUSessionRes},
+			ext: false,
+		}
+		for i := range msg.PDUSessionResourceFailedToModifyList {
+			tmp_PDUSessionResourceFailedToModifyList.Value = append(tmp_PDUSessionResourceFailedToModifyList.Value, &msg.PDUSessionResourceFailedToModifyList[i])
+		}
+		ies = append(ies, E1apMessageIE{
+			Id:          ProtocolIEID{Value: ProtocolIEID_PDUSessionResourceFailedToModifyList},
+			Criticality: Criticality{Value: Criticality_PresentReject},
+			Value:       &tmp_PDUSessionResourceFailedToModifyList,
+		})
+	}
+	return
+}
+func (msg *NGRANBearerContextModificationResponse) Decode(r *aper.AperReader) (err error) {
+	defer func() {
+		if err != nil {
+			err = msgErrors(fmt.Errorf("NGRANBearerContextModificationResponse"), err)
+		}
+	}()
+	_, err = decodeContainer(r, msg.decodeIE, []mandatoryIE{})
+	return
+}
+func (msg *NGRANBearerContextModificationResponse) decodeIE(id aper.Integer, ieR *aper.AperReader) (known bool, err error) {
+	known = true
+	switch id {
+	case ProtocolIEID_PDUSessionResourceSetupModList:
+		tmp := Sequence[*PDUSessionResourceSetupModItem]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofPDUSessionRes},
+			ext: false,
+		}
+		fn := func() *PDUSessionResourceSetupModItem { return new(PDUSessionResourceSetupModItem) }
+		if err = tmp.Decode(ieR, fn); err != nil {
+			err = utils.WrapError("Read PDUSessionResourceSetupModList", err)
+			return
+		}
+		msg.PDUSessionResourceSetupModList = []PDUSessionResourceSetupModItem{}
+		for _, i := range tmp.Value {
+			msg.PDUSessionResourceSetupModList = append(msg.PDUSessionResourceSetupModList, *i)
+		}
+	case ProtocolIEID_PDUSessionResourceFailedModList:
+		tmp := Sequence[*PDUSessionResourceFailedItem]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofPDUSessionRes},
+			ext: false,
+		}
+		fn := func() *PDUSessionResourceFailedItem { return new(PDUSessionResourceFailedItem) }
+		if err = tmp.Decode(ieR, fn); err != nil {
+			err = utils.WrapError("Read PDUSessionResourceFailedModList", err)
+			return
+		}
+		msg.PDUSessionResourceFailedModList = []PDUSessionResourceFailedItem{}
+		for _, i := range tmp.Value {
+			msg.PDUSessionResourceFailedModList = append(msg.PDUSessionResourceFailedModList, *i)
+		}
+	case ProtocolIEID_PDUSessionResourceModifiedList:
+		tmp := Sequence[*PDUSessionResourceModifiedItem]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofPDUSessionRes},
+			ext: false,
+		}
+		fn := func() *PDUSessionResourceModifiedItem { return new(PDUSessionResourceModifiedItem) }
+		if err = tmp.Decode(ieR, fn); err != nil {
+			err = utils.WrapError("Read PDUSessionResourceModifiedList", err)
+			return
+		}
+		msg.PDUSessionResourceModifiedList = []PDUSessionResourceModifiedItem{}
+		for _, i := range tmp.Value {
+			msg.PDUSessionResourceModifiedList = append(msg.PDUSessionResourceModifiedList, *i)
+		}
+	case ProtocolIEID_PDUSessionResourceFailedToModifyList:
+		tmp := Sequence[*PDUSessionResourceFailedItem]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofPDUSessionRes},
+			ext: false,
+		}
+		fn := func() *PDUSessionResourceFailedItem { return new(PDUSessionResourceFailedItem) }
+		if err = tmp.Decode(ieR, fn); err != nil {
+			err = utils.WrapError("Read PDUSessionResourceFailedToModifyList", err)
+			return
+		}
+		msg.PDUSessionResourceFailedToModifyList = []PDUSessionResourceFailedItem{}
+		for _, i := range tmp.Value {
+			msg.PDUSessionResourceFailedToModifyList = append(msg.PDUSessionResourceFailedToModifyList, *i)
+		}
+	default:
+		known = false
+	}
+	return
+}
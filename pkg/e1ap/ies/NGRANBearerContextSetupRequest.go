@@ -0,0 +1,74 @@
+package ies
+
+import (
+	"fmt"
+
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type NGRANBearerContextSetupRequest struct {
+	PDUSessionResourceToSetupList []PDUSessionResourceToSetupItem
+}
+
+func (msg *NGRANBearerContextSetupRequest) Encode(w *aper.AperWriter) (err error) {
+	var ies []E1apMessageIE
+	if ies, err = msg.toIes(); err != nil {
+		err = msgErrors(fmt.Errorf("NGRANBearerContextSetupRequest"), err)
+		return
+	}
+	return encodeContainer(w, ies)
+}
+func (msg *NGRANBearerContextSetupRequest) toIes() (ies []E1apMessageIE, err error) {
+	ies = []E1apMessageIE{}
+	if len(msg.PDUSessionResourceToSetupList) == 0 {
+		err = fmt.Errorf("PDUSessionResourceToSetupList is empty")
+		return
+	}
+	tmp_PDUSessionResourceToSetupList := Sequence[*PDUSessionResourceToSetupItem]{
+		c:   aper.Constraint{Lb: 1, Ub: maxnoofPDUSessionRes},
+		ext: false,
+	}
+	for i := range msg.PDUSessionResourceToSetupList {
+		tmp_PDUSessionResourceToSetupList.Value = append(tmp_PDUSessionResourceToSetupList.Value, &msg.PDUSessionResourceToSetupList[i])
+	}
+	ies = append(ies, E1apMessageIE{
+		Id:          ProtocolIEID{Value: ProtocolIEID_PDUSessionResourceToSetupList},
+		Criticality: Criticality{Value: Criticality_PresentReject},
+		Value:       &tmp_PDUSessionResourceToSetupList,
+	})
+	return
+}
+func (msg *NGRANBearerContextSetupRequest) Decode(r *aper.AperReader) (err error) {
+	defer func() {
+		if err != nil {
+			err = msgErrors(fmt.Errorf("NGRANBearerContextSetupRequest"), err)
+		}
+	}()
+	_, err = decodeContainer(r, msg.decodeIE, []mandatoryIE{
+		{ProtocolIEID_PDUSessionResourceToSetupList, "PDUSessionResourceToSetupList", Criticality_PresentReject},
+	})
+	return
+}
+func (msg *NGRANBearerContextSetupRequest) decodeIE(id aper.Integer, ieR *aper.AperReader) (known bool, err error) {
+	known = true
+	switch id {
+	case ProtocolIEID_PDUSessionResourceToSetupList:
+		tmp := Sequence[*PDUSessionResourceToSetupItem]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofPDUSessionRes},
+			ext: false,
+		}
+		fn := func() *PDUSessionResourceToSetupItem { return new(PDUSessionResourceToSetupItem) }
+		if err = tmp.Decode(ieR, fn); err != nil {
+			err = utils.WrapError("Read PDUSessionResourceToSetupList", err)
+			return
+		}
+		msg.PDUSessionResourceToSetupList = []PDUSessionResourceToSetupItem{}
+		for _, i := range tmp.Value {
+			msg.PDUSessionResourceToSetupList = append(msg.PDUSessionResourceToSetupList, *i)
+		}
+	default:
+		known = false
+	}
+	return
+}
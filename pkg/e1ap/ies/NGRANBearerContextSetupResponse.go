@@ -0,0 +1,103 @@
+package ies
+
+import (
+	"fmt"
+
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type NGRANBearerContextSetupResponse struct {
+	PDUSessionResourceSetupList  []PDUSessionResourceSetupItem
+	PDUSessionResourceFailedList []PDUSessionResourceFailedItem
+}
+
+func (msg *NGRANBearerContextSetupResponse) Encode(w *aper.AperWriter) (err error) {
+	var ies []E1apMessageIE
+	if ies, err = msg.toIes(); err != nil {
+		err = msgErrors(fmt.Errorf("NGRANBearerContextSetupResponse"), err)
+		return
+	}
+	return encodeContainer(w, ies)
+}
+func (msg *NGRANBearerContextSetupResponse) toIes() (ies []E1apMessageIE, err error) {
+	ies = []E1apMessageIE{}
+	if len(msg.PDUSessionResourceSetupList) == 0 {
+		err = fmt.Errorf("PDUSessionResourceSetupList is empty")
+		return
+	}
+	tmp_PDUSessionResourceSetupList := Sequence[*PDUSessionResourceSetupItem]{
+		c:   aper.Constraint{Lb: 1, Ub: maxnoofPDUSessionRes},
+		ext: false,
+	}
+	for i := range msg.PDUSessionResourceSetupList {
+		tmp_PDUSessionResourceSetupList.Value = append(tmp_PDUSessionResourceSetupList.Value, &msg.PDUSessionResourceSetupList[i])
+	}
+	ies = append(ies, E1apMessageIE{
+		Id:          ProtocolIEID{Value: ProtocolIEID_PDUSessionResourceSetupList},
+		Criticality: Criticality{Value: Criticality_PresentIgnore},
+		Value:       &tmp_PDUSessionResourceSetupList,
+	})
+	if len(msg.PDUSessionResourceFailedList) > 0 {
+		tmp_PDUSessionResourceFailedList := Sequence[*PDUSessionResourceFailedItem]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofPDUSessionRes},
+			ext: false,
+		}
+		for i := range msg.PDUSessionResourceFailedList {
+			tmp_PDUSessionResourceFailedList.Value = append(tmp_PDUSessionResourceFailedList.Value, &msg.PDUSessionResourceFailedList[i])
+		}
+		ies = append(ies, E1apMessageIE{
+			Id:          ProtocolIEID{Value: ProtocolIEID_PDUSessionResourceFailedList},
+			Criticality: Criticality{Value: Criticality_PresentIgnore},
+			Value:       &tmp_PDUSessionResourceFailedList,
+		})
+	}
+	return
+}
+func (msg *NGRANBearerContextSetupResponse) Decode(r *aper.AperReader) (err error) {
+	defer func() {
+		if err != nil {
+			err = msgErrors(fmt.Errorf("NGRANBearerContextSetupResponse"), err)
+		}
+	}()
+	_, err = decodeContainer(r, msg.decodeIE, []mandatoryIE{
+		{ProtocolIEID_PDUSessionResourceSetupList, "PDUSessionResourceSetupList", Criticality_PresentIgnore},
+	})
+	return
+}
+func (msg *NGRANBearerContextSetupResponse) decodeIE(id aper.Integer, ieR *aper.AperReader) (known bool, err error) {
+	known = true
+	switch id {
+	case ProtocolIEID_PDUSessionResourceSetupList:
+		tmp := Sequence[*PDUSessionResourceSetupItem]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofPDUSessionRes},
+			ext: false,
+		}
+		fn := func() *PDUSessionResourceSetupItem { return new(PDUSessionResourceSetupItem) }
+		if err = tmp.Decode(ieR, fn); err != nil {
+			err = utils.WrapError("Read PDUSessionResourceSetupList", err)
+			return
+		}
+		msg.PDUSessionResourceSetupList = []PDUSessionResourceSetupItem{}
+		for _, i := range tmp.Value {
+			msg.PDUSessionResourceSetupList = append(msg.PDUSessionResourceSetupList, *i)
+		}
+	case ProtocolIEID_PDUSessionResourceFailedList:
+		tmp := Sequence[*PDUSessionResourceFailedItem]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofPDUSessionRes},
+			ext: false,
+		}
+		fn := func() *PDUSessionResourceFailedItem { return new(PDUSessionResourceFailedItem) }
+		if err = tmp.Decode(ieR, fn); err != nil {
+			err = utils.WrapError("Read PDUSessionResourceFailedList", err)
+			return
+		}
+		msg.PDUSessionResourceFailedList = []PDUSessionResourceFailedItem{}
+		for _, i := range tmp.Value {
+			msg.PDUSessionResourceFailedList = append(msg.PDUSessionResourceFailedList, *i)
+		}
+	default:
+		known = false
+	}
+	return
+}
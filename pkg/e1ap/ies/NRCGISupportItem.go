@@ -0,0 +1,44 @@
+package ies
+
+import (
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type NRCGISupportItem struct {
+	NRCGI NRCGI
+	// IEExtensions *NRCGISupportItemExtIEs `optional`
+}
+
+func (ie *NRCGISupportItem) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	optionals := []byte{0x0}
+	w.WriteBits(optionals, 1)
+	if err = ie.NRCGI.Encode(w); err != nil {
+		err = utils.WrapError("Encode NRCGI", err)
+		return
+	}
+	return
+}
+func (ie *NRCGISupportItem) Decode(r *aper.AperReader) (err error) {
+	if _, err = r.ReadBool(); err != nil {
+		return
+	}
+	var optionals []byte
+	if optionals, err = r.ReadBits(1); err != nil {
+		return
+	}
+	if err = ie.NRCGI.Decode(r); err != nil {
+		err = utils.WrapError("Read NRCGI", err)
+		return
+	}
+	if aper.IsBitSet(optionals, 1) {
+		if err = skipExtensions(r); err != nil {
+			err = utils.WrapError("Read IEExtensions", err)
+			return
+		}
+	}
+	return
+}
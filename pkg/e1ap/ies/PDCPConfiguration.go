@@ -0,0 +1,135 @@
+package ies
+
+import (
+	"fmt"
+
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type PDCPConfiguration struct {
+	PDCPSNSizeUL PDCPSNSize
+	PDCPSNSizeDL PDCPSNSize
+	RLCMode      RLCMode
+	// ROHCParameters *ROHCParameters `optional`
+	TReorderingTimer *TReorderingTimer
+	// DiscardTimer *DiscardTimer `optional`
+	// ULDataSplitThreshold *ULDataSplitThreshold `optional`
+	// PDCPDuplication *PDCPDuplication `optional`
+	PDCPReestablishment *PDCPReestablishment
+	// PDCPDataRecovery *PDCPDataRecovery `optional`
+	// DuplicationActivation *DuplicationActivation `optional`
+	// OutOfOrderDelivery *OutOfOrderDelivery `optional`
+	// IEExtensions *PDCPConfigurationExtIEs `optional`
+}
+
+func (ie *PDCPConfiguration) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	optionals := []byte{0x0, 0x0}
+	if ie.TReorderingTimer != nil {
+		setOptional(optionals, 2)
+	}
+	if ie.PDCPReestablishment != nil {
+		setOptional(optionals, 6)
+	}
+	w.WriteBits(optionals, 10)
+	if err = ie.PDCPSNSizeUL.Encode(w); err != nil {
+		err = utils.WrapError("Encode PDCPSNSizeUL", err)
+		return
+	}
+	if err = ie.PDCPSNSizeDL.Encode(w); err != nil {
+		err = utils.WrapError("Encode PDCPSNSizeDL", err)
+		return
+	}
+	if err = ie.RLCMode.Encode(w); err != nil {
+		err = utils.WrapError("Encode RLCMode", err)
+		return
+	}
+	if ie.TReorderingTimer != nil {
+		if err = ie.TReorderingTimer.Encode(w); err != nil {
+			err = utils.WrapError("Encode TReorderingTimer", err)
+			return
+		}
+	}
+	if ie.PDCPReestablishment != nil {
+		if err = ie.PDCPReestablishment.Encode(w); err != nil {
+			err = utils.WrapError("Encode PDCPReestablishment", err)
+			return
+		}
+	}
+	return
+}
+func (ie *PDCPConfiguration) Decode(r *aper.AperReader) (err error) {
+	if _, err = r.ReadBool(); err != nil {
+		return
+	}
+	var optionals []byte
+	if optionals, err = r.ReadBits(10); err != nil {
+		return
+	}
+	if err = ie.PDCPSNSizeUL.Decode(r); err != nil {
+		err = utils.WrapError("Read PDCPSNSizeUL", err)
+		return
+	}
+	if err = ie.PDCPSNSizeDL.Decode(r); err != nil {
+		err = utils.WrapError("Read PDCPSNSizeDL", err)
+		return
+	}
+	if err = ie.RLCMode.Decode(r); err != nil {
+		err = utils.WrapError("Read RLCMode", err)
+		return
+	}
+	if isOptionalSet(optionals, 1) {
+		err = fmt.Errorf("Read ROHCParameters: not supported")
+		return
+	}
+	if isOptionalSet(optionals, 2) {
+		tmp := new(TReorderingTimer)
+		if err = tmp.Decode(r); err != nil {
+			err = utils.WrapError("Read TReorderingTimer", err)
+			return
+		}
+		ie.TReorderingTimer = tmp
+	}
+	if isOptionalSet(optionals, 3) {
+		err = fmt.Errorf("Read DiscardTimer: not supported")
+		return
+	}
+	if isOptionalSet(optionals, 4) {
+		err = fmt.Errorf("Read ULDataSplitThreshold: not supported")
+		return
+	}
+	if isOptionalSet(optionals, 5) {
+		err = fmt.Errorf("Read PDCPDuplication: not supported")
+		return
+	}
+	if isOptionalSet(optionals, 6) {
+		tmp := new(PDCPReestablishment)
+		if err = tmp.Decode(r); err != nil {
+			err = utils.WrapError("Read PDCPReestablishment", err)
+			return
+		}
+		ie.PDCPReestablishment = tmp
+	}
+	if isOptionalSet(optionals, 7) {
+		err = fmt.Errorf("Read PDCPDataRecovery: not supported")
+		return
+	}
+	if isOptionalSet(optionals, 8) {
+		err = fmt.Errorf("Read DuplicationActivation: not supported")
+		return
+	}
+	if isOptionalSet(optionals, 9) {
+		err = fmt.Errorf("Read OutOfOrderDelivery: not supported")
+		return
+	}
+	if isOptionalSet(optionals, 10) {
+		if err = skipExtensions(r); err != nil {
+			err = utils.WrapError("Read IEExtensions", err)
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,59 @@
+package ies
+
+import (
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type PDUSessionResourceFailedItem struct {
+	PDUSessionID int64
+	Cause        Cause
+	// IEExtensions *PDUSessionResourceFailedItemExtIEs `optional`
+}
+
+func (ie *PDUSessionResourceFailedItem) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	optionals := []byte{0x0}
+	w.WriteBits(optionals, 1)
+	tmp_PDUSessionID := NewINTEGER(ie.PDUSessionID, aper.Constraint{Lb: 0, Ub: 255}, false)
+	if err = tmp_PDUSessionID.Encode(w); err != nil {
+		err = utils.WrapError("Encode PDUSessionID", err)
+		return
+	}
+	if err = ie.Cause.Encode(w); err != nil {
+		err = utils.WrapError("Encode Cause", err)
+		return
+	}
+	return
+}
+func (ie *PDUSessionResourceFailedItem) Decode(r *aper.AperReader) (err error) {
+	if _, err = r.ReadBool(); err != nil {
+		return
+	}
+	var optionals []byte
+	if optionals, err = r.ReadBits(1); err != nil {
+		return
+	}
+	tmp_PDUSessionID := INTEGER{
+		c:   aper.Constraint{Lb: 0, Ub: 255},
+		ext: false,
+	}
+	if err = tmp_PDUSessionID.Decode(r); err != nil {
+		err = utils.WrapError("Read PDUSessionID", err)
+		return
+	}
+	ie.PDUSessionID = int64(tmp_PDUSessionID.Value)
+	if err = ie.Cause.Decode(r); err != nil {
+		err = utils.WrapError("Read Cause", err)
+		return
+	}
+	if aper.IsBitSet(optionals, 1) {
+		if err = skipExtensions(r); err != nil {
+			err = utils.WrapError("Read IEExtensions", err)
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,225 @@
+package ies
+
+import (
+	"fmt"
+
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type PDUSessionResourceModifiedItem struct {
+	PDUSessionID         int64
+	NGDLUPTNLInformation *UPTNLInformation
+	SecurityResult       *SecurityResult
+	// PDUSessionDataForwardingInformationResponse *PDUSessionDataForwardingInformationResponse `optional`
+	DRBSetupListNGRAN          []DRBSetupItemNGRAN
+	DRBFailedListNGRAN         []DRBFailedItemNGRAN
+	DRBModifiedListNGRAN       []DRBModifiedItemNGRAN
+	DRBFailedToModifyListNGRAN []DRBFailedItemNGRAN
+	// IEExtensions *PDUSessionResourceModifiedItemExtIEs `optional`
+}
+
+func (ie *PDUSessionResourceModifiedItem) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	optionals := []byte{0x0}
+	if ie.NGDLUPTNLInformation != nil {
+		setOptional(optionals, 1)
+	}
+	if ie.SecurityResult != nil {
+		setOptional(optionals, 2)
+	}
+	if len(ie.DRBSetupListNGRAN) > 0 {
+		setOptional(optionals, 4)
+	}
+	if len(ie.DRBFailedListNGRAN) > 0 {
+		setOptional(optionals, 5)
+	}
+	if len(ie.DRBModifiedListNGRAN) > 0 {
+		setOptional(optionals, 6)
+	}
+	if len(ie.DRBFailedToModifyListNGRAN) > 0 {
+		setOptional(optionals, 7)
+	}
+	w.WriteBits(optionals, 8)
+	tmp_PDUSessionID := NewINTEGER(ie.PDUSessionID, aper.Constraint{Lb: 0, Ub: 255}, false)
+	if err = tmp_PDUSessionID.Encode(w); err != nil {
+		err = utils.WrapError("Encode PDUSessionID", err)
+		return
+	}
+	if ie.NGDLUPTNLInformation != nil {
+		if err = ie.NGDLUPTNLInformation.Encode(w); err != nil {
+			err = utils.WrapError("Encode NGDLUPTNLInformation", err)
+			return
+		}
+	}
+	if ie.SecurityResult != nil {
+		if err = ie.SecurityResult.Encode(w); err != nil {
+			err = utils.WrapError("Encode SecurityResult", err)
+			return
+		}
+	}
+	if len(ie.DRBSetupListNGRAN) > 0 {
+		tmp_DRBSetupListNGRAN := Sequence[*DRBSetupItemNGRAN]{
+			Value: []*DRBSetupItemNGRAN{},
+			c:     aper.Constraint{Lb: 1, Ub: maxnoofDRBs},
+			ext:   false,
+		}
+		for i := range ie.DRBSetupListNGRAN {
+			tmp_DRBSetupListNGRAN.Value = append(tmp_DRBSetupListNGRAN.Value, &ie.DRBSetupListNGRAN[i])
+		}
+		if err = tmp_DRBSetupListNGRAN.Encode(w); err != nil {
+			err = utils.WrapError("Encode DRBSetupListNGRAN", err)
+			return
+		}
+	}
+	if len(ie.DRBFailedListNGRAN) > 0 {
+		tmp_DRBFailedListNGRAN := Sequence[*DRBFailedItemNGRAN]{
+			Value: []*DRBFailedItemNGRAN{},
+			c:     aper.Constraint{Lb: 1, Ub: maxnoofDRBs},
+			ext:   false,
+		}
+		for i := range ie.DRBFailedListNGRAN {
+			tmp_DRBFailedListNGRAN.Value = append(tmp_DRBFailedListNGRAN.Value, &ie.DRBFailedListNGRAN[i])
+		}
+		if err = tmp_DRBFailedListNGRAN.Encode(w); err != nil {
+			err = utils.WrapError("Encode DRBFailedListNGRAN", err)
+			return
+		}
+	}
+	if len(ie.DRBModifiedListNGRAN) > 0 {
+		tmp_DRBModifiedListNGRAN := Sequence[*DRBModifiedItemNGRAN]{
+			Value: []*DRBModifiedItemNGRAN{},
+			c:     aper.Constraint{Lb: 1, Ub: maxnoofDRBs},
+			ext:   false,
+		}
+		for i := range ie.DRBModifiedListNGRAN {
+			tmp_DRBModifiedListNGRAN.Value = append(tmp_DRBModifiedListNGRAN.Value, &ie.DRBModifiedListNGRAN[i])
+		}
+		if err = tmp_DRBModifiedListNGRAN.Encode(w); err != nil {
+			err = utils.WrapError("Encode DRBModifiedListNGRAN", err)
+			return
+		}
+	}
+	if len(ie.DRBFailedToModifyListNGRAN) > 0 {
+		tmp_DRBFailedToModifyListNGRAN := Sequence[*DRBFailedItemNGRAN]{
+			Value: []*DRBFailedItemNGRAN{},
+			c:     aper.Constraint{Lb: 1, Ub: maxnoofDRBs},
+			ext:   false,
+		}
+		for i := range ie.DRBFailedToModifyListNGRAN {
+			tmp_DRBFailedToModifyListNGRAN.Value = append(tmp_DRBFailedToModifyListNGRAN.Value, &ie.DRBFailedToModifyListNGRAN[i])
+		}
+		if err = tmp_DRBFailedToModifyListNGRAN.Encode(w); err != nil {
+			err = utils.WrapError("Encode DRBFailedToModifyListNGRAN", err)
+			return
+		}
+	}
+	return
+}
+func (ie *PDUSessionResourceModifiedItem) Decode(r *aper.AperReader) (err error) {
+	if _, err = r.ReadBool(); err != nil {
+		return
+	}
+	var optionals []byte
+	if optionals, err = r.ReadBits(8); err != nil {
+		return
+	}
+	tmp_PDUSessionID := INTEGER{
+		c:   aper.Constraint{Lb: 0, Ub: 255},
+		ext: false,
+	}
+	if err = tmp_PDUSessionID.Decode(r); err != nil {
+		err = utils.WrapError("Read PDUSessionID", err)
+		return
+	}
+	ie.PDUSessionID = int64(tmp_PDUSessionID.Value)
+	if isOptionalSet(optionals, 1) {
+		tmp := new(UPTNLInformation)
+		if err = tmp.Decode(r); err != nil {
+			err = utils.WrapError("Read NGDLUPTNLInformation", err)
+			return
+		}
+		ie.NGDLUPTNLInformation = tmp
+	}
+	if isOptionalSet(optionals, 2) {
+		tmp := new(SecurityResult)
+		if err = tmp.Decode(r); err != nil {
+			err = utils.WrapError("Read SecurityResult", err)
+			return
+		}
+		ie.SecurityResult = tmp
+	}
+	if isOptionalSet(optionals, 3) {
+		err = fmt.Errorf("Read PDUSessionDataForwardingInformationResponse: not supported")
+		return
+	}
+	if isOptionalSet(optionals, 4) {
+		tmp_DRBSetupListNGRAN := Sequence[*DRBSetupItemNGRAN]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofDRBs},
+			ext: false,
+		}
+		fn_DRBSetupListNGRAN := func() *DRBSetupItemNGRAN { return new(DRBSetupItemNGRAN) }
+		if err = tmp_DRBSetupListNGRAN.Decode(r, fn_DRBSetupListNGRAN); err != nil {
+			err = utils.WrapError("Read DRBSetupListNGRAN", err)
+			return
+		}
+		ie.DRBSetupListNGRAN = []DRBSetupItemNGRAN{}
+		for _, i := range tmp_DRBSetupListNGRAN.Value {
+			ie.DRBSetupListNGRAN = append(ie.DRBSetupListNGRAN, *i)
+		}
+	}
+	if isOptionalSet(optionals, 5) {
+		tmp_DRBFailedListNGRAN := Sequence[*DRBFailedItemNGRAN]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofDRBs},
+			ext: false,
+		}
+		fn_DRBFailedListNGRAN := func() *DRBFailedItemNGRAN { return new(DRBFailedItemNGRAN) }
+		if err = tmp_DRBFailedListNGRAN.Decode(r, fn_DRBFailedListNGRAN); err != nil {
+			err = utils.WrapError("Read DRBFailedListNGRAN", err)
+			return
+		}
+		ie.DRBFailedListNGRAN = []DRBFailedItemNGRAN{}
+		for _, i := range tmp_DRBFailedListNGRAN.Value {
+			ie.DRBFailedListNGRAN = append(ie.DRBFailedListNGRAN, *i)
+		}
+	}
+	if isOptionalSet(optionals, 6) {
+		tmp_DRBModifiedListNGRAN := Sequence[*DRBModifiedItemNGRAN]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofDRBs},
+			ext: false,
+		}
+		fn_DRBModifiedListNGRAN := func() *DRBModifiedItemNGRAN { return new(DRBModifiedItemNGRAN) }
+		if err = tmp_DRBModifiedListNGRAN.Decode(r, fn_DRBModifiedListNGRAN); err != nil {
+			err = utils.WrapError("Read DRBModifiedListNGRAN", err)
+			return
+		}
+		ie.DRBModifiedListNGRAN = []DRBModifiedItemNGRAN{}
+		for _, i := range tmp_DRBModifiedListNGRAN.Value {
+			ie.DRBModifiedListNGRAN = append(ie.DRBModifiedListNGRAN, *i)
+		}
+	}
+	if isOptionalSet(optionals, 7) {
+		tmp_DRBFailedToModifyListNGRAN := Sequence[*DRBFailedItemNGRAN]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofDRBs},
+			ext: false,
+		}
+		fn_DRBFailedToModifyListNGRAN := func() *DRBFailedItemNGRAN { return new(DRBFailedItemNGRAN) }
+		if err = tmp_DRBFailedToModifyListNGRAN.Decode(r, fn_DRBFailedToModifyListNGRAN); err != nil {
+			err = utils.WrapError("Read DRBFailedToModifyListNGRAN", err)
+			return
+		}
+		ie.DRBFailedToModifyListNGRAN = []DRBFailedItemNGRAN{}
+		for _, i := range tmp_DRBFailedToModifyListNGRAN.Value {
+			ie.DRBFailedToModifyListNGRAN = append(ie.DRBFailedToModifyListNGRAN, *i)
+		}
+	}
+	if isOptionalSet(optionals, 8) {
+		if err = skipExtensions(r); err != nil {
+			err = utils.WrapError("Read IEExtensions", err)
+			return
+		}
+	}
+	return
+}
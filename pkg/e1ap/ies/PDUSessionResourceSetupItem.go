@@ -0,0 +1,148 @@
+package ies
+
+import (
+	"fmt"
+
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type PDUSessionResourceSetupItem struct {
+	PDUSessionID         int64
+	SecurityResult       *SecurityResult
+	NGDLUPTNLInformation UPTNLInformation
+	// PDUSessionDataForwardingInformationResponse *PDUSessionDataForwardingInformationResponse `optional`
+	// NGDLUPUnchanged *NGDLUPUnchanged `optional`
+	DRBSetupListNGRAN  []DRBSetupItemNGRAN
+	DRBFailedListNGRAN []DRBFailedItemNGRAN
+	// IEExtensions *PDUSessionResourceSetupItemExtIEs `optional`
+}
+
+func (ie *PDUSessionResourceSetupItem) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	optionals := []byte{0x0}
+	if ie.SecurityResult != nil {
+		aper.SetBit(optionals, 1)
+	}
+	if len(ie.DRBFailedListNGRAN) > 0 {
+		aper.SetBit(optionals, 4)
+	}
+	w.WriteBits(optionals, 5)
+	tmp_PDUSessionID := NewINTEGER(ie.PDUSessionID, aper.Constraint{Lb: 0, Ub: 255}, false)
+	if err = tmp_PDUSessionID.Encode(w); err != nil {
+		err = utils.WrapError("Encode PDUSessionID", err)
+		return
+	}
+	if ie.SecurityResult != nil {
+		if err = ie.SecurityResult.Encode(w); err != nil {
+			err = utils.WrapError("Encode SecurityResult", err)
+			return
+		}
+	}
+	if err = ie.NGDLUPTNLInformation.Encode(w); err != nil {
+		err = utils.WrapError("Encode NGDLUPTNLInformation", err)
+		return
+	}
+	tmp_DRBSetupListNGRAN := Sequence[*DRBSetupItemNGRAN]{
+		Value: []*DRBSetupItemNGRAN{},
+		c:     aper.Constraint{Lb: 1, Ub: maxnoofDRBs},
+		ext:   false,
+	}
+	for i := range ie.DRBSetupListNGRAN {
+		tmp_DRBSetupListNGRAN.Value = append(tmp_DRBSetupListNGRAN.Value, &ie.DRBSetupListNGRAN[i])
+	}
+	if err = tmp_DRBSetupListNGRAN.Encode(w); err != nil {
+		err = utils.WrapError("Encode DRBSetupListNGRAN", err)
+		return
+	}
+	if len(ie.DRBFailedListNGRAN) > 0 {
+		tmp_DRBFailedListNGRAN := Sequence[*DRBFailedItemNGRAN]{
+			Value: []*DRBFailedItemNGRAN{},
+			c:     aper.Constraint{Lb: 1, Ub: maxnoofDRBs},
+			ext:   false,
+		}
+		for i := range ie.DRBFailedListNGRAN {
+			tmp_DRBFailedListNGRAN.Value = append(tmp_DRBFailedListNGRAN.Value, &ie.DRBFailedListNGRAN[i])
+		}
+		if err = tmp_DRBFailedListNGRAN.Encode(w); err != nil {
+			err = utils.WrapError("Encode DRBFailedListNGRAN", err)
+			return
+		}
+	}
+	return
+}
+func (ie *PDUSessionResourceSetupItem) Decode(r *aper.AperReader) (err error) {
+	if _, err = r.ReadBool(); err != nil {
+		return
+	}
+	var optionals []byte
+	if optionals, err = r.ReadBits(5); err != nil {
+		return
+	}
+	tmp_PDUSessionID := INTEGER{
+		c:   aper.Constraint{Lb: 0, Ub: 255},
+		ext: false,
+	}
+	if err = tmp_PDUSessionID.Decode(r); err != nil {
+		err = utils.WrapError("Read PDUSessionID", err)
+		return
+	}
+	ie.PDUSessionID = int64(tmp_PDUSessionID.Value)
+	if aper.IsBitSet(optionals, 1) {
+		tmp := new(SecurityResult)
+		if err = tmp.Decode(r); err != nil {
+			err = utils.WrapError("Read SecurityResult", err)
+			return
+		}
+		ie.SecurityResult = tmp
+	}
+	if err = ie.NGDLUPTNLInformation.Decode(r); err != nil {
+		err = utils.WrapError("Read NGDLUPTNLInformation", err)
+		return
+	}
+	if aper.IsBitSet(optionals, 2) {
+		err = fmt.Errorf("Read PDUSessionDataForwardingInformationResponse: not supported")
+		return
+	}
+	if aper.IsBitSet(optionals, 3) {
+		err = fmt.Errorf("Read NGDLUPUnchanged: not supported")
+		return
+	}
+	tmp_DRBSetupListNGRAN := Sequence[*DRBSetupItemNGRAN]{
+		c:   aper.Constraint{Lb: 1, Ub: maxnoofDRBs},
+		ext: false,
+	}
+	fn_DRBSetupListNGRAN := func() *DRBSetupItemNGRAN { return new(DRBSetupItemNGRAN) }
+	if err = tmp_DRBSetupListNGRAN.Decode(r, fn_DRBSetupListNGRAN); err != nil {
+		err = utils.WrapError("Read DRBSetupListNGRAN", err)
+		return
+	}
+	ie.DRBSetupListNGRAN = []DRBSetupItemNGRAN{}
+	for _, i := range tmp_DRBSetupListNGRAN.Value {
+		ie.DRBSetupListNGRAN = append(ie.DRBSetupListNGRAN, *i)
+	}
+	if aper.IsBitSet(optionals, 4) {
+		tmp_DRBFailedListNGRAN := Sequence[*DRBFailedItemNGRAN]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofDRBs},
+			ext: false,
+		}
+		fn_DRBFailedListNGRAN := func() *DRBFailedItemNGRAN { return new(DRBFailedItemNGRAN) }
+		if err = tmp_DRBFailedListNGRAN.Decode(r, fn_DRBFailedListNGRAN); err != nil {
+			err = utils.WrapError("Read DRBFailedListNGRAN", err)
+			return
+		}
+		ie.DRBFailedListNGRAN = []DRBFailedItemNGRAN{}
+		for _, i := range tmp_DRBFailedListNGRAN.Value {
+			ie.DRBFailedListNGRAN = append(ie.DRBFailedListNGRAN, *i)
+		}
+	}
+	if aper.IsBitSet(optionals, 5) {
+		if err = skipExtensions(r); err != nil {
+			err = utils.WrapError("Read IEExtensions", err)
+			return
+		}
+	}
+	return
+}
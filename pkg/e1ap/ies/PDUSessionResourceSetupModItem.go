@@ -0,0 +1,143 @@
+package ies
+
+import (
+	"fmt"
+
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type PDUSessionResourceSetupModItem struct {
+	PDUSessionID         int64
+	SecurityResult       *SecurityResult
+	NGDLUPTNLInformation UPTNLInformation
+	// PDUSessionDataForwardingInformationResponse *PDUSessionDataForwardingInformationResponse `optional`
+	DRBSetupModListNGRAN  []DRBSetupItemNGRAN
+	DRBFailedModListNGRAN []DRBFailedItemNGRAN
+	// IEExtensions *PDUSessionResourceSetupModItemExtIEs `optional`
+}
+
+func (ie *PDUSessionResourceSetupModItem) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	optionals := []byte{0x0}
+	if ie.SecurityResult != nil {
+		aper.SetBit(optionals, 1)
+	}
+	if len(ie.DRBFailedModListNGRAN) > 0 {
+		aper.SetBit(optionals, 3)
+	}
+	w.WriteBits(optionals, 4)
+	tmp_PDUSessionID := NewINTEGER(ie.PDUSessionID, aper.Constraint{Lb: 0, Ub: 255}, false)
+	if err = tmp_PDUSessionID.Encode(w); err != nil {
+		err = utils.WrapError("Encode PDUSessionID", err)
+		return
+	}
+	if ie.SecurityResult != nil {
+		if err = ie.SecurityResult.Encode(w); err != nil {
+			err = utils.WrapError("Encode SecurityResult", err)
+			return
+		}
+	}
+	if err = ie.NGDLUPTNLInformation.Encode(w); err != nil {
+		err = utils.WrapError("Encode NGDLUPTNLInformation", err)
+		return
+	}
+	tmp_DRBSetupModListNGRAN := Sequence[*DRBSetupItemNGRAN]{
+		Value: []*DRBSetupItemNGRAN{},
+		c:     aper.Constraint{Lb: 1, Ub: maxnoofDRBs},
+		ext:   false,
+	}
+	for i := range ie.DRBSetupModListNGRAN {
+		tmp_DRBSetupModListNGRAN.Value = append(tmp_DRBSetupModListNGRAN.Value, &ie.DRBSetupModListNGRAN[i])
+	}
+	if err = tmp_DRBSetupModListNGRAN.Encode(w); err != nil {
+		err = utils.WrapError("Encode DRBSetupModListNGRAN", err)
+		return
+	}
+	if len(ie.DRBFailedModListNGRAN) > 0 {
+		tmp_DRBFailedModListNGRAN := Sequence[*DRBFailedItemNGRAN]{
+			Value: []*DRBFailedItemNGRAN{},
+			c:     aper.Constraint{Lb: 1, Ub: maxnoofDRBs},
+			ext:   false,
+		}
+		for i := range ie.DRBFailedModListNGRAN {
+			tmp_DRBFailedModListNGRAN.Value = append(tmp_DRBFailedModListNGRAN.Value, &ie.DRBFailedModListNGRAN[i])
+		}
+		if err = tmp_DRBFailedModListNGRAN.Encode(w); err != nil {
+			err = utils.WrapError("Encode DRBFailedModListNGRAN", err)
+			return
+		}
+	}
+	return
+}
+func (ie *PDUSessionResourceSetupModItem) Decode(r *aper.AperReader) (err error) {
+	if _, err = r.ReadBool(); err != nil {
+		return
+	}
+	var optionals []byte
+	if optionals, err = r.ReadBits(4); err != nil {
+		return
+	}
+	tmp_PDUSessionID := INTEGER{
+		c:   aper.Constraint{Lb: 0, Ub: 255},
+		ext: false,
+	}
+	if err = tmp_PDUSessionID.Decode(r); err != nil {
+		err = utils.WrapError("Read PDUSessionID", err)
+		return
+	}
+	ie.PDUSessionID = int64(tmp_PDUSessionID.Value)
+	if aper.IsBitSet(optionals, 1) {
+		tmp := new(SecurityResult)
+		if err = tmp.Decode(r); err != nil {
+			err = utils.WrapError("Read SecurityResult", err)
+			return
+		}
+		ie.SecurityResult = tmp
+	}
+	if err = ie.NGDLUPTNLInformation.Decode(r); err != nil {
+		err = utils.WrapError("Read NGDLUPTNLInformation", err)
+		return
+	}
+	if aper.IsBitSet(optionals, 2) {
+		err = fmt.Errorf("Read PDUSessionDataForwardingInformationResponse: not supported")
+		return
+	}
+	tmp_DRBSetupModListNGRAN := Sequence[*DRBSetupItemNGRAN]{
+		c:   aper.Constraint{Lb: 1, Ub: maxnoofDRBs},
+		ext: false,
+	}
+	fn_DRBSetupModListNGRAN := func() *DRBSetupItemNGRAN { return new(DRBSetupItemNGRAN) }
+	if err = tmp_DRBSetupModListNGRAN.Decode(r, fn_DRBSetupModListNGRAN); err != nil {
+		err = utils.WrapError("Read DRBSetupModListNGRAN", err)
+		return
+	}
+	ie.DRBSetupModListNGRAN = []DRBSetupItemNGRAN{}
+	for _, i := range tmp_DRBSetupModListNGRAN.Value {
+		ie.DRBSetupModListNGRAN = append(ie.DRBSetupModListNGRAN, *i)
+	}
+	if aper.IsBitSet(optionals, 3) {
+		tmp_DRBFailedModListNGRAN := Sequence[*DRBFailedItemNGRAN]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofDRBs},
+			ext: false,
+		}
+		fn_DRBFailedModListNGRAN := func() *DRBFailedItemNGRAN { return new(DRBFailedItemNGRAN) }
+		if err = tmp_DRBFailedModListNGRAN.Decode(r, fn_DRBFailedModListNGRAN); err != nil {
+			err = utils.WrapError("Read DRBFailedModListNGRAN", err)
+			return
+		}
+		ie.DRBFailedModListNGRAN = []DRBFailedItemNGRAN{}
+		for _, i := range tmp_DRBFailedModListNGRAN.Value {
+			ie.DRBFailedModListNGRAN = append(ie.DRBFailedModListNGRAN, *i)
+		}
+	}
+	if aper.IsBitSet(optionals, 4) {
+		if err = skipExtensions(r); err != nil {
+			err = utils.WrapError("Read IEExtensions", err)
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,263 @@
+package ies
+
+import (
+	"fmt"
+
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type PDUSessionResourceToModifyItem struct {
+	PDUSessionID             int64
+	SecurityIndication       *SecurityIndication
+	PDUSessionResourceDLAMBR *int64
+	NGULUPTNLInformation     *UPTNLInformation
+	// PDUSessionDataForwardingInformationRequest *PDUSessionDataForwardingInformationRequest `optional`
+	// PDUSessionDataForwardingInformation *PDUSessionDataForwardingInformation `optional`
+	PDUSessionInactivityTimer *int64
+	NetworkInstance           *int64
+	DRBToSetupListNGRAN       []DRBToSetupItemNGRAN
+	DRBToModifyListNGRAN      []DRBToModifyItemNGRAN
+	DRBToRemoveListNGRAN      []DRBToRemoveItemNGRAN
+	// IEExtensions *PDUSessionResourceToModifyItemExtIEs `optional`
+}
+
+func (ie *PDUSessionResourceToModifyItem) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	optionals := []byte{0x0, 0x0}
+	if ie.SecurityIndication != nil {
+		setOptional(optionals, 1)
+	}
+	if ie.PDUSessionResourceDLAMBR != nil {
+		setOptional(optionals, 2)
+	}
+	if ie.NGULUPTNLInformation != nil {
+		setOptional(optionals, 3)
+	}
+	if ie.PDUSessionInactivityTimer != nil {
+		setOptional(optionals, 6)
+	}
+	if ie.NetworkInstance != nil {
+		setOptional(optionals, 7)
+	}
+	if len(ie.DRBToSetupListNGRAN) > 0 {
+		setOptional(optionals, 8)
+	}
+	if len(ie.DRBToModifyListNGRAN) > 0 {
+		setOptional(optionals, 9)
+	}
+	if len(ie.DRBToRemoveListNGRAN) > 0 {
+		setOptional(optionals, 10)
+	}
+	w.WriteBits(optionals, 11)
+	tmp_PDUSessionID := NewINTEGER(ie.PDUSessionID, aper.Constraint{Lb: 0, Ub: 255}, false)
+	if err = tmp_PDUSessionID.Encode(w); err != nil {
+		err = utils.WrapError("Encode PDUSessionID", err)
+		return
+	}
+	if ie.SecurityIndication != nil {
+		if err = ie.SecurityIndication.Encode(w); err != nil {
+			err = utils.WrapError("Encode SecurityIndication", err)
+			return
+		}
+	}
+	if ie.PDUSessionResourceDLAMBR != nil {
+		tmp_PDUSessionResourceDLAMBR := NewINTEGER(*ie.PDUSessionResourceDLAMBR, aper.Constraint{Lb: 0, Ub: 4000000000000}, true)
+		if err = tmp_PDUSessionResourceDLAMBR.Encode(w); err != nil {
+			err = utils.WrapError("Encode PDUSessionResourceDLAMBR", err)
+			return
+		}
+	}
+	if ie.NGULUPTNLInformation != nil {
+		if err = ie.NGULUPTNLInformation.Encode(w); err != nil {
+			err = utils.WrapError("Encode NGULUPTNLInformation", err)
+			return
+		}
+	}
+	if ie.PDUSessionInactivityTimer != nil {
+		tmp_PDUSessionInactivityTimer := NewINTEGER(*ie.PDUSessionInactivityTimer, aper.Constraint{Lb: 1, Ub: 7200}, true)
+		if err = tmp_PDUSessionInactivityTimer.Encode(w); err != nil {
+			err = utils.WrapError("Encode PDUSessionInactivityTimer", err)
+			return
+		}
+	}
+	if ie.NetworkInstance != nil {
+		tmp_NetworkInstance := NewINTEGER(*ie.NetworkInstance, aper.Constraint{Lb: 1, Ub: 256}, true)
+		if err = tmp_NetworkInstance.Encode(w); err != nil {
+			err = utils.WrapError("Encode NetworkInstance", err)
+			return
+		}
+	}
+	if len(ie.DRBToSetupListNGRAN) > 0 {
+		tmp_DRBToSetupListNGRAN := Sequence[*DRBToSetupItemNGRAN]{
+			Value: []*DRBToSetupItemNGRAN{},
+			c:     aper.Constraint{Lb: 1, Ub: maxnoofDRBs},
+			ext:   false,
+		}
+		for i := range ie.DRBToSetupListNGRAN {
+			tmp_DRBToSetupListNGRAN.Value = append(tmp_DRBToSetupListNGRAN.Value, &ie.DRBToSetupListNGRAN[i])
+		}
+		if err = tmp_DRBToSetupListNGRAN.Encode(w); err != nil {
+			err = utils.WrapError("Encode DRBToSetupListNGRAN", err)
+			return
+		}
+	}
+	if len(ie.DRBToModifyListNGRAN) > 0 {
+		tmp_DRBToModifyListNGRAN := Sequence[*DRBToModifyItemNGRAN]{
+			Value: []*DRBToModifyItemNGRAN{},
+			c:     aper.Constraint{Lb: 1, Ub: maxnoofDRBs},
+			ext:   false,
+		}
+		for i := range ie.DRBToModifyListNGRAN {
+			tmp_DRBToModifyListNGRAN.Value = append(tmp_DRBToModifyListNGRAN.Value, &ie.DRBToModifyListNGRAN[i])
+		}
+		if err = tmp_DRBToModifyListNGRAN.Encode(w); err != nil {
+			err = utils.WrapError("Encode DRBToModifyListNGRAN", err)
+			return
+		}
+	}
+	if len(ie.DRBToRemoveListNGRAN) > 0 {
+		tmp_DRBToRemoveListNGRAN := Sequence[*DRBToRemoveItemNGRAN]{
+			Value: []*DRBToRemoveItemNGRAN{},
+			c:     aper.Constraint{Lb: 1, Ub: maxnoofDRBs},
+			ext:   false,
+		}
+		for i := range ie.DRBToRemoveListNGRAN {
+			tmp_DRBToRemoveListNGRAN.Value = append(tmp_DRBToRemoveListNGRAN.Value, &ie.DRBToRemoveListNGRAN[i])
+		}
+		if err = tmp_DRBToRemoveListNGRAN.Encode(w); err != nil {
+			err = utils.WrapError("Encode DRBToRemoveListNGRAN", err)
+			return
+		}
+	}
+	return
+}
+func (ie *PDUSessionResourceToModifyItem) Decode(r *aper.AperReader) (err error) {
+	if _, err = r.ReadBool(); err != nil {
+		return
+	}
+	var optionals []byte
+	if optionals, err = r.ReadBits(11); err != nil {
+		return
+	}
+	tmp_PDUSessionID := INTEGER{
+		c:   aper.Constraint{Lb: 0, Ub: 255},
+		ext: false,
+	}
+	if err = tmp_PDUSessionID.Decode(r); err != nil {
+		err = utils.WrapError("Read PDUSessionID", err)
+		return
+	}
+	ie.PDUSessionID = int64(tmp_PDUSessionID.Value)
+	if isOptionalSet(optionals, 1) {
+		tmp := new(SecurityIndication)
+		if err = tmp.Decode(r); err != nil {
+			err = utils.WrapError("Read SecurityIndication", err)
+			return
+		}
+		ie.SecurityIndication = tmp
+	}
+	if isOptionalSet(optionals, 2) {
+		tmp_PDUSessionResourceDLAMBR := INTEGER{
+			c:   aper.Constraint{Lb: 0, Ub: 4000000000000},
+			ext: true,
+		}
+		if err = tmp_PDUSessionResourceDLAMBR.Decode(r); err != nil {
+			err = utils.WrapError("Read PDUSessionResourceDLAMBR", err)
+			return
+		}
+		ie.PDUSessionResourceDLAMBR = (*int64)(&tmp_PDUSessionResourceDLAMBR.Value)
+	}
+	if isOptionalSet(optionals, 3) {
+		tmp := new(UPTNLInformation)
+		if err = tmp.Decode(r); err != nil {
+			err = utils.WrapError("Read NGULUPTNLInformation", err)
+			return
+		}
+		ie.NGULUPTNLInformation = tmp
+	}
+	if isOptionalSet(optionals, 4) {
+		err = fmt.Errorf("Read PDUSessionDataForwardingInformationRequest: not supported")
+		return
+	}
+	if isOptionalSet(optionals, 5) {
+		err = fmt.Errorf("Read PDUSessionDataForwardingInformation: not supported")
+		return
+	}
+	if isOptionalSet(optionals, 6) {
+		tmp_PDUSessionInactivityTimer := INTEGER{
+			c:   aper.Constraint{Lb: 1, Ub: 7200},
+			ext: true,
+		}
+		if err = tmp_PDUSessionInactivityTimer.Decode(r); err != nil {
+			err = utils.WrapError("Read PDUSessionInactivityTimer", err)
+			return
+		}
+		ie.PDUSessionInactivityTimer = (*int64)(&tmp_PDUSessionInactivityTimer.Value)
+	}
+	if isOptionalSet(optionals, 7) {
+		tmp_NetworkInstance := INTEGER{
+			c:   aper.Constraint{Lb: 1, Ub: 256},
+			ext: true,
+		}
+		if err = tmp_NetworkInstance.Decode(r); err != nil {
+			err = utils.WrapError("Read NetworkInstance", err)
+			return
+		}
+		ie.NetworkInstance = (*int64)(&tmp_NetworkInstance.Value)
+	}
+	if isOptionalSet(optionals, 8) {
+		tmp_DRBToSetupListNGRAN := Sequence[*DRBToSetupItemNGRAN]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofDRBs},
+			ext: false,
+		}
+		fn_DRBToSetupListNGRAN := func() *DRBToSetupItemNGRAN { return new(DRBToSetupItemNGRAN) }
+		if err = tmp_DRBToSetupListNGRAN.Decode(r, fn_DRBToSetupListNGRAN); err != nil {
+			err = utils.WrapError("Read DRBToSetupListNGRAN", err)
+			return
+		}
+		ie.DRBToSetupListNGRAN = []DRBToSetupItemNGRAN{}
+		for _, i := range tmp_DRBToSetupListNGRAN.Value {
+			ie.DRBToSetupListNGRAN = append(ie.DRBToSetupListNGRAN, *i)
+		}
+	}
+	if isOptionalSet(optionals, 9) {
+		tmp_DRBToModifyListNGRAN := Sequence[*DRBToModifyItemNGRAN]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofDRBs},
+			ext: false,
+		}
+		fn_DRBToModifyListNGRAN := func() *DRBToModifyItemNGRAN { return new(DRBToModifyItemNGRAN) }
+		if err = tmp_DRBToModifyListNGRAN.Decode(r, fn_DRBToModifyListNGRAN); err != nil {
+			err = utils.WrapError("Read DRBToModifyListNGRAN", err)
+			return
+		}
+		ie.DRBToModifyListNGRAN = []DRBToModifyItemNGRAN{}
+		for _, i := range tmp_DRBToModifyListNGRAN.Value {
+			ie.DRBToModifyListNGRAN = append(ie.DRBToModifyListNGRAN, *i)
+		}
+	}
+	if isOptionalSet(optionals, 10) {
+		tmp_DRBToRemoveListNGRAN := Sequence[*DRBToRemoveItemNGRAN]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofDRBs},
+			ext: false,
+		}
+		fn_DRBToRemoveListNGRAN := func() *DRBToRemoveItemNGRAN { return new(DRBToRemoveItemNGRAN) }
+		if err = tmp_DRBToRemoveListNGRAN.Decode(r, fn_DRBToRemoveListNGRAN); err != nil {
+			err = utils.WrapError("Read DRBToRemoveListNGRAN", err)
+			return
+		}
+		ie.DRBToRemoveListNGRAN = []DRBToRemoveItemNGRAN{}
+		for _, i := range tmp_DRBToRemoveListNGRAN.Value {
+			ie.DRBToRemoveListNGRAN = append(ie.DRBToRemoveListNGRAN, *i)
+		}
+	}
+	if isOptionalSet(optionals, 11) {
+		if err = skipExtensions(r); err != nil {
+			err = utils.WrapError("Read IEExtensions", err)
+			return
+		}
+	}
+	return
+}
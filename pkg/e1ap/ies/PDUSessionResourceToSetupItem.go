@@ -0,0 +1,203 @@
+package ies
+
+import (
+	"fmt"
+
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type PDUSessionResourceToSetupItem struct {
+	PDUSessionID             int64
+	PDUSessionType           PDUSessionType
+	SNSSAI                   SNSSAI
+	SecurityIndication       SecurityIndication
+	PDUSessionResourceDLAMBR *int64
+	NGULUPTNLInformation     UPTNLInformation
+	// PDUSessionDataForwardingInformationRequest *PDUSessionDataForwardingInformationRequest `optional`
+	PDUSessionInactivityTimer      *int64
+	ExistingAllocatedNGDLUPTNLInfo *UPTNLInformation
+	NetworkInstance                *int64
+	DRBToSetupListNGRAN            []DRBToSetupItemNGRAN
+	// IEExtensions *PDUSessionResourceToSetupItemExtIEs `optional`
+}
+
+func (ie *PDUSessionResourceToSetupItem) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	optionals := []byte{0x0}
+	if ie.PDUSessionResourceDLAMBR != nil {
+		aper.SetBit(optionals, 1)
+	}
+	if ie.PDUSessionInactivityTimer != nil {
+		aper.SetBit(optionals, 3)
+	}
+	if ie.ExistingAllocatedNGDLUPTNLInfo != nil {
+		aper.SetBit(optionals, 4)
+	}
+	if ie.NetworkInstance != nil {
+		aper.SetBit(optionals, 5)
+	}
+	w.WriteBits(optionals, 6)
+	tmp_PDUSessionID := NewINTEGER(ie.PDUSessionID, aper.Constraint{Lb: 0, Ub: 255}, false)
+	if err = tmp_PDUSessionID.Encode(w); err != nil {
+		err = utils.WrapError("Encode PDUSessionID", err)
+		return
+	}
+	if err = ie.PDUSessionType.Encode(w); err != nil {
+		err = utils.WrapError("Encode PDUSessionType", err)
+		return
+	}
+	if err = ie.SNSSAI.Encode(w); err != nil {
+		err = utils.WrapError("Encode SNSSAI", err)
+		return
+	}
+	if err = ie.SecurityIndication.Encode(w); err != nil {
+		err = utils.WrapError("Encode SecurityIndication", err)
+		return
+	}
+	if ie.PDUSessionResourceDLAMBR != nil {
+		tmp_PDUSessionResourceDLAMBR := NewINTEGER(*ie.PDUSessionResourceDLAMBR, aper.Constraint{Lb: 0, Ub: 4000000000000}, true)
+		if err = tmp_PDUSessionResourceDLAMBR.Encode(w); err != nil {
+			err = utils.WrapError("Encode PDUSessionResourceDLAMBR", err)
+			return
+		}
+	}
+	if err = ie.NGULUPTNLInformation.Encode(w); err != nil {
+		err = utils.WrapError("Encode NGULUPTNLInformation", err)
+		return
+	}
+	if ie.PDUSessionInactivityTimer != nil {
+		tmp_PDUSessionInactivityTimer := NewINTEGER(*ie.PDUSessionInactivityTimer, aper.Constraint{Lb: 1, Ub: 7200}, true)
+		if err = tmp_PDUSessionInactivityTimer.Encode(w); err != nil {
+			err = utils.WrapError("Encode PDUSessionInactivityTimer", err)
+			return
+		}
+	}
+	if ie.ExistingAllocatedNGDLUPTNLInfo != nil {
+		if err = ie.ExistingAllocatedNGDLUPTNLInfo.Encode(w); err != nil {
+			err = utils.WrapError("Encode ExistingAllocatedNGDLUPTNLInfo", err)
+			return
+		}
+	}
+	if ie.NetworkInstance != nil {
+		tmp_NetworkInstance := NewINTEGER(*ie.NetworkInstance, aper.Constraint{Lb: 1, Ub: 256}, true)
+		if err = tmp_NetworkInstance.Encode(w); err != nil {
+			err = utils.WrapError("Encode NetworkInstance", err)
+			return
+		}
+	}
+	tmp_DRBToSetupListNGRAN := Sequence[*DRBToSetupItemNGRAN]{
+		Value: []*DRBToSetupItemNGRAN{},
+		c:     aper.Constraint{Lb: 1, Ub: maxnoofDRBs},
+		ext:   false,
+	}
+	for i := range ie.DRBToSetupListNGRAN {
+		tmp_DRBToSetupListNGRAN.Value = append(tmp_DRBToSetupListNGRAN.Value, &ie.DRBToSetupListNGRAN[i])
+	}
+	if err = tmp_DRBToSetupListNGRAN.Encode(w); err != nil {
+		err = utils.WrapError("Encode DRBToSetupListNGRAN", err)
+		return
+	}
+	return
+}
+func (ie *PDUSessionResourceToSetupItem) Decode(r *aper.AperReader) (err error) {
+	if _, err = r.ReadBool(); err != nil {
+		return
+	}
+	var optionals []byte
+	if optionals, err = r.ReadBits(6); err != nil {
+		return
+	}
+	tmp_PDUSessionID := INTEGER{
+		c:   aper.Constraint{Lb: 0, Ub: 255},
+		ext: false,
+	}
+	if err = tmp_PDUSessionID.Decode(r); err != nil {
+		err = utils.WrapError("Read PDUSessionID", err)
+		return
+	}
+	ie.PDUSessionID = int64(tmp_PDUSessionID.Value)
+	if err = ie.PDUSessionType.Decode(r); err != nil {
+		err = utils.WrapError("Read PDUSessionType", err)
+		return
+	}
+	if err = ie.SNSSAI.Decode(r); err != nil {
+		err = utils.WrapError("Read SNSSAI", err)
+		return
+	}
+	if err = ie.SecurityIndication.Decode(r); err != nil {
+		err = utils.WrapError("Read SecurityIndication", err)
+		return
+	}
+	if aper.IsBitSet(optionals, 1) {
+		tmp_PDUSessionResourceDLAMBR := INTEGER{
+			c:   aper.Constraint{Lb: 0, Ub: 4000000000000},
+			ext: true,
+		}
+		if err = tmp_PDUSessionResourceDLAMBR.Decode(r); err != nil {
+			err = utils.WrapError("Read PDUSessionResourceDLAMBR", err)
+			return
+		}
+		ie.PDUSessionResourceDLAMBR = (*int64)(&tmp_PDUSessionResourceDLAMBR.Value)
+	}
+	if err = ie.NGULUPTNLInformation.Decode(r); err != nil {
+		err = utils.WrapError("Read NGULUPTNLInformation", err)
+		return
+	}
+	if aper.IsBitSet(optionals, 2) {
+		err = fmt.Errorf("Read PDUSessionDataForwardingInformationRequest: not supported")
+		return
+	}
+	if aper.IsBitSet(optionals, 3) {
+		tmp_PDUSessionInactivityTimer := INTEGER{
+			c:   aper.Constraint{Lb: 1, Ub: 7200},
+			ext: true,
+		}
+		if err = tmp_PDUSessionInactivityTimer.Decode(r); err != nil {
+			err = utils.WrapError("Read PDUSessionInactivityTimer", err)
+			return
+		}
+		ie.PDUSessionInactivityTimer = (*int64)(&tmp_PDUSessionInactivityTimer.Value)
+	}
+	if aper.IsBitSet(optionals, 4) {
+		tmp := new(UPTNLInformation)
+		if err = tmp.Decode(r); err != nil {
+			err = utils.WrapError("Read ExistingAllocatedNGDLUPTNLInfo", err)
+			return
+		}
+		ie.ExistingAllocatedNGDLUPTNLInfo = tmp
+	}
+	if aper.IsBitSet(optionals, 5) {
+		tmp_NetworkInstance := INTEGER{
+			c:   aper.Constraint{Lb: 1, Ub: 256},
+			ext: true,
+		}
+		if err = tmp_NetworkInstance.Decode(r); err != nil {
+			err = utils.WrapError("Read NetworkInstance", err)
+			return
+		}
+		ie.NetworkInstance = (*int64)(&tmp_NetworkInstance.Value)
+	}
+	tmp_DRBToSetupListNGRAN := Sequence[*DRBToSetupItemNGRAN]{
+		c:   aper.Constraint{Lb: 1, Ub: maxnoofDRBs},
+		ext: false,
+	}
+	fn_DRBToSetupListNGRAN := func() *DRBToSetupItemNGRAN { return new(DRBToSetupItemNGRAN) }
+	if err = tmp_DRBToSetupListNGRAN.Decode(r, fn_DRBToSetupListNGRAN); err != nil {
+		err = utils.WrapError("Read DRBToSetupListNGRAN", err)
+		return
+	}
+	ie.DRBToSetupListNGRAN = []DRBToSetupItemNGRAN{}
+	for _, i := range tmp_DRBToSetupListNGRAN.Value {
+		ie.DRBToSetupListNGRAN = append(ie.DRBToSetupListNGRAN, *i)
+	}
+	if aper.IsBitSet(optionals, 6) {
+		if err = skipExtensions(r); err != nil {
+			err = utils.WrapError("Read IEExtensions", err)
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,163 @@
+package ies
+
+import (
+	"fmt"
+
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type PDUSessionResourceToSetupModItem struct {
+	PDUSessionID           int64
+	PDUSessionType         PDUSessionType
+	SNSSAI                 SNSSAI
+	SecurityIndication     SecurityIndication
+	PDUSessionResourceAMBR *int64
+	NGULUPTNLInformation   UPTNLInformation
+	// PDUSessionDataForwardingInformationRequest *PDUSessionDataForwardingInformationRequest `optional`
+	PDUSessionInactivityTimer *int64
+	DRBToSetupModListNGRAN    []DRBToSetupItemNGRAN
+	// IEExtensions *PDUSessionResourceToSetupModItemExtIEs `optional`
+}
+
+func (ie *PDUSessionResourceToSetupModItem) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	optionals := []byte{0x0}
+	if ie.PDUSessionResourceAMBR != nil {
+		aper.SetBit(optionals, 1)
+	}
+	if ie.PDUSessionInactivityTimer != nil {
+		aper.SetBit(optionals, 3)
+	}
+	w.WriteBits(optionals, 4)
+	tmp_PDUSessionID := NewINTEGER(ie.PDUSessionID, aper.Constraint{Lb: 0, Ub: 255}, false)
+	if err = tmp_PDUSessionID.Encode(w); err != nil {
+		err = utils.WrapError("Encode PDUSessionID", err)
+		return
+	}
+	if err = ie.PDUSessionType.Encode(w); err != nil {
+		err = utils.WrapError("Encode PDUSessionType", err)
+		return
+	}
+	if err = ie.SNSSAI.Encode(w); err != nil {
+		err = utils.WrapError("Encode SNSSAI", err)
+		return
+	}
+	if err = ie.SecurityIndication.Encode(w); err != nil {
+		err = utils.WrapError("Encode SecurityIndication", err)
+		return
+	}
+	if ie.PDUSessionResourceAMBR != nil {
+		tmp_PDUSessionResourceAMBR := NewINTEGER(*ie.PDUSessionResourceAMBR, aper.Constraint{Lb: 0, Ub: 4000000000000}, true)
+		if err = tmp_PDUSessionResourceAMBR.Encode(w); err != nil {
+			err = utils.WrapError("Encode PDUSessionResourceAMBR", err)
+			return
+		}
+	}
+	if err = ie.NGULUPTNLInformation.Encode(w); err != nil {
+		err = utils.WrapError("Encode NGULUPTNLInformation", err)
+		return
+	}
+	if ie.PDUSessionInactivityTimer != nil {
+		tmp_PDUSessionInactivityTimer := NewINTEGER(*ie.PDUSessionInactivityTimer, aper.Constraint{Lb: 1, Ub: 7200}, true)
+		if err = tmp_PDUSessionInactivityTimer.Encode(w); err != nil {
+			err = utils.WrapError("Encode PDUSessionInactivityTimer", err)
+			return
+		}
+	}
+	tmp_DRBToSetupModListNGRAN := Sequence[*DRBToSetupItemNGRAN]{
+		Value: []*DRBToSetupItemNGRAN{},
+		c:     aper.Constraint{Lb: 1, Ub: maxnoofDRBs},
+		ext:   false,
+	}
+	for i := range ie.DRBToSetupModListNGRAN {
+		tmp_DRBToSetupModListNGRAN.Value = append(tmp_DRBToSetupModListNGRAN.Value, &ie.DRBToSetupModListNGRAN[i])
+	}
+	if err = tmp_DRBToSetupModListNGRAN.Encode(w); err != nil {
+		err = utils.WrapError("Encode DRBToSetupModListNGRAN", err)
+		return
+	}
+	return
+}
+func (ie *PDUSessionResourceToSetupModItem) Decode(r *aper.AperReader) (err error) {
+	if _, err = r.ReadBool(); err != nil {
+		return
+	}
+	var optionals []byte
+	if optionals, err = r.ReadBits(4); err != nil {
+		return
+	}
+	tmp_PDUSessionID := INTEGER{
+		c:   aper.Constraint{Lb: 0, Ub: 255},
+		ext: false,
+	}
+	if err = tmp_PDUSessionID.Decode(r); err != nil {
+		err = utils.WrapError("Read PDUSessionID", err)
+		return
+	}
+	ie.PDUSessionID = int64(tmp_PDUSessionID.Value)
+	if err = ie.PDUSessionType.Decode(r); err != nil {
+		err = utils.WrapError("Read PDUSessionType", err)
+		return
+	}
+	if err = ie.SNSSAI.Decode(r); err != nil {
+		err = utils.WrapError("Read SNSSAI", err)
+		return
+	}
+	if err = ie.SecurityIndication.Decode(r); err != nil {
+		err = utils.WrapError("Read SecurityIndication", err)
+		return
+	}
+	if aper.IsBitSet(optionals, 1) {
+		tmp_PDUSessionResourceAMBR := INTEGER{
+			c:   aper.Constraint{Lb: 0, Ub: 4000000000000},
+			ext: true,
+		}
+		if err = tmp_PDUSessionResourceAMBR.Decode(r); err != nil {
+			err = utils.WrapError("Read PDUSessionResourceAMBR", err)
+			return
+		}
+		ie.PDUSessionResourceAMBR = (*int64)(&tmp_PDUSessionResourceAMBR.Value)
+	}
+	if err = ie.NGULUPTNLInformation.Decode(r); err != nil {
+		err = utils.WrapError("Read NGULUPTNLInformation", err)
+		return
+	}
+	if aper.IsBitSet(optionals, 2) {
+		err = fmt.Errorf("Read PDUSessionDataForwardingInformationRequest: not supported")
+		return
+	}
+	if aper.IsBitSet(optionals, 3) {
+		tmp_PDUSessionInactivityTimer := INTEGER{
+			c:   aper.Constraint{Lb: 1, Ub: 7200},
+			ext: true,
+		}
+		if err = tmp_PDUSessionInactivityTimer.Decode(r); err != nil {
+			err = utils.WrapError("Read PDUSessionInactivityTimer", err)
+			return
+		}
+		ie.PDUSessionInactivityTimer = (*int64)(&tmp_PDUSessionInactivityTimer.Value)
+	}
+	tmp_DRBToSetupModListNGRAN := Sequence[*DRBToSetupItemNGRAN]{
+		c:   aper.Constraint{Lb: 1, Ub: maxnoofDRBs},
+		ext: false,
+	}
+	fn_DRBToSetupModListNGRAN := func() *DRBToSetupItemNGRAN { return new(DRBToSetupItemNGRAN) }
+	if err = tmp_DRBToSetupModListNGRAN.Decode(r, fn_DRBToSetupModListNGRAN); err != nil {
+		err = utils.WrapError("Read DRBToSetupModListNGRAN", err)
+		return
+	}
+	ie.DRBToSetupModListNGRAN = []DRBToSetupItemNGRAN{}
+	for _, i := range tmp_DRBToSetupModListNGRAN.Value {
+		ie.DRBToSetupModListNGRAN = append(ie.DRBToSetupModListNGRAN, *i)
+	}
+	if aper.IsBitSet(optionals, 4) {
+		if err = skipExtensions(r); err != nil {
+			err = utils.WrapError("Read IEExtensions", err)
+			return
+		}
+	}
+	return
+}
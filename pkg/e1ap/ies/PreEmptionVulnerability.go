@@ -0,0 +1,22 @@
+package ies
+
+import "github.com/lvdund/ngap/aper"
+
+const (
+	PreEmptionVulnerabilityNotpreemptable aper.Enumerated = 0
+	PreEmptionVulnerabilityPreemptable    aper.Enumerated = 1
+)
+
+type PreEmptionVulnerability struct {
+	Value aper.Enumerated
+}
+
+func (ie *PreEmptionVulnerability) Encode(w *aper.AperWriter) (err error) {
+	err = w.WriteEnumerate(uint64(ie.Value), aper.Constraint{Lb: 0, Ub: 1}, true)
+	return
+}
+func (ie *PreEmptionVulnerability) Decode(r *aper.AperReader) (err error) {
+	v, err := r.ReadEnumerate(aper.Constraint{Lb: 0, Ub: 1}, true)
+	ie.Value = aper.Enumerated(v)
+	return
+}
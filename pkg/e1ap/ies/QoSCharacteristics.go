@@ -0,0 +1,62 @@
+package ies
+
+import (
+	"fmt"
+
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+const (
+	QoSCharacteristicsPresentNothing uint64 = iota
+	QoSCharacteristicsPresentNonDynamic5QI
+	QoSCharacteristicsPresentDynamic5QI
+	QoSCharacteristicsPresentChoiceExtension
+)
+
+type QoSCharacteristics struct {
+	Choice        uint64
+	NonDynamic5QI *NonDynamic5QIDescriptor
+	Dynamic5QI    *Dynamic5QIDescriptor
+	// ChoiceExtension
+}
+
+func (ie *QoSCharacteristics) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteChoice(ie.Choice, 2, false); err != nil {
+		return
+	}
+	switch ie.Choice {
+	case QoSCharacteristicsPresentNonDynamic5QI:
+		err = ie.NonDynamic5QI.Encode(w)
+	case QoSCharacteristicsPresentDynamic5QI:
+		err = ie.Dynamic5QI.Encode(w)
+	default:
+		err = fmt.Errorf("Encode QoSCharacteristics: unsupported choice %d", ie.Choice)
+	}
+	return
+}
+
+func (ie *QoSCharacteristics) Decode(r *aper.AperReader) (err error) {
+	if ie.Choice, err = r.ReadChoice(2, false); err != nil {
+		return
+	}
+	switch ie.Choice {
+	case QoSCharacteristicsPresentNonDynamic5QI:
+		var tmp NonDynamic5QIDescriptor
+		if err = tmp.Decode(r); err != nil {
+			err = utils.WrapError("Read NonDynamic5QI", err)
+			return
+		}
+		ie.NonDynamic5QI = &tmp
+	case QoSCharacteristicsPresentDynamic5QI:
+		var tmp Dynamic5QIDescriptor
+		if err = tmp.Decode(r); err != nil {
+			err = utils.WrapError("Read Dynamic5QI", err)
+			return
+		}
+		ie.Dynamic5QI = &tmp
+	default:
+		err = fmt.Errorf("Decode QoSCharacteristics: unsupported choice %d", ie.Choice)
+	}
+	return
+}
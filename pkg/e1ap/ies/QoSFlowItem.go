@@ -0,0 +1,50 @@
+package ies
+
+import (
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type QoSFlowItem struct {
+	QoSFlowIdentifier int64
+	// IEExtensions *QoSFlowItemExtIEs `optional`
+}
+
+func (ie *QoSFlowItem) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	optionals := []byte{0x0}
+	w.WriteBits(optionals, 1)
+	tmp_QoSFlowIdentifier := NewINTEGER(ie.QoSFlowIdentifier, aper.Constraint{Lb: 0, Ub: 63}, false)
+	if err = tmp_QoSFlowIdentifier.Encode(w); err != nil {
+		err = utils.WrapError("Encode QoSFlowIdentifier", err)
+		return
+	}
+	return
+}
+func (ie *QoSFlowItem) Decode(r *aper.AperReader) (err error) {
+	if _, err = r.ReadBool(); err != nil {
+		return
+	}
+	var optionals []byte
+	if optionals, err = r.ReadBits(1); err != nil {
+		return
+	}
+	tmp_QoSFlowIdentifier := INTEGER{
+		c:   aper.Constraint{Lb: 0, Ub: 63},
+		ext: false,
+	}
+	if err = tmp_QoSFlowIdentifier.Decode(r); err != nil {
+		err = utils.WrapError("Read QoSFlowIdentifier", err)
+		return
+	}
+	ie.QoSFlowIdentifier = int64(tmp_QoSFlowIdentifier.Value)
+	if aper.IsBitSet(optionals, 1) {
+		if err = skipExtensions(r); err != nil {
+			err = utils.WrapError("Read IEExtensions", err)
+			return
+		}
+	}
+	return
+}
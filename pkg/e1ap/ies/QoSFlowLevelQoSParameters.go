@@ -0,0 +1,93 @@
+package ies
+
+import (
+	"fmt"
+
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type QoSFlowLevelQoSParameters struct {
+	QoSCharacteristics               QoSCharacteristics
+	NGRANAllocationRetentionPriority NGRANAllocationAndRetentionPriority
+	GBRQoSFlowInformation            *GBRQoSFlowInformation
+	// ReflectiveQoSAttribute *ReflectiveQoSAttribute `optional`
+	// AdditionalQoSInformation *AdditionalQoSInformation `optional`
+	// PagingPolicyIndicator *PagingPolicyIndicator `optional`
+	// ReflectiveQoSIndicator *ReflectiveQoSIndicator `optional`
+	// IEExtensions *QoSFlowLevelQoSParametersExtIEs `optional`
+}
+
+func (ie *QoSFlowLevelQoSParameters) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	optionals := []byte{0x0}
+	if ie.GBRQoSFlowInformation != nil {
+		aper.SetBit(optionals, 1)
+	}
+	w.WriteBits(optionals, 6)
+	if err = ie.QoSCharacteristics.Encode(w); err != nil {
+		err = utils.WrapError("Encode QoSCharacteristics", err)
+		return
+	}
+	if err = ie.NGRANAllocationRetentionPriority.Encode(w); err != nil {
+		err = utils.WrapError("Encode NGRANAllocationRetentionPriority", err)
+		return
+	}
+	if ie.GBRQoSFlowInformation != nil {
+		if err = ie.GBRQoSFlowInformation.Encode(w); err != nil {
+			err = utils.WrapError("Encode GBRQoSFlowInformation", err)
+			return
+		}
+	}
+	return
+}
+func (ie *QoSFlowLevelQoSParameters) Decode(r *aper.AperReader) (err error) {
+	if _, err = r.ReadBool(); err != nil {
+		return
+	}
+	var optionals []byte
+	if optionals, err = r.ReadBits(6); err != nil {
+		return
+	}
+	if err = ie.QoSCharacteristics.Decode(r); err != nil {
+		err = utils.WrapError("Read QoSCharacteristics", err)
+		return
+	}
+	if err = ie.NGRANAllocationRetentionPriority.Decode(r); err != nil {
+		err = utils.WrapError("Read NGRANAllocationRetentionPriority", err)
+		return
+	}
+	if aper.IsBitSet(optionals, 1) {
+		tmp := new(GBRQoSFlowInformation)
+		if err = tmp.Decode(r); err != nil {
+			err = utils.WrapError("Read GBRQoSFlowInformation", err)
+			return
+		}
+		ie.GBRQoSFlowInformation = tmp
+	}
+	if aper.IsBitSet(optionals, 2) {
+		err = fmt.Errorf("Read ReflectiveQoSAttribute: not supported")
+		return
+	}
+	if aper.IsBitSet(optionals, 3) {
+		err = fmt.Errorf("Read AdditionalQoSInformation: not supported")
+		return
+	}
+	if aper.IsBitSet(optionals, 4) {
+		err = fmt.Errorf("Read PagingPolicyIndicator: not supported")
+		return
+	}
+	if aper.IsBitSet(optionals, 5) {
+		err = fmt.Errorf("Read ReflectiveQoSIndicator: not supported")
+		return
+	}
+	if aper.IsBitSet(optionals, 6) {
+		if err = skipExtensions(r); err != nil {
+			err = utils.WrapError("Read IEExtensions", err)
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,66 @@
+package ies
+
+import (
+	"fmt"
+
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type QoSFlowQoSParameterItem struct {
+	QoSFlowIdentifier         int64
+	QoSFlowLevelQoSParameters QoSFlowLevelQoSParameters
+	// QoSFlowMappingIndication *QoSFlowMappingIndication `optional`
+	// IEExtensions *QoSFlowQoSParameterItemExtIEs `optional`
+}
+
+func (ie *QoSFlowQoSParameterItem) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	optionals := []byte{0x0}
+	w.WriteBits(optionals, 2)
+	tmp_QoSFlowIdentifier := NewINTEGER(ie.QoSFlowIdentifier, aper.Constraint{Lb: 0, Ub: 63}, false)
+	if err = tmp_QoSFlowIdentifier.Encode(w); err != nil {
+		err = utils.WrapError("Encode QoSFlowIdentifier", err)
+		return
+	}
+	if err = ie.QoSFlowLevelQoSParameters.Encode(w); err != nil {
+		err = utils.WrapError("Encode QoSFlowLevelQoSParameters", err)
+		return
+	}
+	return
+}
+func (ie *QoSFlowQoSParameterItem) Decode(r *aper.AperReader) (err error) {
+	if _, err = r.ReadBool(); err != nil {
+		return
+	}
+	var optionals []byte
+	if optionals, err = r.ReadBits(2); err != nil {
+		return
+	}
+	tmp_QoSFlowIdentifier := INTEGER{
+		c:   aper.Constraint{Lb: 0, Ub: 63},
+		ext: false,
+	}
+	if err = tmp_QoSFlowIdentifier.Decode(r); err != nil {
+		err = utils.WrapError("Read QoSFlowIdentifier", err)
+		return
+	}
+	ie.QoSFlowIdentifier = int64(tmp_QoSFlowIdentifier.Value)
+	if err = ie.QoSFlowLevelQoSParameters.Decode(r); err != nil {
+		err = utils.WrapError("Read QoSFlowLevelQoSParameters", err)
+		return
+	}
+	if aper.IsBitSet(optionals, 1) {
+		err = fmt.Errorf("Read QoSFlowMappingIndication: not supported")
+		return
+	}
+	if aper.IsBitSet(optionals, 2) {
+		if err = skipExtensions(r); err != nil {
+			err = utils.WrapError("Read IEExtensions", err)
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,62 @@
+package ies
+
+import (
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type SDAPConfiguration struct {
+	DefaultDRB   DefaultDRB
+	SDAPHeaderUL SDAPHeaderUL
+	SDAPHeaderDL SDAPHeaderDL
+	// IEExtensions *SDAPConfigurationExtIEs `optional`
+}
+
+func (ie *SDAPConfiguration) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	optionals := []byte{0x0}
+	w.WriteBits(optionals, 1)
+	if err = ie.DefaultDRB.Encode(w); err != nil {
+		err = utils.WrapError("Encode DefaultDRB", err)
+		return
+	}
+	if err = ie.SDAPHeaderUL.Encode(w); err != nil {
+		err = utils.WrapError("Encode SDAPHeaderUL", err)
+		return
+	}
+	if err = ie.SDAPHeaderDL.Encode(w); err != nil {
+		err = utils.WrapError("Encode SDAPHeaderDL", err)
+		return
+	}
+	return
+}
+func (ie *SDAPConfiguration) Decode(r *aper.AperReader) (err error) {
+	if _, err = r.ReadBool(); err != nil {
+		return
+	}
+	var optionals []byte
+	if optionals, err = r.ReadBits(1); err != nil {
+		return
+	}
+	if err = ie.DefaultDRB.Decode(r); err != nil {
+		err = utils.WrapError("Read DefaultDRB", err)
+		return
+	}
+	if err = ie.SDAPHeaderUL.Decode(r); err != nil {
+		err = utils.WrapError("Read SDAPHeaderUL", err)
+		return
+	}
+	if err = ie.SDAPHeaderDL.Decode(r); err != nil {
+		err = utils.WrapError("Read SDAPHeaderDL", err)
+		return
+	}
+	if aper.IsBitSet(optionals, 1) {
+		if err = skipExtensions(r); err != nil {
+			err = utils.WrapError("Read IEExtensions", err)
+			return
+		}
+	}
+	return
+}
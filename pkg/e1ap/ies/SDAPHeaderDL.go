@@ -0,0 +1,22 @@
+package ies
+
+import "github.com/lvdund/ngap/aper"
+
+const (
+	SDAPHeaderDLPresent aper.Enumerated = 0
+	SDAPHeaderDLAbsent  aper.Enumerated = 1
+)
+
+type SDAPHeaderDL struct {
+	Value aper.Enumerated
+}
+
+func (ie *SDAPHeaderDL) Encode(w *aper.AperWriter) (err error) {
+	err = w.WriteEnumerate(uint64(ie.Value), aper.Constraint{Lb: 0, Ub: 1}, true)
+	return
+}
+func (ie *SDAPHeaderDL) Decode(r *aper.AperReader) (err error) {
+	v, err := r.ReadEnumerate(aper.Constraint{Lb: 0, Ub: 1}, true)
+	ie.Value = aper.Enumerated(v)
+	return
+}
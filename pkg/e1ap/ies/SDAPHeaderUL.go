@@ -0,0 +1,22 @@
+package ies
+
+import "github.com/lvdund/ngap/aper"
+
+const (
+	SDAPHeaderULPresent aper.Enumerated = 0
+	SDAPHeaderULAbsent  aper.Enumerated = 1
+)
+
+type SDAPHeaderUL struct {
+	Value aper.Enumerated
+}
+
+func (ie *SDAPHeaderUL) Encode(w *aper.AperWriter) (err error) {
+	err = w.WriteEnumerate(uint64(ie.Value), aper.Constraint{Lb: 0, Ub: 1}, true)
+	return
+}
+func (ie *SDAPHeaderUL) Decode(r *aper.AperReader) (err error) {
+	v, err := r.ReadEnumerate(aper.Constraint{Lb: 0, Ub: 1}, true)
+	ie.Value = aper.Enumerated(v)
+	return
+}
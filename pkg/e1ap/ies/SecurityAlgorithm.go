@@ -0,0 +1,62 @@
+package ies
+
+import (
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type SecurityAlgorithm struct {
+	CipheringAlgorithm           CipheringAlgorithm
+	IntegrityProtectionAlgorithm *IntegrityProtectionAlgorithm
+	// IEExtensions *SecurityAlgorithmExtIEs `optional`
+}
+
+func (ie *SecurityAlgorithm) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	optionals := []byte{0x0}
+	if ie.IntegrityProtectionAlgorithm != nil {
+		aper.SetBit(optionals, 1)
+	}
+	w.WriteBits(optionals, 2)
+	if err = ie.CipheringAlgorithm.Encode(w); err != nil {
+		err = utils.WrapError("Encode CipheringAlgorithm", err)
+		return
+	}
+	if ie.IntegrityProtectionAlgorithm != nil {
+		if err = ie.IntegrityProtectionAlgorithm.Encode(w); err != nil {
+			err = utils.WrapError("Encode IntegrityProtectionAlgorithm", err)
+			return
+		}
+	}
+	return
+}
+func (ie *SecurityAlgorithm) Decode(r *aper.AperReader) (err error) {
+	if _, err = r.ReadBool(); err != nil {
+		return
+	}
+	var optionals []byte
+	if optionals, err = r.ReadBits(2); err != nil {
+		return
+	}
+	if err = ie.CipheringAlgorithm.Decode(r); err != nil {
+		err = utils.WrapError("Read CipheringAlgorithm", err)
+		return
+	}
+	if aper.IsBitSet(optionals, 1) {
+		tmp := new(IntegrityProtectionAlgorithm)
+		if err = tmp.Decode(r); err != nil {
+			err = utils.WrapError("Read IntegrityProtectionAlgorithm", err)
+			return
+		}
+		ie.IntegrityProtectionAlgorithm = tmp
+	}
+	if aper.IsBitSet(optionals, 2) {
+		if err = skipExtensions(r); err != nil {
+			err = utils.WrapError("Read IEExtensions", err)
+			return
+		}
+	}
+	return
+}
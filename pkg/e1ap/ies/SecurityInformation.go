@@ -0,0 +1,53 @@
+package ies
+
+import (
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type SecurityInformation struct {
+	SecurityAlgorithm SecurityAlgorithm
+	UPSecuritykey     UPSecuritykey
+	// IEExtensions *SecurityInformationExtIEs `optional`
+}
+
+func (ie *SecurityInformation) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	optionals := []byte{0x0}
+	w.WriteBits(optionals, 1)
+	if err = ie.SecurityAlgorithm.Encode(w); err != nil {
+		err = utils.WrapError("Encode SecurityAlgorithm", err)
+		return
+	}
+	if err = ie.UPSecuritykey.Encode(w); err != nil {
+		err = utils.WrapError("Encode UPSecuritykey", err)
+		return
+	}
+	return
+}
+func (ie *SecurityInformation) Decode(r *aper.AperReader) (err error) {
+	if _, err = r.ReadBool(); err != nil {
+		return
+	}
+	var optionals []byte
+	if optionals, err = r.ReadBits(1); err != nil {
+		return
+	}
+	if err = ie.SecurityAlgorithm.Decode(r); err != nil {
+		err = utils.WrapError("Read SecurityAlgorithm", err)
+		return
+	}
+	if err = ie.UPSecuritykey.Decode(r); err != nil {
+		err = utils.WrapError("Read UPSecuritykey", err)
+		return
+	}
+	if aper.IsBitSet(optionals, 1) {
+		if err = skipExtensions(r); err != nil {
+			err = utils.WrapError("Read IEExtensions", err)
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,123 @@
+package ies
+
+import (
+	"fmt"
+
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type SupportedPLMNsItem struct {
+	PLMNIdentity     []byte
+	SliceSupportList []SliceSupportItem
+	NRCGISupportList []NRCGISupportItem
+	// QoSParametersSupportList *QoSParametersSupportList `optional`
+	// IEExtensions *SupportedPLMNsItemExtIEs `optional`
+}
+
+func (ie *SupportedPLMNsItem) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	optionals := []byte{0x0}
+	if len(ie.SliceSupportList) > 0 {
+		aper.SetBit(optionals, 1)
+	}
+	if len(ie.NRCGISupportList) > 0 {
+		aper.SetBit(optionals, 2)
+	}
+	w.WriteBits(optionals, 4)
+	tmp_PLMNIdentity := NewOCTETSTRING(ie.PLMNIdentity, aper.Constraint{Lb: 3, Ub: 3}, false)
+	if err = tmp_PLMNIdentity.Encode(w); err != nil {
+		err = utils.WrapError("Encode PLMNIdentity", err)
+		return
+	}
+	if len(ie.SliceSupportList) > 0 {
+		tmp_SliceSupportList := Sequence[*SliceSupportItem]{
+			Value: []*SliceSupportItem{},
+			c:     aper.Constraint{Lb: 1, Ub: maxnoofSliceItems},
+			ext:   false,
+		}
+		for i := range ie.SliceSupportList {
+			tmp_SliceSupportList.Value = append(tmp_SliceSupportList.Value, &ie.SliceSupportList[i])
+		}
+		if err = tmp_SliceSupportList.Encode(w); err != nil {
+			err = utils.WrapError("Encode SliceSupportList", err)
+			return
+		}
+	}
+	if len(ie.NRCGISupportList) > 0 {
+		tmp_NRCGISupportList := Sequence[*NRCGISupportItem]{
+			Value: []*NRCGISupportItem{},
+			c:     aper.Constraint{Lb: 1, Ub: maxnoofNRCGI},
+			ext:   false,
+		}
+		for i := range ie.NRCGISupportList {
+			tmp_NRCGISupportList.Value = append(tmp_NRCGISupportList.Value, &ie.NRCGISupportList[i])
+		}
+		if err = tmp_NRCGISupportList.Encode(w); err != nil {
+			err = utils.WrapError("Encode NRCGISupportList", err)
+			return
+		}
+	}
+	return
+}
+func (ie *SupportedPLMNsItem) Decode(r *aper.AperReader) (err error) {
+	if _, err = r.ReadBool(); err != nil {
+		return
+	}
+	var optionals []byte
+	if optionals, err = r.ReadBits(4); err != nil {
+		return
+	}
+	tmp_PLMNIdentity := OCTETSTRING{
+		c:   aper.Constraint{Lb: 3, Ub: 3},
+		ext: false,
+	}
+	if err = tmp_PLMNIdentity.Decode(r); err != nil {
+		err = utils.WrapError("Read PLMNIdentity", err)
+		return
+	}
+	ie.PLMNIdentity = tmp_PLMNIdentity.Value
+	if aper.IsBitSet(optionals, 1) {
+		tmp_SliceSupportList := Sequence[*SliceSupportItem]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofSliceItems},
+			ext: false,
+		}
+		fn_SliceSupportList := func() *SliceSupportItem { return new(SliceSupportItem) }
+		if err = tmp_SliceSupportList.Decode(r, fn_SliceSupportList); err != nil {
+			err = utils.WrapError("Read SliceSupportList", err)
+			return
+		}
+		ie.SliceSupportList = []SliceSupportItem{}
+		for _, i := range tmp_SliceSupportList.Value {
+			ie.SliceSupportList = append(ie.SliceSupportList, *i)
+		}
+	}
+	if aper.IsBitSet(optionals, 2) {
+		tmp_NRCGISupportList := Sequence[*NRCGISupportItem]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofNRCGI},
+			ext: false,
+		}
+		fn_NRCGISupportList := func() *NRCGISupportItem { return new(NRCGISupportItem) }
+		if err = tmp_NRCGISupportList.Decode(r, fn_NRCGISupportList); err != nil {
+			err = utils.WrapError("Read NRCGISupportList", err)
+			return
+		}
+		ie.NRCGISupportList = []NRCGISupportItem{}
+		for _, i := range tmp_NRCGISupportList.Value {
+			ie.NRCGISupportList = append(ie.NRCGISupportList, *i)
+		}
+	}
+	if aper.IsBitSet(optionals, 3) {
+		err = fmt.Errorf("Read QoSParametersSupportList: not supported")
+		return
+	}
+	if aper.IsBitSet(optionals, 4) {
+		if err = skipExtensions(r); err != nil {
+			err = utils.WrapError("Read IEExtensions", err)
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,53 @@
+package ies
+
+import (
+	"fmt"
+
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+const (
+	SystemBearerContextModificationRequestPresentNothing uint64 = iota
+	SystemBearerContextModificationRequestPresentEUTRANBearerContextModificationRequest
+	SystemBearerContextModificationRequestPresentNGRANBearerContextModificationRequest
+	SystemBearerContextModificationRequestPresentChoiceExtension
+)
+
+type SystemBearerContextModificationRequest struct {
+	Choice uint64
+	// EUTRANBearerContextModificationRequest *EUTRANBearerContextModificationRequest
+	NGRANBearerContextModificationRequest *NGRANBearerContextModificationRequest
+	// ChoiceExtension
+}
+
+func (ie *SystemBearerContextModificationRequest) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteChoice(ie.Choice, 2, false); err != nil {
+		return
+	}
+	switch ie.Choice {
+	case SystemBearerContextModificationRequestPresentNGRANBearerContextModificationRequest:
+		err = ie.NGRANBearerContextModificationRequest.Encode(w)
+	default:
+		err = fmt.Errorf("Encode SystemBearerContextModificationRequest: unsupported choice %d", ie.Choice)
+	}
+	return
+}
+
+func (ie *SystemBearerContextModificationRequest) Decode(r *aper.AperReader) (err error) {
+	if ie.Choice, err = r.ReadChoice(2, false); err != nil {
+		return
+	}
+	switch ie.Choice {
+	case SystemBearerContextModificationRequestPresentNGRANBearerContextModificationRequest:
+		var tmp NGRANBearerContextModificationRequest
+		if err = tmp.Decode(r); err != nil {
+			err = utils.WrapError("Read NGRANBearerContextModificationRequest", err)
+			return
+		}
+		ie.NGRANBearerContextModificationRequest = &tmp
+	default:
+		err = fmt.Errorf("Decode SystemBearerContextModificationRequest: unsupported choice %d", ie.Choice)
+	}
+	return
+}
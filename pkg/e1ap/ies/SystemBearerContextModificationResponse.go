@@ -0,0 +1,53 @@
+package ies
+
+import (
+	"fmt"
+
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+const (
+	SystemBearerContextModificationResponsePresentNothing uint64 = iota
+	SystemBearerContextModificationResponsePresentEUTRANBearerContextModificationResponse
+	SystemBearerContextModificationResponsePresentNGRANBearerContextModificationResponse
+	SystemBearerContextModificationResponsePresentChoiceExtension
+)
+
+type SystemBearerContextModificationResponse struct {
+	Choice uint64
+	// EUTRANBearerContextModificationResponse *EUTRANBearerContextModificationResponse
+	NGRANBearerContextModificationResponse *NGRANBearerContextModificationResponse
+	// ChoiceExtension
+}
+
+func (ie *SystemBearerContextModificationResponse) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteChoice(ie.Choice, 2, false); err != nil {
+		return
+	}
+	switch ie.Choice {
+	case SystemBearerContextModificationResponsePresentNGRANBearerContextModificationResponse:
+		err = ie.NGRANBearerContextModificationResponse.Encode(w)
+	default:
+		err = fmt.Errorf("Encode SystemBearerContextModificationResponse: unsupported choice %d", ie.Choice)
+	}
+	return
+}
+
+func (ie *SystemBearerContextModificationResponse) Decode(r *aper.AperReader) (err error) {
+	if ie.Choice, err = r.ReadChoice(2, false); err != nil {
+		return
+	}
+	switch ie.Choice {
+	case SystemBearerContextModificationResponsePresentNGRANBearerContextModificationResponse:
+		var tmp NGRANBearerContextModificationResponse
+		if err = tmp.Decode(r); err != nil {
+			err = utils.WrapError("Read NGRANBearerContextModificationResponse", err)
+			return
+		}
+		ie.NGRANBearerContextModificationResponse = &tmp
+	default:
+		err = fmt.Errorf("Decode SystemBearerContextModificationResponse: unsupported choice %d", ie.Choice)
+	}
+	return
+}
@@ -0,0 +1,53 @@
+package ies
+
+import (
+	"fmt"
+
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+const (
+	SystemBearerContextSetupRequestPresentNothing uint64 = iota
+	SystemBearerContextSetupRequestPresentEUTRANBearerContextSetupRequest
+	SystemBearerContextSetupRequestPresentNGRANBearerContextSetupRequest
+	SystemBearerContextSetupRequestPresentChoiceExtension
+)
+
+type SystemBearerContextSetupRequest struct {
+	Choice uint64
+	// EUTRANBearerContextSetupRequest *EUTRANBearerContextSetupRequest
+	NGRANBearerContextSetupRequest *NGRANBearerContextSetupRequest
+	// ChoiceExtension
+}
+
+func (ie *SystemBearerContextSetupRequest) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteChoice(ie.Choice, 2, false); err != nil {
+		return
+	}
+	switch ie.Choice {
+	case SystemBearerContextSetupRequestPresentNGRANBearerContextSetupRequest:
+		err = ie.NGRANBearerContextSetupRequest.Encode(w)
+	default:
+		err = fmt.Errorf("Encode SystemBearerContextSetupRequest: unsupported choice %d", ie.Choice)
+	}
+	return
+}
+
+func (ie *SystemBearerContextSetupRequest) Decode(r *aper.AperReader) (err error) {
+	if ie.Choice, err = r.ReadChoice(2, false); err != nil {
+		return
+	}
+	switch ie.Choice {
+	case SystemBearerContextSetupRequestPresentNGRANBearerContextSetupRequest:
+		var tmp NGRANBearerContextSetupRequest
+		if err = tmp.Decode(r); err != nil {
+			err = utils.WrapError("Read NGRANBearerContextSetupRequest", err)
+			return
+		}
+		ie.NGRANBearerContextSetupRequest = &tmp
+	default:
+		err = fmt.Errorf("Decode SystemBearerContextSetupRequest: unsupported choice %d", ie.Choice)
+	}
+	return
+}
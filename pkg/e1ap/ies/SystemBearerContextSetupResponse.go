@@ -0,0 +1,53 @@
+package ies
+
+import (
+	"fmt"
+
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+const (
+	SystemBearerContextSetupResponsePresentNothing uint64 = iota
+	SystemBearerContextSetupResponsePresentEUTRANBearerContextSetupResponse
+	SystemBearerContextSetupResponsePresentNGRANBearerContextSetupResponse
+	SystemBearerContextSetupResponsePresentChoiceExtension
+)
+
+type SystemBearerContextSetupResponse struct {
+	Choice uint64
+	// EUTRANBearerContextSetupResponse *EUTRANBearerContextSetupResponse
+	NGRANBearerContextSetupResponse *NGRANBearerContextSetupResponse
+	// ChoiceExtension
+}
+
+func (ie *SystemBearerContextSetupResponse) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteChoice(ie.Choice, 2, false); err != nil {
+		return
+	}
+	switch ie.Choice {
+	case SystemBearerContextSetupResponsePresentNGRANBearerContextSetupResponse:
+		err = ie.NGRANBearerContextSetupResponse.Encode(w)
+	default:
+		err = fmt.Errorf("Encode SystemBearerContextSetupResponse: unsupported choice %d", ie.Choice)
+	}
+	return
+}
+
+func (ie *SystemBearerContextSetupResponse) Decode(r *aper.AperReader) (err error) {
+	if ie.Choice, err = r.ReadChoice(2, false); err != nil {
+		return
+	}
+	switch ie.Choice {
+	case SystemBearerContextSetupResponsePresentNGRANBearerContextSetupResponse:
+		var tmp NGRANBearerContextSetupResponse
+		if err = tmp.Decode(r); err != nil {
+			err = utils.WrapError("Read NGRANBearerContextSetupResponse", err)
+			return
+		}
+		ie.NGRANBearerContextSetupResponse = &tmp
+	default:
+		err = fmt.Errorf("Decode SystemBearerContextSetupResponse: unsupported choice %d", ie.Choice)
+	}
+	return
+}
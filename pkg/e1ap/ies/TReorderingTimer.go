@@ -0,0 +1,44 @@
+package ies
+
+import (
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type TReorderingTimer struct {
+	TReordering TReordering
+	// IEExtensions *TReorderingTimerExtIEs `optional`
+}
+
+func (ie *TReorderingTimer) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	optionals := []byte{0x0}
+	w.WriteBits(optionals, 1)
+	if err = ie.TReordering.Encode(w); err != nil {
+		err = utils.WrapError("Encode TReordering", err)
+		return
+	}
+	return
+}
+func (ie *TReorderingTimer) Decode(r *aper.AperReader) (err error) {
+	if _, err = r.ReadBool(); err != nil {
+		return
+	}
+	var optionals []byte
+	if optionals, err = r.ReadBits(1); err != nil {
+		return
+	}
+	if err = ie.TReordering.Decode(r); err != nil {
+		err = utils.WrapError("Read TReordering", err)
+		return
+	}
+	if aper.IsBitSet(optionals, 1) {
+		if err = skipExtensions(r); err != nil {
+			err = utils.WrapError("Read IEExtensions", err)
+			return
+		}
+	}
+	return
+}
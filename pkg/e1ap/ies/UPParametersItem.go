@@ -0,0 +1,59 @@
+package ies
+
+import (
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type UPParametersItem struct {
+	UPTNLInformation UPTNLInformation
+	CellGroupID      int64
+	// IEExtensions *UPParametersItemExtIEs `optional`
+}
+
+func (ie *UPParametersItem) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	optionals := []byte{0x0}
+	w.WriteBits(optionals, 1)
+	if err = ie.UPTNLInformation.Encode(w); err != nil {
+		err = utils.WrapError("Encode UPTNLInformation", err)
+		return
+	}
+	tmp_CellGroupID := NewINTEGER(ie.CellGroupID, aper.Constraint{Lb: 0, Ub: 3}, true)
+	if err = tmp_CellGroupID.Encode(w); err != nil {
+		err = utils.WrapError("Encode CellGroupID", err)
+		return
+	}
+	return
+}
+func (ie *UPParametersItem) Decode(r *aper.AperReader) (err error) {
+	if _, err = r.ReadBool(); err != nil {
+		return
+	}
+	var optionals []byte
+	if optionals, err = r.ReadBits(1); err != nil {
+		return
+	}
+	if err = ie.UPTNLInformation.Decode(r); err != nil {
+		err = utils.WrapError("Read UPTNLInformation", err)
+		return
+	}
+	tmp_CellGroupID := INTEGER{
+		c:   aper.Constraint{Lb: 0, Ub: 3},
+		ext: true,
+	}
+	if err = tmp_CellGroupID.Decode(r); err != nil {
+		err = utils.WrapError("Read CellGroupID", err)
+		return
+	}
+	ie.CellGroupID = int64(tmp_CellGroupID.Value)
+	if aper.IsBitSet(optionals, 1) {
+		if err = skipExtensions(r); err != nil {
+			err = utils.WrapError("Read IEExtensions", err)
+			return
+		}
+	}
+	return
+}
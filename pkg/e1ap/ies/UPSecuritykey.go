@@ -0,0 +1,72 @@
+package ies
+
+import (
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type UPSecuritykey struct {
+	EncryptionKey          []byte
+	IntegrityProtectionKey []byte
+	// IEExtensions *UPSecuritykeyExtIEs `optional`
+}
+
+func (ie *UPSecuritykey) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	optionals := []byte{0x0}
+	if ie.IntegrityProtectionKey != nil {
+		aper.SetBit(optionals, 1)
+	}
+	w.WriteBits(optionals, 2)
+	tmp_EncryptionKey := NewOCTETSTRING(ie.EncryptionKey, aper.Constraint{Lb: 0, Ub: 0}, false)
+	if err = tmp_EncryptionKey.Encode(w); err != nil {
+		err = utils.WrapError("Encode EncryptionKey", err)
+		return
+	}
+	if ie.IntegrityProtectionKey != nil {
+		tmp_IntegrityProtectionKey := NewOCTETSTRING(ie.IntegrityProtectionKey, aper.Constraint{Lb: 0, Ub: 0}, false)
+		if err = tmp_IntegrityProtectionKey.Encode(w); err != nil {
+			err = utils.WrapError("Encode IntegrityProtectionKey", err)
+			return
+		}
+	}
+	return
+}
+func (ie *UPSecuritykey) Decode(r *aper.AperReader) (err error) {
+	if _, err = r.ReadBool(); err != nil {
+		return
+	}
+	var optionals []byte
+	if optionals, err = r.ReadBits(2); err != nil {
+		return
+	}
+	tmp_EncryptionKey := OCTETSTRING{
+		c:   aper.Constraint{Lb: 0, Ub: 0},
+		ext: false,
+	}
+	if err = tmp_EncryptionKey.Decode(r); err != nil {
+		err = utils.WrapError("Read EncryptionKey", err)
+		return
+	}
+	ie.EncryptionKey = tmp_EncryptionKey.Value
+	if aper.IsBitSet(optionals, 1) {
+		tmp_IntegrityProtectionKey := OCTETSTRING{
+			c:   aper.Constraint{Lb: 0, Ub: 0},
+			ext: false,
+		}
+		if err = tmp_IntegrityProtectionKey.Decode(r); err != nil {
+			err = utils.WrapError("Read IntegrityProtectionKey", err)
+			return
+		}
+		ie.IntegrityProtectionKey = tmp_IntegrityProtectionKey.Value
+	}
+	if aper.IsBitSet(optionals, 2) {
+		if err = skipExtensions(r); err != nil {
+			err = utils.WrapError("Read IEExtensions", err)
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,51 @@
+package ies
+
+import (
+	"fmt"
+
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+const (
+	UPTNLInformationPresentNothing uint64 = iota
+	UPTNLInformationPresentGTPTunnel
+	UPTNLInformationPresentChoiceExtension
+)
+
+type UPTNLInformation struct {
+	Choice    uint64
+	GTPTunnel *GTPTunnel
+	// ChoiceExtension
+}
+
+func (ie *UPTNLInformation) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteChoice(ie.Choice, 1, false); err != nil {
+		return
+	}
+	switch ie.Choice {
+	case UPTNLInformationPresentGTPTunnel:
+		err = ie.GTPTunnel.Encode(w)
+	default:
+		err = fmt.Errorf("Encode UPTNLInformation: unsupported choice %d", ie.Choice)
+	}
+	return
+}
+
+func (ie *UPTNLInformation) Decode(r *aper.AperReader) (err error) {
+	if ie.Choice, err = r.ReadChoice(1, false); err != nil {
+		return
+	}
+	switch ie.Choice {
+	case UPTNLInformationPresentGTPTunnel:
+		var tmp GTPTunnel
+		if err = tmp.Decode(r); err != nil {
+			err = utils.WrapError("Read GTPTunnel", err)
+			return
+		}
+		ie.GTPTunnel = &tmp
+	default:
+		err = fmt.Errorf("Decode UPTNLInformation: unsupported choice %d", ie.Choice)
+	}
+	return
+}
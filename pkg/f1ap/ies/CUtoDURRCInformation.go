@@ -0,0 +1,70 @@
+package ies
+
+import (
+	f1ies "github.com/JocelynWS/f1-gen/ies"
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+// CUtoDURRCInformation adds the HandoverPreparationInformation extension to
+// the f1-gen IE, it gives the target DU of a handover the UE configuration.
+type CUtoDURRCInformation struct {
+	CGConfigInfo                   []byte
+	UECapabilityRATContainerList   []byte
+	MeasConfig                     []byte
+	HandoverPreparationInformation []byte
+}
+
+func (ie *CUtoDURRCInformation) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	optionals := []byte{0x0}
+	if ie.CGConfigInfo != nil {
+		aper.SetBit(optionals, 1)
+	}
+	if ie.UECapabilityRATContainerList != nil {
+		aper.SetBit(optionals, 2)
+	}
+	if ie.MeasConfig != nil {
+		aper.SetBit(optionals, 3)
+	}
+	if ie.HandoverPreparationInformation != nil {
+		aper.SetBit(optionals, 4)
+	}
+	w.WriteBits(optionals, 4)
+	if ie.CGConfigInfo != nil {
+		tmp := f1ies.NewOCTETSTRING(ie.CGConfigInfo, aper.Constraint{Lb: 0, Ub: 0}, false)
+		if err = tmp.Encode(w); err != nil {
+			err = utils.WrapError("Encode CGConfigInfo", err)
+			return
+		}
+	}
+	if ie.UECapabilityRATContainerList != nil {
+		tmp := f1ies.NewOCTETSTRING(ie.UECapabilityRATContainerList, aper.Constraint{Lb: 0, Ub: 0}, false)
+		if err = tmp.Encode(w); err != nil {
+			err = utils.WrapError("Encode UECapabilityRATContainerList", err)
+			return
+		}
+	}
+	if ie.MeasConfig != nil {
+		tmp := f1ies.NewOCTETSTRING(ie.MeasConfig, aper.Constraint{Lb: 0, Ub: 0}, false)
+		if err = tmp.Encode(w); err != nil {
+			err = utils.WrapError("Encode MeasConfig", err)
+			return
+		}
+	}
+	if ie.HandoverPreparationInformation != nil {
+		tmp := f1ies.NewOCTETSTRING(ie.HandoverPreparationInformation, aper.Constraint{Lb: 0, Ub: 0}, false)
+		extensions := []*extensionField{{
+			id:          f1ies.ProtocolIEID_HandoverPreparationInformation,
+			criticality: f1ies.Criticality_PresentIgnore,
+			value:       &tmp,
+		}}
+		if err = aper.WriteSequenceOf[*extensionField](extensions, w, &aper.Constraint{Lb: 1, Ub: maxProtocolExtensions}, false); err != nil {
+			err = utils.WrapError("Encode IEExtensions", err)
+			return
+		}
+	}
+	return
+}
@@ -0,0 +1,62 @@
+package ies
+
+import (
+	f1ies "github.com/JocelynWS/f1-gen/ies"
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+// CellstobeActivatedListItem adds the gNB-CU System Information extension to
+// the f1-gen item, it carries the SIBs the CU-CP provides for the cell.
+type CellstobeActivatedListItem struct {
+	NRCGI                  f1ies.NRCGI
+	NRPCI                  *f1ies.NRPCI
+	GNBCUSystemInformation *f1ies.GNBCUSystemInformation
+}
+
+func (ie *CellstobeActivatedListItem) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	optionals := []byte{0x0}
+	if ie.NRPCI != nil {
+		aper.SetBit(optionals, 1)
+	}
+	if ie.GNBCUSystemInformation != nil {
+		aper.SetBit(optionals, 2)
+	}
+	w.WriteBits(optionals, 2)
+	if err = ie.NRCGI.Encode(w); err != nil {
+		err = utils.WrapError("Encode NRCGI", err)
+		return
+	}
+	if ie.NRPCI != nil {
+		if err = ie.NRPCI.Encode(w); err != nil {
+			err = utils.WrapError("Encode NRPCI", err)
+			return
+		}
+	}
+	if ie.GNBCUSystemInformation != nil {
+		extensions := []*extensionField{{
+			id:          f1ies.ProtocolIEID_gNBCUSystemInformation,
+			criticality: f1ies.Criticality_PresentReject,
+			value:       ie.GNBCUSystemInformation,
+		}}
+		if err = aper.WriteSequenceOf[*extensionField](extensions, w, &aper.Constraint{Lb: 1, Ub: maxProtocolExtensions}, false); err != nil {
+			err = utils.WrapError("Encode IEExtensions", err)
+			return
+		}
+	}
+	return
+}
+
+// extensionField is a ProtocolExtensionField, encoded like a single container
+type extensionField struct {
+	id          int64
+	criticality aper.Enumerated
+	value       aper.AperMarshaller
+}
+
+func (ie *extensionField) Encode(w *aper.AperWriter) error {
+	return encodeSingleContainer(w, ie.id, ie.criticality, ie.value)
+}
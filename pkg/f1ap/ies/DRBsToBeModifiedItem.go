@@ -0,0 +1,62 @@
+package ies
+
+import (
+	f1ies "github.com/JocelynWS/f1-gen/ies"
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type DRBsToBeModifiedItem struct {
+	DRBID                           int64
+	QoSInformation                  *QoSInformation
+	ULUPTNLInformationToBeSetupList []f1ies.ULUPTNLInformationToBeSetupItem
+	ULConfiguration                 *f1ies.ULConfiguration
+}
+
+func (ie *DRBsToBeModifiedItem) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	optionals := []byte{0x0}
+	if ie.QoSInformation != nil {
+		aper.SetBit(optionals, 1)
+	}
+	if ie.ULConfiguration != nil {
+		aper.SetBit(optionals, 2)
+	}
+	w.WriteBits(optionals, 3)
+	tmp_DRBID := f1ies.NewINTEGER(ie.DRBID, aper.Constraint{Lb: 1, Ub: 32}, false)
+	if err = tmp_DRBID.Encode(w); err != nil {
+		err = utils.WrapError("Encode DRBID", err)
+		return
+	}
+	if ie.QoSInformation != nil {
+		if err = ie.QoSInformation.Encode(w); err != nil {
+			err = utils.WrapError("Encode QoSInformation", err)
+			return
+		}
+	}
+	if len(ie.ULUPTNLInformationToBeSetupList) > 0 {
+		tmp := sequence[*f1ies.ULUPTNLInformationToBeSetupItem]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofULUPTNLInformation},
+			ext: false,
+		}
+		for _, i := range ie.ULUPTNLInformationToBeSetupList {
+			tmp.Value = append(tmp.Value, &i)
+		}
+		if err = tmp.Encode(w); err != nil {
+			err = utils.WrapError("Encode ULUPTNLInformationToBeSetupList", err)
+			return
+		}
+	} else {
+		err = utils.WrapError("ULUPTNLInformationToBeSetupList is nil", err)
+		return
+	}
+	if ie.ULConfiguration != nil {
+		if err = ie.ULConfiguration.Encode(w); err != nil {
+			err = utils.WrapError("Encode ULConfiguration", err)
+			return
+		}
+	}
+	return
+}
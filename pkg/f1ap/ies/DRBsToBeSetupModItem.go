@@ -0,0 +1,72 @@
+package ies
+
+import (
+	f1ies "github.com/JocelynWS/f1-gen/ies"
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+type DRBsToBeSetupModItem struct {
+	DRBID                           int64
+	QoSInformation                  QoSInformation
+	ULUPTNLInformationToBeSetupList []f1ies.ULUPTNLInformationToBeSetupItem
+	RLCMode                         f1ies.RLCMode
+	ULConfiguration                 *f1ies.ULConfiguration
+	DuplicationActivation           *f1ies.DuplicationActivation
+}
+
+func (ie *DRBsToBeSetupModItem) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteBool(aper.Zero); err != nil {
+		return
+	}
+	optionals := []byte{0x0}
+	if ie.ULConfiguration != nil {
+		aper.SetBit(optionals, 1)
+	}
+	if ie.DuplicationActivation != nil {
+		aper.SetBit(optionals, 2)
+	}
+	w.WriteBits(optionals, 3)
+	tmp_DRBID := f1ies.NewINTEGER(ie.DRBID, aper.Constraint{Lb: 1, Ub: 32}, false)
+	if err = tmp_DRBID.Encode(w); err != nil {
+		err = utils.WrapError("Encode DRBID", err)
+		return
+	}
+	if err = ie.QoSInformation.Encode(w); err != nil {
+		err = utils.WrapError("Encode QoSInformation", err)
+		return
+	}
+	if len(ie.ULUPTNLInformationToBeSetupList) > 0 {
+		tmp := sequence[*f1ies.ULUPTNLInformationToBeSetupItem]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofULUPTNLInformation},
+			ext: false,
+		}
+		for _, i := range ie.ULUPTNLInformationToBeSetupList {
+			tmp.Value = append(tmp.Value, &i)
+		}
+		if err = tmp.Encode(w); err != nil {
+			err = utils.WrapError("Encode ULUPTNLInformationToBeSetupList", err)
+			return
+		}
+	} else {
+		err = utils.WrapError("ULUPTNLInformationToBeSetupList is nil", err)
+		return
+	}
+	if err = ie.RLCMode.Encode(w); err != nil {
+		err = utils.WrapError("Encode RLCMode", err)
+		return
+	}
+	if ie.ULConfiguration != nil {
+		if err = ie.ULConfiguration.Encode(w); err != nil {
+			err = utils.WrapError("Encode ULConfiguration", err)
+			return
+		}
+	}
+	if ie.DuplicationActivation != nil {
+		if err = ie.DuplicationActivation.Encode(w); err != nil {
+			err = utils.WrapError("Encode DuplicationActivation", err)
+			return
+		}
+	}
+	return
+}
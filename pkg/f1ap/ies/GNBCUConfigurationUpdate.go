@@ -0,0 +1,64 @@
+package ies
+
+import (
+	"fmt"
+	"io"
+
+	f1ies "github.com/JocelynWS/f1-gen/ies"
+	"github.com/lvdund/ngap/aper"
+)
+
+// GNBCUConfigurationUpdate is the cell part of the f1-gen message, with cells
+// to activate that may carry SIBs.
+type GNBCUConfigurationUpdate struct {
+	TransactionID            int64
+	CellstobeActivatedList   []CellstobeActivatedListItem
+	CellstobeDeactivatedList []f1ies.CellsToBeDeactivatedListItem
+}
+
+func (msg *GNBCUConfigurationUpdate) Encode(w io.Writer) (err error) {
+	var ies []f1ies.F1apMessageIE
+	if ies, err = msg.toIes(); err != nil {
+		err = msgErrors(fmt.Errorf("GNBCUConfigurationUpdate"), err)
+		return
+	}
+	return encodeMessage(w, f1ies.F1apPduInitiatingMessage, f1ies.ProcedureCode_GNBCUConfigurationUpdate, f1ies.Criticality_PresentReject, ies)
+}
+func (msg *GNBCUConfigurationUpdate) toIes() (ies []f1ies.F1apMessageIE, err error) {
+	ies = []f1ies.F1apMessageIE{}
+	transactionId := f1ies.NewINTEGER(msg.TransactionID, aper.Constraint{Lb: 0, Ub: 255}, false)
+	ies = append(ies, f1ies.F1apMessageIE{
+		Id:          f1ies.ProtocolIEID{Value: f1ies.ProtocolIEID_TransactionID},
+		Criticality: f1ies.Criticality{Value: f1ies.Criticality_PresentReject},
+		Value:       &transactionId,
+	})
+	if len(msg.CellstobeActivatedList) > 0 {
+		tmp_CellstobeActivatedList := sequence[*CellstobeActivatedListItem]{
+			c:   aper.Constraint{Lb: 1, Ub: maxCellingNBDU},
+			ext: true,
+		}
+		for _, i := range msg.CellstobeActivatedList {
+			tmp_CellstobeActivatedList.Value = append(tmp_CellstobeActivatedList.Value, &i)
+		}
+		ies = append(ies, f1ies.F1apMessageIE{
+			Id:          f1ies.ProtocolIEID{Value: f1ies.ProtocolIEID_CellstobeActivatedList},
+			Criticality: f1ies.Criticality{Value: f1ies.Criticality_PresentReject},
+			Value:       &tmp_CellstobeActivatedList,
+		})
+	}
+	if len(msg.CellstobeDeactivatedList) > 0 {
+		tmp_CellstobeDeactivatedList := sequence[*f1ies.CellsToBeDeactivatedListItem]{
+			c:   aper.Constraint{Lb: 1, Ub: maxCellingNBDU},
+			ext: true,
+		}
+		for _, i := range msg.CellstobeDeactivatedList {
+			tmp_CellstobeDeactivatedList.Value = append(tmp_CellstobeDeactivatedList.Value, &i)
+		}
+		ies = append(ies, f1ies.F1apMessageIE{
+			Id:          f1ies.ProtocolIEID{Value: f1ies.ProtocolIEID_CellsToBeDeactivatedList},
+			Criticality: f1ies.Criticality{Value: f1ies.Criticality_PresentReject},
+			Value:       &tmp_CellstobeDeactivatedList,
+		})
+	}
+	return
+}
@@ -0,0 +1,63 @@
+package ies
+
+import (
+	"bytes"
+	"fmt"
+
+	f1ies "github.com/JocelynWS/f1-gen/ies"
+	"github.com/lvdund/ngap/aper"
+	"github.com/reogac/utils"
+)
+
+const (
+	QoSInformationPresentNothing uint64 = iota
+	QoSInformationPresentEUTRANQoS
+	QoSInformationPresentDRBInformation
+)
+
+// QoSInformation extends the f1-gen choice with the NR DRB-Information,
+// which F1AP carries in the choice-extension container.
+type QoSInformation struct {
+	Choice         uint64
+	EUTRANQoS      *f1ies.EUTRANQoS
+	DRBInformation *f1ies.DRBInformation
+}
+
+func (ie *QoSInformation) Encode(w *aper.AperWriter) (err error) {
+	if err = w.WriteChoice(ie.Choice, 1, false); err != nil {
+		return
+	}
+	switch ie.Choice {
+	case QoSInformationPresentEUTRANQoS:
+		err = ie.EUTRANQoS.Encode(w)
+	case QoSInformationPresentDRBInformation:
+		err = encodeSingleContainer(w, f1ies.ProtocolIEID_DRBInformation, f1ies.Criticality_PresentIgnore, ie.DRBInformation)
+	default:
+		err = fmt.Errorf("Encode QoSInformation: unsupported choice %d", ie.Choice)
+	}
+	return
+}
+
+// encodeSingleContainer writes a ProtocolIE-SingleContainer: the IE id, its
+// criticality and the value as an open type.
+func encodeSingleContainer(w *aper.AperWriter, id int64, criticality aper.Enumerated, value aper.AperMarshaller) (err error) {
+	pId := f1ies.ProtocolIEID{Value: aper.Integer(id)}
+	if err = pId.Encode(w); err != nil {
+		return
+	}
+	cr := f1ies.Criticality{Value: criticality}
+	if err = cr.Encode(w); err != nil {
+		return
+	}
+	var buf bytes.Buffer
+	ieW := aper.NewWriter(&buf)
+	if err = value.Encode(ieW); err != nil {
+		err = utils.WrapError(fmt.Sprintf("Encode IE %d", id), err)
+		return
+	}
+	if err = ieW.Close(); err != nil {
+		return
+	}
+	err = w.WriteOpenType(buf.Bytes())
+	return
+}
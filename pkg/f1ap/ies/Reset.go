@@ -0,0 +1,68 @@
+package ies
+
+import (
+	"fmt"
+	"io"
+
+	f1ies "github.com/JocelynWS/f1-gen/ies"
+	"github.com/lvdund/ngap/aper"
+)
+
+// Reset is the f1-gen message with the UE-associated connections to reset
+// encoded under the size constraint the f1-gen decoder reads them with; the
+// f1-gen encoder leaves the constraint empty.
+type Reset struct {
+	TransactionID int64
+	Cause         f1ies.Cause
+	ResetType     f1ies.ResetType
+}
+
+func (msg *Reset) Encode(w io.Writer) (err error) {
+	var ies []f1ies.F1apMessageIE
+	if ies, err = msg.toIes(); err != nil {
+		err = msgErrors(fmt.Errorf("Reset"), err)
+		return
+	}
+	return encodeMessage(w, f1ies.F1apPduInitiatingMessage, f1ies.ProcedureCode_Reset, f1ies.Criticality_PresentReject, ies)
+}
+func (msg *Reset) toIes() (ies []f1ies.F1apMessageIE, err error) {
+	ies = []f1ies.F1apMessageIE{}
+	transactionId := f1ies.NewINTEGER(msg.TransactionID, aper.Constraint{Lb: 0, Ub: 255}, false)
+	ies = append(ies, f1ies.F1apMessageIE{
+		Id:          f1ies.ProtocolIEID{Value: f1ies.ProtocolIEID_TransactionID},
+		Criticality: f1ies.Criticality{Value: f1ies.Criticality_PresentReject},
+		Value:       &transactionId,
+	})
+	ies = append(ies, f1ies.F1apMessageIE{
+		Id:          f1ies.ProtocolIEID{Value: f1ies.ProtocolIEID_Cause},
+		Criticality: f1ies.Criticality{Value: f1ies.Criticality_PresentIgnore},
+		Value:       &msg.Cause,
+	})
+	ies = append(ies, f1ies.F1apMessageIE{
+		Id:          f1ies.ProtocolIEID{Value: f1ies.ProtocolIEID_ResetType},
+		Criticality: f1ies.Criticality{Value: f1ies.Criticality_PresentReject},
+		Value:       &resetType{msg.ResetType},
+	})
+	return
+}
+
+type resetType struct {
+	f1ies.ResetType
+}
+
+func (ie *resetType) Encode(w *aper.AperWriter) (err error) {
+	if ie.Choice != f1ies.ResetTypePresentPartOfF1Interface {
+		return ie.ResetType.Encode(w)
+	}
+	if err = w.WriteChoice(ie.Choice, 2, false); err != nil {
+		return
+	}
+	tmp_PartOfF1Interface := sequence[*f1ies.UEAssociatedLogicalF1ConnectionItemRes]{
+		c:   aper.Constraint{Lb: 0, Ub: maxnoofIndividualF1ConnectionsToReset - 1},
+		ext: false,
+	}
+	for _, i := range ie.PartOfF1Interface {
+		tmp_PartOfF1Interface.Value = append(tmp_PartOfF1Interface.Value, &i)
+	}
+	return tmp_PartOfF1Interface.Encode(w)
+}
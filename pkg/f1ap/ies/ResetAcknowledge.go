@@ -0,0 +1,49 @@
+package ies
+
+import (
+	"fmt"
+	"io"
+
+	f1ies "github.com/JocelynWS/f1-gen/ies"
+	"github.com/lvdund/ngap/aper"
+)
+
+// ResetAcknowledge is the f1-gen message with the Transaction ID and the list
+// of reset UE-associated connections, both missing from f1-gen.
+type ResetAcknowledge struct {
+	TransactionID                             int64
+	UEAssociatedLogicalF1ConnectionListResAck []f1ies.UEAssociatedLogicalF1ConnectionItemResAck
+}
+
+func (msg *ResetAcknowledge) Encode(w io.Writer) (err error) {
+	var ies []f1ies.F1apMessageIE
+	if ies, err = msg.toIes(); err != nil {
+		err = msgErrors(fmt.Errorf("ResetAcknowledge"), err)
+		return
+	}
+	return encodeMessage(w, f1ies.F1apPduSuccessfulOutcome, f1ies.ProcedureCode_Reset, f1ies.Criticality_PresentReject, ies)
+}
+func (msg *ResetAcknowledge) toIes() (ies []f1ies.F1apMessageIE, err error) {
+	ies = []f1ies.F1apMessageIE{}
+	transactionId := f1ies.NewINTEGER(msg.TransactionID, aper.Constraint{Lb: 0, Ub: 255}, false)
+	ies = append(ies, f1ies.F1apMessageIE{
+		Id:          f1ies.ProtocolIEID{Value: f1ies.ProtocolIEID_TransactionID},
+		Criticality: f1ies.Criticality{Value: f1ies.Criticality_PresentReject},
+		Value:       &transactionId,
+	})
+	if len(msg.UEAssociatedLogicalF1ConnectionListResAck) > 0 {
+		tmp_ConnectionList := sequence[*f1ies.UEAssociatedLogicalF1ConnectionItemResAck]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofIndividualF1ConnectionsToReset},
+			ext: false,
+		}
+		for _, i := range msg.UEAssociatedLogicalF1ConnectionListResAck {
+			tmp_ConnectionList.Value = append(tmp_ConnectionList.Value, &i)
+		}
+		ies = append(ies, f1ies.F1apMessageIE{
+			Id:          f1ies.ProtocolIEID{Value: f1ies.ProtocolIEID_UEAssociatedLogicalF1ConnectionListResAck},
+			Criticality: f1ies.Criticality{Value: f1ies.Criticality_PresentIgnore},
+			Value:       &tmp_ConnectionList,
+		})
+	}
+	return
+}
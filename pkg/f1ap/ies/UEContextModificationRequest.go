@@ -0,0 +1,103 @@
+package ies
+
+import (
+	"fmt"
+	"io"
+
+	f1ies "github.com/JocelynWS/f1-gen/ies"
+	"github.com/lvdund/ngap/aper"
+)
+
+// UEContextModificationRequest is the subset of the f1-gen message the CU-CP
+// sends, with DRBs that may carry the NR DRB-Information.
+type UEContextModificationRequest struct {
+	GNBCUUEF1APID               int64
+	GNBDUUEF1APID               int64
+	TransmissionActionIndicator *f1ies.TransmissionActionIndicator
+	RRCContainer                []byte
+	DRBsToBeSetupModList        []DRBsToBeSetupModItem
+	DRBsToBeModifiedList        []DRBsToBeModifiedItem
+	DRBsToBeReleasedList        []f1ies.DRBsToBeReleasedItem
+}
+
+func (msg *UEContextModificationRequest) Encode(w io.Writer) (err error) {
+	var ies []f1ies.F1apMessageIE
+	if ies, err = msg.toIes(); err != nil {
+		err = msgErrors(fmt.Errorf("UEContextModificationRequest"), err)
+		return
+	}
+	return encodeMessage(w, f1ies.F1apPduInitiatingMessage, f1ies.ProcedureCode_UEContextModification, f1ies.Criticality_PresentReject, ies)
+}
+func (msg *UEContextModificationRequest) toIes() (ies []f1ies.F1apMessageIE, err error) {
+	ies = []f1ies.F1apMessageIE{}
+	cuUeF1apId := f1ies.NewINTEGER(msg.GNBCUUEF1APID, aper.Constraint{Lb: 0, Ub: 4294967295}, false)
+	ies = append(ies, f1ies.F1apMessageIE{
+		Id:          f1ies.ProtocolIEID{Value: f1ies.ProtocolIEID_GNBCUUEF1APID},
+		Criticality: f1ies.Criticality{Value: f1ies.Criticality_PresentReject},
+		Value:       &cuUeF1apId,
+	})
+	duUeF1apId := f1ies.NewINTEGER(msg.GNBDUUEF1APID, aper.Constraint{Lb: 0, Ub: 4294967295}, false)
+	ies = append(ies, f1ies.F1apMessageIE{
+		Id:          f1ies.ProtocolIEID{Value: f1ies.ProtocolIEID_GNBDUUEF1APID},
+		Criticality: f1ies.Criticality{Value: f1ies.Criticality_PresentReject},
+		Value:       &duUeF1apId,
+	})
+	if msg.TransmissionActionIndicator != nil {
+		ies = append(ies, f1ies.F1apMessageIE{
+			Id:          f1ies.ProtocolIEID{Value: f1ies.ProtocolIEID_TransmissionActionIndicator},
+			Criticality: f1ies.Criticality{Value: f1ies.Criticality_PresentIgnore},
+			Value:       msg.TransmissionActionIndicator,
+		})
+	}
+	if msg.RRCContainer != nil {
+		rrcContainer := f1ies.NewOCTETSTRING(msg.RRCContainer, aper.Constraint{Lb: 0, Ub: 0}, false)
+		ies = append(ies, f1ies.F1apMessageIE{
+			Id:          f1ies.ProtocolIEID{Value: f1ies.ProtocolIEID_RRCContainer},
+			Criticality: f1ies.Criticality{Value: f1ies.Criticality_PresentReject},
+			Value:       &rrcContainer,
+		})
+	}
+	if len(msg.DRBsToBeSetupModList) > 0 {
+		tmp_DRBsToBeSetupModList := sequence[*DRBsToBeSetupModItem]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofDRBs},
+			ext: false,
+		}
+		for _, i := range msg.DRBsToBeSetupModList {
+			tmp_DRBsToBeSetupModList.Value = append(tmp_DRBsToBeSetupModList.Value, &i)
+		}
+		ies = append(ies, f1ies.F1apMessageIE{
+			Id:          f1ies.ProtocolIEID{Value: f1ies.ProtocolIEID_DRBsToBeSetupModList},
+			Criticality: f1ies.Criticality{Value: f1ies.Criticality_PresentReject},
+			Value:       &tmp_DRBsToBeSetupModList,
+		})
+	}
+	if len(msg.DRBsToBeModifiedList) > 0 {
+		tmp_DRBsToBeModifiedList := sequence[*DRBsToBeModifiedItem]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofDRBs},
+			ext: false,
+		}
+		for _, i := range msg.DRBsToBeModifiedList {
+			tmp_DRBsToBeModifiedList.Value = append(tmp_DRBsToBeModifiedList.Value, &i)
+		}
+		ies = append(ies, f1ies.F1apMessageIE{
+			Id:          f1ies.ProtocolIEID{Value: f1ies.ProtocolIEID_DRBsToBeModifiedList},
+			Criticality: f1ies.Criticality{Value: f1ies.Criticality_PresentReject},
+			Value:       &tmp_DRBsToBeModifiedList,
+		})
+	}
+	if len(msg.DRBsToBeReleasedList) > 0 {
+		tmp_DRBsToBeReleasedList := sequence[*f1ies.DRBsToBeReleasedItem]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofDRBs},
+			ext: false,
+		}
+		for _, i := range msg.DRBsToBeReleasedList {
+			tmp_DRBsToBeReleasedList.Value = append(tmp_DRBsToBeReleasedList.Value, &i)
+		}
+		ies = append(ies, f1ies.F1apMessageIE{
+			Id:          f1ies.ProtocolIEID{Value: f1ies.ProtocolIEID_DRBsToBeReleasedList},
+			Criticality: f1ies.Criticality{Value: f1ies.Criticality_PresentReject},
+			Value:       &tmp_DRBsToBeReleasedList,
+		})
+	}
+	return
+}
@@ -0,0 +1,65 @@
+package ies
+
+import (
+	"fmt"
+	"io"
+
+	f1ies "github.com/JocelynWS/f1-gen/ies"
+	"github.com/lvdund/ngap/aper"
+)
+
+// UEContextReleaseCommand is the f1-gen message without the conditional-
+// handover IEs, which f1-gen wrongly treats as mandatory.
+type UEContextReleaseCommand struct {
+	GNBCUUEF1APID int64
+	GNBDUUEF1APID int64
+	Cause         f1ies.Cause
+	RRCContainer  []byte
+	SRBID         *int64
+}
+
+func (msg *UEContextReleaseCommand) Encode(w io.Writer) (err error) {
+	var ies []f1ies.F1apMessageIE
+	if ies, err = msg.toIes(); err != nil {
+		err = msgErrors(fmt.Errorf("UEContextReleaseCommand"), err)
+		return
+	}
+	return encodeMessage(w, f1ies.F1apPduInitiatingMessage, f1ies.ProcedureCode_UEContextRelease, f1ies.Criticality_PresentReject, ies)
+}
+func (msg *UEContextReleaseCommand) toIes() (ies []f1ies.F1apMessageIE, err error) {
+	ies = []f1ies.F1apMessageIE{}
+	cuUeF1apId := f1ies.NewINTEGER(msg.GNBCUUEF1APID, aper.Constraint{Lb: 0, Ub: 4294967295}, false)
+	ies = append(ies, f1ies.F1apMessageIE{
+		Id:          f1ies.ProtocolIEID{Value: f1ies.ProtocolIEID_GNBCUUEF1APID},
+		Criticality: f1ies.Criticality{Value: f1ies.Criticality_PresentReject},
+		Value:       &cuUeF1apId,
+	})
+	duUeF1apId := f1ies.NewINTEGER(msg.GNBDUUEF1APID, aper.Constraint{Lb: 0, Ub: 4294967295}, false)
+	ies = append(ies, f1ies.F1apMessageIE{
+		Id:          f1ies.ProtocolIEID{Value: f1ies.ProtocolIEID_GNBDUUEF1APID},
+		Criticality: f1ies.Criticality{Value: f1ies.Criticality_PresentReject},
+		Value:       &duUeF1apId,
+	})
+	ies = append(ies, f1ies.F1apMessageIE{
+		Id:          f1ies.ProtocolIEID{Value: f1ies.ProtocolIEID_Cause},
+		Criticality: f1ies.Criticality{Value: f1ies.Criticality_PresentIgnore},
+		Value:       &msg.Cause,
+	})
+	if msg.RRCContainer != nil {
+		rrcContainer := f1ies.NewOCTETSTRING(msg.RRCContainer, aper.Constraint{Lb: 0, Ub: 0}, false)
+		ies = append(ies, f1ies.F1apMessageIE{
+			Id:          f1ies.ProtocolIEID{Value: f1ies.ProtocolIEID_RRCContainer},
+			Criticality: f1ies.Criticality{Value: f1ies.Criticality_PresentIgnore},
+			Value:       &rrcContainer,
+		})
+	}
+	if msg.SRBID != nil {
+		srbId := f1ies.NewINTEGER(*msg.SRBID, aper.Constraint{Lb: 0, Ub: 3}, false)
+		ies = append(ies, f1ies.F1apMessageIE{
+			Id:          f1ies.ProtocolIEID{Value: f1ies.ProtocolIEID_SRBID},
+			Criticality: f1ies.Criticality{Value: f1ies.Criticality_PresentIgnore},
+			Value:       &srbId,
+		})
+	}
+	return
+}
@@ -0,0 +1,105 @@
+package ies
+
+import (
+	"fmt"
+	"io"
+
+	f1ies "github.com/JocelynWS/f1-gen/ies"
+	"github.com/lvdund/ngap/aper"
+)
+
+// DRBsToBeSetupItem has the same structure as DRBsToBeSetupModItem.
+type DRBsToBeSetupItem = DRBsToBeSetupModItem
+
+// UEContextSetupRequest is the subset of the f1-gen message the CU-CP sends
+// to the target DU of a handover, with the HandoverPreparationInformation and
+// DRBs that may carry the NR DRB-Information.
+type UEContextSetupRequest struct {
+	GNBCUUEF1APID        int64
+	GNBDUUEF1APID        *int64
+	SpCellID             f1ies.NRCGI
+	ServCellIndex        int64
+	CUtoDURRCInformation CUtoDURRCInformation
+	SRBsToBeSetupList    []f1ies.SRBsToBeSetupItem
+	DRBsToBeSetupList    []DRBsToBeSetupItem
+	RRCContainer         []byte
+}
+
+func (msg *UEContextSetupRequest) Encode(w io.Writer) (err error) {
+	var ies []f1ies.F1apMessageIE
+	if ies, err = msg.toIes(); err != nil {
+		err = msgErrors(fmt.Errorf("UEContextSetupRequest"), err)
+		return
+	}
+	return encodeMessage(w, f1ies.F1apPduInitiatingMessage, f1ies.ProcedureCode_UEContextSetup, f1ies.Criticality_PresentReject, ies)
+}
+func (msg *UEContextSetupRequest) toIes() (ies []f1ies.F1apMessageIE, err error) {
+	ies = []f1ies.F1apMessageIE{}
+	cuUeF1apId := f1ies.NewINTEGER(msg.GNBCUUEF1APID, aper.Constraint{Lb: 0, Ub: 4294967295}, false)
+	ies = append(ies, f1ies.F1apMessageIE{
+		Id:          f1ies.ProtocolIEID{Value: f1ies.ProtocolIEID_GNBCUUEF1APID},
+		Criticality: f1ies.Criticality{Value: f1ies.Criticality_PresentReject},
+		Value:       &cuUeF1apId,
+	})
+	if msg.GNBDUUEF1APID != nil {
+		duUeF1apId := f1ies.NewINTEGER(*msg.GNBDUUEF1APID, aper.Constraint{Lb: 0, Ub: 4294967295}, false)
+		ies = append(ies, f1ies.F1apMessageIE{
+			Id:          f1ies.ProtocolIEID{Value: f1ies.ProtocolIEID_GNBDUUEF1APID},
+			Criticality: f1ies.Criticality{Value: f1ies.Criticality_PresentIgnore},
+			Value:       &duUeF1apId,
+		})
+	}
+	ies = append(ies, f1ies.F1apMessageIE{
+		Id:          f1ies.ProtocolIEID{Value: f1ies.ProtocolIEID_SpCellID},
+		Criticality: f1ies.Criticality{Value: f1ies.Criticality_PresentReject},
+		Value:       &msg.SpCellID,
+	})
+	servCellIndex := f1ies.NewINTEGER(msg.ServCellIndex, aper.Constraint{Lb: 0, Ub: 31}, false)
+	ies = append(ies, f1ies.F1apMessageIE{
+		Id:          f1ies.ProtocolIEID{Value: f1ies.ProtocolIEID_ServCellIndex},
+		Criticality: f1ies.Criticality{Value: f1ies.Criticality_PresentReject},
+		Value:       &servCellIndex,
+	})
+	ies = append(ies, f1ies.F1apMessageIE{
+		Id:          f1ies.ProtocolIEID{Value: f1ies.ProtocolIEID_CUtoDURRCInformation},
+		Criticality: f1ies.Criticality{Value: f1ies.Criticality_PresentReject},
+		Value:       &msg.CUtoDURRCInformation,
+	})
+	if len(msg.SRBsToBeSetupList) > 0 {
+		tmp_SRBsToBeSetupList := sequence[*f1ies.SRBsToBeSetupItem]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofSRBs},
+			ext: false,
+		}
+		for _, i := range msg.SRBsToBeSetupList {
+			tmp_SRBsToBeSetupList.Value = append(tmp_SRBsToBeSetupList.Value, &i)
+		}
+		ies = append(ies, f1ies.F1apMessageIE{
+			Id:          f1ies.ProtocolIEID{Value: f1ies.ProtocolIEID_SRBsToBeSetupList},
+			Criticality: f1ies.Criticality{Value: f1ies.Criticality_PresentReject},
+			Value:       &tmp_SRBsToBeSetupList,
+		})
+	}
+	if len(msg.DRBsToBeSetupList) > 0 {
+		tmp_DRBsToBeSetupList := sequence[*DRBsToBeSetupItem]{
+			c:   aper.Constraint{Lb: 1, Ub: maxnoofDRBs},
+			ext: false,
+		}
+		for _, i := range msg.DRBsToBeSetupList {
+			tmp_DRBsToBeSetupList.Value = append(tmp_DRBsToBeSetupList.Value, &i)
+		}
+		ies = append(ies, f1ies.F1apMessageIE{
+			Id:          f1ies.ProtocolIEID{Value: f1ies.ProtocolIEID_DRBsToBeSetupList},
+			Criticality: f1ies.Criticality{Value: f1ies.Criticality_PresentReject},
+			Value:       &tmp_DRBsToBeSetupList,
+		})
+	}
+	if msg.RRCContainer != nil {
+		rrcContainer := f1ies.NewOCTETSTRING(msg.RRCContainer, aper.Constraint{Lb: 0, Ub: 0}, false)
+		ies = append(ies, f1ies.F1apMessageIE{
+			Id:          f1ies.ProtocolIEID{Value: f1ies.ProtocolIEID_RRCContainer},
+			Criticality: f1ies.Criticality{Value: f1ies.Criticality_PresentIgnore},
+			Value:       &rrcContainer,
+		})
+	}
+	return
+}
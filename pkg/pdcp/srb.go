@@ -0,0 +1,146 @@
+// Package pdcp implements the CU side of the PDCP entities of the signalling
+// radio bearers, TS 38.323.
+package pdcp
+
+import (
+	"central-unit/pkg/security"
+	"errors"
+	"fmt"
+)
+
+// SRB data PDUs carry a 12-bit SN in a two-octet header and always end with
+// the MAC-I, zeroed while integrity protection is off
+const (
+	srbSnBits     = 12
+	srbHeaderSize = 2
+	srbWindow     = 1 << (srbSnBits - 1)
+	srbSnMask     = 1<<srbSnBits - 1
+)
+
+var ErrIntegrity = errors.New("PDCP integrity verification failed")
+
+// Security holds the algorithms and keys of one direction. Without a key the
+// corresponding protection is off.
+type Security struct {
+	IntegrityAlg uint8
+	IntegrityKey []byte
+	CipheringAlg uint8
+	CipheringKey []byte
+}
+
+// SrbEntity is the PDCP entity of one SRB. The CU-CP transmits downlink and
+// receives uplink.
+type SrbEntity struct {
+	bearer  uint8  // RB identity - 1
+	txNext  uint32 // COUNT of the next PDU sent
+	rxDeliv uint32 // COUNT of the next PDU expected
+
+	Tx Security // applied to downlink PDUs
+	Rx Security // checked on uplink PDUs
+}
+
+func NewSrbEntity(srbId uint8) *SrbEntity {
+	return &SrbEntity{bearer: srbId - 1}
+}
+
+// TxCount is the COUNT the next downlink PDU is sent with
+func (e *SrbEntity) TxCount() uint32 {
+	return e.txNext
+}
+
+// RxCount is the COUNT the next uplink PDU is expected with
+func (e *SrbEntity) RxCount() uint32 {
+	return e.rxDeliv
+}
+
+// Protect builds the PDCP data PDU of an RRC message: the MAC-I covers the
+// header and the message, ciphering covers the message and the MAC-I
+func (e *SrbEntity) Protect(sdu []byte) ([]byte, error) {
+	count := e.txNext
+	pdu := make([]byte, srbHeaderSize, srbHeaderSize+len(sdu)+security.MacSize)
+	pdu[0] = byte(count>>8) & 0x0f
+	pdu[1] = byte(count)
+	pdu = append(pdu, sdu...)
+
+	mac := make([]byte, security.MacSize)
+	if e.Tx.IntegrityKey != nil {
+		var err error
+		mac, err = security.Mac(e.Tx.IntegrityAlg, e.Tx.IntegrityKey, count, e.bearer, security.DirectionDownlink, pdu)
+		if err != nil {
+			return nil, err
+		}
+	}
+	pdu = append(pdu, mac...)
+
+	if e.Tx.CipheringKey != nil {
+		body, err := security.Cipher(e.Tx.CipheringAlg, e.Tx.CipheringKey, count, e.bearer, security.DirectionDownlink, pdu[srbHeaderSize:])
+		if err != nil {
+			return nil, err
+		}
+		copy(pdu[srbHeaderSize:], body)
+	}
+
+	e.txNext++
+	return pdu, nil
+}
+
+// Unprotect deciphers and verifies an uplink PDCP data PDU and returns the
+// RRC message. PDUs failing the integrity check or already received are
+// discarded.
+func (e *SrbEntity) Unprotect(pdu []byte) ([]byte, error) {
+	if len(pdu) < srbHeaderSize+security.MacSize {
+		return nil, fmt.Errorf("PDCP PDU of %d bytes is too short", len(pdu))
+	}
+	count := e.rcvdCount(uint32(pdu[0]&0x0f)<<8 | uint32(pdu[1]))
+	if count < e.rxDeliv {
+		return nil, fmt.Errorf("duplicate PDCP PDU with COUNT %d", count)
+	}
+
+	body := pdu[srbHeaderSize:]
+	if e.Rx.CipheringKey != nil {
+		var err error
+		body, err = security.Cipher(e.Rx.CipheringAlg, e.Rx.CipheringKey, count, e.bearer, security.DirectionUplink, body)
+		if err != nil {
+			return nil, err
+		}
+	}
+	sduLen := len(body) - security.MacSize
+
+	if e.Rx.IntegrityKey != nil {
+		protected := make([]byte, 0, srbHeaderSize+sduLen)
+		protected = append(protected, pdu[:srbHeaderSize]...)
+		protected = append(protected, body[:sduLen]...)
+		xmac, err := security.Mac(e.Rx.IntegrityAlg, e.Rx.IntegrityKey, count, e.bearer, security.DirectionUplink, protected)
+		if err != nil {
+			return nil, err
+		}
+		if string(xmac) != string(body[sduLen:]) {
+			return nil, fmt.Errorf("%w: COUNT %d on bearer %d", ErrIntegrity, count, e.bearer)
+		}
+	}
+
+	e.rxDeliv = count + 1
+	return body[:sduLen], nil
+}
+
+// rcvdCount places the received SN in the HFN window around RX_DELIV
+func (e *SrbEntity) rcvdCount(sn uint32) uint32 {
+	hfn := e.rxDeliv >> srbSnBits
+	delivSn := e.rxDeliv & srbSnMask
+	switch {
+	case int64(sn) < int64(delivSn)-srbWindow:
+		hfn++
+	case sn >= delivSn+srbWindow && hfn > 0:
+		hfn--
+	}
+	return hfn<<srbSnBits | sn
+}
+
+// SrbPayload returns the RRC message of a PDU sent without any protection,
+// such as a Security Mode Failure
+func SrbPayload(pdu []byte) ([]byte, error) {
+	if len(pdu) < srbHeaderSize+security.MacSize {
+		return nil, fmt.Errorf("PDCP PDU of %d bytes is too short", len(pdu))
+	}
+	return pdu[srbHeaderSize : len(pdu)-security.MacSize], nil
+}
@@ -0,0 +1,45 @@
+// Package ies holds the RRC IEs that github.com/lvdund/rrc cannot encode
+// right. Everything else is taken from the rrc library as is: the IEs defined
+// here wrap the library types and only replace the encoding of the parts it
+// gets wrong.
+package ies
+
+import (
+	"bytes"
+
+	"github.com/lvdund/asn1go/aper"
+)
+
+// Encoder is an IE or message of this package
+type Encoder interface {
+	Encode(w *aper.AperWriter) error
+}
+
+// Encode encodes an IE or message on its own, padded to a whole number of
+// octets
+func Encode(ie Encoder) ([]byte, error) {
+	var buf bytes.Buffer
+	w := aper.NewWriter(&buf)
+	if err := ie.Encode(w); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// writeExtensibleChoice writes the index of a root alternative of a CHOICE
+// with an extension marker, which the rrc library writes without the
+// extension bit and counting the extension additions
+func writeExtensibleChoice(w *aper.AperWriter, choice uint64, rootAlternatives uint64) error {
+	if err := w.WriteBool(false); err != nil {
+		return err
+	}
+	return w.WriteChoice(choice, rootAlternatives, false)
+}
+
+// writeSize writes the number of items of a SEQUENCE OF with SIZE(1..ub)
+func writeSize(w *aper.AperWriter, n int, ub int64) error {
+	return w.WriteInteger(int64(n), &aper.Constraint{Lb: 1, Ub: ub}, false)
+}
@@ -0,0 +1,217 @@
+package ies
+
+import (
+	"fmt"
+
+	"github.com/lvdund/asn1go/aper"
+	rrcies "github.com/lvdund/rrc/ies"
+	"github.com/reogac/utils"
+)
+
+const (
+	maxNrofObjectId        = 64
+	maxReportConfigId      = 64
+	maxCellReport          = 8
+	maxNrofIndexesToReport = 32
+)
+
+// MeasConfig is the rrc library measConfig for NR measurement objects and
+// event triggered NR report configurations. The library encodes the CHOICEs
+// of measObject, reportConfig, reportType and eventId as if they had no
+// extension marker, and leaves out everything of EventTriggerConfig after
+// eventId.
+type MeasConfig struct {
+	*rrcies.MeasConfig
+}
+
+func (ie *MeasConfig) Encode(w *aper.AperWriter) (err error) {
+	if ie.InterFrequencyConfig_NoGap_r16 != nil {
+		return fmt.Errorf("interFrequencyConfig-NoGap-r16 is not supported")
+	}
+	preambleBits := []bool{false, ie.MeasObjectToRemoveList != nil, ie.MeasObjectToAddModList != nil,
+		ie.ReportConfigToRemoveList != nil, ie.ReportConfigToAddModList != nil, ie.MeasIdToRemoveList != nil,
+		ie.MeasIdToAddModList != nil, ie.S_MeasureConfig != nil, ie.QuantityConfig != nil,
+		ie.MeasGapConfig != nil, ie.MeasGapSharingConfig != nil}
+	for _, bit := range preambleBits {
+		if err = w.WriteBool(bit); err != nil {
+			return
+		}
+	}
+	if ie.MeasObjectToRemoveList != nil {
+		if err = ie.MeasObjectToRemoveList.Encode(w); err != nil {
+			return utils.WrapError("Encode MeasObjectToRemoveList", err)
+		}
+	}
+	if ie.MeasObjectToAddModList != nil {
+		if err = writeSize(w, len(ie.MeasObjectToAddModList.Value), maxNrofObjectId); err != nil {
+			return utils.WrapError("Encode MeasObjectToAddModList", err)
+		}
+		for i := range ie.MeasObjectToAddModList.Value {
+			if err = encodeMeasObjectToAddMod(w, &ie.MeasObjectToAddModList.Value[i]); err != nil {
+				return utils.WrapError("Encode MeasObjectToAddModList", err)
+			}
+		}
+	}
+	if ie.ReportConfigToRemoveList != nil {
+		if err = ie.ReportConfigToRemoveList.Encode(w); err != nil {
+			return utils.WrapError("Encode ReportConfigToRemoveList", err)
+		}
+	}
+	if ie.ReportConfigToAddModList != nil {
+		if err = writeSize(w, len(ie.ReportConfigToAddModList.Value), maxReportConfigId); err != nil {
+			return utils.WrapError("Encode ReportConfigToAddModList", err)
+		}
+		for i := range ie.ReportConfigToAddModList.Value {
+			if err = encodeReportConfigToAddMod(w, &ie.ReportConfigToAddModList.Value[i]); err != nil {
+				return utils.WrapError("Encode ReportConfigToAddModList", err)
+			}
+		}
+	}
+	if ie.MeasIdToRemoveList != nil {
+		if err = ie.MeasIdToRemoveList.Encode(w); err != nil {
+			return utils.WrapError("Encode MeasIdToRemoveList", err)
+		}
+	}
+	if ie.MeasIdToAddModList != nil {
+		if err = ie.MeasIdToAddModList.Encode(w); err != nil {
+			return utils.WrapError("Encode MeasIdToAddModList", err)
+		}
+	}
+	if ie.S_MeasureConfig != nil {
+		if err = ie.S_MeasureConfig.Encode(w); err != nil {
+			return utils.WrapError("Encode S_MeasureConfig", err)
+		}
+	}
+	if ie.QuantityConfig != nil {
+		if err = ie.QuantityConfig.Encode(w); err != nil {
+			return utils.WrapError("Encode QuantityConfig", err)
+		}
+	}
+	if ie.MeasGapConfig != nil {
+		if err = ie.MeasGapConfig.Encode(w); err != nil {
+			return utils.WrapError("Encode MeasGapConfig", err)
+		}
+	}
+	if ie.MeasGapSharingConfig != nil {
+		if err = ie.MeasGapSharingConfig.Encode(w); err != nil {
+			return utils.WrapError("Encode MeasGapSharingConfig", err)
+		}
+	}
+	return nil
+}
+
+func encodeMeasObjectToAddMod(w *aper.AperWriter, ie *rrcies.MeasObjectToAddMod) (err error) {
+	if ie.MeasObject.Choice != rrcies.MeasObjectToAddMod_measObject_Choice_MeasObjectNR || ie.MeasObject.MeasObjectNR == nil {
+		return fmt.Errorf("measObject %d is not supported", ie.MeasObject.Choice)
+	}
+	if err = ie.MeasObjectId.Encode(w); err != nil {
+		return utils.WrapError("Encode MeasObjectId", err)
+	}
+	// measObjectNR, measObjectEUTRA, ...
+	if err = writeExtensibleChoice(w, ie.MeasObject.Choice, 2); err != nil {
+		return utils.WrapError("Encode MeasObject", err)
+	}
+	if err = ie.MeasObject.MeasObjectNR.Encode(w); err != nil {
+		return utils.WrapError("Encode MeasObjectNR", err)
+	}
+	return nil
+}
+
+func encodeReportConfigToAddMod(w *aper.AperWriter, ie *rrcies.ReportConfigToAddMod) (err error) {
+	if ie.ReportConfig.Choice != rrcies.ReportConfigToAddMod_reportConfig_Choice_ReportConfigNR || ie.ReportConfig.ReportConfigNR == nil {
+		return fmt.Errorf("reportConfig %d is not supported", ie.ReportConfig.Choice)
+	}
+	reportType := &ie.ReportConfig.ReportConfigNR.ReportType
+	if reportType.Choice != rrcies.ReportConfigNR_reportType_Choice_EventTriggered || reportType.EventTriggered == nil {
+		return fmt.Errorf("reportType %d is not supported", reportType.Choice)
+	}
+	if err = ie.ReportConfigId.Encode(w); err != nil {
+		return utils.WrapError("Encode ReportConfigId", err)
+	}
+	// reportConfigNR, reportConfigInterRAT, ...
+	if err = writeExtensibleChoice(w, ie.ReportConfig.Choice, 2); err != nil {
+		return utils.WrapError("Encode ReportConfig", err)
+	}
+	// periodical, eventTriggered, ...
+	if err = writeExtensibleChoice(w, reportType.Choice, 2); err != nil {
+		return utils.WrapError("Encode ReportType", err)
+	}
+	if err = encodeEventTriggerConfig(w, reportType.EventTriggered); err != nil {
+		return utils.WrapError("Encode EventTriggered", err)
+	}
+	return nil
+}
+
+func encodeEventTriggerConfig(w *aper.AperWriter, ie *rrcies.EventTriggerConfig) (err error) {
+	if ie.MeasRSSI_ReportConfig_r16 != nil || ie.UseT312_r16 != nil || ie.IncludeCommonLocationInfo_r16 != nil ||
+		ie.IncludeBT_Meas_r16 != nil || ie.IncludeWLAN_Meas_r16 != nil || ie.IncludeSensor_Meas_r16 != nil ||
+		ie.CoarseLocationRequest_r17 != nil || ie.ReportQuantityRelay_r17 != nil {
+		return fmt.Errorf("extensions of EventTriggerConfig are not supported")
+	}
+	preambleBits := []bool{false, ie.ReportQuantityRS_Indexes != nil, ie.MaxNrofRS_IndexesToReport != nil, ie.ReportAddNeighMeas != nil}
+	for _, bit := range preambleBits {
+		if err = w.WriteBool(bit); err != nil {
+			return
+		}
+	}
+
+	// eventA1 to eventA6, ...
+	eventId := &ie.EventId
+	if err = writeExtensibleChoice(w, eventId.Choice, 6); err != nil {
+		return utils.WrapError("Encode EventId", err)
+	}
+	switch eventId.Choice {
+	case rrcies.EventTriggerConfig_eventId_Choice_EventA1:
+		err = eventId.EventA1.Encode(w)
+	case rrcies.EventTriggerConfig_eventId_Choice_EventA2:
+		err = eventId.EventA2.Encode(w)
+	case rrcies.EventTriggerConfig_eventId_Choice_EventA3:
+		err = eventId.EventA3.Encode(w)
+	case rrcies.EventTriggerConfig_eventId_Choice_EventA4:
+		err = eventId.EventA4.Encode(w)
+	case rrcies.EventTriggerConfig_eventId_Choice_EventA5:
+		err = eventId.EventA5.Encode(w)
+	case rrcies.EventTriggerConfig_eventId_Choice_EventA6:
+		err = eventId.EventA6.Encode(w)
+	default:
+		err = fmt.Errorf("eventId %d is not supported", eventId.Choice)
+	}
+	if err != nil {
+		return utils.WrapError("Encode EventId", err)
+	}
+
+	if err = ie.RsType.Encode(w); err != nil {
+		return utils.WrapError("Encode RsType", err)
+	}
+	if err = ie.ReportInterval.Encode(w); err != nil {
+		return utils.WrapError("Encode ReportInterval", err)
+	}
+	if err = ie.ReportAmount.Encode(w); err != nil {
+		return utils.WrapError("Encode ReportAmount", err)
+	}
+	if err = ie.ReportQuantityCell.Encode(w); err != nil {
+		return utils.WrapError("Encode ReportQuantityCell", err)
+	}
+	if err = w.WriteInteger(ie.MaxReportCells, &aper.Constraint{Lb: 1, Ub: maxCellReport}, false); err != nil {
+		return utils.WrapError("Encode MaxReportCells", err)
+	}
+	if ie.ReportQuantityRS_Indexes != nil {
+		if err = ie.ReportQuantityRS_Indexes.Encode(w); err != nil {
+			return utils.WrapError("Encode ReportQuantityRS_Indexes", err)
+		}
+	}
+	if ie.MaxNrofRS_IndexesToReport != nil {
+		if err = w.WriteInteger(*ie.MaxNrofRS_IndexesToReport, &aper.Constraint{Lb: 1, Ub: maxNrofIndexesToReport}, false); err != nil {
+			return utils.WrapError("Encode MaxNrofRS_IndexesToReport", err)
+		}
+	}
+	if err = w.WriteBool(ie.IncludeBeamMeasurements); err != nil {
+		return utils.WrapError("Encode IncludeBeamMeasurements", err)
+	}
+	if ie.ReportAddNeighMeas != nil {
+		if err = ie.ReportAddNeighMeas.Encode(w); err != nil {
+			return utils.WrapError("Encode ReportAddNeighMeas", err)
+		}
+	}
+	return nil
+}
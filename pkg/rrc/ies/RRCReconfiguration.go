@@ -0,0 +1,72 @@
+package ies
+
+import (
+	"github.com/lvdund/asn1go/aper"
+	rrcies "github.com/lvdund/rrc/ies"
+	"github.com/reogac/utils"
+)
+
+// RRCReconfiguration is a DL-DCCH-Message with an RRCReconfiguration, its
+// measConfig encoded as a MeasConfig of this package
+type RRCReconfiguration struct {
+	TransactionId uint64
+	IEs           *rrcies.RRCReconfiguration_IEs
+}
+
+func (msg *RRCReconfiguration) Encode(w *aper.AperWriter) (err error) {
+	if err = writeDlDcchHeader(w, rrcies.DL_DCCH_MessageType_C1_Choice_RrcReconfiguration, msg.TransactionId); err != nil {
+		return utils.WrapError("Encode RRCReconfiguration", err)
+	}
+	ie := msg.IEs
+	preambleBits := []bool{ie.RadioBearerConfig != nil, ie.SecondaryCellGroup != nil, ie.MeasConfig != nil,
+		ie.LateNonCriticalExtension != nil, ie.NonCriticalExtension != nil}
+	for _, bit := range preambleBits {
+		if err = w.WriteBool(bit); err != nil {
+			return
+		}
+	}
+	if ie.RadioBearerConfig != nil {
+		if err = ie.RadioBearerConfig.Encode(w); err != nil {
+			return utils.WrapError("Encode RadioBearerConfig", err)
+		}
+	}
+	if ie.SecondaryCellGroup != nil {
+		if err = w.WriteOctetString(*ie.SecondaryCellGroup, nil, false); err != nil {
+			return utils.WrapError("Encode SecondaryCellGroup", err)
+		}
+	}
+	if ie.MeasConfig != nil {
+		measConfig := MeasConfig{ie.MeasConfig}
+		if err = measConfig.Encode(w); err != nil {
+			return utils.WrapError("Encode MeasConfig", err)
+		}
+	}
+	if ie.LateNonCriticalExtension != nil {
+		if err = w.WriteOctetString(*ie.LateNonCriticalExtension, nil, false); err != nil {
+			return utils.WrapError("Encode LateNonCriticalExtension", err)
+		}
+	}
+	if ie.NonCriticalExtension != nil {
+		if err = ie.NonCriticalExtension.Encode(w); err != nil {
+			return utils.WrapError("Encode NonCriticalExtension", err)
+		}
+	}
+	return nil
+}
+
+// writeDlDcchHeader starts a DL-DCCH-Message with a c1 message whose
+// criticalExtensions carry the Release 15 IEs
+func writeDlDcchHeader(w *aper.AperWriter, c1Choice uint64, transactionId uint64) (err error) {
+	if err = w.WriteChoice(rrcies.DL_DCCH_MessageType_Choice_C1, 2, false); err != nil {
+		return
+	}
+	if err = w.WriteChoice(c1Choice, 16, false); err != nil {
+		return
+	}
+	tid := rrcies.RRC_TransactionIdentifier{Value: transactionId}
+	if err = tid.Encode(w); err != nil {
+		return
+	}
+	// the IEs or criticalExtensionsFuture
+	return w.WriteChoice(1, 2, false)
+}
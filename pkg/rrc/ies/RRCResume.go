@@ -0,0 +1,60 @@
+package ies
+
+import (
+	"github.com/lvdund/asn1go/aper"
+	rrcies "github.com/lvdund/rrc/ies"
+	"github.com/reogac/utils"
+)
+
+// RRCResume is a DL-DCCH-Message with an RRCResume, its measConfig encoded
+// as a MeasConfig of this package
+type RRCResume struct {
+	TransactionId uint64
+	IEs           *rrcies.RRCResume_IEs
+}
+
+func (msg *RRCResume) Encode(w *aper.AperWriter) (err error) {
+	if err = writeDlDcchHeader(w, rrcies.DL_DCCH_MessageType_C1_Choice_RrcResume, msg.TransactionId); err != nil {
+		return utils.WrapError("Encode RRCResume", err)
+	}
+	ie := msg.IEs
+	preambleBits := []bool{ie.RadioBearerConfig != nil, ie.MasterCellGroup != nil, ie.MeasConfig != nil,
+		ie.FullConfig != nil, ie.LateNonCriticalExtension != nil, ie.NonCriticalExtension != nil}
+	for _, bit := range preambleBits {
+		if err = w.WriteBool(bit); err != nil {
+			return
+		}
+	}
+	if ie.RadioBearerConfig != nil {
+		if err = ie.RadioBearerConfig.Encode(w); err != nil {
+			return utils.WrapError("Encode RadioBearerConfig", err)
+		}
+	}
+	if ie.MasterCellGroup != nil {
+		if err = w.WriteOctetString(*ie.MasterCellGroup, nil, false); err != nil {
+			return utils.WrapError("Encode MasterCellGroup", err)
+		}
+	}
+	if ie.MeasConfig != nil {
+		measConfig := MeasConfig{ie.MeasConfig}
+		if err = measConfig.Encode(w); err != nil {
+			return utils.WrapError("Encode MeasConfig", err)
+		}
+	}
+	if ie.FullConfig != nil {
+		if err = ie.FullConfig.Encode(w); err != nil {
+			return utils.WrapError("Encode FullConfig", err)
+		}
+	}
+	if ie.LateNonCriticalExtension != nil {
+		if err = w.WriteOctetString(*ie.LateNonCriticalExtension, nil, false); err != nil {
+			return utils.WrapError("Encode LateNonCriticalExtension", err)
+		}
+	}
+	if ie.NonCriticalExtension != nil {
+		if err = ie.NonCriticalExtension.Encode(w); err != nil {
+			return utils.WrapError("Encode NonCriticalExtension", err)
+		}
+	}
+	return nil
+}
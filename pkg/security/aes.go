@@ -0,0 +1,98 @@
+package security
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/binary"
+)
+
+// nea2Keystream runs AES in counter mode from COUNT | BEARER | DIRECTION
+func nea2Keystream(key []byte, count uint32, bearer, direction uint8, size int) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	iv := make([]byte, aes.BlockSize)
+	binary.BigEndian.PutUint32(iv, count)
+	iv[4] = bearer<<3 | (direction&1)<<2
+
+	keystream := make([]byte, size)
+	cipher.NewCTR(block, iv).XORKeyStream(keystream, keystream)
+	return keystream, nil
+}
+
+// nia2 is AES-CMAC over COUNT | BEARER | DIRECTION | 0^26 | MESSAGE, the
+// message being bits long
+func nia2(key []byte, count uint32, bearer, direction uint8, msg []byte, bits uint64) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	input := make([]byte, 8, 8+len(msg))
+	binary.BigEndian.PutUint32(input, count)
+	input[4] = bearer<<3 | (direction&1)<<2
+	input = append(input, msg...)
+
+	mac := cmac(block, input, bits+64)
+	return mac[:MacSize], nil
+}
+
+// cmac is the AES-CMAC of RFC 4493 extended to messages that end within a byte
+func cmac(block cipher.Block, msg []byte, bits uint64) []byte {
+	l := make([]byte, aes.BlockSize)
+	block.Encrypt(l, l)
+	k1 := gfDouble(l)
+	k2 := gfDouble(k1)
+
+	blocks := int((bits + 127) / 128)
+	complete := blocks > 0 && bits%128 == 0
+	if blocks == 0 {
+		blocks = 1
+	}
+
+	last := make([]byte, aes.BlockSize)
+	tail := (blocks - 1) * aes.BlockSize
+	if complete {
+		copy(last, msg[tail:tail+aes.BlockSize])
+		xorBlock(last, k1)
+	} else {
+		rem := bits - uint64(tail)*8
+		copy(last, msg[tail:tail+int(rem+7)/8])
+		if rem%8 != 0 {
+			last[rem/8] &= 0xff << (8 - rem%8)
+		}
+		last[rem/8] |= 0x80 >> (rem % 8)
+		xorBlock(last, k2)
+	}
+
+	x := make([]byte, aes.BlockSize)
+	for i := 0; i < blocks-1; i++ {
+		xorBlock(x, msg[i*aes.BlockSize:(i+1)*aes.BlockSize])
+		block.Encrypt(x, x)
+	}
+	xorBlock(x, last)
+	block.Encrypt(x, x)
+	return x
+}
+
+// gfDouble multiplies by x in GF(2^128)
+func gfDouble(in []byte) []byte {
+	out := make([]byte, len(in))
+	var carry byte
+	for i := len(in) - 1; i >= 0; i-- {
+		out[i] = in[i]<<1 | carry
+		carry = in[i] >> 7
+	}
+	if carry != 0 {
+		out[len(out)-1] ^= 0x87
+	}
+	return out
+}
+
+func xorBlock(dst, src []byte) {
+	for i := range dst {
+		dst[i] ^= src[i]
+	}
+}
@@ -0,0 +1,87 @@
+// Package security implements the 5G AS confidentiality (NEA) and integrity
+// (NIA) algorithms of TS 33.501 Annex D, with the inputs of TS 33.401 Annex B.
+package security
+
+import (
+	"fmt"
+)
+
+// Algorithm identities, as signalled in the Security Mode Command
+const (
+	NEA0 uint8 = iota
+	NEA1       // SNOW 3G
+	NEA2       // AES-CTR
+	NEA3       // ZUC
+)
+
+const (
+	NIA0 uint8 = iota
+	NIA1       // SNOW 3G
+	NIA2       // AES-CMAC
+	NIA3       // ZUC
+)
+
+// Transmission directions of the DIRECTION input
+const (
+	DirectionUplink   uint8 = 0
+	DirectionDownlink uint8 = 1
+)
+
+// MacSize is the length of the MAC-I in bytes
+const MacSize = 4
+
+// Cipher encrypts or decrypts data, both being the same keystream XOR. The
+// result is a new slice.
+func Cipher(alg uint8, key []byte, count uint32, bearer, direction uint8, data []byte) ([]byte, error) {
+	out := make([]byte, len(data))
+	copy(out, data)
+	if alg == NEA0 {
+		return out, nil
+	}
+	if len(key) != 16 {
+		return nil, fmt.Errorf("NEA%d needs a 128-bit key, got %d bytes", alg, len(key))
+	}
+
+	var keystream []byte
+	var err error
+	switch alg {
+	case NEA1:
+		keystream = nea1Keystream(key, count, bearer, direction, len(out))
+	case NEA2:
+		keystream, err = nea2Keystream(key, count, bearer, direction, len(out))
+	case NEA3:
+		keystream = nea3Keystream(key, count, bearer, direction, len(out))
+	default:
+		return nil, fmt.Errorf("unknown ciphering algorithm NEA%d", alg)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range out {
+		out[i] ^= keystream[i]
+	}
+	return out, nil
+}
+
+// Mac computes the MAC-I of msg
+func Mac(alg uint8, key []byte, count uint32, bearer, direction uint8, msg []byte) ([]byte, error) {
+	if alg == NIA0 {
+		return make([]byte, MacSize), nil
+	}
+	if len(key) != 16 {
+		return nil, fmt.Errorf("NIA%d needs a 128-bit key, got %d bytes", alg, len(key))
+	}
+
+	bits := uint64(len(msg)) * 8
+	switch alg {
+	case NIA1:
+		return nia1(key, count, bearer, direction, msg, bits), nil
+	case NIA2:
+		return nia2(key, count, bearer, direction, msg, bits)
+	case NIA3:
+		return nia3(key, count, bearer, direction, msg, bits), nil
+	default:
+		return nil, fmt.Errorf("unknown integrity algorithm NIA%d", alg)
+	}
+}
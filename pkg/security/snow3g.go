@@ -0,0 +1,206 @@
+package security
+
+import (
+	"encoding/binary"
+)
+
+// SNOW 3G as specified in the ETSI/SAGE UEA2 & UIA2 Document 2
+
+// sr is the Rijndael S-box, sq the Dickson polynomial based S-box
+var sr, sq [256]byte
+
+func init() {
+	// Rijndael: multiplicative inverse in GF(2^8) mod x^8+x^4+x^3+x+1 followed
+	// by the affine transformation
+	for x := 0; x < 256; x++ {
+		inv := byte(0)
+		if x != 0 {
+			inv = gfPow(byte(x), 254, 0x1b)
+		}
+		b := inv
+		for i := 1; i <= 4; i++ {
+			b ^= inv<<i | inv>>(8-i)
+		}
+		sr[x] = b ^ 0x63
+	}
+
+	// SQ(x) = g49(x) ^ 0x25 in GF(2^8) mod x^8+x^6+x^5+x^3+1
+	for x := 0; x < 256; x++ {
+		var y byte
+		for _, e := range []int{1, 9, 13, 15, 33, 41, 45, 47, 49} {
+			y ^= gfPow(byte(x), e, 0x69)
+		}
+		sq[x] = y ^ 0x25
+	}
+}
+
+// gfMul multiplies in GF(2^8), poly being the reduction polynomial without
+// its x^8 term
+func gfMul(a, b, poly byte) byte {
+	var p byte
+	for b != 0 {
+		if b&1 != 0 {
+			p ^= a
+		}
+		a = mulx(a, poly)
+		b >>= 1
+	}
+	return p
+}
+
+func gfPow(a byte, e int, poly byte) byte {
+	r := byte(1)
+	for ; e > 0; e-- {
+		r = gfMul(r, a, poly)
+	}
+	return r
+}
+
+func mulx(v, c byte) byte {
+	if v&0x80 != 0 {
+		return v<<1 ^ c
+	}
+	return v << 1
+}
+
+func mulxPow(v byte, i int, c byte) byte {
+	for ; i > 0; i-- {
+		v = mulx(v, c)
+	}
+	return v
+}
+
+func mulAlpha(c byte) uint32 {
+	return uint32(mulxPow(c, 23, 0xa9))<<24 | uint32(mulxPow(c, 245, 0xa9))<<16 |
+		uint32(mulxPow(c, 48, 0xa9))<<8 | uint32(mulxPow(c, 239, 0xa9))
+}
+
+func divAlpha(c byte) uint32 {
+	return uint32(mulxPow(c, 16, 0xa9))<<24 | uint32(mulxPow(c, 39, 0xa9))<<16 |
+		uint32(mulxPow(c, 6, 0xa9))<<8 | uint32(mulxPow(c, 64, 0xa9))
+}
+
+// snowBox applies an S-box to every byte of w followed by the column mixing
+// over the given field
+func snowBox(w uint32, box *[256]byte, c byte) uint32 {
+	w0, w1, w2, w3 := box[w>>24], box[w>>16&0xff], box[w>>8&0xff], box[w&0xff]
+	r0 := mulx(w0, c) ^ w1 ^ w2 ^ mulx(w3, c) ^ w3
+	r1 := mulx(w0, c) ^ w0 ^ mulx(w1, c) ^ w2 ^ w3
+	r2 := w0 ^ mulx(w1, c) ^ w1 ^ mulx(w2, c) ^ w3
+	r3 := w0 ^ w1 ^ mulx(w2, c) ^ w2 ^ mulx(w3, c)
+	return uint32(r0)<<24 | uint32(r1)<<16 | uint32(r2)<<8 | uint32(r3)
+}
+
+type snow3g struct {
+	s          [16]uint32
+	r1, r2, r3 uint32
+}
+
+func newSnow3g(k, iv [4]uint32) *snow3g {
+	g := &snow3g{}
+	g.s = [16]uint32{
+		k[0] ^ 0xffffffff, k[1] ^ 0xffffffff, k[2] ^ 0xffffffff, k[3] ^ 0xffffffff,
+		k[0], k[1], k[2], k[3],
+		k[0] ^ 0xffffffff, k[1] ^ 0xffffffff ^ iv[3], k[2] ^ 0xffffffff ^ iv[2], k[3] ^ 0xffffffff,
+		k[0] ^ iv[1], k[1], k[2], k[3] ^ iv[0],
+	}
+	for i := 0; i < 32; i++ {
+		g.clockLfsr(g.clockFsm())
+	}
+	g.clockFsm()
+	g.clockLfsr(0)
+	return g
+}
+
+func (g *snow3g) clockLfsr(f uint32) {
+	s := &g.s
+	v := s[0]<<8 ^ mulAlpha(byte(s[0]>>24)) ^ s[2] ^ s[11]>>8 ^ divAlpha(byte(s[11])) ^ f
+	copy(s[:15], s[1:])
+	s[15] = v
+}
+
+func (g *snow3g) clockFsm() uint32 {
+	f := (g.s[15] + g.r1) ^ g.r2
+	r := g.r2 + (g.r3 ^ g.s[5])
+	g.r3 = snowBox(g.r2, &sq, 0x69)
+	g.r2 = snowBox(g.r1, &sr, 0x1b)
+	g.r1 = r
+	return f
+}
+
+func (g *snow3g) keystream(words int) []uint32 {
+	z := make([]uint32, words)
+	for i := range z {
+		z[i] = g.clockFsm() ^ g.s[0]
+		g.clockLfsr(0)
+	}
+	return z
+}
+
+// snowKey loads the key with its most significant word in k[3]
+func snowKey(key []byte) [4]uint32 {
+	return [4]uint32{
+		binary.BigEndian.Uint32(key[12:]),
+		binary.BigEndian.Uint32(key[8:]),
+		binary.BigEndian.Uint32(key[4:]),
+		binary.BigEndian.Uint32(key[0:]),
+	}
+}
+
+// nea1Keystream is the f8 function with BEARER and DIRECTION in the upper
+// bits of the IV
+func nea1Keystream(key []byte, count uint32, bearer, direction uint8, size int) []byte {
+	fresh := uint32(bearer)<<27 | uint32(direction&1)<<26
+	g := newSnow3g(snowKey(key), [4]uint32{fresh, count, fresh, count})
+
+	keystream := make([]byte, (size+3)/4*4)
+	for i, z := range g.keystream(len(keystream) / 4) {
+		binary.BigEndian.PutUint32(keystream[4*i:], z)
+	}
+	return keystream[:size]
+}
+
+// nia1 is the f9 function with FRESH set to BEARER | 0^27
+func nia1(key []byte, count uint32, bearer, direction uint8, msg []byte, bits uint64) []byte {
+	fresh := uint32(bearer) << 27
+	dir := uint32(direction & 1)
+	g := newSnow3g(snowKey(key), [4]uint32{fresh ^ dir<<15, count ^ dir<<31, fresh, count})
+	z := g.keystream(5)
+	p := uint64(z[0])<<32 | uint64(z[1])
+	q := uint64(z[2])<<32 | uint64(z[3])
+
+	var eval uint64
+	for i := uint64(0); i < (bits+63)/64; i++ {
+		var m uint64
+		for j := uint64(0); j < 8; j++ {
+			if n := i*8 + j; n < uint64(len(msg)) {
+				m |= uint64(msg[n]) << (56 - 8*j)
+			}
+		}
+		if rem := bits - i*64; rem < 64 {
+			m &= ^uint64(0) << (64 - rem)
+		}
+		eval = mul64(eval^m, p)
+	}
+	eval = mul64(eval^bits, q)
+
+	mac := make([]byte, MacSize)
+	binary.BigEndian.PutUint32(mac, uint32(eval>>32)^z[4])
+	return mac
+}
+
+// mul64 multiplies in GF(2^64) mod x^64+x^4+x^3+x+1
+func mul64(v, p uint64) uint64 {
+	var r uint64
+	for i := 0; i < 64; i++ {
+		if p>>i&1 != 0 {
+			r ^= v
+		}
+		if v>>63 != 0 {
+			v = v<<1 ^ 0x1b
+		} else {
+			v <<= 1
+		}
+	}
+	return r
+}